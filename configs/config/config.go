@@ -5,7 +5,14 @@ package config
 // 仅修改包声明为config
 import (
 	"fmt"
+	"gin-center/pkg/health"
+	"gin-center/pkg/http/clientip"
+	"gin-center/pkg/security/useCookie"
 	"gin-center/pkg/security/useJwt"
+	"gin-center/pkg/security/useOAuth"
+	"gin-center/pkg/security/usePassword"
+	"gin-center/pkg/storage/useStorage"
+	"gin-center/pkg/tracer"
 
 	"log"
 	"os"
@@ -27,6 +34,9 @@ var (
 	once sync.Once
 	// validate 用于配置验证
 	validate *validator.Validate
+	// listeners 配置文件变更回调
+	listeners   []func(*GlobalConfig)
+	listenersMu sync.Mutex
 )
 
 // BaseConfig 定义基础配置项
@@ -117,6 +127,133 @@ type LogConfig struct {
 	MaxAge int `mapstructure:"max_age" validate:"required"`
 	// Compress 是否压缩历史日志
 	Compress bool `mapstructure:"compress"`
+	// RotateInterval 按时间切割日志文件的间隔，为0时仅按大小切割
+	RotateInterval time.Duration `mapstructure:"rotate_interval"`
+	// Format 日志格式，可选值：json/console，默认json
+	Format string `mapstructure:"format" validate:"omitempty,oneof=json console"`
+	// Outputs 日志输出，未配置时仅输出到Filename
+	Outputs []LogOutputConfig `mapstructure:"outputs" validate:"dive"`
+	// Request 访问日志配置
+	Request RequestLogConfig `mapstructure:"request"`
+}
+
+// LogOutputConfig 单个日志输出的配置
+type LogOutputConfig struct {
+	// Type 输出类型，可选值：file/stdout/stderr/syslog
+	Type string `mapstructure:"type" validate:"required,oneof=file stdout stderr syslog"`
+	// Level 该输出的最低级别，为空时与全局级别一致；低于全局级别的日志同样不会输出
+	Level string `mapstructure:"level" validate:"omitempty,oneof=debug info warn error"`
+	// Format 该输出的日志格式，为空时使用全局格式
+	Format string `mapstructure:"format" validate:"omitempty,oneof=json console"`
+	// Filename 文件路径，仅file类型有效，为空时使用全局Filename，按全局的大小和时间规则切割
+	Filename string `mapstructure:"filename"`
+	// Network syslog网络类型，如udp/tcp，为空时连接本机syslog
+	Network string `mapstructure:"network"`
+	// Address syslog服务地址
+	Address string `mapstructure:"address"`
+	// Tag syslog标识，为空时使用应用名
+	Tag string `mapstructure:"tag"`
+}
+
+// RequestLogConfig 访问日志配置
+type RequestLogConfig struct {
+	// SkipPaths 不记录访问日志的路径，以*结尾时按前缀匹配
+	SkipPaths []string `mapstructure:"skip_paths"`
+	// MaxBodySize 记录请求体与响应体的最大字节数，超出时不记录内容
+	MaxBodySize int64 `mapstructure:"max_body_size"`
+	// LogHeaders 是否记录请求头
+	LogHeaders bool `mapstructure:"log_headers"`
+	// LogBody 是否记录JSON和表单格式的请求体
+	LogBody bool `mapstructure:"log_body"`
+	// LogResponse 是否记录JSON格式的响应体
+	LogResponse bool `mapstructure:"log_response"`
+	// SensitiveLog 为true时不脱敏密码、令牌等敏感字段，仅用于本地调试
+	SensitiveLog bool `mapstructure:"sensitive_log"`
+}
+
+// OAuthServerConfig 授权服务配置
+type OAuthServerConfig struct {
+	// CodeTTL 授权码有效期
+	CodeTTL time.Duration `mapstructure:"code_ttl"`
+}
+
+// UploadConfig 分片上传配置，大小单位均为字节
+type UploadConfig struct {
+	// MaxFileSize 单个文件大小上限
+	MaxFileSize int64 `mapstructure:"max_file_size" validate:"gt=0"`
+	// ChunkSize 分片大小，除最后一个分片外每个分片均为该大小
+	ChunkSize int64 `mapstructure:"chunk_size" validate:"gt=0"`
+	// UserQuota 每个用户的存储配额，包含进行中的上传任务
+	UserQuota int64 `mapstructure:"user_quota" validate:"gt=0"`
+	// MaxPending 每个用户同时进行的上传任务数上限
+	MaxPending int `mapstructure:"max_pending" validate:"gt=0"`
+	// TTL 上传任务有效期，每次收到分片后顺延
+	TTL time.Duration `mapstructure:"ttl" validate:"gt=0"`
+	// CleanupInterval 清理过期上传任务分片的间隔，为0时不清理
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval" validate:"gte=0"`
+}
+
+// MetricsConfig Prometheus指标配置
+type MetricsConfig struct {
+	// Enabled 是否采集并暴露指标
+	Enabled bool `mapstructure:"enabled"`
+	// Path 指标端点路径，默认/metrics
+	Path string `mapstructure:"path"`
+	// Token 抓取指标时需携带的Bearer令牌，为空时不校验
+	Token string `mapstructure:"token"`
+}
+
+// IPFilterConfig 按路由组限制客户端IP与国家/地区
+// 配置中的规则与管理接口添加的规则合并生效，配置中的规则不能通过接口删除
+type IPFilterConfig struct {
+	// Enabled 是否启用访问控制
+	Enabled bool `mapstructure:"enabled"`
+	// GeoDatabase MaxMind格式（.mmdb）的国家或城市库，为空时不能使用国家规则
+	GeoDatabase string `mapstructure:"geo_database"`
+	// ReloadInterval 从Redis全量刷新规则的间隔，用于补偿丢失的变更通知，默认1分钟
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+	// Groups 各路由组的规则，键为路由组名称，目前支持admin
+	Groups map[string]IPFilterRules `mapstructure:"groups"`
+}
+
+// IPFilterRules 路由组的访问规则，先匹配禁止规则；存在允许规则时，只有命中允许规则的地址可以访问
+type IPFilterRules struct {
+	// Allow 允许的IP或CIDR
+	Allow []string `mapstructure:"allow"`
+	// Deny 禁止的IP或CIDR
+	Deny []string `mapstructure:"deny"`
+	// AllowCountries 允许的国家/地区，ISO 3166-1两位代码
+	AllowCountries []string `mapstructure:"allow_countries"`
+	// DenyCountries 禁止的国家/地区，ISO 3166-1两位代码
+	DenyCountries []string `mapstructure:"deny_countries"`
+}
+
+// SecurityConfig 浏览器相关的安全配置
+type SecurityConfig struct {
+	// Cookie 以HttpOnly Cookie下发令牌，供Web控制台使用
+	Cookie useCookie.Config `mapstructure:"cookie"`
+	// Headers 安全响应头
+	Headers SecurityHeadersConfig `mapstructure:"headers"`
+}
+
+// SecurityHeadersConfig 安全响应头配置，启用后总是发送X-Content-Type-Options: nosniff
+type SecurityHeadersConfig struct {
+	// Enabled 是否发送安全响应头
+	Enabled bool `mapstructure:"enabled"`
+	// HSTSMaxAge Strict-Transport-Security的有效期，为0时不发送；仅对HTTPS请求发送
+	HSTSMaxAge time.Duration `mapstructure:"hsts_max_age"`
+	// HSTSIncludeSubdomains 是否包含子域名
+	HSTSIncludeSubdomains bool `mapstructure:"hsts_include_subdomains"`
+	// HSTSPreload 是否申请加入浏览器预加载列表
+	HSTSPreload bool `mapstructure:"hsts_preload"`
+	// ContentSecurityPolicy 内容安全策略，为空时不发送
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	// CSPSkipPaths 不发送内容安全策略的路径前缀，如依赖内联脚本的/swagger/
+	CSPSkipPaths []string `mapstructure:"csp_skip_paths"`
+	// FrameOptions X-Frame-Options，DENY或SAMEORIGIN，为空时不发送
+	FrameOptions string `mapstructure:"frame_options" validate:"omitempty,oneof=DENY SAMEORIGIN"`
+	// ReferrerPolicy Referrer-Policy，为空时不发送
+	ReferrerPolicy string `mapstructure:"referrer_policy"`
 }
 
 // ServerConfig HTTP服务器配置
@@ -135,12 +272,94 @@ type ServerConfig struct {
 	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" default:"3s"`
 	// ShutdownTimeout 优雅关闭超时时间
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"30s"`
+	// ShutdownDelay 收到停止信号后、停止接收请求前的等待时间，留给负载均衡感知就绪检查失败
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
 	// MaxHeaderBytes 请求头最大字节数
 	MaxHeaderBytes int `mapstructure:"max_header_bytes" default:"1048576"`
-	// MaxConnsPerIP 每个IP的最大连接数
+	// Limits 请求处理时限与请求体大小限制
+	Limits RequestLimitsConfig `mapstructure:"limits"`
+	// MaxConnsPerIP 每个客户端IP同时保持的最大连接数，超出的连接直接关闭，为0时不限制
 	MaxConnsPerIP int `mapstructure:"max_conns_per_ip"`
-	// MaxRequestsPerConn 每个连接的最大请求数
+	// MaxRequestsPerConn 每个长连接处理的最大请求数，达到后响应完成即关闭连接，为0时不限制
 	MaxRequestsPerConn int `mapstructure:"max_requests_per_conn"`
+	// UnixSocket 不为空时监听该Unix域套接字而非Port，套接字文件权限为0660
+	UnixSocket string `mapstructure:"unix_socket"`
+	// ClientIP 可信代理与转发头配置，决定登录记录、审计与访问日志中的客户端IP
+	ClientIP clientip.Config `mapstructure:"client_ip"`
+	// ProxyProtocol PROXY协议配置，位于四层负载均衡之后时用于获取真实客户端地址
+	ProxyProtocol ProxyProtocolConfig `mapstructure:"proxy_protocol"`
+	// TLS HTTPS配置
+	TLS TLSConfig `mapstructure:"tls"`
+}
+
+// RequestLimitsConfig 请求处理时限与请求体大小限制，Routes中的路径前缀可单独设置
+type RequestLimitsConfig struct {
+	// Timeout 请求处理时限，传递到服务与数据库调用的context，为0时使用30s，为负数时不限制
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxBodySize 请求体最大字节数，超出时返回413，为0时使用1MB，为负数时不限制
+	MaxBodySize int64 `mapstructure:"max_body_size"`
+	// Routes 按路径前缀覆盖的限制，匹配最长的前缀
+	Routes []RouteLimitConfig `mapstructure:"routes" validate:"dive"`
+}
+
+// RouteLimitConfig 路径前缀的请求限制，为0的字段使用全局设置
+type RouteLimitConfig struct {
+	// Prefix 路径前缀，如/api/v1/uploads，按路径段匹配
+	Prefix string `mapstructure:"prefix" validate:"required,startswith=/"`
+	// Timeout 请求处理时限，为负数时不限制
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxBodySize 请求体最大字节数，为负数时不限制
+	MaxBodySize int64 `mapstructure:"max_body_size"`
+}
+
+// ProxyProtocolConfig PROXY协议（v1/v2）配置，启用后连接数限制与日志中的客户端IP均取自PROXY头
+type ProxyProtocolConfig struct {
+	// Enabled 是否解析PROXY头
+	Enabled bool `mapstructure:"enabled"`
+	// TrustedCIDRs 允许发送PROXY头的负载均衡地址段，监听TCP端口时必须配置；其他来源发送PROXY头时拒绝连接
+	TrustedCIDRs []string `mapstructure:"trusted_cidrs"`
+	// Required 来自可信地址的连接是否必须携带PROXY头
+	Required bool `mapstructure:"required"`
+	// ReadHeaderTimeout 等待PROXY头的超时时间，默认10秒
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+}
+
+// TLSConfig HTTPS配置，启用后Port上只接受TLS连接
+type TLSConfig struct {
+	// Enabled 是否启用HTTPS
+	Enabled bool `mapstructure:"enabled"`
+	// CertFile 证书文件，可包含中间证书
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile 私钥文件
+	KeyFile string `mapstructure:"key_file"`
+	// ReloadInterval 检查证书、私钥和客户端CA文件是否变化的间隔，默认30秒，变化后新连接使用新证书
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+	// MinVersion 最低TLS版本，可选值：1.2/1.3，默认1.2
+	MinVersion string `mapstructure:"min_version" validate:"omitempty,oneof=1.2 1.3"`
+	// CipherSuites TLS 1.2可用的密码套件名称，如TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256，为空时使用Go的默认安全套件；TLS 1.3的套件不可配置
+	CipherSuites []string `mapstructure:"cipher_suites"`
+	// DisableHTTP2 是否禁用HTTP/2，默认通过ALPN协商HTTP/2
+	DisableHTTP2 bool `mapstructure:"disable_http2"`
+	// ClientAuth 客户端证书校验方式，可选值：none/optional/require；optional时只校验客户端提供的证书
+	ClientAuth string `mapstructure:"client_auth" validate:"omitempty,oneof=none optional require"`
+	// ClientCAFile 校验客户端证书的CA证书文件，ClientAuth不为none时必填
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ServiceAccounts 客户端证书身份到服务账号的映射，内部服务可凭证书调用接口
+	ServiceAccounts []ServiceAccountConfig `mapstructure:"service_accounts" validate:"dive"`
+	// RedirectPort 大于0时在该端口监听HTTP，将请求永久重定向到HTTPS
+	RedirectPort int `mapstructure:"redirect_port"`
+}
+
+// ServiceAccountConfig 客户端证书映射的服务账号
+type ServiceAccountConfig struct {
+	// Identity 证书身份，与证书的URI、DNS、邮箱SAN或Subject CN任一项相同即匹配，如spiffe://example.org/billing
+	Identity string `mapstructure:"identity"`
+	// Role 账号类型，可选值：admin/user
+	Role string `mapstructure:"role" validate:"oneof=admin user"`
+	// Username 映射到的账号用户名，权限取该账号当前的权限
+	Username string `mapstructure:"username"`
+	// Scopes 授权范围，权限需同时在授权范围与账号权限内
+	Scopes []string `mapstructure:"scopes"`
 }
 
 // GlobalConfig 应用程序总配置结构
@@ -148,12 +367,22 @@ type GlobalConfig struct {
 	Logger *zap.Logger
 	mu     sync.RWMutex
 
-	App      AppConfig        `mapstructure:"app"`
-	Server   ServerConfig     `mapstructure:"server"`
-	Database DatabaseConfig   `mapstructure:"database"`
-	Redis    RedisConfig      `mapstructure:"redis"`
-	Log      LogConfig        `mapstructure:"log"`
-	JWT      useJwt.JWTConfig `mapstructure:"jwt"`
+	App         AppConfig          `mapstructure:"app"`
+	Server      ServerConfig       `mapstructure:"server"`
+	Database    DatabaseConfig     `mapstructure:"database"`
+	Redis       RedisConfig        `mapstructure:"redis"`
+	Log         LogConfig          `mapstructure:"log"`
+	JWT         useJwt.JWTConfig   `mapstructure:"jwt"`
+	Password    usePassword.Config `mapstructure:"password"`
+	OAuth       useOAuth.Config    `mapstructure:"oauth"`
+	OAuthServer OAuthServerConfig  `mapstructure:"oauth_server"`
+	Storage     useStorage.Config  `mapstructure:"storage"`
+	Upload      UploadConfig       `mapstructure:"upload"`
+	Metrics     MetricsConfig      `mapstructure:"metrics"`
+	Trace       tracer.Config      `mapstructure:"trace"`
+	Health      health.Config      `mapstructure:"health"`
+	IPFilter    IPFilterConfig     `mapstructure:"ip_filter"`
+	Security    SecurityConfig     `mapstructure:"security"`
 }
 
 // 调整AppConfig结构体映射方式
@@ -172,7 +401,6 @@ type AppConfig struct {
 func LoadConfig(configPath string) (*GlobalConfig, error) {
 	var err error
 	once.Do(func() {
-		instance = &GlobalConfig{}
 		validate = validator.New()
 
 		// 初始化viper配置
@@ -187,7 +415,7 @@ func LoadConfig(configPath string) (*GlobalConfig, error) {
 		}
 
 		// 解析并验证配置
-		if err = parseConfig(); err != nil {
+		if instance, err = parseConfig(); err != nil {
 			return
 		}
 
@@ -240,36 +468,52 @@ func loadEnvConfig(configPath string) error {
 }
 
 // parseConfig 解析并验证配置
-// 将配置文件内容解析到结构体中，并进行验证
-func parseConfig() error {
-	if err := viper.Unmarshal(instance); err != nil {
-		return fmt.Errorf("配置解析失败: %w", err)
+// 每次解析到新的结构体中，已交给各组件的配置不会被修改
+func parseConfig() (*GlobalConfig, error) {
+	cfg := &GlobalConfig{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("配置解析失败: %w", err)
 	}
 
-	if err := validate.Struct(instance); err != nil {
-		return fmt.Errorf("配置验证失败: %w", err)
+	if err := validate.Struct(cfg); err != nil {
+		return nil, fmt.Errorf("配置验证失败: %w", err)
 	}
-	return nil
+	return cfg, nil
 }
 
 // setupConfigWatcher 设置配置文件变更监听
-// 监听配置文件变更，自动重新加载配置
+// 配置文件变更后解析为新的配置并交给OnChange注册的回调，GetConfig返回的启动时配置保持不变，
+// 请求处理中无锁读取的配置项因此不会与重新加载产生数据竞争
 func setupConfigWatcher() {
 	viper.WatchConfig()
 	viper.OnConfigChange(func(e fsnotify.Event) {
-		instance.mu.Lock()
-		defer instance.mu.Unlock()
-
-		if err := parseConfig(); err != nil {
+		cfg, err := parseConfig()
+		if err != nil {
 			log.Printf("配置重新加载失败: %v\n", err)
 			return
 		}
 		log.Printf("配置已更新，文件: %s\n", e.Name)
+
+		listenersMu.Lock()
+		fns := append([]func(*GlobalConfig){}, listeners...)
+		listenersMu.Unlock()
+		for _, fn := range fns {
+			fn(cfg)
+		}
 	})
 }
 
+// OnChange 注册配置文件变更回调，配置重新解析并验证成功后按注册顺序执行
+// 回调收到的是新解析的配置，只有通过回调更新自身状态的组件（如跨域策略）会应用变更
+// 回调在监听文件变更的协程中执行，应尽快返回
+func OnChange(fn func(cfg *GlobalConfig)) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
 // GetConfig 获取配置实例
-// 返回值: 全局配置实例
+// 返回值: 启动时加载的全局配置实例，配置文件变更不会修改该实例，需要跟随变更的组件使用OnChange
 func GetConfig() *GlobalConfig {
 	return instance
 }
@@ -281,12 +525,22 @@ func (c *GlobalConfig) GetConfigByKey(key string) (interface{}, bool) {
 	defer c.mu.RUnlock()
 
 	configs := map[string]any{
-		"app":      &c.App,
-		"server":   &c.Server,
-		"database": &c.Database,
-		"redis":    &c.Redis,
-		"log":      &c.Log,
-		"jwt":      &c.JWT,
+		"app":          &c.App,
+		"server":       &c.Server,
+		"database":     &c.Database,
+		"redis":        &c.Redis,
+		"log":          &c.Log,
+		"jwt":          &c.JWT,
+		"password":     &c.Password,
+		"oauth":        &c.OAuth,
+		"oauth_server": &c.OAuthServer,
+		"storage":      &c.Storage,
+		"upload":       &c.Upload,
+		"metrics":      &c.Metrics,
+		"trace":        &c.Trace,
+		"health":       &c.Health,
+		"ip_filter":    &c.IPFilter,
+		"security":     &c.Security,
 	}
 
 	config, exists := configs[key]
@@ -305,11 +559,29 @@ func (c *GlobalConfig) IsProduction() bool {
 	return c.App.Env == "production"
 }
 
+// CORSConfig 跨域配置，顶层字段为默认策略，Groups中的路由组使用各自的策略
+// 配置文件变更后重新加载
 type CORSConfig struct {
-	AllowOrigins     []string `mapstructure:"allow_origins"`
-	AllowMethods     []string `mapstructure:"allow_methods"`
-	AllowHeaders     []string `mapstructure:"allow_headers"`
-	ExposeHeaders    []string `mapstructure:"expose_headers"`
-	AllowCredentials bool     `mapstructure:"allow_credentials"`
-	MaxAge           int      `mapstructure:"max_age"`
+	// Enabled 是否处理跨域请求，未启用时只能同源访问
+	Enabled    bool `mapstructure:"enabled"`
+	CORSPolicy `mapstructure:",squash"`
+	// Groups 各路由组的策略，键为路由组名称，目前支持auth与admin；未配置的路由组使用默认策略
+	Groups map[string]CORSPolicy `mapstructure:"groups"`
+}
+
+// CORSPolicy 跨域策略
+type CORSPolicy struct {
+	// AllowOrigins 允许的来源，支持完整来源、*、通配子域名（https://*.example.com）与regex:开头的正则表达式
+	// 为空时该策略不允许跨域访问；允许携带凭据时不能使用*
+	AllowOrigins []string `mapstructure:"allow_origins"`
+	// AllowMethods 允许的方法，为空时允许GET、POST、PUT、PATCH、DELETE、HEAD
+	AllowMethods []string `mapstructure:"allow_methods"`
+	// AllowHeaders 允许的请求头，为空时允许认证、CSRF与请求ID等本服务使用的请求头
+	AllowHeaders []string `mapstructure:"allow_headers"`
+	// ExposeHeaders 允许脚本读取的响应头
+	ExposeHeaders []string `mapstructure:"expose_headers"`
+	// AllowCredentials 是否允许携带Cookie等凭据
+	AllowCredentials bool `mapstructure:"allow_credentials"`
+	// MaxAge 预检结果的缓存时间，单位秒
+	MaxAge int `mapstructure:"max_age" validate:"gte=0"`
 }
@@ -2,7 +2,7 @@
 CREATE TABLE IF NOT EXISTS `sys_users` (
     `id` char(36) NOT NULL,
     `username` varchar(32) NOT NULL COMMENT '用户名',
-    `password` char(60) NOT NULL COMMENT '密码',
+    `password` varchar(255) NOT NULL COMMENT '密码哈希(PHC格式)',
     `nickname` varchar(32) DEFAULT NULL COMMENT '昵称',
     `avatar` varchar(255) DEFAULT NULL COMMENT '头像',
     `is_admin` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否管理员 0:否 1:是',
@@ -20,7 +20,7 @@ CREATE TABLE IF NOT EXISTS `sys_users` (
 CREATE TABLE IF NOT EXISTS `normal_users` (
     `id` char(36) NOT NULL,
     `username` varchar(32) NOT NULL COMMENT '用户名',
-    `password` char(60) NOT NULL COMMENT '密码',
+    `password` varchar(255) NOT NULL COMMENT '密码哈希(PHC格式)',
     `nickname` varchar(32) DEFAULT NULL COMMENT '昵称',
     `avatar` varchar(255) DEFAULT NULL COMMENT '头像',
     `phone` char(11) DEFAULT NULL COMMENT '手机号',
@@ -63,10 +63,7 @@ CREATE TABLE IF NOT EXISTS `user_permissions` (
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_user_perm` (`user_id`, `user_type`, `permission_id`),
     KEY `idx_permission` (`permission_id`),
-    CONSTRAINT `fk_up_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
-    CONSTRAINT `fk_up_sys_user` FOREIGN KEY (`user_id`) REFERENCES `sys_users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
-    CONSTRAINT `fk_up_normal_user` FOREIGN KEY (`user_id`) REFERENCES `normal_users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
-    CONSTRAINT `fk_up_operator` FOREIGN KEY (`operator_id`) REFERENCES `sys_users` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
+    CONSTRAINT `fk_up_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '用户权限关联表';
 
 -- 角色表
@@ -123,10 +120,109 @@ CREATE TABLE IF NOT EXISTS `operation_logs` (
     `params` text COMMENT '请求参数',
     `ip` varchar(39) DEFAULT NULL COMMENT '操作IP',
     `status` int(11) NOT NULL COMMENT '操作状态',
+    `actor_id` varchar(36) NOT NULL DEFAULT '' COMMENT '模拟登录时实际操作的管理员ID',
+    `actor_name` varchar(32) NOT NULL DEFAULT '' COMMENT '模拟登录时实际操作的管理员用户名',
     `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
     KEY `idx_user_created` (`user_id`, `user_type`, `created_at`),
     KEY `idx_operation` (`operation`),
-    CONSTRAINT `fk_ol_sys_user` FOREIGN KEY (`user_id`) REFERENCES `sys_users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
-    CONSTRAINT `fk_ol_normal_user` FOREIGN KEY (`user_id`) REFERENCES `normal_users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+    KEY `idx_actor_created` (`actor_id`, `created_at`)
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '操作日志表';
+
+-- 登录历史表
+CREATE TABLE IF NOT EXISTS `login_history` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL DEFAULT '0' COMMENT '用户ID，用户不存在时为0',
+    `user_type` tinyint(1) NOT NULL COMMENT '用户类型 0:普通用户 1:管理员',
+    `username` varchar(64) NOT NULL COMMENT '登录时提交的用户名',
+    `success` tinyint(1) NOT NULL COMMENT '是否成功 0:失败 1:成功',
+    `reason` varchar(32) NOT NULL DEFAULT '' COMMENT '失败原因',
+    `ip` varchar(39) DEFAULT NULL COMMENT '登录IP',
+    `user_agent` varchar(512) DEFAULT NULL COMMENT '客户端User-Agent',
+    `device` varchar(64) DEFAULT NULL COMMENT '设备',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_user_created` (`user_id`, `user_type`, `created_at`),
+    KEY `idx_ip_created` (`ip`, `created_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '登录历史表';
+
+CREATE TABLE IF NOT EXISTS `user_identities` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL COMMENT '普通用户ID',
+    `provider` varchar(32) NOT NULL COMMENT '第三方登录提供方',
+    `subject` varchar(255) NOT NULL COMMENT '提供方用户唯一标识',
+    `email` varchar(255) DEFAULT NULL COMMENT '提供方返回的邮箱',
+    `display_name` varchar(128) DEFAULT NULL COMMENT '提供方返回的显示名称',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_provider_subject` (`provider`, `subject`),
+    UNIQUE KEY `uk_user_provider` (`user_id`, `provider`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '第三方身份关联表';
+
+CREATE TABLE IF NOT EXISTS `oauth_clients` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `client_id` varchar(64) NOT NULL COMMENT '客户端ID',
+    `secret_hash` char(64) NOT NULL DEFAULT '' COMMENT '客户端密钥SHA-256摘要，公开客户端为空',
+    `name` varchar(64) NOT NULL COMMENT '应用名称',
+    `redirect_uris` text COMMENT '回调地址，空格分隔',
+    `grant_types` varchar(64) NOT NULL COMMENT '允许的授权模式，空格分隔',
+    `scopes` varchar(255) NOT NULL DEFAULT '' COMMENT '允许的授权范围，空格分隔',
+    `confidential` tinyint(1) NOT NULL DEFAULT '1' COMMENT '客户端类型 0:公开 1:机密',
+    `status` tinyint(1) NOT NULL DEFAULT '1' COMMENT '状态 0:禁用 1:启用',
+    `created_by` bigint unsigned NOT NULL DEFAULT '0' COMMENT '创建者管理员ID',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_client_id` (`client_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = 'OAuth2客户端表';
+
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL COMMENT '所有者ID',
+    `user_type` tinyint(1) NOT NULL COMMENT '用户类型 0:普通用户 1:管理员',
+    `type` tinyint(1) NOT NULL COMMENT '类型 1:API密钥 2:个人访问令牌',
+    `name` varchar(64) NOT NULL COMMENT '名称',
+    `prefix` varchar(16) NOT NULL COMMENT '明文前缀，用于识别密钥',
+    `key_hash` char(64) NOT NULL COMMENT '明文SHA-256摘要',
+    `scopes` varchar(512) NOT NULL DEFAULT '' COMMENT '授权的权限编码，空格分隔',
+    `expires_at` datetime DEFAULT NULL COMMENT '过期时间',
+    `last_used_at` datetime DEFAULT NULL COMMENT '最近使用时间',
+    `last_used_ip` varchar(39) DEFAULT NULL COMMENT '最近使用IP',
+    `revoked_at` datetime DEFAULT NULL COMMENT '撤销时间',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_key_hash` (`key_hash`),
+    KEY `idx_user` (`user_id`, `user_type`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = 'API密钥表';
+
+CREATE TABLE IF NOT EXISTS `user_files` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL COMMENT '所有者ID',
+    `user_type` tinyint(1) NOT NULL COMMENT '用户类型 0:普通用户 1:管理员',
+    `filename` varchar(255) NOT NULL COMMENT '原始文件名',
+    `content_type` varchar(100) NOT NULL COMMENT '文件类型',
+    `size` bigint unsigned NOT NULL COMMENT '文件大小（字节）',
+    `sha256` char(64) NOT NULL COMMENT '文件SHA-256摘要',
+    `storage_key` varchar(255) NOT NULL COMMENT '对象存储中的键',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_user` (`user_id`, `user_type`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '用户上传文件表';
+
+-- 初始化接口权限，管理员默认拥有全部启用的权限
+INSERT IGNORE INTO `permissions` (`id`, `name`, `code`, `type`, `path`, `remark`) VALUES
+    (UUID(), '查看用户', 'user:read', 3, '/api/v1/admin/users', '查看用户、会话、登录历史及在线用户'),
+    (UUID(), '管理用户', 'user:write', 3, '/api/v1/admin/users', '强制下线用户会话'),
+    (UUID(), '管理OAuth客户端', 'oauth_client:manage', 3, '/api/v1/admin/oauth/clients', NULL),
+    (UUID(), '管理权限', 'permission:manage', 3, '/api/v1/admin/permissions', NULL),
+    (UUID(), '查看系统配置', 'system:read', 3, '/api/v1/system', '查看系统配置与运行指标'),
+    (UUID(), '修改系统配置', 'system:write', 3, '/api/v1/system/config', NULL);
+
+-- 模拟登录权限需由超级管理员显式授予，管理员不默认拥有
+INSERT IGNORE INTO `permissions` (`id`, `name`, `code`, `type`, `path`, `remark`) VALUES
+    (UUID(), '模拟用户登录', 'user:impersonate', 3, '/api/v1/admin/users', '以目标用户身份签发短期令牌，仅限显式授予');
+
+-- 管理端访问控制规则保存在Redis中，此处仅初始化管理权限
+INSERT IGNORE INTO `permissions` (`id`, `name`, `code`, `type`, `path`, `remark`) VALUES
+    (UUID(), '管理访问控制规则', 'ip_rule:manage', 3, '/api/v1/admin/ip-rules', '按IP与国家/地区限制管理端访问');
@@ -0,0 +1,3 @@
+-- 密码哈希改为PHC格式字符串，argon2id哈希长度超过60
+ALTER TABLE `sys_users` MODIFY `password` varchar(255) NOT NULL COMMENT '密码哈希(PHC格式)';
+ALTER TABLE `normal_users` MODIFY `password` varchar(255) NOT NULL COMMENT '密码哈希(PHC格式)';
@@ -0,0 +1,3 @@
+-- user_id同时指向sys_users或normal_users，由user_type区分，无法使用外键约束
+ALTER TABLE `user_permissions` DROP FOREIGN KEY `fk_up_sys_user`, DROP FOREIGN KEY `fk_up_normal_user`, DROP FOREIGN KEY `fk_up_operator`;
+ALTER TABLE `operation_logs` DROP FOREIGN KEY `fk_ol_sys_user`, DROP FOREIGN KEY `fk_ol_normal_user`;
@@ -0,0 +1,5 @@
+-- 模拟登录期间的请求同时记录被模拟用户与实际操作的管理员
+ALTER TABLE `operation_logs`
+    ADD COLUMN `actor_id` varchar(36) NOT NULL DEFAULT '' COMMENT '模拟登录时实际操作的管理员ID' AFTER `status`,
+    ADD COLUMN `actor_name` varchar(32) NOT NULL DEFAULT '' COMMENT '模拟登录时实际操作的管理员用户名' AFTER `actor_id`,
+    ADD KEY `idx_actor_created` (`actor_id`, `created_at`);
@@ -5,31 +5,46 @@ import (
 	"fmt"
 	"gin-center/configs/config"
 	"gin-center/infrastructure/container"
+	infraErrors "gin-center/infrastructure/errors"
 	zaplogger "gin-center/infrastructure/zaplogger"
+	"gin-center/pkg/http/clientip"
 	use_http "gin-center/pkg/http/http"
+	"gin-center/pkg/tracer"
 	"os"
-	"path/filepath"
-	"strings"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 // App 应用程序的核心结构体，包含所有主要组件
 type App struct {
-	Context   context.Context      // 应用程序的上下文，用于控制生命周期
-	Config    *config.GlobalConfig // 应用程序配置
-	Container *container.Container // 依赖注入容器
-	Server    use_http.HTTPServer  // HTTP服务器实例
-	Engine    *gin.Engine          // Gin引擎实例
-	cancel    context.CancelFunc   // 用于取消上下文的函数
-	once      sync.Once            // 确保Close方法只执行一次的同步原语
+	Context        context.Context          // 应用程序的上下文，用于控制生命周期
+	Config         *config.GlobalConfig     // 应用程序配置
+	Container      *container.Container     // 依赖注入容器
+	Server         use_http.HTTPServer      // HTTP服务器实例
+	Engine         *gin.Engine              // Gin引擎实例
+	cancel         context.CancelFunc       // 用于取消上下文的函数
+	tracerProvider *sdktrace.TracerProvider // 链路追踪，未启用时为nil
+	once           sync.Once                // 确保Close方法只执行一次的同步原语
+	hooksMu        sync.Mutex               // 保护hooks
+	hooks          []shutdownHook           // 关闭钩子，按注册的逆序执行
 }
 
+// shutdownHook 具名的关闭钩子
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// defaultShutdownTimeout 未配置server.shutdown_timeout时的优雅关闭时限
+const defaultShutdownTimeout = 30 * time.Second
+
 // InitializeApp 初始化并返回一个新的应用程序实例
 func InitializeApp() (*App, error) {
 	// 创建带取消功能的上下文
@@ -48,36 +63,53 @@ func InitializeApp() (*App, error) {
 		return nil, fmt.Errorf("初始化日志系统失败: %w", err)
 	}
 
+	// 初始化链路追踪，须先于容器，数据库与Redis的追踪钩子在容器中注册
+	tracerProvider, err := tracer.Setup(ctx, &cfg.Trace, cfg.App.Name, cfg.App.Version, cfg.App.Env)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("初始化链路追踪失败: %w", err)
+	}
+
 	// 初始化依赖注入容器
 	c, err := container.NewContainer(cfg)
 	if err != nil {
 		cancel()
+		shutdownTracer(tracerProvider)
 		return nil, fmt.Errorf("初始化容器失败: %w", err)
 	}
 
-	// 初始化Gin引擎
-	engine := gin.Default()
+	// 初始化Gin引擎，访问日志由路由中的中间件记录，不使用gin默认的文本日志
+	// 最外层的panic恢复兜底路由中间件之前发生的panic，路由中另有一层，使panic计入访问日志
+	engine := gin.New()
+	engine.Use(infraErrors.ErrorHandler(zaplogger.NewServiceLogger()))
+
+	// 客户端IP须在其他中间件之前解析，之后取得的都是同一个结果
+	resolver, err := clientip.NewResolver(&cfg.Server.ClientIP)
+	if err == nil {
+		err = resolver.Apply(engine)
+	}
+	if err != nil {
+		cancel()
+		c.Close()
+		shutdownTracer(tracerProvider)
+		return nil, fmt.Errorf("配置可信代理失败: %w", err)
+	}
 
 	// 配置HTTP服务器
-	httpServer := use_http.NewHTTPServer(
-		cfg.Server.Port,
-		cfg.Server.ReadTimeout,
-		cfg.Server.WriteTimeout,
-		cfg.Server.IdleTimeout,
-		cfg.Server.ReadHeaderTimeout,
-		cfg.Server.ShutdownTimeout,
-		cfg.Server.MaxHeaderBytes,
-		cfg.Server.MaxRequestsPerConn,
-	)
-
-	return &App{
-		Context:   ctx,
-		Config:    cfg,
-		Container: c,
-		Server:    httpServer,
-		Engine:    engine,
-		cancel:    cancel,
-	}, nil
+	httpServer := use_http.NewHTTPServer(&cfg.Server)
+
+	app := &App{
+		Context:        ctx,
+		Config:         cfg,
+		Container:      c,
+		Server:         httpServer,
+		Engine:         engine,
+		cancel:         cancel,
+		tracerProvider: tracerProvider,
+	}
+	// 停止接收请求后先停止后台任务，再关闭其依赖的数据库与Redis
+	app.OnShutdown("后台任务", c.StopBackground)
+	return app, nil
 }
 
 // loadConfig 加载应用程序配置
@@ -90,63 +122,138 @@ func loadConfig() (*config.GlobalConfig, error) {
 	return cfg, nil
 }
 
-// Close 优雅关闭应用程序
-// 确保所有组件按照正确的顺序关闭，避免资源泄漏
+// OnShutdown 注册关闭钩子，钩子在HTTP服务器排空请求之后、关闭数据库与Redis之前按注册的逆序执行
+// 钩子的ctx受server.shutdown_timeout限制，返回的错误只记录日志，不影响后续钩子执行
+func (a *App) OnShutdown(name string, fn func(ctx context.Context) error) {
+	a.hooksMu.Lock()
+	defer a.hooksMu.Unlock()
+	a.hooks = append(a.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run 启动HTTP服务器并阻塞，直到收到SIGINT/SIGTERM、服务器异常退出或应用上下文被取消，随后按顺序关闭应用
+// 关闭过程中再次收到信号时立即断开所有连接；返回服务器的异常，正常关闭时返回nil
+func (a *App) Run() error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.Server.Start(a.Engine)
+	}()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	logger := zaplogger.NewServiceLogger()
+	var err error
+	select {
+	case sig := <-signals:
+		logger.LogInfo("收到停止信号，开始优雅关闭", zap.String("signal", sig.String()))
+		go func() {
+			sig, ok := <-signals
+			if !ok {
+				return
+			}
+			logger.LogWarn("再次收到停止信号，立即断开所有连接", zap.String("signal", sig.String()))
+			_ = a.Server.Close()
+		}()
+	case err = <-serveErr:
+		if err != nil {
+			logger.LogError("服务器异常退出", zap.Error(err))
+		}
+	case <-a.Context.Done():
+	}
+
+	a.Close()
+	return err
+}
+
+// Close 优雅关闭应用程序，按以下顺序执行，重复调用只执行一次：
+// 1. 就绪检查失败，等待server.shutdown_delay让负载均衡摘除实例
+// 2. 停止接收新连接并等待进行中的请求完成，超过server.shutdown_timeout时强制断开
+// 3. 按逆序执行关闭钩子，如停止后台任务
+// 4. 关闭Redis与数据库连接，导出剩余的span
 func (a *App) Close() {
 	a.once.Do(func() {
-		// 取消应用程序上下文
-		a.cancel()
+		logger := zaplogger.NewServiceLogger()
+		timeout := a.Config.Server.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+
+		// 就绪检查先行失败，负载均衡不再分配新请求
+		if a.Container != nil && a.Container.Health != nil {
+			a.Container.Health.SetShuttingDown()
+			if delay := a.Config.Server.ShutdownDelay; delay > 0 {
+				time.Sleep(delay)
+			}
+		}
 
-		// 关闭HTTP服务器
+		// 停止接收请求并排空进行中的请求
 		if a.Server != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_ = a.Server.Shutdown(ctx)
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			if err := a.Server.Shutdown(ctx); err != nil {
+				logger.LogWarn("等待请求完成超时，强制断开剩余连接", zap.Error(err))
+				_ = a.Server.Close()
+			}
+			cancel()
 		}
 
+		// 取消应用程序上下文
+		a.cancel()
+
+		// 执行关闭钩子
+		a.runShutdownHooks(timeout, logger)
+
 		// 关闭依赖注入容器
 		if a.Container != nil {
 			a.Container.Close()
 		}
+
+		// 导出剩余的span
+		shutdownTracer(a.tracerProvider)
+		logger.LogInfo("应用已关闭")
 	})
 }
 
+// runShutdownHooks 按注册的逆序执行关闭钩子，所有钩子共用同一时限
+func (a *App) runShutdownHooks(timeout time.Duration, logger *zaplogger.ServiceLogger) {
+	a.hooksMu.Lock()
+	hooks := append([]shutdownHook(nil), a.hooks...)
+	a.hooksMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if err := hook.fn(ctx); err != nil {
+			logger.LogError("关闭钩子执行失败", zap.String("hook", hook.name), zap.Error(err))
+		}
+	}
+}
+
 // Cleanup 执行最终的清理工作
 // 在应用程序退出前调用，确保所有资源都被正确释放
 func (a *App) Cleanup() {
 	a.Close()
-	_ = zap.L().Sync()
+	_ = zaplogger.Close()
 }
 
 // initLogger 初始化日志系统
-// 配置并初始化zap日志记录器，设置日志级别、输出路径等
+// 按配置构建全局日志记录器，容器和路由中的ServiceLogger均基于该记录器
 func initLogger(cfg *config.GlobalConfig) error {
-	// 创建日志目录
-	logDir := filepath.Dir(cfg.Log.Filename)
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return errors.Wrap(err, "创建日志目录失败")
+	if err := zaplogger.Setup(&cfg.Log, cfg.App.Name); err != nil {
+		return errors.Wrap(err, "构建日志记录器失败")
 	}
+	return nil
+}
 
-	// 配置日志输出
-	logConfig := zap.NewProductionConfig()
-	logConfig.OutputPaths = []string{cfg.Log.Filename}
-	logConfig.ErrorOutputPaths = []string{cfg.Log.Filename}
-
-	// 设置日志级别
-	logLevel := zap.NewAtomicLevel()
-	if err := logLevel.UnmarshalText([]byte(strings.ToLower(cfg.Log.Level))); err != nil {
-		logLevel.SetLevel(zap.InfoLevel)
+// shutdownTracer 关闭TracerProvider并导出缓冲中的span
+func shutdownTracer(provider *sdktrace.TracerProvider) {
+	if provider == nil {
+		return
 	}
-	logConfig.Level = logLevel
-	logConfig.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-
-	// 构建日志记录器
-	logger, err := logConfig.Build()
-	if err != nil {
-		return errors.Wrap(zaplogger.ErrInitLoggerFailed, err.Error())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := provider.Shutdown(ctx); err != nil {
+		zaplogger.NewServiceLogger().LogWarn("关闭链路追踪失败", zap.Error(err))
 	}
-
-	// 设置全局日志记录器
-	zap.ReplaceGlobals(logger)
-	return nil
 }
@@ -7,18 +7,58 @@ import (
 	"gin-center/configs/config"
 	"gin-center/infrastructure/cache"
 	"gin-center/infrastructure/database"
+	"gin-center/infrastructure/metrics"
 	"gin-center/infrastructure/repository/admin"
+	api_key_repo "gin-center/infrastructure/repository/api_key"
+	identity_repo "gin-center/infrastructure/repository/identity"
+	ip_rule_repo "gin-center/infrastructure/repository/ip_rule"
+	login_history_repo "gin-center/infrastructure/repository/login_history"
+	oauth_client_repo "gin-center/infrastructure/repository/oauth_client"
+	operation_log_repo "gin-center/infrastructure/repository/operation_log"
+	permission_repo "gin-center/infrastructure/repository/permission"
+	session_repo "gin-center/infrastructure/repository/session"
+	upload_repo "gin-center/infrastructure/repository/upload"
 	user_repo "gin-center/infrastructure/repository/user"
+	user_import_repo "gin-center/infrastructure/repository/user_import"
 	"gin-center/infrastructure/zaplogger"
 	AdminService "gin-center/internal/application/admin/service"
+	api_key_service "gin-center/internal/application/api_key/service"
+	impersonation_service "gin-center/internal/application/impersonation/service"
+	ip_rule_service "gin-center/internal/application/ip_rule/service"
+	login_history_service "gin-center/internal/application/login_history/service"
+	oauth_service "gin-center/internal/application/oauth/service"
+	oauth_server_service "gin-center/internal/application/oauth_server/service"
+	operation_log_service "gin-center/internal/application/operation_log/service"
+	permission_service "gin-center/internal/application/permission/service"
+	service_account_service "gin-center/internal/application/service_account/service"
+	session_service "gin-center/internal/application/session/service"
 	systemService "gin-center/internal/application/system/system_service"
+	upload_service "gin-center/internal/application/upload/service"
 	user_service "gin-center/internal/application/user/service"
+	user_import_service "gin-center/internal/application/user_import/service"
+	use_apiKeyInterface "gin-center/internal/domain/interface/api_key"
+	use_impersonationInterface "gin-center/internal/domain/interface/impersonation"
+	use_ipRuleInterface "gin-center/internal/domain/interface/ip_rule"
+	use_loginHistoryInterface "gin-center/internal/domain/interface/login_history"
+	use_oauthInterface "gin-center/internal/domain/interface/oauth"
+	use_oauthServerInterface "gin-center/internal/domain/interface/oauth_server"
+	use_operationLogInterface "gin-center/internal/domain/interface/operation_log"
+	use_permissionInterface "gin-center/internal/domain/interface/permission"
+	use_serviceAccountInterface "gin-center/internal/domain/interface/service_account"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
+	use_uploadInterface "gin-center/internal/domain/interface/upload"
 	use_userInterface "gin-center/internal/domain/interface/user"
+	use_userImportInterface "gin-center/internal/domain/interface/user_import"
 	"gin-center/internal/types/constants"
+	"gin-center/pkg/health"
+	"gin-center/pkg/security/useCookie"
 	"gin-center/pkg/security/useJwt"
+	"gin-center/pkg/security/useOAuth"
+	"gin-center/pkg/security/usePassword"
+	"gin-center/pkg/storage/useStorage"
+	"gin-center/pkg/tracer"
 	"os"
 	"sync"
-	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/go-redis/redis/v8"
@@ -29,17 +69,34 @@ import (
 
 // Container 应用程序的依赖注入容器
 type Container struct {
-	Config        *config.GlobalConfig                   // 应用程序配置
-	DB            *gorm.DB                               // 数据库连接
-	Redis         *redis.Client                          // Redis客户端
-	Logger        *zaplogger.ServiceLogger               // 修改为自定义日志类型  // 日志记录器
-	UserService   use_userInterface.UserServiceInterface // 用户服务接口
-	AdminService  *AdminService.AdminService             // 管理员服务接口（保持接口名称不变）
-	SystemService *systemService.SystemService           // 系统配置服务
-	Validator     *validator.Validate                    // 数据验证器
-	JWTConfig     *useJwt.JWTConfig                      // JWT配置
-	Cache         cache.Cache                            // 缓存接口
-	shutdown      sync.Once                              // 确保关闭操作只执行一次
+	Config                *config.GlobalConfig                                       // 应用程序配置
+	DB                    *gorm.DB                                                   // 数据库连接
+	Redis                 *redis.Client                                              // Redis客户端
+	Logger                *zaplogger.ServiceLogger                                   // 修改为自定义日志类型  // 日志记录器
+	UserService           use_userInterface.UserServiceInterface                     // 用户服务接口
+	AdminService          *AdminService.AdminService                                 // 管理员服务接口（保持接口名称不变）
+	SystemService         *systemService.SystemService                               // 系统配置服务
+	SessionService        use_sessionInterface.SessionServiceInterface               // 登录会话服务
+	LoginHistoryService   use_loginHistoryInterface.LoginHistoryServiceInterface     // 登录历史服务
+	OAuthService          use_oauthInterface.OAuthServiceInterface                   // 第三方登录服务
+	OAuthServerService    use_oauthServerInterface.OAuthServerServiceInterface       // OAuth2授权服务
+	PermissionService     use_permissionInterface.PermissionServiceInterface         // 权限服务
+	APIKeyService         use_apiKeyInterface.APIKeyServiceInterface                 // API密钥服务
+	OperationLogService   use_operationLogInterface.OperationLogServiceInterface     // 操作审计日志服务
+	ImpersonationService  use_impersonationInterface.ImpersonationServiceInterface   // 模拟登录服务
+	UserImportService     use_userImportInterface.UserImportServiceInterface         // 用户导入导出服务
+	UploadService         use_uploadInterface.UploadServiceInterface                 // 分片上传服务
+	ServiceAccountService use_serviceAccountInterface.ServiceAccountServiceInterface // 客户端证书服务账号
+	IPRuleService         use_ipRuleInterface.IPRuleServiceInterface                 // 管理端访问控制
+	Storage               useStorage.Storage                                         // 对象存储
+	Validator             *validator.Validate                                        // 数据验证器
+	JWTConfig             *useJwt.JWTConfig                                          // JWT配置
+	TokenCookies          *useCookie.Transport                                       // 令牌Cookie传输
+	Cache                 cache.Cache                                                // 缓存接口
+	Health                *health.Registry                                           // 健康检查
+	shutdown              sync.Once                                                  // 确保关闭操作只执行一次
+	cancel                context.CancelFunc                                         // 停止后台任务
+	background            sync.WaitGroup                                             // 运行中的后台任务
 }
 
 // NewContainer 创建并初始化一个新的依赖注入容器
@@ -61,49 +118,187 @@ func NewContainer(cfg *config.GlobalConfig) (*Container, error) {
 		return nil, fmt.Errorf("初始化核心组件失败: %w", err)
 	}
 
+	// 为数据库与Redis操作生成span
+	if cfg.Trace.Enabled {
+		if err := db.Use(tracer.GormPlugin(cfg.Database.Driver)); err != nil {
+			return nil, fmt.Errorf("注册数据库追踪回调失败: %w", err)
+		}
+		redisClient.AddHook(tracer.RedisHook())
+	}
+
+	// 采集数据库与Redis指标
+	if cfg.Metrics.Enabled {
+		if err := metrics.RegisterDB(db); err != nil {
+			return nil, err
+		}
+		if err := metrics.RegisterRedis(redisClient); err != nil {
+			return nil, fmt.Errorf("注册Redis指标失败: %w", err)
+		}
+	}
+
 	// 初始化缓存实例
 	cacheInstance := cache.NewRedisCache(redisClient)
 
-	// 配置JWT
-	jwtConfig := &useJwt.JWTConfig{
-		SecretKey:  jwtSecret,
-		Expiration: time.Hour * 72,
+	// 配置JWT，服务层与认证中间件共用同一实例
+	jwtConfig := useJwt.NewJWTConfig(&useJwt.JWTConfig{
+		SecretKey:                  jwtSecret,
+		Expiration:                 cfg.JWT.Expiration,
+		Issuer:                     cfg.JWT.Issuer,
+		AccessTokenLifetime:        cfg.JWT.AccessTokenLifetime,
+		RefreshTokenLifetime:       cfg.JWT.RefreshTokenLifetime,
+		ImpersonationTokenLifetime: cfg.JWT.ImpersonationTokenLifetime,
+		BlacklistCleanupTick:       cfg.JWT.BlacklistCleanupTick,
+	})
+
+	// 令牌Cookie的有效期与刷新令牌一致
+	tokenCookies, err := useCookie.New(&cfg.Security.Cookie, jwtConfig.RefreshTokenLifetime)
+	if err != nil {
+		return nil, fmt.Errorf("初始化令牌Cookie失败: %w", err)
+	}
+
+	// 初始化密码哈希器
+	passwordHasher, err := usePassword.NewHasher(&cfg.Password)
+	if err != nil {
+		return nil, fmt.Errorf("初始化密码哈希器失败: %w", err)
+	}
+
+	// 初始化第三方登录提供方
+	oauthRegistry, err := useOAuth.NewRegistry(&cfg.OAuth)
+	if err != nil {
+		return nil, fmt.Errorf("初始化第三方登录失败: %w", err)
+	}
+
+	// 初始化对象存储
+	storage, err := useStorage.NewStorage(context.Background(), &cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("初始化对象存储失败: %w", err)
+	}
+
+	// 注册就绪检查
+	healthRegistry, err := newHealthRegistry(cfg, db, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("初始化健康检查失败: %w", err)
 	}
 
 	// 初始化仓储层
 	adminRepo := admin.NewAdminRepository(db)
 	userRepo := user_repo.NewUserRepository(db)
+	sessionRepo := session_repo.NewSessionRepository(redisClient)
+	loginHistoryRepo := login_history_repo.NewLoginHistoryRepository(db)
+	identityRepo := identity_repo.NewIdentityRepository(db, redisClient)
+	oauthClientRepo := oauth_client_repo.NewOAuthClientRepository(db, redisClient)
+	permissionRepo := permission_repo.NewPermissionRepository(db)
+	apiKeyRepo := api_key_repo.NewAPIKeyRepository(db)
+	operationLogRepo := operation_log_repo.NewOperationLogRepository(db)
+	userImportRepo := user_import_repo.NewUserImportRepository(redisClient)
+	uploadRepo := upload_repo.NewUploadRepository(db, redisClient)
+	ipRuleRepo := ip_rule_repo.NewIPRuleRepository(redisClient)
 
 	// 初始化服务层
 	services, err := initServices(&serviceConfig{
-		DB:           db,
-		Cache:        cacheInstance,
-		AdminRepo:    adminRepo,
-		UserRepo:     userRepo,
-		JWTSecret:    jwtSecret,
-		GlobalConfig: cfg,
-		RedisClient:  redisClient,
-		Logger:       logger,
+		DB:               db,
+		Cache:            cacheInstance,
+		AdminRepo:        adminRepo,
+		UserRepo:         userRepo,
+		SessionRepo:      sessionRepo,
+		LoginHistoryRepo: loginHistoryRepo,
+		IdentityRepo:     identityRepo,
+		OAuthClientRepo:  oauthClientRepo,
+		PermissionRepo:   permissionRepo,
+		APIKeyRepo:       apiKeyRepo,
+		OperationLogRepo: operationLogRepo,
+		UserImportRepo:   userImportRepo,
+		UploadRepo:       uploadRepo,
+		IPRuleRepo:       ipRuleRepo,
+		OAuthRegistry:    oauthRegistry,
+		JWTConfig:        jwtConfig,
+		GlobalConfig:     cfg,
+		RedisClient:      redisClient,
+		Logger:           logger,
+		Hasher:           passwordHasher,
+		Storage:          storage,
+		Health:           healthRegistry,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("初始化服务层失败: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	// 初始化验证器
 	validatorInstance := validator.New()
 
-	return &Container{
-		Config:        cfg,
-		DB:            db,
-		Redis:         redisClient,
-		Logger:        logger,
-		UserService:   services.UserService,
-		AdminService:  services.AdminService,
-		SystemService: services.SystemService,
-		Validator:     validatorInstance,
-		JWTConfig:     jwtConfig,
-		Cache:         cacheInstance,
-	}, nil
+	c := &Container{
+		Config:                cfg,
+		DB:                    db,
+		Redis:                 redisClient,
+		Logger:                logger,
+		UserService:           services.UserService,
+		AdminService:          services.AdminService,
+		SystemService:         services.SystemService,
+		SessionService:        services.SessionService,
+		LoginHistoryService:   services.LoginHistoryService,
+		OAuthService:          services.OAuthService,
+		OAuthServerService:    services.OAuthServerService,
+		PermissionService:     services.PermissionService,
+		APIKeyService:         services.APIKeyService,
+		OperationLogService:   services.OperationLogService,
+		ImpersonationService:  services.ImpersonationService,
+		UserImportService:     services.UserImportService,
+		UploadService:         services.UploadService,
+		ServiceAccountService: services.ServiceAccountService,
+		IPRuleService:         services.IPRuleService,
+		Storage:               storage,
+		Validator:             validatorInstance,
+		JWTConfig:             jwtConfig,
+		TokenCookies:          tokenCookies,
+		Cache:                 cacheInstance,
+		Health:                healthRegistry,
+		cancel:                cancel,
+	}
+
+	// 启动后台任务，StopBackground时停止
+	c.goBackground(func() {
+		upload_service.RunCleanup(ctx, services.UploadService, cfg.Upload.CleanupInterval, logger)
+	})
+	c.goBackground(func() {
+		services.IPRuleService.Run(ctx)
+	})
+	return c, nil
+}
+
+// goBackground 启动后台任务并计入background，任务须在容器的ctx取消后返回
+func (c *Container) goBackground(task func()) {
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+		task()
+	}()
+}
+
+// StopBackground 停止定时任务并等待其退出，同时等待进行中的异步导入任务完成
+// 须在HTTP服务器停止接收请求之后、关闭数据库与Redis之前调用
+func (c *Container) StopBackground(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if c.JWTConfig != nil {
+		c.JWTConfig.Close()
+	}
+	done := make(chan struct{})
+	go func() {
+		c.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("等待后台任务退出超时: %w", ctx.Err())
+	}
+	if err := c.UserImportService.Wait(ctx); err != nil {
+		return fmt.Errorf("等待导入任务完成超时: %w", err)
+	}
+	return nil
 }
 
 func initCoreComponents(cfg *config.GlobalConfig) (*zaplogger.ServiceLogger, string, *redis.Client, *gorm.DB, error) {
@@ -183,39 +378,110 @@ func initRedisClient(redisCfg *config.RedisConfig) (*redis.Client, error) {
 	}), nil
 }
 
+// newHealthRegistry 注册数据库、Redis，以及按配置启用的磁盘空间与数据表检查
+func newHealthRegistry(cfg *config.GlobalConfig, db *gorm.DB, redisClient *redis.Client) (*health.Registry, error) {
+	registry := health.NewRegistry(&cfg.Health)
+	registry.Register("database", 0, health.DatabaseCheck(db))
+	registry.Register("redis", 0, health.RedisCheck(redisClient))
+	if cfg.Health.DiskPath != "" {
+		registry.Register("disk", 0, health.DiskCheck(cfg.Health.DiskPath, cfg.Health.DiskMinFreeMB))
+	}
+	if cfg.Health.SchemaFile != "" {
+		check, err := health.SchemaCheck(db, cfg.Health.SchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register("migrations", 0, check)
+	}
+	return registry, nil
+}
+
 func initDatabase(cfg *config.GlobalConfig) (*gorm.DB, error) {
 	return database.InitDatabase(cfg)
 }
 
 // serviceConfig 服务初始化配置
 type serviceConfig struct {
-	DB           *gorm.DB
-	Cache        cache.Cache
-	AdminRepo    *admin.AdminRepository
-	UserRepo     *user_repo.UserRepository
-	JWTSecret    string
-	GlobalConfig *config.GlobalConfig
-	RedisClient  *redis.Client
-	Logger       *zaplogger.ServiceLogger // 修改日志类型
+	DB               *gorm.DB
+	Cache            cache.Cache
+	AdminRepo        *admin.AdminRepository
+	UserRepo         *user_repo.UserRepository
+	SessionRepo      *session_repo.SessionRepository
+	LoginHistoryRepo *login_history_repo.LoginHistoryRepository
+	IdentityRepo     *identity_repo.IdentityRepository
+	OAuthClientRepo  *oauth_client_repo.OAuthClientRepository
+	PermissionRepo   *permission_repo.PermissionRepository
+	APIKeyRepo       *api_key_repo.APIKeyRepository
+	OperationLogRepo *operation_log_repo.OperationLogRepository
+	UserImportRepo   *user_import_repo.UserImportRepository
+	UploadRepo       *upload_repo.UploadRepository
+	IPRuleRepo       *ip_rule_repo.IPRuleRepository
+	OAuthRegistry    *useOAuth.Registry
+	JWTConfig        *useJwt.JWTConfig
+	GlobalConfig     *config.GlobalConfig
+	RedisClient      *redis.Client
+	Logger           *zaplogger.ServiceLogger // 修改日志类型
+	Hasher           usePassword.Hasher
+	Storage          useStorage.Storage
+	Health           *health.Registry
 }
 
 // ServiceContainer 服务容器，包含所有初始化的服务实例
 type ServiceContainer struct {
-	UserService   use_userInterface.UserServiceInterface
-	AdminService  *AdminService.AdminService
-	SystemService *systemService.SystemService
+	UserService           use_userInterface.UserServiceInterface
+	AdminService          *AdminService.AdminService
+	SystemService         *systemService.SystemService
+	SessionService        use_sessionInterface.SessionServiceInterface
+	LoginHistoryService   use_loginHistoryInterface.LoginHistoryServiceInterface
+	OAuthService          use_oauthInterface.OAuthServiceInterface
+	OAuthServerService    use_oauthServerInterface.OAuthServerServiceInterface
+	PermissionService     use_permissionInterface.PermissionServiceInterface
+	APIKeyService         use_apiKeyInterface.APIKeyServiceInterface
+	OperationLogService   use_operationLogInterface.OperationLogServiceInterface
+	ImpersonationService  use_impersonationInterface.ImpersonationServiceInterface
+	UserImportService     use_userImportInterface.UserImportServiceInterface
+	UploadService         use_uploadInterface.UploadServiceInterface
+	ServiceAccountService use_serviceAccountInterface.ServiceAccountServiceInterface
+	IPRuleService         use_ipRuleInterface.IPRuleServiceInterface
 }
 
 // initServices 初始化应用服务
 func initServices(cfg *serviceConfig) (*ServiceContainer, error) {
-	adminService := AdminService.NewAdminService(cfg.AdminRepo, &useJwt.JWTConfig{SecretKey: cfg.JWTSecret}, cfg.GlobalConfig, cfg.Logger)
-	userService := user_service.NewUserService(cfg.UserRepo, cfg.Logger, &useJwt.JWTConfig{SecretKey: cfg.JWTSecret})
-	systemService := systemService.NewSystemService(cfg.RedisClient, cfg.Logger)
+	sessionService := session_service.NewSessionService(cfg.SessionRepo, cfg.JWTConfig, cfg.Logger)
+	loginHistoryService := login_history_service.NewLoginHistoryService(cfg.LoginHistoryRepo, cfg.Logger)
+	adminService := AdminService.NewAdminService(cfg.AdminRepo, cfg.JWTConfig, cfg.GlobalConfig, cfg.Logger, cfg.Hasher, sessionService, loginHistoryService)
+	userService := user_service.NewUserService(cfg.UserRepo, cfg.Logger, cfg.JWTConfig, cfg.Hasher, sessionService, loginHistoryService, cfg.Storage)
+	oauthService := oauth_service.NewOAuthService(cfg.OAuthRegistry, cfg.GlobalConfig.OAuth.StateTTL, cfg.IdentityRepo, cfg.UserRepo, sessionService, loginHistoryService, cfg.Hasher, cfg.Logger)
+	oauthServerService := oauth_server_service.NewOAuthServerService(cfg.OAuthClientRepo, cfg.UserRepo, cfg.AdminRepo, cfg.JWTConfig, cfg.GlobalConfig.OAuthServer.CodeTTL, cfg.Logger)
+	permissionService := permission_service.NewPermissionService(cfg.PermissionRepo, cfg.Logger)
+	operationLogService := operation_log_service.NewOperationLogService(cfg.OperationLogRepo, cfg.Logger)
+	impersonationService := impersonation_service.NewImpersonationService(cfg.UserRepo, sessionService, operationLogService, cfg.Logger)
+	apiKeyService := api_key_service.NewAPIKeyService(cfg.APIKeyRepo, operationLogService, cfg.UserRepo, cfg.AdminRepo, permissionService, cfg.Logger)
+	userImportService := user_import_service.NewUserImportService(cfg.UserRepo, cfg.UserImportRepo, cfg.Hasher, cfg.Logger)
+	uploadService := upload_service.NewUploadService(cfg.UploadRepo, cfg.Storage, &cfg.GlobalConfig.Upload, cfg.Logger)
+	systemService := systemService.NewSystemService(cfg.Health, cfg.Logger)
+	serviceAccountService := service_account_service.NewServiceAccountService(cfg.GlobalConfig.Server.TLS.ServiceAccounts, cfg.UserRepo, cfg.AdminRepo, cfg.Logger)
+	ipRuleService, err := ip_rule_service.NewIPRuleService(context.Background(), &cfg.GlobalConfig.IPFilter, cfg.IPRuleRepo, cfg.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化访问控制失败: %w", err)
+	}
 
 	return &ServiceContainer{
-		UserService:   userService,
-		AdminService:  adminService,
-		SystemService: systemService,
+		UserService:           userService,
+		AdminService:          adminService,
+		SystemService:         systemService,
+		SessionService:        sessionService,
+		LoginHistoryService:   loginHistoryService,
+		OAuthService:          oauthService,
+		OAuthServerService:    oauthServerService,
+		PermissionService:     permissionService,
+		APIKeyService:         apiKeyService,
+		OperationLogService:   operationLogService,
+		ImpersonationService:  impersonationService,
+		UserImportService:     userImportService,
+		UploadService:         uploadService,
+		ServiceAccountService: serviceAccountService,
+		IPRuleService:         ipRuleService,
 	}, nil
 }
 
@@ -230,12 +496,20 @@ func (c *Container) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) er
 // Close 优雅关闭容器中的所有资源
 //
 // 按以下顺序关闭组件：
-// 1. HTTP服务器
+// 1. 后台任务
 // 2. Redis连接
 // 3. 数据库连接
 // 4. 日志系统
 func (c *Container) Close() {
 	c.shutdown.Do(func() {
+		// 停止后台任务
+		if c.cancel != nil {
+			c.cancel()
+		}
+		if c.JWTConfig != nil {
+			c.JWTConfig.Close()
+		}
+
 		// 关闭Redis连接
 		if c.Redis != nil {
 			if err := c.Redis.Close(); err != nil {
@@ -38,6 +38,8 @@ func InitDatabase(cfg *config.GlobalConfig) (*gorm.DB, error) {
 
 	config := &gorm.Config{
 		Logger: gormLogger,
+		// 将唯一键冲突等驱动错误转换为gorm.ErrDuplicatedKey等通用错误
+		TranslateError: true,
 		NowFunc: func() time.Time {
 			return time.Now().Local()
 		},
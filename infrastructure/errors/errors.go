@@ -2,13 +2,20 @@
 package infraErrors
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
 	"runtime/debug"
+	"strings"
 	"time"
 
+	"gin-center/pkg/http/clientip"
+
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 // AuthErrorCode 定义认证相关错误类型
@@ -90,6 +97,8 @@ var (
 	ErrServiceUnavailable = NewError(503, "服务不可用")
 	// ErrTimeout 表示请求超时
 	ErrTimeout = NewError(504, "请求超时")
+	// ErrPayloadTooLarge 表示请求体超过大小限制
+	ErrPayloadTooLarge = NewError(413, "请求体过大")
 	// ErrRateLimit 表示请求达到限制
 	ErrRateLimit = NewError(429, "请求达到限制")
 	// ErrCircuitBreaker 表示熔断器触发
@@ -115,82 +124,134 @@ var (
 	ErrPasswordMismatch = NewError(401, "密码错误")
 )
 
+// Logger 错误处理中间件使用的日志接口，由zaplogger.ServiceLogger实现
+// zaplogger依赖配置包，配置包又间接依赖本包，这里以接口避免循环引用
+type Logger interface {
+	LogWarn(msg string, fields ...zap.Field)
+	LogError(msg string, fields ...zap.Field)
+}
+
 // ErrorHandler 返回一个Gin中间件，用于全局错误处理
-// 该中间件可以捕获panic，记录错误日志，并返回统一的错误响应
-func ErrorHandler() gin.HandlerFunc {
+// 该中间件捕获panic，通过zap记录错误日志与调用栈，并返回带trace_id的统一错误响应
+func ErrorHandler(logger Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID := c.GetString("trace_id")
-		if traceID == "" {
-			traceID = uuid.New().String()
-			c.Set("trace_id", traceID)
-			c.Header("X-Trace-ID", traceID)
-		}
 		defer func() {
-			if err := recover(); err != nil {
-				stack := string(debug.Stack())
-				logFields := logrus.Fields{
-					"trace_id":    traceID,
-					"error":       err,
-					"stack_trace": stack,
-					"path":        c.Request.URL.Path,
-					"method":      c.Request.Method,
-					"client_ip":   c.ClientIP(),
-					"user_agent":  c.Request.UserAgent(),
-					"referer":     c.Request.Referer(),
-					"timestamp":   time.Now().Format(time.RFC3339),
-				}
-				// 记录请求头信息，排除敏感信息
-				headers := make(map[string]string)
-				for k, v := range c.Request.Header {
-					if k != "Authorization" && k != "Cookie" {
-						headers[k] = v[0]
-					}
+			err := recover()
+			if err == nil {
+				return
+			}
+			// http.ErrAbortHandler用于主动中断响应，交由net/http处理
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			// 客户端已断开时无法写入响应，只记录警告
+			if brokenPipe(err) {
+				logger.LogWarn("客户端连接已断开",
+					zap.String("request_id", c.GetString("request_id")),
+					zap.Any("error", err),
+					zap.String("path", c.Request.URL.Path))
+				c.Abort()
+				return
+			}
+
+			// 记录请求头信息，排除敏感信息
+			headers := make(map[string]string)
+			for k, v := range c.Request.Header {
+				if k != "Authorization" && k != "Cookie" && k != "X-Api-Key" && k != "X-Csrf-Token" {
+					headers[k] = v[0]
 				}
-				logFields["headers"] = headers
-				logFields["query_params"] = c.Request.URL.Query()
-				logrus.WithFields(logFields).Error("Panic recovered")
-
-				// 处理不同类型的错误
-				var appError *AppError
-				switch e := err.(type) {
-				case *AppError:
-					appError = e
-				case error:
-					switch {
-					case e.Error() == "record not found":
-						appError = ErrNotFound
-					case e.Error() == "validation failed":
-						appError = ErrValidation
-					default:
-						appError = &AppError{
-							Code:    ErrInternalServer.Code,
-							Message: ErrInternalServer.Message,
-							Err:     e,
-						}
-					}
+			}
+			logger.LogError("Panic recovered",
+				zap.String("request_id", c.GetString("request_id")),
+				zap.String("trace_id", TraceID(c)),
+				zap.Any("error", err),
+				zap.String("stack_trace", string(debug.Stack())),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("method", c.Request.Method),
+				zap.String("client_ip", clientip.Get(c)),
+				zap.String("user_agent", c.Request.UserAgent()),
+				zap.String("referer", c.Request.Referer()),
+				zap.Any("headers", headers),
+				zap.Any("query_params", c.Request.URL.Query()))
+
+			// 处理不同类型的错误
+			var appError *AppError
+			switch e := err.(type) {
+			case *AppError:
+				appError = e
+			case error:
+				switch {
+				case e.Error() == "record not found":
+					appError = ErrNotFound
+				case e.Error() == "validation failed":
+					appError = ErrValidation
 				default:
 					appError = &AppError{
 						Code:    ErrInternalServer.Code,
 						Message: ErrInternalServer.Message,
-						Err:     fmt.Errorf("%v", err),
+						Err:     e,
 					}
 				}
+			default:
+				appError = &AppError{
+					Code:    ErrInternalServer.Code,
+					Message: ErrInternalServer.Message,
+					Err:     fmt.Errorf("%v", err),
+				}
+			}
 
-				// 返回统一的错误响应
-				c.JSON(appError.Code, ErrorResponse{
-					Code:     appError.Code,
-					Message:  appError.Message,
-					TraceID:  traceID,
-					Details:  appError.Details,
-					DateTime: time.Now().Format(time.RFC3339),
-				})
+			// 已经开始写入响应时无法再返回错误响应
+			if c.Writer.Written() {
 				c.Abort()
+				return
 			}
+			Abort(c, appError)
 		}()
 		c.Next()
 	}
 }
 
+// Abort 中止请求并返回统一的错误响应
+func Abort(c *gin.Context, appError *AppError) {
+	c.AbortWithStatusJSON(appError.Code, ErrorResponse{
+		Code:     appError.Code,
+		Message:  appError.Message,
+		TraceID:  TraceID(c),
+		Details:  appError.Details,
+		DateTime: time.Now().Format(time.RFC3339),
+	})
+}
+
+// TraceID 返回当前请求的trace_id，未启用链路追踪时退回请求ID
+func TraceID(c *gin.Context) string {
+	if traceID := c.GetString("trace_id"); traceID != "" {
+		return traceID
+	}
+	if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return c.GetString("request_id")
+}
+
+// brokenPipe 判断panic是否由客户端断开连接后的写入引起
+func brokenPipe(err any) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(e, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if errors.As(opErr, &sysErr) {
+		msg := strings.ToLower(sysErr.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+	return false
+}
+
 const (
 	ErrCodeInvalidUsername          AuthErrorCode = 1001
 	ErrCodeUsernameValidationFailed AuthErrorCode = 1002
@@ -0,0 +1,146 @@
+// Package metrics 定义Prometheus指标，包括HTTP请求、数据库、Redis、Go运行时、进程以及登录注册等业务指标
+package metrics
+
+import (
+	"net/http"
+
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 自定义指标的名称前缀
+const namespace = "gin_center"
+
+// Registry 应用使用的指标注册表，不使用prometheus的默认注册表，避免第三方库注册的指标混入
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests HTTP请求数，route为路由模板，未匹配路由的请求记为unmatched
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration HTTP请求耗时
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPInFlight 正在处理的HTTP请求数
+	HTTPInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "正在处理的HTTP请求数",
+	})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "数据库操作耗时（秒）",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "数据库操作错误数，不含记录不存在",
+	}, []string{"operation", "table"})
+
+	redisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "command_duration_seconds",
+		Help:      "Redis命令耗时（秒），管道按整体计时，命令名记为pipeline",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+	}, []string{"command"})
+
+	redisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "command_errors_total",
+		Help:      "Redis命令错误数，不含键不存在",
+	}, []string{"command"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "登录次数，result为success或失败原因",
+	}, []string{"user_type", "result"})
+
+	lockouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "lockouts_total",
+		Help:      "因账户被禁用而拒绝的登录次数",
+	}, []string{"user_type"})
+
+	registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "registrations_total",
+		Help:      "新建账户数，source为register、oauth、admin或import",
+	}, []string{"user_type", "source"})
+)
+
+// 注册来源
+const (
+	SourceRegister = "register"
+	SourceOAuth    = "oauth"
+	SourceAdmin    = "admin"
+	SourceImport   = "import"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, HTTPInFlight,
+		dbDuration, dbErrors,
+		redisDuration, redisErrors,
+		logins, lockouts, registrations,
+	)
+}
+
+// Handler 返回输出Registry中全部指标的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RecordLogin 记录一次登录，reason为空表示登录成功
+func RecordLogin(userType int, reason string) {
+	result := reason
+	if result == "" {
+		result = "success"
+	}
+	logins.WithLabelValues(userTypeLabel(userType), result).Inc()
+	if reason == LoginHistoryModel.ReasonAccountDisabled {
+		lockouts.WithLabelValues(userTypeLabel(userType)).Inc()
+	}
+}
+
+// RecordRegistrations 记录新建的账户数
+func RecordRegistrations(userType int, source string, count int) {
+	if count > 0 {
+		registrations.WithLabelValues(userTypeLabel(userType), source).Add(float64(count))
+	}
+}
+
+func userTypeLabel(userType int) string {
+	if userType == LoginHistoryModel.UserTypeAdmin {
+		return "admin"
+	}
+	return "user"
+}
@@ -0,0 +1,88 @@
+package api_key_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	APIKeyModel "gin-center/internal/domain/model/api_key"
+	"gin-center/internal/types/constants"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	*base_repository.GenericRepository[APIKeyModel.APIKey]
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		GenericRepository: base_repository.NewGenericRepository[APIKeyModel.APIKey](db),
+	}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *APIKeyModel.APIKey) error {
+	if err := r.GenericRepository.Create(ctx, key); err != nil {
+		return fmt.Errorf("保存API密钥失败: %w", err)
+	}
+	return nil
+}
+
+// FindByHash 按明文摘要查询密钥，不过滤撤销与过期状态
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*APIKeyModel.APIKey, error) {
+	var key APIKeyModel.APIKey
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("key_hash = ?", keyHash).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("查询API密钥失败: %w", err)
+	}
+	return &key, nil
+}
+
+// ListByUser 查询用户未撤销的密钥
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userType int, userID uint) ([]APIKeyModel.APIKey, error) {
+	var keys []APIKeyModel.APIKey
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("user_id = ? AND user_type = ? AND revoked_at IS NULL", userID, userType).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询API密钥失败: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke 撤销用户自己的密钥
+func (r *APIKeyRepository) Revoke(ctx context.Context, userType int, userID, id uint, at time.Time) error {
+	result := r.GenericRepository.DB.WithContext(ctx).
+		Model(&APIKeyModel.APIKey{}).
+		Where("id = ? AND user_id = ? AND user_type = ? AND revoked_at IS NULL", id, userID, userType).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("撤销API密钥失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed 更新最近使用时间与IP
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uint, ip string, at time.Time) error {
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Model(&APIKeyModel.APIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("更新API密钥使用信息失败: %w", err)
+	}
+	return nil
+}
@@ -84,8 +84,17 @@ func (r *GenericRepository[T]) FindWithPagination(ctx context.Context, page, siz
 	return entities, total, nil
 }
 
+// ContextWithDB 将数据库连接放入上下文，供WithTx开启事务
+func ContextWithDB(ctx context.Context, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, dbContextKey, db)
+}
+
 func WithTx[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (T, error) {
-	db := ctx.Value(dbContextKey).(*gorm.DB)
+	db, ok := ctx.Value(dbContextKey).(*gorm.DB)
+	if !ok || db == nil {
+		var zero T
+		return zero, fmt.Errorf("failed to begin transaction: database not found in context")
+	}
 	tx := db.Begin()
 	if tx.Error != nil {
 		var zero T
@@ -0,0 +1,113 @@
+package identity_repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	IdentityModel "gin-center/internal/domain/model/identity"
+	"gin-center/internal/types/constants"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const oauthStateKeyPrefix = "oauth:state:"
+
+type IdentityRepository struct {
+	*base_repository.GenericRepository[IdentityModel.UserIdentity]
+	client *redis.Client
+}
+
+func NewIdentityRepository(db *gorm.DB, client *redis.Client) *IdentityRepository {
+	return &IdentityRepository{
+		GenericRepository: base_repository.NewGenericRepository[IdentityModel.UserIdentity](db),
+		client:            client,
+	}
+}
+
+func (r *IdentityRepository) Create(ctx context.Context, identity *IdentityModel.UserIdentity) error {
+	if err := r.GenericRepository.Create(ctx, identity); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return constants.ErrIdentityLinked
+		}
+		return fmt.Errorf("保存第三方身份失败: %w", err)
+	}
+	return nil
+}
+
+func (r *IdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*IdentityModel.UserIdentity, error) {
+	var identity IdentityModel.UserIdentity
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrIdentityNotLinked
+		}
+		return nil, fmt.Errorf("查询第三方身份失败: %w", err)
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) ListByUser(ctx context.Context, userID uint) ([]IdentityModel.UserIdentity, error) {
+	var identities []IdentityModel.UserIdentity
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&identities).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询第三方身份失败: %w", err)
+	}
+	return identities, nil
+}
+
+func (r *IdentityRepository) DeleteByUserProvider(ctx context.Context, userID uint, provider string) error {
+	result := r.GenericRepository.DB.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&IdentityModel.UserIdentity{})
+	if result.Error != nil {
+		return fmt.Errorf("解除第三方身份关联失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrIdentityNotLinked
+	}
+	return nil
+}
+
+// SaveState 保存授权请求状态
+func (r *IdentityRepository) SaveState(ctx context.Context, state string, value *IdentityModel.OAuthState, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化授权状态失败: %w", err)
+	}
+	if err := r.client.Set(ctx, oauthStateKeyPrefix+state, data, ttl).Err(); err != nil {
+		return fmt.Errorf("保存授权状态失败: %w", err)
+	}
+	return nil
+}
+
+// ConsumeState 读取并删除授权请求状态，保证每个state只能使用一次
+func (r *IdentityRepository) ConsumeState(ctx context.Context, state string) (*IdentityModel.OAuthState, error) {
+	key := oauthStateKeyPrefix + state
+	var get *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, constants.ErrInvalidOAuthState
+		}
+		return nil, fmt.Errorf("读取授权状态失败: %w", err)
+	}
+
+	var value IdentityModel.OAuthState
+	if err := json.Unmarshal([]byte(get.Val()), &value); err != nil {
+		return nil, fmt.Errorf("解析授权状态失败: %w", err)
+	}
+	return &value, nil
+}
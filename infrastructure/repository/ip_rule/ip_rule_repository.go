@@ -0,0 +1,83 @@
+package ip_rule_repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	IPRuleModel "gin-center/internal/domain/model/ip_rule"
+	"gin-center/internal/types/constants"
+	"sort"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	rulesKey = "ip_filter:rules"
+	// changedChannel 规则变更后发布通知，各实例收到后重新加载
+	changedChannel = "ip_filter:changed"
+)
+
+// IPRuleRepository 基于Redis的访问控制规则存储，规则以ID为字段保存在同一个哈希中
+type IPRuleRepository struct {
+	client *redis.Client
+}
+
+func NewIPRuleRepository(client *redis.Client) *IPRuleRepository {
+	return &IPRuleRepository{client: client}
+}
+
+// List 返回全部规则，按创建时间排序
+func (r *IPRuleRepository) List(ctx context.Context) ([]IPRuleModel.IPRule, error) {
+	values, err := r.client.HGetAll(ctx, rulesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询访问控制规则失败: %w", err)
+	}
+	rules := make([]IPRuleModel.IPRule, 0, len(values))
+	for id, value := range values {
+		var rule IPRuleModel.IPRule
+		if err := json.Unmarshal([]byte(value), &rule); err != nil {
+			return nil, fmt.Errorf("解析访问控制规则%s失败: %w", id, err)
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+	return rules, nil
+}
+
+// Save 保存规则并通知其他实例
+func (r *IPRuleRepository) Save(ctx context.Context, rule *IPRuleModel.IPRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("序列化访问控制规则失败: %w", err)
+	}
+	if err := r.client.HSet(ctx, rulesKey, rule.ID, data).Err(); err != nil {
+		return fmt.Errorf("保存访问控制规则失败: %w", err)
+	}
+	return r.publish(ctx)
+}
+
+// Delete 删除规则并通知其他实例
+func (r *IPRuleRepository) Delete(ctx context.Context, id string) error {
+	deleted, err := r.client.HDel(ctx, rulesKey, id).Result()
+	if err != nil {
+		return fmt.Errorf("删除访问控制规则失败: %w", err)
+	}
+	if deleted == 0 {
+		return constants.ErrIPRuleNotFound
+	}
+	return r.publish(ctx)
+}
+
+// Subscribe 订阅规则变更通知，调用方负责关闭
+func (r *IPRuleRepository) Subscribe(ctx context.Context) *redis.PubSub {
+	return r.client.Subscribe(ctx, changedChannel)
+}
+
+func (r *IPRuleRepository) publish(ctx context.Context) error {
+	if err := r.client.Publish(ctx, changedChannel, "1").Err(); err != nil {
+		return fmt.Errorf("发布访问控制规则变更失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package login_history_repo
+
+import (
+	"context"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+
+	"gorm.io/gorm"
+)
+
+type LoginHistoryRepository struct {
+	*base_repository.GenericRepository[LoginHistoryModel.LoginHistory]
+}
+
+func NewLoginHistoryRepository(db *gorm.DB) *LoginHistoryRepository {
+	return &LoginHistoryRepository{
+		GenericRepository: base_repository.NewGenericRepository[LoginHistoryModel.LoginHistory](db),
+	}
+}
+
+func (r *LoginHistoryRepository) Create(ctx context.Context, history *LoginHistoryModel.LoginHistory) error {
+	if err := r.GenericRepository.Create(ctx, history); err != nil {
+		return fmt.Errorf("保存登录历史失败: %w", err)
+	}
+	return nil
+}
+
+// ListByUser 按时间倒序分页查询用户的登录历史，success为nil时不按结果过滤
+func (r *LoginHistoryRepository) ListByUser(ctx context.Context, userType int, userID uint, success *bool, page, pageSize int) ([]LoginHistoryModel.LoginHistory, int64, error) {
+	var histories []LoginHistoryModel.LoginHistory
+	var total int64
+
+	tx := r.GenericRepository.DB.WithContext(ctx).
+		Model(&LoginHistoryModel.LoginHistory{}).
+		Where("user_type = ? AND user_id = ?", userType, userID)
+	if success != nil {
+		tx = tx.Where("success = ?", *success)
+	}
+
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计登录历史失败: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := tx.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&histories).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询登录历史失败: %w", err)
+	}
+	return histories, total, nil
+}
@@ -0,0 +1,159 @@
+package oauth_client_repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	OAuthClientModel "gin-center/internal/domain/model/oauth_client"
+	"gin-center/internal/types/constants"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	authCodeKeyPrefix     = "oauth:code:"
+	revokedTokenKeyPrefix = "oauth:revoked:"
+)
+
+// ErrCodeNotFound 授权码不存在、已使用或已过期
+var ErrCodeNotFound = errors.New("授权码无效或已过期")
+
+type OAuthClientRepository struct {
+	*base_repository.GenericRepository[OAuthClientModel.OAuthClient]
+	client *redis.Client
+}
+
+func NewOAuthClientRepository(db *gorm.DB, client *redis.Client) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		GenericRepository: base_repository.NewGenericRepository[OAuthClientModel.OAuthClient](db),
+		client:            client,
+	}
+}
+
+func (r *OAuthClientRepository) Create(ctx context.Context, client *OAuthClientModel.OAuthClient) error {
+	if err := r.GenericRepository.Create(ctx, client); err != nil {
+		return fmt.Errorf("保存OAuth客户端失败: %w", err)
+	}
+	return nil
+}
+
+func (r *OAuthClientRepository) FindByID(ctx context.Context, id uint) (*OAuthClientModel.OAuthClient, error) {
+	client, err := r.GenericRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("查询OAuth客户端失败: %w", err)
+	}
+	return client, nil
+}
+
+func (r *OAuthClientRepository) FindByClientID(ctx context.Context, clientID string) (*OAuthClientModel.OAuthClient, error) {
+	var client OAuthClientModel.OAuthClient
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("client_id = ?", clientID).
+		First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("查询OAuth客户端失败: %w", err)
+	}
+	return &client, nil
+}
+
+func (r *OAuthClientRepository) List(ctx context.Context, page, pageSize int) ([]OAuthClientModel.OAuthClient, int64, error) {
+	var (
+		clients []OAuthClientModel.OAuthClient
+		total   int64
+	)
+	db := r.GenericRepository.DB.WithContext(ctx).Model(&OAuthClientModel.OAuthClient{})
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计OAuth客户端失败: %w", err)
+	}
+	err := db.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&clients).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询OAuth客户端失败: %w", err)
+	}
+	return clients, total, nil
+}
+
+func (r *OAuthClientRepository) Update(ctx context.Context, client *OAuthClientModel.OAuthClient) error {
+	if err := r.GenericRepository.Update(ctx, client); err != nil {
+		return fmt.Errorf("更新OAuth客户端失败: %w", err)
+	}
+	return nil
+}
+
+func (r *OAuthClientRepository) Delete(ctx context.Context, id uint) error {
+	result := r.GenericRepository.DB.WithContext(ctx).Delete(&OAuthClientModel.OAuthClient{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除OAuth客户端失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+// SaveCode 保存授权码上下文
+func (r *OAuthClientRepository) SaveCode(ctx context.Context, code string, value *OAuthClientModel.AuthorizationCode, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化授权码失败: %w", err)
+	}
+	if err := r.client.Set(ctx, authCodeKeyPrefix+code, data, ttl).Err(); err != nil {
+		return fmt.Errorf("保存授权码失败: %w", err)
+	}
+	return nil
+}
+
+// ConsumeCode 读取并删除授权码，授权码只能兑换一次
+func (r *OAuthClientRepository) ConsumeCode(ctx context.Context, code string) (*OAuthClientModel.AuthorizationCode, error) {
+	key := authCodeKeyPrefix + code
+	var get *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrCodeNotFound
+		}
+		return nil, fmt.Errorf("读取授权码失败: %w", err)
+	}
+
+	var value OAuthClientModel.AuthorizationCode
+	if err := json.Unmarshal([]byte(get.Val()), &value); err != nil {
+		return nil, fmt.Errorf("解析授权码失败: %w", err)
+	}
+	return &value, nil
+}
+
+// RevokeToken 将令牌ID加入撤销列表，保留至令牌自然过期
+func (r *OAuthClientRepository) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	if err := r.client.Set(ctx, revokedTokenKeyPrefix+tokenID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("撤销令牌失败: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked 判断令牌是否已被撤销
+func (r *OAuthClientRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	n, err := r.client.Exists(ctx, revokedTokenKeyPrefix+tokenID).Result()
+	if err != nil {
+		return false, fmt.Errorf("查询令牌撤销状态失败: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,27 @@
+package operation_log_repo
+
+import (
+	"context"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+
+	"gorm.io/gorm"
+)
+
+type OperationLogRepository struct {
+	*base_repository.GenericRepository[OperationLogModel.OperationLog]
+}
+
+func NewOperationLogRepository(db *gorm.DB) *OperationLogRepository {
+	return &OperationLogRepository{
+		GenericRepository: base_repository.NewGenericRepository[OperationLogModel.OperationLog](db),
+	}
+}
+
+func (r *OperationLogRepository) Create(ctx context.Context, log *OperationLogModel.OperationLog) error {
+	if err := r.GenericRepository.Create(ctx, log); err != nil {
+		return fmt.Errorf("保存操作日志失败: %w", err)
+	}
+	return nil
+}
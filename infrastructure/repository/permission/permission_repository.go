@@ -0,0 +1,96 @@
+package permission_repo
+
+import (
+	"context"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	PermissionModel "gin-center/internal/domain/model/permission"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PermissionRepository struct {
+	*base_repository.GenericRepository[PermissionModel.Permission]
+}
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{
+		GenericRepository: base_repository.NewGenericRepository[PermissionModel.Permission](db),
+	}
+}
+
+// ListActive 查询所有启用的权限
+func (r *PermissionRepository) ListActive(ctx context.Context) ([]PermissionModel.Permission, error) {
+	var permissions []PermissionModel.Permission
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("status = ?", 1).
+		Order("code ASC").
+		Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询权限失败: %w", err)
+	}
+	return permissions, nil
+}
+
+// FindActiveByCodes 按编码查询启用的权限
+func (r *PermissionRepository) FindActiveByCodes(ctx context.Context, codes []string) ([]PermissionModel.Permission, error) {
+	var permissions []PermissionModel.Permission
+	if len(codes) == 0 {
+		return permissions, nil
+	}
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("code IN ? AND status = ?", codes, 1).
+		Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询权限失败: %w", err)
+	}
+	return permissions, nil
+}
+
+// ListCodesByUser 查询用户被授予且仍启用的权限编码
+func (r *PermissionRepository) ListCodesByUser(ctx context.Context, userType int, userID string) ([]string, error) {
+	var codes []string
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Table("user_permissions AS up").
+		Joins("JOIN permissions AS p ON p.id = up.permission_id").
+		Where("up.user_id = ? AND up.user_type = ? AND p.status = ?", userID, userType, 1).
+		Order("p.code ASC").
+		Pluck("p.code", &codes).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询用户权限失败: %w", err)
+	}
+	return codes, nil
+}
+
+// ReplaceUserPermissions 以给定权限集合覆盖用户现有的权限
+func (r *PermissionRepository) ReplaceUserPermissions(ctx context.Context, userType int, userID string, permissionIDs []string, operatorID string) error {
+	return r.GenericRepository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND user_type = ?", userID, userType).
+			Delete(&PermissionModel.UserPermission{}).Error
+		if err != nil {
+			return fmt.Errorf("清除用户权限失败: %w", err)
+		}
+		if len(permissionIDs) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		grants := make([]PermissionModel.UserPermission, len(permissionIDs))
+		for i, permissionID := range permissionIDs {
+			grants[i] = PermissionModel.UserPermission{
+				ID:           uuid.New().String(),
+				UserID:       userID,
+				UserType:     userType,
+				PermissionID: permissionID,
+				OperatorID:   operatorID,
+				CreatedAt:    now,
+			}
+		}
+		if err := tx.Create(&grants).Error; err != nil {
+			return fmt.Errorf("保存用户权限失败: %w", err)
+		}
+		return nil
+	})
+}
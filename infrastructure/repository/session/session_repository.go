@@ -0,0 +1,219 @@
+package session_repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/constants"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	sessionKeyPrefix   = "session:"
+	userSessionsPrefix = "session:user:"
+	activityKeyPrefix  = "session:activity:"
+)
+
+// SessionRepository 基于Redis的会话存储
+// 会话详情按ID存储并随刷新令牌过期，另以集合维护用户到会话的索引，
+// 以有序集合按最近活跃时间维护各角色的会话，用于统计在线用户
+type SessionRepository struct {
+	client *redis.Client
+}
+
+func NewSessionRepository(client *redis.Client) *SessionRepository {
+	return &SessionRepository{client: client}
+}
+
+func sessionKey(id string) string {
+	return sessionKeyPrefix + id
+}
+
+func userSessionsKey(role string, userID uint) string {
+	return fmt.Sprintf("%s%s:%d", userSessionsPrefix, role, userID)
+}
+
+func activityKey(role string) string {
+	return activityKeyPrefix + role
+}
+
+// Save 保存会话，过期时间与会话的ExpiresAt一致
+func (r *SessionRepository) Save(ctx context.Context, session *SessionModel.Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return constants.ErrSessionNotFound
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("序列化会话失败: %w", err)
+	}
+
+	indexKey := userSessionsKey(session.Role, session.UserID)
+	indexTTL, err := r.client.TTL(ctx, indexKey).Result()
+	if err != nil {
+		return fmt.Errorf("查询会话索引失败: %w", err)
+	}
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(session.ID), data, ttl)
+		pipe.SAdd(ctx, indexKey, session.ID)
+		if indexTTL < ttl {
+			pipe.Expire(ctx, indexKey, ttl)
+		}
+		pipe.ZAdd(ctx, activityKey(session.Role), &redis.Z{
+			Score:  float64(session.LastSeenAt.Unix()),
+			Member: session.ID,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+	return nil
+}
+
+// FindByID 根据ID查询会话
+func (r *SessionRepository) FindByID(ctx context.Context, id string) (*SessionModel.Session, error) {
+	data, err := r.client.Get(ctx, sessionKey(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, constants.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	var session SessionModel.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("解析会话失败: %w", err)
+	}
+	return &session, nil
+}
+
+// ListByUser 查询用户的全部有效会话，并清理索引中已过期的会话ID
+func (r *SessionRepository) ListByUser(ctx context.Context, role string, userID uint) ([]*SessionModel.Session, error) {
+	indexKey := userSessionsKey(role, userID)
+	ids, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询会话索引失败: %w", err)
+	}
+	if len(ids) == 0 {
+		return []*SessionModel.Session{}, nil
+	}
+
+	sessions, stale, err := r.loadSessions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(stale) > 0 {
+		r.client.SRem(ctx, indexKey, stale...)
+	}
+	return sessions, nil
+}
+
+// Delete 删除会话及其索引
+func (r *SessionRepository) Delete(ctx context.Context, session *SessionModel.Session) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(session.ID))
+		pipe.SRem(ctx, userSessionsKey(session.Role, session.UserID), session.ID)
+		pipe.ZRem(ctx, activityKey(session.Role), session.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("删除会话失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUser 删除用户的全部会话，返回删除的会话数量
+func (r *SessionRepository) DeleteByUser(ctx context.Context, role string, userID uint) (int, error) {
+	indexKey := userSessionsKey(role, userID)
+	ids, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("查询会话索引失败: %w", err)
+	}
+
+	keys := make([]string, 0, len(ids)+1)
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, sessionKey(id))
+		members = append(members, id)
+	}
+	keys = append(keys, indexKey)
+	deleted, err := r.client.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("删除会话失败: %w", err)
+	}
+	if len(members) > 0 {
+		r.client.ZRem(ctx, activityKey(role), members...)
+	}
+	// 索引键本身不计入会话数量
+	if deleted > 0 {
+		deleted--
+	}
+	return int(deleted), nil
+}
+
+// ListActive 查询指定角色在since之后仍有活动的会话
+func (r *SessionRepository) ListActive(ctx context.Context, role string, since time.Time) ([]*SessionModel.Session, error) {
+	key := activityKey(role)
+	ids, err := r.client.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询活跃会话失败: %w", err)
+	}
+	if len(ids) == 0 {
+		return []*SessionModel.Session{}, nil
+	}
+
+	sessions, stale, err := r.loadSessions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(stale) > 0 {
+		r.client.ZRem(ctx, key, stale...)
+	}
+	return sessions, nil
+}
+
+// PruneActivity 清理最近活跃时间早于before的会话活动记录
+func (r *SessionRepository) PruneActivity(ctx context.Context, role string, before time.Time) error {
+	err := r.client.ZRemRangeByScore(ctx, activityKey(role), "-inf", "("+strconv.FormatInt(before.Unix(), 10)).Err()
+	if err != nil {
+		return fmt.Errorf("清理会话活动记录失败: %w", err)
+	}
+	return nil
+}
+
+// loadSessions 批量读取会话，返回已不存在或无法解析的会话ID
+func (r *SessionRepository) loadSessions(ctx context.Context, ids []string) ([]*SessionModel.Session, []interface{}, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKey(id)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("批量查询会话失败: %w", err)
+	}
+
+	sessions := make([]*SessionModel.Session, 0, len(values))
+	stale := make([]interface{}, 0)
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			stale = append(stale, ids[i])
+			continue
+		}
+		var session SessionModel.Session
+		if err := json.Unmarshal([]byte(raw), &session); err != nil {
+			stale = append(stale, ids[i])
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+	return sessions, stale, nil
+}
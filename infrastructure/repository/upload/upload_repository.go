@@ -0,0 +1,305 @@
+package upload_repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	base_repository "gin-center/infrastructure/repository/base_repository"
+	UploadModel "gin-center/internal/domain/model/upload"
+	"gin-center/internal/types/constants"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	uploadKeyPrefix     = "upload:"
+	userUploadsPrefix   = "upload:user:"
+	uploadExpiryKey     = "upload:expiry"
+	uploadChunksPostfix = ":chunks"
+	userLockPrefix      = "upload:lock:"
+
+	// userLockTTL 用户锁的最长持有时间，持有锁的实例异常退出后到期自动释放
+	userLockTTL = 10 * time.Second
+	// userLockWait 等待其他请求释放用户锁的最长时间
+	userLockWait  = 3 * time.Second
+	userLockRetry = 50 * time.Millisecond
+)
+
+// unlockScript 仅删除自己持有的锁，避免锁到期后误删其他请求获取的锁
+var unlockScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// UploadRepository 上传任务保存在Redis中，按ID存储并随任务过期，另以集合维护用户到任务的索引，
+// 以有序集合按过期时间维护全部任务，供后台清理过期任务的分片；上传完成的文件保存在数据库中
+type UploadRepository struct {
+	*base_repository.GenericRepository[UploadModel.File]
+	client *redis.Client
+}
+
+func NewUploadRepository(db *gorm.DB, client *redis.Client) *UploadRepository {
+	return &UploadRepository{
+		GenericRepository: base_repository.NewGenericRepository[UploadModel.File](db),
+		client:            client,
+	}
+}
+
+func uploadKey(id string) string {
+	return uploadKeyPrefix + id
+}
+
+func chunksKey(id string) string {
+	return uploadKeyPrefix + id + uploadChunksPostfix
+}
+
+func userUploadsKey(userType int, userID uint) string {
+	return fmt.Sprintf("%s%d:%d", userUploadsPrefix, userType, userID)
+}
+
+// LockUser 获取用户的上传锁，同一用户的配额检查与任务创建串行执行，多个实例间同样有效
+// 等待超时返回ErrUploadBusy；返回的函数释放锁，须在任务保存后调用
+func (r *UploadRepository) LockUser(ctx context.Context, userType int, userID uint) (func(), error) {
+	key := fmt.Sprintf("%s%d:%d", userLockPrefix, userType, userID)
+	token := uuid.New().String()
+	deadline := time.Now().Add(userLockWait)
+	for {
+		acquired, err := r.client.SetNX(ctx, key, token, userLockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("获取上传锁失败: %w", err)
+		}
+		if acquired {
+			return func() {
+				unlockScript.Run(context.WithoutCancel(ctx), r.client, []string{key}, token)
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, constants.ErrUploadBusy
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(userLockRetry):
+		}
+	}
+}
+
+// SaveUpload 保存上传任务，过期时间与任务的ExpiresAt一致
+func (r *UploadRepository) SaveUpload(ctx context.Context, upload *UploadModel.Upload) error {
+	ttl := time.Until(upload.ExpiresAt)
+	if ttl <= 0 {
+		return constants.ErrUploadNotFound
+	}
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return fmt.Errorf("序列化上传任务失败: %w", err)
+	}
+	indexKey := userUploadsKey(upload.UserType, upload.UserID)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, uploadKey(upload.ID), data, ttl)
+		pipe.SAdd(ctx, indexKey, upload.ID)
+		pipe.Expire(ctx, indexKey, ttl)
+		pipe.ZAdd(ctx, uploadExpiryKey, &redis.Z{
+			Score:  float64(upload.ExpiresAt.Unix()),
+			Member: upload.ID,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("保存上传任务失败: %w", err)
+	}
+	return nil
+}
+
+// FindUpload 根据ID查询上传任务
+func (r *UploadRepository) FindUpload(ctx context.Context, id string) (*UploadModel.Upload, error) {
+	data, err := r.client.Get(ctx, uploadKey(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, constants.ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("查询上传任务失败: %w", err)
+	}
+	var upload UploadModel.Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("解析上传任务失败: %w", err)
+	}
+	return &upload, nil
+}
+
+// ListUploads 查询用户进行中的上传任务，并清理索引中已过期的任务ID
+func (r *UploadRepository) ListUploads(ctx context.Context, userType int, userID uint) ([]*UploadModel.Upload, error) {
+	indexKey := userUploadsKey(userType, userID)
+	ids, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询上传任务索引失败: %w", err)
+	}
+	if len(ids) == 0 {
+		return []*UploadModel.Upload{}, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = uploadKey(id)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("批量查询上传任务失败: %w", err)
+	}
+
+	uploads := make([]*UploadModel.Upload, 0, len(values))
+	stale := make([]interface{}, 0)
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			stale = append(stale, ids[i])
+			continue
+		}
+		var upload UploadModel.Upload
+		if err := json.Unmarshal([]byte(raw), &upload); err != nil {
+			stale = append(stale, ids[i])
+			continue
+		}
+		uploads = append(uploads, &upload)
+	}
+	if len(stale) > 0 {
+		r.client.SRem(ctx, indexKey, stale...)
+	}
+	return uploads, nil
+}
+
+// MarkChunk 记录已接收的分片，分片集合与任务同时过期
+func (r *UploadRepository) MarkChunk(ctx context.Context, upload *UploadModel.Upload, index int) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, chunksKey(upload.ID), index)
+		pipe.ExpireAt(ctx, chunksKey(upload.ID), upload.ExpiresAt)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("记录分片失败: %w", err)
+	}
+	return nil
+}
+
+// ReceivedChunks 返回已接收的分片序号
+func (r *UploadRepository) ReceivedChunks(ctx context.Context, id string) ([]int, error) {
+	members, err := r.client.SMembers(ctx, chunksKey(id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询已接收分片失败: %w", err)
+	}
+	chunks := make([]int, 0, len(members))
+	for _, member := range members {
+		if index, err := strconv.Atoi(member); err == nil {
+			chunks = append(chunks, index)
+		}
+	}
+	return chunks, nil
+}
+
+// ClaimUpload 从过期索引中移除任务，返回false表示任务已被其他请求或清理任务认领
+// 合并文件与清理过期任务前均需先认领，避免同一任务被重复处理
+func (r *UploadRepository) ClaimUpload(ctx context.Context, id string) (bool, error) {
+	removed, err := r.client.ZRem(ctx, uploadExpiryKey, id).Result()
+	if err != nil {
+		return false, fmt.Errorf("认领上传任务失败: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// DeleteUpload 删除上传任务及其分片记录和索引
+func (r *UploadRepository) DeleteUpload(ctx context.Context, upload *UploadModel.Upload) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, uploadKey(upload.ID), chunksKey(upload.ID))
+		pipe.SRem(ctx, userUploadsKey(upload.UserType, upload.UserID), upload.ID)
+		pipe.ZRem(ctx, uploadExpiryKey, upload.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("删除上传任务失败: %w", err)
+	}
+	return nil
+}
+
+// ExpiredUploads 查询过期时间早于before的任务ID
+func (r *UploadRepository) ExpiredUploads(ctx context.Context, before time.Time, limit int64) ([]string, error) {
+	ids, err := r.client.ZRangeByScore(ctx, uploadExpiryKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询过期上传任务失败: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *UploadRepository) CreateFile(ctx context.Context, file *UploadModel.File) error {
+	if err := r.GenericRepository.Create(ctx, file); err != nil {
+		return fmt.Errorf("保存文件记录失败: %w", err)
+	}
+	return nil
+}
+
+// FindFile 查询用户自己的文件
+func (r *UploadRepository) FindFile(ctx context.Context, userType int, userID, id uint) (*UploadModel.File, error) {
+	var file UploadModel.File
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND user_type = ?", id, userID, userType).
+		First(&file).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constants.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("查询文件失败: %w", err)
+	}
+	return &file, nil
+}
+
+// ListFiles 分页查询用户的文件，按上传时间倒序
+func (r *UploadRepository) ListFiles(ctx context.Context, userType int, userID uint, page, pageSize int) ([]UploadModel.File, int64, error) {
+	var files []UploadModel.File
+	var total int64
+	db := r.GenericRepository.DB.WithContext(ctx).
+		Model(&UploadModel.File{}).
+		Where("user_id = ? AND user_type = ?", userID, userType)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计文件失败: %w", err)
+	}
+	err := db.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&files).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询文件失败: %w", err)
+	}
+	return files, total, nil
+}
+
+// UsedBytes 统计用户已保存文件的总大小
+func (r *UploadRepository) UsedBytes(ctx context.Context, userType int, userID uint) (int64, error) {
+	var used int64
+	err := r.GenericRepository.DB.WithContext(ctx).
+		Model(&UploadModel.File{}).
+		Where("user_id = ? AND user_type = ?", userID, userType).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&used).Error
+	if err != nil {
+		return 0, fmt.Errorf("统计已用空间失败: %w", err)
+	}
+	return used, nil
+}
+
+// DeleteFile 删除用户自己的文件记录
+func (r *UploadRepository) DeleteFile(ctx context.Context, userType int, userID, id uint) error {
+	result := r.GenericRepository.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND user_type = ?", id, userID, userType).
+		Delete(&UploadModel.File{})
+	if result.Error != nil {
+		return fmt.Errorf("删除文件记录失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrFileNotFound
+	}
+	return nil
+}
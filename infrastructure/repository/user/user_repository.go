@@ -5,8 +5,13 @@ import (
 	"errors"
 	"fmt"
 	infraErrors "gin-center/infrastructure/errors"
+	APIKeyModel "gin-center/internal/domain/model/api_key"
+	IdentityModel "gin-center/internal/domain/model/identity"
+	PermissionModel "gin-center/internal/domain/model/permission"
 	UserModel "gin-center/internal/domain/model/user"
 	"gin-center/internal/types/constants"
+	"strconv"
+	"time"
 
 	base_repository "gin-center/infrastructure/repository/base_repository"
 
@@ -35,14 +40,19 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 }
 
 func (r *UserRepository) Register(ctx context.Context, user *UserModel.User) error {
-	_, err := base_repository.WithTx(ctx, func(txCtx context.Context) (*UserModel.User, error) {
+	_, err := base_repository.WithTx(base_repository.ContextWithDB(ctx, r.GenericRepository.DB), func(txCtx context.Context) (*UserModel.User, error) {
 		if exists, err := r.isUsernameExists(txCtx, user.Username); err != nil {
 			return nil, fmt.Errorf("检查用户名是否存在失败: %w", err)
 		} else if exists {
 			return nil, infraErrors.ErrUsernameExists
 		}
 
-		if err := r.GenericRepository.DB.WithContext(txCtx).Create(user).Error; err != nil {
+		// 未填写手机号时不写入phone列，与CreateBatch一致，避免空字符串触发唯一索引冲突
+		db := r.GenericRepository.DB.WithContext(txCtx)
+		if user.Phone == "" {
+			db = db.Omit("Phone")
+		}
+		if err := db.Create(user).Error; err != nil {
 			return nil, fmt.Errorf("创建用户失败: %w", err)
 		}
 		return user, nil
@@ -51,7 +61,7 @@ func (r *UserRepository) Register(ctx context.Context, user *UserModel.User) err
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *UserModel.User) (*UserModel.User, error) {
-	return base_repository.WithTx(ctx, func(txCtx context.Context) (*UserModel.User, error) {
+	return base_repository.WithTx(base_repository.ContextWithDB(ctx, r.GenericRepository.DB), func(txCtx context.Context) (*UserModel.User, error) {
 		existingUser, err := r.FindByID(txCtx, user.ID)
 		if err != nil {
 			return nil, err
@@ -97,26 +107,99 @@ func (r *UserRepository) ListUsers(ctx context.Context, page, pageSize int, quer
 	var users []*UserModel.User
 	var total int64
 
-	tx := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{})
-	for key, value := range query {
-		switch key {
-		case "username":
-			tx = tx.Where("username LIKE ?", fmt.Sprintf("%%%v%%", value))
-		}
-	}
+	tx := applyFilters(r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}), query)
 
 	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("统计用户数量失败: %w", err)
 	}
 
 	offset := (page - 1) * pageSize
-	if err := tx.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err := tx.Order("id DESC").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
 		return nil, 0, fmt.Errorf("查询用户列表失败: %w", err)
 	}
 
 	return users, total, nil
 }
 
+// EachBatch 按ID升序分批遍历符合条件的用户，fn返回错误时停止遍历
+func (r *UserRepository) EachBatch(ctx context.Context, query map[string]interface{}, batchSize int, fn func(users []*UserModel.User) error) error {
+	var lastID uint
+	for {
+		var users []*UserModel.User
+		tx := applyFilters(r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}), query)
+		if err := tx.Where("id > ?", lastID).Order("id ASC").Limit(batchSize).Find(&users).Error; err != nil {
+			return fmt.Errorf("查询用户列表失败: %w", err)
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := fn(users); err != nil {
+			return err
+		}
+		if len(users) < batchSize {
+			return nil
+		}
+		lastID = users[len(users)-1].ID
+	}
+}
+
+// FindExisting 返回已被占用的用户名和手机号
+func (r *UserRepository) FindExisting(ctx context.Context, usernames, phones []string) ([]string, []string, error) {
+	var existingUsernames, existingPhones []string
+	db := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{})
+	if len(usernames) > 0 {
+		if err := db.Where("username IN ?", usernames).Pluck("username", &existingUsernames).Error; err != nil {
+			return nil, nil, fmt.Errorf("查询已存在的用户名失败: %w", err)
+		}
+	}
+	if len(phones) > 0 {
+		db = r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{})
+		if err := db.Where("phone IN ?", phones).Pluck("phone", &existingPhones).Error; err != nil {
+			return nil, nil, fmt.Errorf("查询已存在的手机号失败: %w", err)
+		}
+	}
+	return existingUsernames, existingPhones, nil
+}
+
+// CreateBatch 在同一事务中批量创建用户
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*UserModel.User) error {
+	return r.GenericRepository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 未填写手机号的用户不写入phone列，使其保持NULL以免触发唯一索引冲突
+		var withPhone, withoutPhone []*UserModel.User
+		for _, user := range users {
+			if user.Phone == "" {
+				withoutPhone = append(withoutPhone, user)
+			} else {
+				withPhone = append(withPhone, user)
+			}
+		}
+		if len(withPhone) > 0 {
+			if err := tx.Create(&withPhone).Error; err != nil {
+				return fmt.Errorf("批量创建用户失败: %w", err)
+			}
+		}
+		if len(withoutPhone) > 0 {
+			if err := tx.Omit("Phone").Create(&withoutPhone).Error; err != nil {
+				return fmt.Errorf("批量创建用户失败: %w", err)
+			}
+		}
+		// status为零值时GORM会写入列默认值，需单独更新禁用的用户
+		var disabled []uint
+		for _, user := range users {
+			if user.Status == 0 {
+				disabled = append(disabled, user.ID)
+			}
+		}
+		if len(disabled) == 0 {
+			return nil
+		}
+		if err := tx.Model(&UserModel.User{}).Where("id IN ?", disabled).Update("status", 0).Error; err != nil {
+			return fmt.Errorf("更新用户状态失败: %w", err)
+		}
+		return nil
+	})
+}
+
 func (r *UserRepository) UpdateAvatar(ctx context.Context, userID uint, avatarPath string) error {
 	result := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}).Where("id = ?", userID).Update("avatar", avatarPath)
 	if result.Error != nil {
@@ -128,6 +211,70 @@ func (r *UserRepository) UpdateAvatar(ctx context.Context, userID uint, avatarPa
 	return nil
 }
 
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uint, hashedPassword string) error {
+	result := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}).Where("id = ?", userID).Update("password", hashedPassword)
+	if result.Error != nil {
+		return fmt.Errorf("更新用户密码失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateStatus 启用或禁用用户
+func (r *UserRepository) UpdateStatus(ctx context.Context, userID uint, status int) error {
+	result := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}).Where("id = ?", userID).Update("status", status)
+	if result.Error != nil {
+		return fmt.Errorf("更新用户状态失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.FindByID(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteWithRelations 删除用户及其第三方身份、API密钥和权限授予
+func (r *UserRepository) DeleteWithRelations(ctx context.Context, userID uint, userType int) error {
+	return r.GenericRepository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&UserModel.User{}, userID)
+		if result.Error != nil {
+			return fmt.Errorf("删除用户失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return constants.ErrUserNotFound
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&IdentityModel.UserIdentity{}).Error; err != nil {
+			return fmt.Errorf("删除用户第三方身份失败: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND user_type = ?", userID, userType).Delete(&APIKeyModel.APIKey{}).Error; err != nil {
+			return fmt.Errorf("删除用户API密钥失败: %w", err)
+		}
+		err := tx.Where("user_id = ? AND user_type = ?", strconv.FormatUint(uint64(userID), 10), userType).
+			Delete(&PermissionModel.UserPermission{}).Error
+		if err != nil {
+			return fmt.Errorf("删除用户权限失败: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) UpdateLoginInfo(ctx context.Context, userID uint, ip string, loginAt time.Time) error {
+	result := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"last_login_at": loginAt,
+		"last_login_ip": ip,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("更新登录信息失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return constants.ErrUserNotFound
+	}
+	return nil
+}
+
 func (r *UserRepository) isUsernameExists(ctx context.Context, username string) (bool, error) {
 	var count int64
 	if err := r.GenericRepository.DB.WithContext(ctx).Model(&UserModel.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
@@ -135,3 +282,19 @@ func (r *UserRepository) isUsernameExists(ctx context.Context, username string)
 	}
 	return count > 0, nil
 }
+
+// applyFilters 应用用户列表的筛选条件
+func applyFilters(tx *gorm.DB, query map[string]interface{}) *gorm.DB {
+	for key, value := range query {
+		switch key {
+		case "username":
+			tx = tx.Where("username LIKE ?", fmt.Sprintf("%%%v%%", value))
+		case "keyword":
+			keyword := fmt.Sprintf("%%%v%%", value)
+			tx = tx.Where("username LIKE ? OR nickname LIKE ? OR phone LIKE ?", keyword, keyword, keyword)
+		case "status":
+			tx = tx.Where("status = ?", value)
+		}
+	}
+	return tx
+}
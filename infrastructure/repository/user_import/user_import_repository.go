@@ -0,0 +1,52 @@
+package user_import_repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	UserImportModel "gin-center/internal/domain/model/user_import"
+	"gin-center/internal/types/constants"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const jobKeyPrefix = "user:import:job:"
+
+// UserImportRepository 基于Redis的导入任务存储
+type UserImportRepository struct {
+	client *redis.Client
+}
+
+func NewUserImportRepository(client *redis.Client) *UserImportRepository {
+	return &UserImportRepository{client: client}
+}
+
+// SaveJob 保存导入任务，每次保存都会重置过期时间
+func (r *UserImportRepository) SaveJob(ctx context.Context, job *UserImportModel.Job, ttl time.Duration) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("序列化导入任务失败: %w", err)
+	}
+	if err := r.client.Set(ctx, jobKeyPrefix+job.ID, data, ttl).Err(); err != nil {
+		return fmt.Errorf("保存导入任务失败: %w", err)
+	}
+	return nil
+}
+
+// FindJob 查询导入任务
+func (r *UserImportRepository) FindJob(ctx context.Context, id string) (*UserImportModel.Job, error) {
+	data, err := r.client.Get(ctx, jobKeyPrefix+id).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, constants.ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("查询导入任务失败: %w", err)
+	}
+	var job UserImportModel.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("解析导入任务失败: %w", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,208 @@
+package zaplogger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-center/configs/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var (
+	// level 全局日志级别，所有输出共用，可在运行时调整
+	level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
+	mu sync.RWMutex
+	// base 所有ServiceLogger共用的底层日志记录器
+	base = zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig()), zapcore.Lock(os.Stdout), level), zap.AddCaller())
+	// closers Setup打开的日志文件和syslog连接
+	closers []io.Closer
+	// stopRotate 停止按时间切割的后台任务
+	stopRotate chan struct{}
+)
+
+// Setup 按配置构建全局日志记录器并替换zap的全局记录器，name用作syslog的默认标识
+// 各输出的级别在全局级别之上再次过滤；文件输出按大小切割，配置了rotate_interval时另按时间切割
+func Setup(cfg *config.LogConfig, name string) error {
+	if err := SetLevel(cfg.Level); err != nil {
+		level.SetLevel(zap.InfoLevel)
+	}
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []config.LogOutputConfig{{Type: "file"}}
+	}
+
+	var (
+		cores     []zapcore.Core
+		opened    []io.Closer
+		rotations []*lumberjack.Logger
+	)
+	fail := func(err error) error {
+		for _, c := range opened {
+			_ = c.Close()
+		}
+		return fmt.Errorf("%w: %v", ErrInitLoggerFailed, err)
+	}
+	for _, output := range outputs {
+		enabler, err := outputLevel(output.Level)
+		if err != nil {
+			return fail(err)
+		}
+		format := output.Format
+		if format == "" {
+			format = cfg.Format
+		}
+		encoder := newEncoder(format)
+
+		switch output.Type {
+		case "stdout":
+			cores = append(cores, zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), enabler))
+		case "stderr":
+			cores = append(cores, zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), enabler))
+		case "file":
+			filename := output.Filename
+			if filename == "" {
+				filename = cfg.Filename
+			}
+			if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				return fail(fmt.Errorf("创建日志目录失败: %w", err))
+			}
+			writer := &lumberjack.Logger{
+				Filename:   filename,
+				MaxSize:    cfg.MaxSize,
+				MaxBackups: cfg.MaxBackups,
+				MaxAge:     cfg.MaxAge,
+				Compress:   cfg.Compress,
+				LocalTime:  true,
+			}
+			opened = append(opened, writer)
+			rotations = append(rotations, writer)
+			cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(writer), enabler))
+		case "syslog":
+			tag := output.Tag
+			if tag == "" {
+				tag = name
+			}
+			core, closer, err := newSyslogCore(output.Network, output.Address, tag, encoder, enabler)
+			if err != nil {
+				return fail(fmt.Errorf("连接syslog失败: %w", err))
+			}
+			opened = append(opened, closer)
+			cores = append(cores, core)
+		default:
+			return fail(fmt.Errorf("不支持的日志输出类型: %s", output.Type))
+		}
+	}
+
+	logger := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr)))
+
+	mu.Lock()
+	previous := closers
+	stopRotation()
+	base = logger
+	closers = opened
+	if cfg.RotateInterval > 0 && len(rotations) > 0 {
+		stopRotate = make(chan struct{})
+		go rotateEvery(cfg.RotateInterval, rotations, stopRotate)
+	}
+	mu.Unlock()
+
+	zap.ReplaceGlobals(logger)
+	for _, c := range previous {
+		_ = c.Close()
+	}
+	return nil
+}
+
+// Close 刷新缓冲并关闭日志文件和syslog连接，应在程序退出前调用
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	stopRotation()
+	err := base.Sync()
+	for _, c := range closers {
+		if closeErr := c.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	closers = nil
+	return err
+}
+
+// Level 返回当前的全局日志级别
+func Level() string {
+	return level.String()
+}
+
+// SetLevel 调整全局日志级别，立即对所有日志记录器生效
+func SetLevel(text string) error {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(text))); err != nil {
+		return fmt.Errorf("无效的日志级别: %s", text)
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+// outputLevel 输出级别为空时只受全局级别控制
+func outputLevel(text string) (zapcore.LevelEnabler, error) {
+	if text == "" {
+		return level, nil
+	}
+	var min zapcore.Level
+	if err := min.UnmarshalText([]byte(strings.ToLower(text))); err != nil {
+		return nil, fmt.Errorf("无效的日志级别: %s", text)
+	}
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= min && level.Enabled(l)
+	}), nil
+}
+
+func encoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	return cfg
+}
+
+func newEncoder(format string) zapcore.Encoder {
+	if format == "console" {
+		return zapcore.NewConsoleEncoder(encoderConfig())
+	}
+	return zapcore.NewJSONEncoder(encoderConfig())
+}
+
+// rotateEvery 按固定间隔切割日志文件，切割时间按间隔对齐，如24h在每天0点（UTC）切割
+func rotateEvery(interval time.Duration, writers []*lumberjack.Logger, stop <-chan struct{}) {
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(interval).Add(interval).Sub(now))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			for _, w := range writers {
+				if err := w.Rotate(); err != nil {
+					fmt.Fprintf(os.Stderr, "切割日志文件失败: %v\n", err)
+				}
+			}
+		}
+	}
+}
+
+// stopRotation 调用方需持有mu
+func stopRotation() {
+	if stopRotate != nil {
+		close(stopRotate)
+		stopRotate = nil
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package zaplogger
+
+import (
+	"errors"
+	"io"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func newSyslogCore(network, address, tag string, encoder zapcore.Encoder, enabler zapcore.LevelEnabler) (zapcore.Core, io.Closer, error) {
+	return nil, nil, errors.New("当前平台不支持syslog")
+}
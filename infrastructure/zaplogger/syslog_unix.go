@@ -0,0 +1,65 @@
+//go:build unix
+
+package zaplogger
+
+import (
+	"io"
+	"log/syslog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogCore 按日志级别映射syslog优先级写入
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  *syslog.Writer
+}
+
+func newSyslogCore(network, address, tag string, encoder zapcore.Encoder, enabler zapcore.LevelEnabler) (zapcore.Core, io.Closer, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_LOCAL0, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &syslogCore{LevelEnabler: enabler, encoder: encoder, writer: writer}, writer, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, encoder: encoder, writer: c.writer}
+}
+
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	message := buf.String()
+	switch {
+	case entry.Level >= zapcore.DPanicLevel:
+		return c.writer.Crit(message)
+	case entry.Level == zapcore.ErrorLevel:
+		return c.writer.Err(message)
+	case entry.Level == zapcore.WarnLevel:
+		return c.writer.Warning(message)
+	case entry.Level == zapcore.InfoLevel:
+		return c.writer.Info(message)
+	default:
+		return c.writer.Debug(message)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
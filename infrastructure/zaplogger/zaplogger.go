@@ -1,51 +1,22 @@
 package zaplogger
 
 import (
+	"context"
 	"errors"
-	"os"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 type ServiceLogger struct {
 	logger *zap.Logger
 }
 
-// LoggerConfig 日志配置项
-type LoggerConfig struct {
-	Level    string
-	Filename string
-}
-
-// NewLogger 创建一个新的日志记录器
+// NewServiceLogger 返回基于全局日志配置的日志记录器，Setup之前输出到标准输出
 func NewServiceLogger() *ServiceLogger {
-	// 创建默认配置
-	cfg := LoggerConfig{
-		Level: "info", // 默认日志级别为 info
-	}
-
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder   // 设置时间格式为 ISO8601
-	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder // 级别显示为大写
-
-	// 解析日志级别
-	logLevel := zap.NewAtomicLevel()
-	if err := logLevel.UnmarshalText([]byte(cfg.Level)); err != nil {
-		logLevel.SetLevel(zap.InfoLevel) // 解析失败时回退到 Info 级别
-	}
-
-	// 创建核心组件
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig), // JSON 编码器
-		zapcore.AddSync(os.Stdout),            // 输出到标准输出
-		logLevel,                              // 日志级别
-	)
-
-	// 创建 zap.Logger 并添加调用方信息
-	zapLogger := zap.New(core, zap.AddCaller())
-
-	return &ServiceLogger{logger: zapLogger}
+	mu.RLock()
+	defer mu.RUnlock()
+	return &ServiceLogger{logger: base}
 }
 
 // LogInfo 记录一条信息日志
@@ -85,5 +56,38 @@ func (l *ServiceLogger) With(fields ...zap.Field) *ServiceLogger {
 	}
 }
 
+// loggerKey 请求级日志记录器在context中的键
+type loggerKey struct{}
+
+// WithContext 将请求级日志记录器附加到context，供下游服务记录带请求ID的日志
+func WithContext(ctx context.Context, logger *ServiceLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 返回context中的请求级日志记录器
+func FromContext(ctx context.Context) (*ServiceLogger, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	logger, ok := ctx.Value(loggerKey{}).(*ServiceLogger)
+	return logger, ok && logger != nil
+}
+
+// Ctx 优先返回context中的请求级日志记录器，不存在时返回自身
+// context中有有效的span时附加trace_id和span_id，日志可与链路追踪关联
+func (l *ServiceLogger) Ctx(ctx context.Context) *ServiceLogger {
+	logger := l
+	if scoped, ok := FromContext(ctx); ok {
+		logger = scoped
+	}
+	if ctx == nil {
+		return logger
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return logger.With(zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+	}
+	return logger
+}
+
 // 包级错误定义
 var ErrInitLoggerFailed = errors.New("日志初始化失败")
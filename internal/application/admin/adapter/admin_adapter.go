@@ -2,7 +2,11 @@
 package admin_adapter
 
 import (
+	"context"
 	use_AdminInterface "gin-center/internal/domain/interface/admin"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+	security_types "gin-center/pkg/security/types"
 )
 
 // adminServiceAdapter 实现了AdminServiceInterface接口的适配器结构体
@@ -31,12 +35,12 @@ func NewAdminServiceAdapter(adminService use_AdminInterface.AdminServiceInterfac
 // @Success 200 {object} map[string]interface{} "登录成功"
 // @Failure 401 {object} error "登录失败"
 // @Router /admin/login [post]
-func (a *adminServiceAdapter) Login(username, password string) (string, map[string]interface{}, error) {
-	token, adminInfo, err := a.adminService.Login(username, password)
+func (a *adminServiceAdapter) Login(ctx context.Context, username, password string, meta *auth.LoginMeta) (*security_types.TokenPair, map[string]interface{}, error) {
+	tokens, adminInfo, err := a.adminService.Login(ctx, username, password, meta)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return token, adminInfo, nil
+	return tokens, adminInfo, nil
 }
 
 // Register 管理员注册方法
@@ -50,8 +54,8 @@ func (a *adminServiceAdapter) Login(username, password string) (string, map[stri
 // @Success 200 {string} string "注册成功"
 // @Failure 400 {object} error "注册失败"
 // @Router /admin/register [post]
-func (a *adminServiceAdapter) Register(username, password string) error {
-	return a.adminService.Register(username, password)
+func (a *adminServiceAdapter) Register(ctx context.Context, username, password string) error {
+	return a.adminService.Register(ctx, username, password)
 }
 
 // GetAdminInfo 获取管理员信息
@@ -64,8 +68,8 @@ func (a *adminServiceAdapter) Register(username, password string) error {
 // @Success 200 {object} map[string]interface{} "管理员信息"
 // @Failure 404 {object} error "未找到管理员"
 // @Router /admin/info/{username} [get]
-func (a *adminServiceAdapter) GetAdminInfo(username string) (*map[string]any, error) {
-	return a.adminService.GetAdminInfo(username)
+func (a *adminServiceAdapter) GetAdminInfo(ctx context.Context, username string) (*map[string]any, error) {
+	return a.adminService.GetAdminInfo(ctx, username)
 }
 
 // UpdateAdmin 更新管理员信息
@@ -79,8 +83,8 @@ func (a *adminServiceAdapter) GetAdminInfo(username string) (*map[string]any, er
 // @Success 200 {string} string "更新成功"
 // @Failure 400 {object} error "更新失败"
 // @Router /admin/{username} [put]
-func (a *adminServiceAdapter) UpdateAdmin(username string, updates map[string]interface{}) error {
-	return a.adminService.UpdateAdmin(username, updates)
+func (a *adminServiceAdapter) UpdateAdmin(ctx context.Context, username string, updates map[string]interface{}) error {
+	return a.adminService.UpdateAdmin(ctx, username, updates)
 }
 
 // PaginateAdmins 分页获取管理员列表
@@ -91,9 +95,8 @@ func (a *adminServiceAdapter) UpdateAdmin(username string, updates map[string]in
 // @Produce json
 // @Param page query int true "页码"
 // @Param pageSize query int true "每页数量"
-// @Success 200 {array} map[string]interface{} "管理员列表"
-// @Success 200 {integer} int64 "总数量"
-// @Router /admin/list [get]
-func (a *adminServiceAdapter) PaginateAdmins(page, pageSize int) ([]map[string]any, int64, error) {
-	return a.adminService.PaginateAdmins(page, pageSize)
+// @Success 200 {object} type_response.AdminListResponse "管理员列表"
+// @Router /admin/admins [get]
+func (a *adminServiceAdapter) PaginateAdmins(ctx context.Context, page, pageSize int) (*type_response.AdminListResponse, error) {
+	return a.adminService.PaginateAdmins(ctx, page, pageSize)
 }
@@ -5,17 +5,24 @@ import (
 	"context"
 	"fmt"
 	"gin-center/configs/config"
+	"gin-center/infrastructure/metrics"
 	"gin-center/infrastructure/repository/admin"
 	zaplogger "gin-center/infrastructure/zaplogger"
 	use_Baseservice "gin-center/internal/application"
+	use_loginHistoryInterface "gin-center/internal/domain/interface/login_history"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
 	AdminModel "gin-center/internal/domain/model/admin"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/auth"
 	"gin-center/internal/types/constants"
-	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	security_types "gin-center/pkg/security/types"
 	useJwt "gin-center/pkg/security/useJwt"
+	"gin-center/pkg/security/usePassword"
 	"time"
 
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -26,16 +33,22 @@ type AdminService struct {
 	adminRepo   *admin.AdminRepository
 	jwtConfig   *useJwt.JWTConfig
 	config      *config.GlobalConfig
+	sessions    use_sessionInterface.SessionServiceInterface
+	history     use_loginHistoryInterface.LoginHistoryServiceInterface
 }
 
 // NewAdminService 创建新的管理员服务实例
-func NewAdminService(adminRepo *admin.AdminRepository, jwtConfig *useJwt.JWTConfig, config *config.GlobalConfig, logger *zaplogger.ServiceLogger) *AdminService {
+func NewAdminService(adminRepo *admin.AdminRepository, jwtConfig *useJwt.JWTConfig, config *config.GlobalConfig, logger *zaplogger.ServiceLogger, passwordHasher usePassword.Hasher, sessions use_sessionInterface.SessionServiceInterface, history use_loginHistoryInterface.LoginHistoryServiceInterface) *AdminService {
 	return &AdminService{
-		baseService: use_Baseservice.NewBaseService(&use_Baseservice.BaseServiceConfig{}),
-		logger:      zaplogger.NewServiceLogger(),
-		adminRepo:   adminRepo,
-		jwtConfig:   jwtConfig,
-		config:      config,
+		baseService: use_Baseservice.NewBaseService(&use_Baseservice.BaseServiceConfig{
+			PasswordHasher: passwordHasher,
+		}),
+		logger:    zaplogger.NewServiceLogger(),
+		adminRepo: adminRepo,
+		jwtConfig: jwtConfig,
+		config:    config,
+		sessions:  sessions,
+		history:   history,
 	}
 }
 
@@ -53,7 +66,7 @@ func (s *AdminService) handleLoginError(err error, logMsg string, errType error,
 
 // validatePassword 验证密码有效性
 func (s *AdminService) validatePassword(hashedPassword, inputPassword, username string) error {
-	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(inputPassword)); err != nil {
+	if err := s.baseService.ComparePassword(inputPassword, hashedPassword); err != nil {
 		s.logger.LogWarn("密码验证失败",
 			zap.String("module", "login"),
 			zap.String("username", username),
@@ -83,8 +96,8 @@ func (s *AdminService) checkAdminStatus(admin *AdminModel.Admin, username string
 //   - error: 注册过程中的错误信息
 //
 // 更新后的Register方法
-func (s *AdminService) Register(username, password string) error {
-	return s.withTransaction(context.Background(), func(tx *gorm.DB) error {
+func (s *AdminService) Register(ctx context.Context, username, password string) error {
+	err := s.withTransaction(ctx, func(tx *gorm.DB) error {
 		if err := s.baseService.ValidateUserInput(username, password); err != nil {
 			return s.handleError(err, "register", username, "输入验证失败")
 		}
@@ -99,6 +112,10 @@ func (s *AdminService) Register(username, password string) error {
 			Password: hashedPassword,
 		}).Error
 	})
+	if err == nil {
+		metrics.RecordRegistrations(LoginHistoryModel.UserTypeAdmin, metrics.SourceRegister, 1)
+	}
+	return err
 }
 
 // 更新后的PaginateAdmins方法
@@ -117,8 +134,8 @@ func (s *AdminService) handleError(err error, module string, username string, ms
 
 // 合并后的密码处理流程
 func (s *AdminService) validateAndHashPassword(password string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(hashed), s.handleError(err, "security", "", "密码处理失败")
+	hashed, err := s.baseService.HashPassword(password)
+	return hashed, s.handleError(err, "security", "", "密码处理失败")
 }
 
 // 用户信息构造模板
@@ -141,35 +158,61 @@ func (s *AdminService) withTransaction(ctx context.Context, fn func(tx *gorm.DB)
 	})
 }
 
-func (s *AdminService) Login(username, password string) (string, map[string]interface{}, error) {
-	admin, err := s.adminRepo.FindByUsername(context.Background(), username)
+// Login 管理员登录，验证通过后为本次登录创建会话
+func (s *AdminService) Login(ctx context.Context, username, password string, meta *auth.LoginMeta) (*security_types.TokenPair, map[string]interface{}, error) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+
+	admin, err := s.adminRepo.FindByUsername(ctx, username)
 	if err != nil {
 		s.logger.LogError("管理员登录失败：用户不存在", zap.String("username", username), zap.Error(err))
-		return "", nil, constants.ErrUserNotFound
-	}
-	if err := s.handleError(err, "login", username, "用户查询失败"); err != nil {
-		return "", nil, err
+		s.history.Record(ctx, SessionModel.RoleAdmin, 0, username, meta, LoginHistoryModel.ReasonUserNotFound)
+		return nil, nil, constants.ErrUserNotFound
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(password)); err != nil {
-		return "", nil, s.handleError(err, "login", username, "密码验证失败")
+	if err := s.baseService.ComparePassword(password, admin.Password); err != nil {
+		s.history.Record(ctx, SessionModel.RoleAdmin, admin.ID, username, meta, LoginHistoryModel.ReasonInvalidPassword)
+		return nil, nil, s.handleError(err, "login", username, "密码验证失败")
 	}
 
 	if admin.Status == 0 {
-		return "", nil, s.handleError(nil, "login", username, "账号已禁用")
+		s.history.Record(ctx, SessionModel.RoleAdmin, admin.ID, username, meta, LoginHistoryModel.ReasonAccountDisabled)
+		return nil, nil, s.handleError(constants.ErrUserInactive, "login", username, "账号已禁用")
 	}
 
-	var token string
-	err = s.withTransaction(context.Background(), func(tx *gorm.DB) error {
+	err = s.withTransaction(ctx, func(tx *gorm.DB) error {
 		admin.LastLoginAt = time.Now()
-		if err := tx.Save(admin).Error; err != nil {
-			return err
-		}
-		token, err = s.GenerateToken(admin)
-		return err
+		admin.LastLoginIP = meta.IP
+		s.upgradePasswordHash(admin, password)
+		return tx.Save(admin).Error
 	})
+	if err != nil {
+		s.history.Record(ctx, SessionModel.RoleAdmin, admin.ID, username, meta, LoginHistoryModel.ReasonInternalError)
+		return nil, nil, s.handleError(err, "login", username, "登录流程异常")
+	}
 
-	return token, s.buildAdminInfo(*admin), s.handleError(err, "login", username, "登录流程异常")
+	tokens, err := s.sessions.CreateSession(ctx, SessionModel.RoleAdmin, admin.ID, admin.Username, meta)
+	if err != nil {
+		s.history.Record(ctx, SessionModel.RoleAdmin, admin.ID, username, meta, LoginHistoryModel.ReasonInternalError)
+		return nil, nil, s.handleError(err, "login", username, "创建会话失败")
+	}
+	s.history.Record(ctx, SessionModel.RoleAdmin, admin.ID, username, meta, "")
+	return tokens, s.buildAdminInfo(*admin), nil
+}
+
+// upgradePasswordHash 登录成功后透明升级过时的密码哈希
+// 新哈希随登录信息一同保存，生成失败时保留原哈希
+func (s *AdminService) upgradePasswordHash(admin *AdminModel.Admin, password string) {
+	rehashed, err := s.baseService.RehashPassword(password, admin.Password)
+	if err != nil {
+		s.logger.LogWarn("密码哈希升级失败", zap.String("username", admin.Username), zap.Error(err))
+		return
+	}
+	if rehashed != "" {
+		admin.Password = rehashed
+		s.logger.LogInfo("密码哈希已升级", zap.String("username", admin.Username))
+	}
 }
 
 // UpdateAdmin 更新管理员信息
@@ -179,20 +222,19 @@ func (s *AdminService) Login(username, password string) (string, map[string]inte
 //
 // 返回:
 //   - error: 更新过程中的错误信息
-func (s *AdminService) UpdateAdmin(username string, updates map[string]interface{}) error {
-	ctx := context.Background()
+func (s *AdminService) UpdateAdmin(ctx context.Context, username string, updates map[string]interface{}) error {
 	admin, err := s.adminRepo.FindByUsername(ctx, username)
 	if err != nil {
 		s.logger.LogError("查询用户失败", zap.String("username", username), zap.Error(err))
 		return fmt.Errorf("查询用户失败: %w", err)
 	}
 	if password, ok := updates["password"].(string); ok {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hashedPassword, err := s.baseService.HashPassword(password)
 		if err != nil {
 			s.logger.LogError("密码加密失败", zap.String("username", username), zap.Error(err))
 			return fmt.Errorf("密码加密失败: %w", err)
 		}
-		admin.Password = string(hashedPassword)
+		admin.Password = hashedPassword
 	}
 	if nickname, ok := updates["nickname"].(string); ok {
 		admin.Nickname = nickname
@@ -215,8 +257,7 @@ func (s *AdminService) UpdateAdmin(username string, updates map[string]interface
 // 返回:
 //   - *map[string]interface{}: 包含管理员详细信息的map
 //   - error: 获取过程中的错误信息
-func (s *AdminService) GetAdminInfo(username string) (*map[string]interface{}, error) {
-	ctx := context.Background()
+func (s *AdminService) GetAdminInfo(ctx context.Context, username string) (*map[string]interface{}, error) {
 	admin, err := s.adminRepo.FindByUsername(ctx, username)
 	if err != nil {
 		s.logger.LogError("查询用户失败", zap.String("username", username), zap.Error(err))
@@ -243,54 +284,37 @@ func (s *AdminService) GetAdminInfo(username string) (*map[string]interface{}, e
 //   - pageSize: 每页数量
 //
 // 返回:
-//   - []map[string]interface{}: 管理员列表
-//   - int64: 总记录数
+//   - *type_response.AdminListResponse: 管理员分页列表
 //   - error: 获取过程中的错误信息
-func (s *AdminService) PaginateAdmins(page, pageSize int) ([]map[string]interface{}, int64, error) {
-	ctx := context.Background()
+func (s *AdminService) PaginateAdmins(ctx context.Context, page, pageSize int) (*type_response.AdminListResponse, error) {
 	admins, total, err := s.adminRepo.PaginateAdmins(ctx, page, pageSize)
 	if err != nil {
 		s.logger.LogError("获取管理员列表失败", zap.Error(err))
-		return nil, 0, fmt.Errorf("获取管理员列表失败: %w", err)
+		return nil, fmt.Errorf("获取管理员列表失败: %w", err)
 	}
 
 	// 构造管理员列表
-	result := make([]map[string]interface{}, len(admins))
+	items := make([]type_response.AdminResponse, len(admins))
 	for i, admin := range admins {
-		result[i] = map[string]interface{}{
-			"id":            admin.ID,
-			"username":      admin.Username,
-			"nickname":      admin.Nickname,
-			"avatar":        admin.Avatar,
-			"status":        admin.Status,
-			"is_admin":      admin.IsAdmin,
-			"created_at":    admin.CreatedAt,
-			"updated_at":    admin.UpdatedAt,
-			"last_login_at": admin.LastLoginAt,
-			"last_login_ip": admin.LastLoginIP,
+		items[i] = type_response.AdminResponse{
+			ID:          admin.ID,
+			Username:    admin.Username,
+			Nickname:    admin.Nickname,
+			Avatar:      admin.Avatar,
+			Status:      admin.Status,
+			IsAdmin:     admin.IsAdmin,
+			LastLoginAt: admin.LastLoginAt,
+			LastLoginIP: admin.LastLoginIP,
+			CreatedAt:   admin.CreatedAt,
+			UpdatedAt:   admin.UpdatedAt,
 		}
 	}
-	return result, total, nil
-}
-
-// GenerateToken 生成JWT令牌
-// 参数:
-//   - admin: 管理员实体
-//
-// 返回:
-//   - string: JWT令牌字符串
-//   - error: 生成过程中的错误信息
-func (s *AdminService) GenerateToken(admin *AdminModel.Admin) (string, error) {
-	claims := &structs.AdminClaims{
-		BaseClaims: structs.BaseClaims{
-			Username: admin.Username,
+	return &type_response.AdminListResponse{
+		ListResponse: type_response.ListResponse{
+			Total: total,
+			Page:  page,
+			Size:  pageSize,
 		},
-		IsAdmin: admin.IsAdmin == 1,
-	}
-	tokenString, err := s.jwtConfig.GenerateTokenWithClaims(claims)
-	if err != nil {
-		s.logger.LogError("生成令牌失败", zap.String("username", admin.Username), zap.Error(err))
-		return "", fmt.Errorf("生成令牌失败: %w", err)
-	}
-	return tokenString, nil
+		Items: items,
+	}, nil
 }
@@ -0,0 +1,242 @@
+// Package api_key_service 实现API密钥与个人访问令牌的签发、校验及使用审计
+package api_key_service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"gin-center/infrastructure/repository/admin"
+	api_key_repo "gin-center/infrastructure/repository/api_key"
+	user_repo "gin-center/infrastructure/repository/user"
+	"gin-center/infrastructure/zaplogger"
+	use_apiKeyInterface "gin-center/internal/domain/interface/api_key"
+	use_operationLogInterface "gin-center/internal/domain/interface/operation_log"
+	use_permissionInterface "gin-center/internal/domain/interface/permission"
+	AdminModel "gin-center/internal/domain/model/admin"
+	APIKeyModel "gin-center/internal/domain/model/api_key"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+	SessionModel "gin-center/internal/domain/model/session"
+	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// touchInterval 最近使用时间的最小更新间隔，避免每次请求都写库
+const touchInterval = time.Minute
+
+// keyRepository 密钥存储，由*api_key_repo.APIKeyRepository实现
+type keyRepository interface {
+	Create(ctx context.Context, key *APIKeyModel.APIKey) error
+	FindByHash(ctx context.Context, keyHash string) (*APIKeyModel.APIKey, error)
+	ListByUser(ctx context.Context, userType int, userID uint) ([]APIKeyModel.APIKey, error)
+	Revoke(ctx context.Context, userType int, userID, id uint, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uint, ip string, at time.Time) error
+}
+
+// ownerFinder 按ID查询密钥所有者
+type ownerFinder[T any] interface {
+	FindByID(ctx context.Context, id uint) (*T, error)
+}
+
+// APIKeyService API密钥服务
+type APIKeyService struct {
+	logger      *zaplogger.ServiceLogger
+	keyRepo     keyRepository
+	audit       use_operationLogInterface.OperationLogServiceInterface
+	userRepo    ownerFinder[UserModel.User]
+	adminRepo   ownerFinder[AdminModel.Admin]
+	permissions use_permissionInterface.PermissionServiceInterface
+}
+
+// NewAPIKeyService 创建新的API密钥服务实例
+func NewAPIKeyService(keyRepo *api_key_repo.APIKeyRepository, audit use_operationLogInterface.OperationLogServiceInterface, userRepo *user_repo.UserRepository, adminRepo *admin.AdminRepository, permissions use_permissionInterface.PermissionServiceInterface, logger *zaplogger.ServiceLogger) use_apiKeyInterface.APIKeyServiceInterface {
+	return &APIKeyService{
+		logger:      logger,
+		keyRepo:     keyRepo,
+		audit:       audit,
+		userRepo:    userRepo,
+		adminRepo:   adminRepo,
+		permissions: permissions,
+	}
+}
+
+// Create 实现APIKeyServiceInterface接口，密钥的权限范围不能超出所有者当前的权限
+func (s *APIKeyService) Create(ctx context.Context, role string, userID uint, req *structs.CreateAPIKeyRequest) (*type_response.APIKeyCreatedResponse, error) {
+	owned, err := s.permissions.ListCodes(ctx, role, userID)
+	if err != nil {
+		return nil, err
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !contains(owned, scope) {
+			return nil, constants.ErrInvalidPermission
+		}
+		if !contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	keyType, prefix := APIKeyModel.TypeAPIKey, APIKeyModel.PrefixAPIKey
+	if req.Type == "personal_token" {
+		keyType, prefix = APIKeyModel.TypePersonalToken, APIKeyModel.PrefixPersonalToken
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	raw := prefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+	key := &APIKeyModel.APIKey{
+		UserID:    userID,
+		UserType:  userTypeOf(role),
+		Type:      keyType,
+		Name:      req.Name,
+		Prefix:    raw[:len(prefix)+8],
+		KeyHash:   hashKey(raw),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: &expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	s.logger.LogInfo("已创建API密钥",
+		zap.Uint("key_id", key.ID),
+		zap.String("role", role),
+		zap.Uint("user_id", userID),
+		zap.Strings("scopes", scopes))
+
+	return &type_response.APIKeyCreatedResponse{
+		APIKeyResponse: toResponse(key),
+		Token:          raw,
+	}, nil
+}
+
+// List 实现APIKeyServiceInterface接口
+func (s *APIKeyService) List(ctx context.Context, role string, userID uint) ([]type_response.APIKeyResponse, error) {
+	keys, err := s.keyRepo.ListByUser(ctx, userTypeOf(role), userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]type_response.APIKeyResponse, len(keys))
+	for i := range keys {
+		result[i] = toResponse(&keys[i])
+	}
+	return result, nil
+}
+
+// Revoke 实现APIKeyServiceInterface接口
+func (s *APIKeyService) Revoke(ctx context.Context, role string, userID, id uint) error {
+	if err := s.keyRepo.Revoke(ctx, userTypeOf(role), userID, id, time.Now()); err != nil {
+		return err
+	}
+	s.logger.LogInfo("已撤销API密钥", zap.Uint("key_id", id), zap.String("role", role), zap.Uint("user_id", userID))
+	return nil
+}
+
+// Authenticate 实现APIKeyServiceInterface接口
+func (s *APIKeyService) Authenticate(ctx context.Context, raw, ip string) (*auth.APIKeyPrincipal, error) {
+	if !APIKeyModel.IsToken(raw) {
+		return nil, constants.ErrAPIKeyNotFound
+	}
+	key, err := s.keyRepo.FindByHash(ctx, hashKey(raw))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !key.Valid(now) {
+		return nil, constants.ErrAPIKeyNotFound
+	}
+
+	principal := &auth.APIKeyPrincipal{
+		KeyID:  key.ID,
+		UserID: key.UserID,
+		Scopes: key.ScopeList(),
+	}
+	if key.UserType == LoginHistoryModel.UserTypeAdmin {
+		owner, err := s.adminRepo.FindByID(ctx, key.UserID)
+		if err != nil || owner.Status == 0 {
+			return nil, constants.ErrAPIKeyNotFound
+		}
+		principal.Role, principal.Username = SessionModel.RoleAdmin, owner.Username
+	} else {
+		owner, err := s.userRepo.FindByID(ctx, key.UserID)
+		if err != nil || owner.Status == 0 {
+			return nil, constants.ErrAPIKeyNotFound
+		}
+		principal.Role, principal.Username = SessionModel.RoleUser, owner.Username
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		if err := s.keyRepo.TouchLastUsed(ctx, key.ID, ip, now); err != nil {
+			s.logger.LogWarn("更新API密钥使用信息失败", zap.Uint("key_id", key.ID), zap.Error(err))
+		}
+	}
+	return principal, nil
+}
+
+// RecordUsage 实现APIKeyServiceInterface接口，写入失败仅记录日志
+func (s *APIKeyService) RecordUsage(ctx context.Context, principal *auth.APIKeyPrincipal, method, path, ip string, status int) {
+	s.audit.Record(ctx, &OperationLogModel.OperationLog{
+		UserID:    strconv.FormatUint(uint64(principal.UserID), 10),
+		UserType:  userTypeOf(principal.Role),
+		Operation: OperationLogModel.OperationAPIKey,
+		Method:    method,
+		Path:      path,
+		Params:    "key_id=" + strconv.FormatUint(uint64(principal.KeyID), 10),
+		IP:        ip,
+		Status:    status,
+	})
+}
+
+func toResponse(key *APIKeyModel.APIKey) type_response.APIKeyResponse {
+	keyType := "api_key"
+	if key.Type == APIKeyModel.TypePersonalToken {
+		keyType = "personal_token"
+	}
+	return type_response.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Type:       keyType,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// hashKey 密钥明文为高熵随机串，使用SHA-256摘要存储
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func userTypeOf(role string) int {
+	if role == SessionModel.RoleAdmin {
+		return LoginHistoryModel.UserTypeAdmin
+	}
+	return LoginHistoryModel.UserTypeNormal
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package api_key_service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-center/infrastructure/zaplogger"
+	use_permissionInterface "gin-center/internal/domain/interface/permission"
+	AdminModel "gin-center/internal/domain/model/admin"
+	APIKeyModel "gin-center/internal/domain/model/api_key"
+	baseModel "gin-center/internal/domain/model/base"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
+	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+)
+
+// fakeKeyRepository 内存中的密钥存储，按摘要索引
+type fakeKeyRepository struct {
+	keys    []*APIKeyModel.APIKey
+	touched int
+}
+
+func (r *fakeKeyRepository) Create(ctx context.Context, key *APIKeyModel.APIKey) error {
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *fakeKeyRepository) FindByHash(ctx context.Context, keyHash string) (*APIKeyModel.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, constants.ErrAPIKeyNotFound
+}
+
+func (r *fakeKeyRepository) ListByUser(ctx context.Context, userType int, userID uint) ([]APIKeyModel.APIKey, error) {
+	return nil, nil
+}
+
+func (r *fakeKeyRepository) Revoke(ctx context.Context, userType int, userID, id uint, at time.Time) error {
+	for _, key := range r.keys {
+		if key.ID == id && key.UserID == userID && key.UserType == userType && key.RevokedAt == nil {
+			key.RevokedAt = &at
+			return nil
+		}
+	}
+	return constants.ErrAPIKeyNotFound
+}
+
+func (r *fakeKeyRepository) TouchLastUsed(ctx context.Context, id uint, ip string, at time.Time) error {
+	r.touched++
+	return nil
+}
+
+type fakeOwners[T any] map[uint]*T
+
+func (f fakeOwners[T]) FindByID(ctx context.Context, id uint) (*T, error) {
+	if owner, ok := f[id]; ok {
+		return owner, nil
+	}
+	return nil, constants.ErrUserNotFound
+}
+
+// fakePermissions 只实现ListCodes，按角色返回固定的权限编码
+type fakePermissions struct {
+	use_permissionInterface.PermissionServiceInterface
+	codes map[string][]string
+}
+
+func (p *fakePermissions) ListCodes(ctx context.Context, role string, userID uint) ([]string, error) {
+	return p.codes[role], nil
+}
+
+// newTestService 用户1启用、用户2禁用，管理员1启用
+func newTestService() (*APIKeyService, *fakeKeyRepository) {
+	repo := &fakeKeyRepository{}
+	return &APIKeyService{
+		logger:  zaplogger.NewServiceLogger(),
+		keyRepo: repo,
+		userRepo: fakeOwners[UserModel.User]{
+			1: {BaseModel: baseModel.BaseModel{ID: 1, Status: 1}, Username: "alice"},
+			2: {BaseModel: baseModel.BaseModel{ID: 2, Status: 0}, Username: "bob"},
+		},
+		adminRepo: fakeOwners[AdminModel.Admin]{
+			1: {BaseModel: baseModel.BaseModel{ID: 1, Status: 1}, Username: "root"},
+		},
+		permissions: &fakePermissions{codes: map[string][]string{
+			SessionModel.RoleUser:  {"user:read", "order:read"},
+			SessionModel.RoleAdmin: {"user:read", "user:write", "system:config"},
+		}},
+	}, repo
+}
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		req        structs.CreateAPIKeyRequest
+		wantErr    error
+		wantPrefix string
+		wantScopes string
+	}{
+		{
+			name:       "API密钥",
+			role:       SessionModel.RoleUser,
+			req:        structs.CreateAPIKeyRequest{Name: "ci", Type: "api_key", Scopes: []string{"user:read"}, ExpiresInDays: 30},
+			wantPrefix: APIKeyModel.PrefixAPIKey,
+			wantScopes: "user:read",
+		},
+		{
+			name:       "个人访问令牌",
+			role:       SessionModel.RoleUser,
+			req:        structs.CreateAPIKeyRequest{Name: "cli", Type: "personal_token", Scopes: []string{"user:read", "order:read"}, ExpiresInDays: 1},
+			wantPrefix: APIKeyModel.PrefixPersonalToken,
+			wantScopes: "user:read order:read",
+		},
+		{
+			name:       "重复的权限编码去重",
+			role:       SessionModel.RoleUser,
+			req:        structs.CreateAPIKeyRequest{Name: "ci", Type: "api_key", Scopes: []string{"user:read", "user:read"}, ExpiresInDays: 30},
+			wantPrefix: APIKeyModel.PrefixAPIKey,
+			wantScopes: "user:read",
+		},
+		{
+			name:    "超出所有者权限",
+			role:    SessionModel.RoleUser,
+			req:     structs.CreateAPIKeyRequest{Name: "ci", Type: "api_key", Scopes: []string{"user:read", "user:write"}, ExpiresInDays: 30},
+			wantErr: constants.ErrInvalidPermission,
+		},
+		{
+			name:       "管理员按管理员权限校验",
+			role:       SessionModel.RoleAdmin,
+			req:        structs.CreateAPIKeyRequest{Name: "ops", Type: "api_key", Scopes: []string{"system:config"}, ExpiresInDays: 7},
+			wantPrefix: APIKeyModel.PrefixAPIKey,
+			wantScopes: "system:config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService()
+			resp, err := s.Create(context.Background(), tt.role, 1, &tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.keys) != 0 {
+					t.Error("rejected key was saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if !strings.HasPrefix(resp.Token, tt.wantPrefix) || !strings.HasPrefix(resp.Token, resp.Prefix) {
+				t.Errorf("Create() token = %q, prefix = %q, want prefix %q", resp.Token, resp.Prefix, tt.wantPrefix)
+			}
+			key := repo.keys[0]
+			if key.KeyHash != hashKey(resp.Token) || strings.Contains(key.KeyHash, resp.Token) {
+				t.Errorf("stored hash = %q, want SHA-256 of the token", key.KeyHash)
+			}
+			if key.Scopes != tt.wantScopes {
+				t.Errorf("stored scopes = %q, want %q", key.Scopes, tt.wantScopes)
+			}
+			if key.UserType != userTypeOf(tt.role) {
+				t.Errorf("stored user type = %d, want %d", key.UserType, userTypeOf(tt.role))
+			}
+			if key.ExpiresAt == nil || time.Until(*key.ExpiresAt) > time.Duration(tt.req.ExpiresInDays)*24*time.Hour {
+				t.Errorf("stored expiry = %v", key.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		userType int
+		userID   uint
+		raw      string
+		// token 存入密钥库的明文，为空时与raw相同
+		token     string
+		expiresAt *time.Time
+		revokedAt *time.Time
+		wantRole  string
+		wantErr   bool
+	}{
+		{name: "API密钥", raw: "gck_secret", expiresAt: &future, userID: 1, wantRole: SessionModel.RoleUser},
+		{name: "个人访问令牌", raw: "gcp_secret", expiresAt: &future, userID: 1, wantRole: SessionModel.RoleUser},
+		{name: "管理员的密钥", raw: "gck_secret", expiresAt: &future, userType: LoginHistoryModel.UserTypeAdmin, userID: 1, wantRole: SessionModel.RoleAdmin},
+		{name: "未知前缀", raw: "ghp_secret", token: "ghp_secret", expiresAt: &future, userID: 1, wantErr: true},
+		{name: "无前缀", raw: "secret", token: "secret", expiresAt: &future, userID: 1, wantErr: true},
+		{name: "前缀正确但摘要不匹配", raw: "gck_other", token: "gck_secret", expiresAt: &future, userID: 1, wantErr: true},
+		{name: "大小写不同", raw: "GCK_secret", token: "gck_secret", expiresAt: &future, userID: 1, wantErr: true},
+		{name: "已过期", raw: "gck_secret", expiresAt: &past, userID: 1, wantErr: true},
+		{name: "已撤销", raw: "gck_secret", expiresAt: &future, revokedAt: &past, userID: 1, wantErr: true},
+		{name: "所有者已禁用", raw: "gck_secret", expiresAt: &future, userID: 2, wantErr: true},
+		{name: "所有者已删除", raw: "gck_secret", expiresAt: &future, userID: 9, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService()
+			token := tt.token
+			if token == "" {
+				token = tt.raw
+			}
+			userType := tt.userType
+			if userType == 0 {
+				userType = LoginHistoryModel.UserTypeNormal
+			}
+			repo.keys = append(repo.keys, &APIKeyModel.APIKey{
+				ID:        1,
+				UserID:    tt.userID,
+				UserType:  userType,
+				KeyHash:   hashKey(token),
+				Scopes:    "user:read",
+				ExpiresAt: tt.expiresAt,
+				RevokedAt: tt.revokedAt,
+			})
+
+			principal, err := s.Authenticate(context.Background(), tt.raw, "127.0.0.1")
+			if tt.wantErr {
+				if !errors.Is(err, constants.ErrAPIKeyNotFound) {
+					t.Fatalf("Authenticate() error = %v, want ErrAPIKeyNotFound", err)
+				}
+				if repo.touched != 0 {
+					t.Error("rejected key was touched")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Role != tt.wantRole || principal.UserID != tt.userID || principal.KeyID != 1 {
+				t.Errorf("Authenticate() = %+v", principal)
+			}
+			if len(principal.Scopes) != 1 || principal.Scopes[0] != "user:read" {
+				t.Errorf("Authenticate() scopes = %v, want [user:read]", principal.Scopes)
+			}
+			if repo.touched != 1 {
+				t.Errorf("TouchLastUsed called %d times, want 1", repo.touched)
+			}
+		})
+	}
+}
+
+func TestAuthenticateThrottlesTouch(t *testing.T) {
+	s, repo := newTestService()
+	resp, err := s.Create(context.Background(), SessionModel.RoleUser, 1, &structs.CreateAPIKeyRequest{Name: "ci", Type: "api_key", Scopes: []string{"user:read"}, ExpiresInDays: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recent := time.Now().Add(-time.Second)
+	repo.keys[0].LastUsedAt = &recent
+	if _, err := s.Authenticate(context.Background(), resp.Token, "127.0.0.1"); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if repo.touched != 0 {
+		t.Errorf("TouchLastUsed called %d times within touchInterval, want 0", repo.touched)
+	}
+}
+
+func TestRevokedKeyStopsAuthenticating(t *testing.T) {
+	s, repo := newTestService()
+	resp, err := s.Create(context.Background(), SessionModel.RoleUser, 1, &structs.CreateAPIKeyRequest{Name: "ci", Type: "personal_token", Scopes: []string{"user:read"}, ExpiresInDays: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate(context.Background(), resp.Token, "127.0.0.1"); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	// 其他用户不能撤销
+	if err := s.Revoke(context.Background(), SessionModel.RoleUser, 2, resp.ID); !errors.Is(err, constants.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke() by other user error = %v, want ErrAPIKeyNotFound", err)
+	}
+	if err := s.Revoke(context.Background(), SessionModel.RoleUser, 1, resp.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := s.Authenticate(context.Background(), resp.Token, "127.0.0.1"); !errors.Is(err, constants.ErrAPIKeyNotFound) {
+		t.Errorf("Authenticate() after revoke error = %v, want ErrAPIKeyNotFound", err)
+	}
+	if repo.keys[0].RevokedAt == nil {
+		t.Error("RevokedAt not set")
+	}
+}
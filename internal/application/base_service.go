@@ -11,29 +11,31 @@ import (
 	"gin-center/internal/types/models/structs"
 	security_types "gin-center/pkg/security/types"
 	"gin-center/pkg/security/useJwt"
+	"gin-center/pkg/security/usePassword"
 	"gin-center/pkg/utils/validator"
 	"time"
 
 	"gin-center/infrastructure/zaplogger"
 
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // BaseService 提供基础服务功能，包括数据库操作、缓存管理、日志记录等
 type BaseService struct {
-	DB     *gorm.DB                 // 数据库连接实例
-	Cache  cache.Cache              // 缓存提供者接口
-	Logger *zaplogger.ServiceLogger // 服务日志记录器
-	config *BaseServiceConfig       // 服务配置信息
+	DB             *gorm.DB                 // 数据库连接实例
+	Cache          cache.Cache              // 缓存提供者接口
+	Logger         *zaplogger.ServiceLogger // 服务日志记录器
+	PasswordHasher usePassword.Hasher       // 密码哈希器
+	config         *BaseServiceConfig       // 服务配置信息
 }
 
 // BaseServiceConfig 基础服务配置
 type BaseServiceConfig struct {
-	DB     *gorm.DB
-	Cache  cache.Cache
-	Logger *zaplogger.ServiceLogger
+	DB             *gorm.DB
+	Cache          cache.Cache
+	Logger         *zaplogger.ServiceLogger
+	PasswordHasher usePassword.Hasher
 }
 
 // NewBaseService 创建BaseService实例
@@ -41,11 +43,17 @@ func NewBaseService(config *BaseServiceConfig) *BaseService {
 	if config == nil {
 		panic("service configuration cannot be nil")
 	}
+	hasher := config.PasswordHasher
+	if hasher == nil {
+		// 未注入哈希器时使用默认配置，默认配置必然有效
+		hasher, _ = usePassword.NewHasher(nil)
+	}
 	return &BaseService{
-		DB:     config.DB,
-		Cache:  config.Cache,
-		Logger: zaplogger.NewServiceLogger(),
-		config: config,
+		DB:             config.DB,
+		Cache:          config.Cache,
+		Logger:         zaplogger.NewServiceLogger(),
+		PasswordHasher: hasher,
+		config:         config,
 	}
 }
 
@@ -120,24 +128,32 @@ func (s *BaseService) ValidateUserInput(username, password string) error {
 	return validator.ValidatePassword(password)
 }
 
-// HashPassword 使用bcrypt对密码进行哈希处理
+// HashPassword 使用配置的密码哈希器对密码进行哈希处理，返回PHC格式字符串
 func (s *BaseService) HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := s.PasswordHasher.Hash(password)
 	if err != nil {
 		return "", fmt.Errorf("密码加密失败: %w", err)
 	}
-	return string(hash), nil
+	return hash, nil
 }
 
 // ComparePassword 比较密码是否匹配
 func (s *BaseService) ComparePassword(password, hashedPassword string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	if err != nil {
+	if err := s.PasswordHasher.Verify(password, hashedPassword); err != nil {
 		return fmt.Errorf("密码不匹配: %w", err)
 	}
 	return nil
 }
 
+// RehashPassword 在密码校验通过后检查哈希是否过时
+// 若哈希算法或参数已过时，返回使用当前配置重新生成的哈希；否则返回空字符串
+func (s *BaseService) RehashPassword(password, hashedPassword string) (string, error) {
+	if !s.PasswordHasher.NeedsRehash(hashedPassword) {
+		return "", nil
+	}
+	return s.HashPassword(password)
+}
+
 // GenerateToken 生成JWT令牌
 // 参数:
 //   - jwtConfig: JWT配置信息
@@ -197,11 +213,11 @@ func (s *BaseService) ValidateToken(jwtConfig *useJwt.JWTConfig, tokenString str
 }
 
 // CheckUserExists 检查用户是否已存在
-func (s *BaseService) CheckUserExists(findUserFunc func(ctx context.Context, username string) (*UserModel.User, error), username string) error {
+func (s *BaseService) CheckUserExists(ctx context.Context, findUserFunc func(ctx context.Context, username string) (*UserModel.User, error), username string) error {
 	if findUserFunc == nil {
 		return fmt.Errorf("findUserFunc cannot be nil")
 	}
-	existingUser, err := findUserFunc(context.Background(), username)
+	existingUser, err := findUserFunc(ctx, username)
 	if err != nil {
 		s.Logger.LogError("检查用户存在性失败", zap.String("username", username), zap.Error(err))
 		return fmt.Errorf("检查用户存在性失败: %w", err)
@@ -0,0 +1,94 @@
+// Package impersonation_service 实现管理员模拟普通用户登录
+package impersonation_service
+
+import (
+	"context"
+	user_repo "gin-center/infrastructure/repository/user"
+	"gin-center/infrastructure/zaplogger"
+	use_impersonationInterface "gin-center/internal/domain/interface/impersonation"
+	use_operationLogInterface "gin-center/internal/domain/interface/operation_log"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	type_response "gin-center/internal/types/response"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ImpersonationService 模拟登录服务
+type ImpersonationService struct {
+	logger   *zaplogger.ServiceLogger
+	userRepo *user_repo.UserRepository
+	sessions use_sessionInterface.SessionServiceInterface
+	audit    use_operationLogInterface.OperationLogServiceInterface
+}
+
+// NewImpersonationService 创建新的模拟登录服务实例
+func NewImpersonationService(userRepo *user_repo.UserRepository, sessions use_sessionInterface.SessionServiceInterface, audit use_operationLogInterface.OperationLogServiceInterface, logger *zaplogger.ServiceLogger) use_impersonationInterface.ImpersonationServiceInterface {
+	return &ImpersonationService{
+		logger:   logger,
+		userRepo: userRepo,
+		sessions: sessions,
+		audit:    audit,
+	}
+}
+
+// Start 实现ImpersonationServiceInterface接口
+// 发起模拟本身记录在管理员名下，之后的请求由认证中间件记录在目标用户名下
+func (s *ImpersonationService) Start(ctx context.Context, actor *auth.Actor, userID uint, meta *auth.LoginMeta) (*type_response.ImpersonationResponse, error) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == 0 {
+		return nil, constants.ErrUserInactive
+	}
+
+	session, token, err := s.sessions.Impersonate(ctx, user.ID, user.Username, actor, meta)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, &OperationLogModel.OperationLog{
+		UserID:    strconv.FormatUint(uint64(actor.ID), 10),
+		UserType:  LoginHistoryModel.UserTypeAdmin,
+		Operation: OperationLogModel.OperationImpersonate,
+		Path:      "/api/v1/admin/users/" + strconv.FormatUint(uint64(user.ID), 10) + "/impersonate",
+		Method:    "POST",
+		Params:    "target_id=" + strconv.FormatUint(uint64(user.ID), 10) + ";session_id=" + session.ID,
+		IP:        meta.IP,
+		Status:    200,
+	})
+	s.logger.LogInfo("管理员开始模拟用户",
+		zap.Uint("actor_id", actor.ID),
+		zap.String("actor", actor.Username),
+		zap.Uint("user_id", user.ID),
+		zap.String("session_id", session.ID))
+
+	return &type_response.ImpersonationResponse{
+		AccessToken: token,
+		ExpiresIn:   int64(time.Until(session.ExpiresAt) / time.Second),
+		ExpiresAt:   session.ExpiresAt,
+		User: type_response.UserResponse{
+			ID:          strconv.FormatUint(uint64(user.ID), 10),
+			Username:    user.Username,
+			Nickname:    user.Nickname,
+			Avatar:      user.Avatar,
+			Phone:       user.Phone,
+			LastLoginAt: user.LastLoginAt,
+			LastLoginIP: user.LastLoginIP,
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		},
+		Actor: type_response.ActorResponse{
+			ID:       actor.ID,
+			Username: actor.Username,
+		},
+	}, nil
+}
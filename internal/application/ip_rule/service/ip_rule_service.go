@@ -0,0 +1,402 @@
+// Package ip_rule_service 按路由组判定客户端IP与所在国家/地区能否访问
+// 配置中的规则与Redis中的规则合并后编译为快照，判定时不访问Redis
+package ip_rule_service
+
+import (
+	"context"
+	"fmt"
+	"gin-center/configs/config"
+	ip_rule_repo "gin-center/infrastructure/repository/ip_rule"
+	"gin-center/infrastructure/zaplogger"
+	use_ipRuleInterface "gin-center/internal/domain/interface/ip_rule"
+	IPRuleModel "gin-center/internal/domain/model/ip_rule"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/oschwald/maxminddb-golang"
+	"go.uber.org/zap"
+)
+
+// defaultReloadInterval 未配置reload_interval时全量刷新规则的间隔
+const defaultReloadInterval = time.Minute
+
+// ipRuleRepository 规则存储，由*ip_rule_repo.IPRuleRepository实现
+type ipRuleRepository interface {
+	List(ctx context.Context) ([]IPRuleModel.IPRule, error)
+	Save(ctx context.Context, rule *IPRuleModel.IPRule) error
+	Delete(ctx context.Context, id string) error
+	Subscribe(ctx context.Context) *redis.PubSub
+}
+
+// geoDatabase 地理位置库，由*maxminddb.Reader实现
+type geoDatabase interface {
+	Lookup(ip net.IP, result interface{}) error
+}
+
+// IPRuleService 访问控制服务
+type IPRuleService struct {
+	logger *zaplogger.ServiceLogger
+	repo   ipRuleRepository
+	cfg    *config.IPFilterConfig
+	geo    geoDatabase
+	// configRules 配置中的规则，加载后不再变化
+	configRules []IPRuleModel.IPRule
+	// groups 当前生效的规则快照，键为路由组
+	groups atomic.Pointer[map[string]*ruleSet]
+}
+
+// ruleSet 路由组编译后的规则
+// allowSkipped表示有允许规则未能编译，此时允许列表不完整，未命中已加载的允许规则的请求一律拒绝
+type ruleSet struct {
+	allow          []matcher
+	deny           []matcher
+	allowCountries map[string]string
+	denyCountries  map[string]string
+	allowSkipped   bool
+}
+
+func newRuleSet() *ruleSet {
+	return &ruleSet{allowCountries: map[string]string{}, denyCountries: map[string]string{}}
+}
+
+// clone 复制规则集，用于在不影响当前快照的情况下试算新规则
+func (set *ruleSet) clone() *ruleSet {
+	c := newRuleSet()
+	c.allow = append(c.allow, set.allow...)
+	c.deny = append(c.deny, set.deny...)
+	for k, v := range set.allowCountries {
+		c.allowCountries[k] = v
+	}
+	for k, v := range set.denyCountries {
+		c.denyCountries[k] = v
+	}
+	c.allowSkipped = set.allowSkipped
+	return c
+}
+
+type matcher struct {
+	network *net.IPNet
+	rule    string
+}
+
+// countryRecord 地理位置库中的国家字段，城市库与国家库结构相同
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// NewIPRuleService 创建新的访问控制服务实例，打开地理位置库并加载Redis中的规则
+func NewIPRuleService(ctx context.Context, cfg *config.IPFilterConfig, repo *ip_rule_repo.IPRuleRepository, logger *zaplogger.ServiceLogger) (use_ipRuleInterface.IPRuleServiceInterface, error) {
+	s := &IPRuleService{
+		logger: logger,
+		repo:   repo,
+		cfg:    cfg,
+	}
+	if cfg.GeoDatabase != "" {
+		geo, err := maxminddb.Open(cfg.GeoDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("打开地理位置库失败: %w", err)
+		}
+		s.geo = geo
+	}
+	for group, rules := range cfg.Groups {
+		for _, value := range rules.Allow {
+			s.configRules = append(s.configRules, configRule(group, IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, value))
+		}
+		for _, value := range rules.Deny {
+			s.configRules = append(s.configRules, configRule(group, IPRuleModel.ActionDeny, IPRuleModel.KindCIDR, value))
+		}
+		for _, value := range rules.AllowCountries {
+			s.configRules = append(s.configRules, configRule(group, IPRuleModel.ActionAllow, IPRuleModel.KindCountry, value))
+		}
+		for _, value := range rules.DenyCountries {
+			s.configRules = append(s.configRules, configRule(group, IPRuleModel.ActionDeny, IPRuleModel.KindCountry, value))
+		}
+	}
+	for i := range s.configRules {
+		if err := s.normalize(&s.configRules[i]); err != nil {
+			return nil, fmt.Errorf("访问控制配置有误: %w", err)
+		}
+	}
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func configRule(group, action, kind, value string) IPRuleModel.IPRule {
+	return IPRuleModel.IPRule{
+		ID:     fmt.Sprintf("config:%s:%s:%s:%s", group, action, kind, value),
+		Group:  group,
+		Action: action,
+		Kind:   kind,
+		Value:  value,
+		Source: IPRuleModel.SourceConfig,
+	}
+}
+
+// Check 实现IPRuleServiceInterface接口
+// 无法识别的IP不会命中任何规则，存在允许规则时会被拒绝
+func (s *IPRuleService) Check(group, ip string) IPRuleModel.Decision {
+	if !s.cfg.Enabled {
+		return IPRuleModel.Decision{Allowed: true}
+	}
+	set, ok := (*s.groups.Load())[group]
+	if !ok {
+		return IPRuleModel.Decision{Allowed: true}
+	}
+	return s.decide(set, ip)
+}
+
+// decide 按规则集判定，禁止规则优先，存在允许规则时只允许命中的请求
+func (s *IPRuleService) decide(set *ruleSet, ip string) IPRuleModel.Decision {
+	addr := net.ParseIP(ip)
+	country := ""
+	if addr != nil && s.geo != nil && (len(set.allowCountries) > 0 || len(set.denyCountries) > 0) {
+		country = s.lookupCountry(addr)
+	}
+
+	if addr != nil {
+		for _, m := range set.deny {
+			if m.network.Contains(addr) {
+				return IPRuleModel.Decision{Reason: "命中禁止规则 " + m.rule, Country: country}
+			}
+		}
+	}
+	if rule, ok := set.denyCountries[country]; ok && country != "" {
+		return IPRuleModel.Decision{Reason: "命中禁止规则 " + rule, Country: country}
+	}
+	if len(set.allow) == 0 && len(set.allowCountries) == 0 && !set.allowSkipped {
+		return IPRuleModel.Decision{Allowed: true, Country: country}
+	}
+	if addr != nil {
+		for _, m := range set.allow {
+			if m.network.Contains(addr) {
+				return IPRuleModel.Decision{Allowed: true, Country: country}
+			}
+		}
+	}
+	if _, ok := set.allowCountries[country]; ok && country != "" {
+		return IPRuleModel.Decision{Allowed: true, Country: country}
+	}
+	if set.allowSkipped {
+		return IPRuleModel.Decision{Reason: "不在允许列表中（部分允许规则未能加载）", Country: country}
+	}
+	return IPRuleModel.Decision{Reason: "不在允许列表中", Country: country}
+}
+
+// lookupCountry 优先取实际所在国家，没有时取注册国家，查询失败返回空字符串
+func (s *IPRuleService) lookupCountry(ip net.IP) string {
+	var record countryRecord
+	if err := s.geo.Lookup(ip, &record); err != nil {
+		s.logger.LogWarn("查询IP所在地区失败", zap.String("ip", ip.String()), zap.Error(err))
+		return ""
+	}
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode
+	}
+	return record.RegisteredCountry.ISOCode
+}
+
+// ListRules 实现IPRuleServiceInterface接口
+func (s *IPRuleService) ListRules(ctx context.Context, group string) ([]IPRuleModel.IPRule, error) {
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]IPRuleModel.IPRule, 0, len(s.configRules)+len(stored))
+	for _, rule := range append(append([]IPRuleModel.IPRule{}, s.configRules...), stored...) {
+		if group == "" || rule.Group == group {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// CreateRule 实现IPRuleServiceInterface接口
+func (s *IPRuleService) CreateRule(ctx context.Context, req *structs.IPRuleRequest, operatorID uint, operatorIP string) (*IPRuleModel.IPRule, error) {
+	rule := &IPRuleModel.IPRule{
+		ID:        uuid.New().String(),
+		Group:     req.Group,
+		Action:    req.Action,
+		Kind:      req.Kind,
+		Value:     strings.TrimSpace(req.Value),
+		Remark:    req.Remark,
+		Source:    IPRuleModel.SourceAPI,
+		CreatedBy: operatorID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.normalize(rule); err != nil {
+		return nil, err
+	}
+	if rule.Group == IPRuleModel.GroupAdmin {
+		if decision := s.preview(*rule, operatorIP); !decision.Allowed {
+			return nil, fmt.Errorf("%w: 规则生效后当前IP %s将无法访问管理端（%s）", constants.ErrInvalidIPRule, operatorIP, decision.Reason)
+		}
+	}
+	if err := s.repo.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Ctx(ctx).LogInfo("已添加访问控制规则",
+		zap.String("group", rule.Group),
+		zap.String("action", rule.Action),
+		zap.String("kind", rule.Kind),
+		zap.String("value", rule.Value),
+		zap.Uint("operator_id", operatorID))
+	// 本实例立即生效，不等待变更通知
+	if err := s.reload(ctx); err != nil {
+		s.logger.Ctx(ctx).LogError("重新加载访问控制规则失败", zap.Error(err))
+	}
+	return rule, nil
+}
+
+// preview 试算添加规则后客户端IP能否访问规则所在的路由组
+// 管理端的规则若把操作者自己挡在外面，就无法再通过管理接口删除该规则
+func (s *IPRuleService) preview(rule IPRuleModel.IPRule, ip string) IPRuleModel.Decision {
+	set := newRuleSet()
+	if current, ok := (*s.groups.Load())[rule.Group]; ok {
+		set = current.clone()
+	}
+	_ = s.compile(set, rule)
+	return s.decide(set, ip)
+}
+
+// DeleteRule 实现IPRuleServiceInterface接口
+func (s *IPRuleService) DeleteRule(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Ctx(ctx).LogInfo("已删除访问控制规则", zap.String("id", id))
+	if err := s.reload(ctx); err != nil {
+		s.logger.Ctx(ctx).LogError("重新加载访问控制规则失败", zap.Error(err))
+	}
+	return nil
+}
+
+// Run 实现IPRuleServiceInterface接口
+func (s *IPRuleService) Run(ctx context.Context) {
+	interval := s.cfg.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 断线后go-redis会自动重新订阅，期间错过的变更由定时刷新补上
+	sub := s.repo.Subscribe(ctx)
+	defer sub.Close()
+	changes := sub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		case <-ticker.C:
+		}
+		if err := s.reload(ctx); err != nil && ctx.Err() == nil {
+			s.logger.LogError("重新加载访问控制规则失败，继续使用当前规则", zap.Error(err))
+		}
+	}
+}
+
+// reload 读取Redis中的规则，与配置中的规则合并编译后替换快照
+// 无法编译的规则记录日志并跳过；跳过的是允许规则时该路由组只允许命中其余允许规则的请求，
+// 例如在未配置地理位置库的实例上加载国家允许规则，不能因允许列表变空而放行所有请求
+func (s *IPRuleService) reload(ctx context.Context) error {
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	groups := map[string]*ruleSet{}
+	for _, rule := range append(append([]IPRuleModel.IPRule{}, s.configRules...), stored...) {
+		set, ok := groups[rule.Group]
+		if !ok {
+			set = newRuleSet()
+			groups[rule.Group] = set
+		}
+		if err := s.compile(set, rule); err != nil {
+			if rule.Action != IPRuleModel.ActionDeny {
+				set.allowSkipped = true
+			}
+			s.logger.LogWarn("跳过无效的访问控制规则", zap.String("id", rule.ID), zap.String("group", rule.Group), zap.Error(err))
+		}
+	}
+	s.groups.Store(&groups)
+	return nil
+}
+
+func (s *IPRuleService) compile(set *ruleSet, rule IPRuleModel.IPRule) error {
+	if err := s.normalize(&rule); err != nil {
+		return err
+	}
+	label := rule.Value
+	if rule.Source == IPRuleModel.SourceAPI {
+		label += " (" + rule.ID + ")"
+	}
+	if rule.Kind == IPRuleModel.KindCountry {
+		if rule.Action == IPRuleModel.ActionAllow {
+			set.allowCountries[rule.Value] = label
+		} else {
+			set.denyCountries[rule.Value] = label
+		}
+		return nil
+	}
+	_, network, _ := net.ParseCIDR(rule.Value)
+	if rule.Action == IPRuleModel.ActionAllow {
+		set.allow = append(set.allow, matcher{network: network, rule: label})
+	} else {
+		set.deny = append(set.deny, matcher{network: network, rule: label})
+	}
+	return nil
+}
+
+// normalize 校验规则，单个IP转换为CIDR，国家代码转换为大写
+func (s *IPRuleService) normalize(rule *IPRuleModel.IPRule) error {
+	if rule.Action != IPRuleModel.ActionAllow && rule.Action != IPRuleModel.ActionDeny {
+		return fmt.Errorf("%w: 未知的动作%s", constants.ErrInvalidIPRule, rule.Action)
+	}
+	switch rule.Kind {
+	case IPRuleModel.KindCIDR:
+		value := rule.Value
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return fmt.Errorf("%w: %s不是有效的IP", constants.ErrInvalidIPRule, value)
+			}
+			// IPv4映射的IPv6地址按IPv4处理，否则"::ffff:a.b.c.d/32"会被解析成"::/32"
+			if ip4 := ip.To4(); ip4 != nil {
+				value = ip4.String() + "/32"
+			} else {
+				value = ip.String() + "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s不是有效的CIDR", constants.ErrInvalidIPRule, rule.Value)
+		}
+		rule.Value = network.String()
+	case IPRuleModel.KindCountry:
+		if s.geo == nil {
+			return fmt.Errorf("%w: 未配置地理位置库，不能使用国家规则", constants.ErrInvalidIPRule)
+		}
+		value := strings.ToUpper(rule.Value)
+		if len(value) != 2 || value[0] < 'A' || value[0] > 'Z' || value[1] < 'A' || value[1] > 'Z' {
+			return fmt.Errorf("%w: %s不是两位国家代码", constants.ErrInvalidIPRule, rule.Value)
+		}
+		rule.Value = value
+	default:
+		return fmt.Errorf("%w: 未知的规则类型%s", constants.ErrInvalidIPRule, rule.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package ip_rule_service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"gin-center/configs/config"
+	"gin-center/infrastructure/zaplogger"
+	IPRuleModel "gin-center/internal/domain/model/ip_rule"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeRepository 内存中的规则存储
+type fakeRepository struct {
+	rules []IPRuleModel.IPRule
+}
+
+func (r *fakeRepository) List(ctx context.Context) ([]IPRuleModel.IPRule, error) {
+	return append([]IPRuleModel.IPRule{}, r.rules...), nil
+}
+
+func (r *fakeRepository) Save(ctx context.Context, rule *IPRuleModel.IPRule) error {
+	r.rules = append(r.rules, *rule)
+	return nil
+}
+
+func (r *fakeRepository) Delete(ctx context.Context, id string) error {
+	for i, rule := range r.rules {
+		if rule.ID == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return constants.ErrIPRuleNotFound
+}
+
+func (r *fakeRepository) Subscribe(ctx context.Context) *redis.PubSub {
+	return nil
+}
+
+// fakeGeo 按IP返回固定的国家代码
+type fakeGeo map[string]string
+
+func (g fakeGeo) Lookup(ip net.IP, result interface{}) error {
+	result.(*countryRecord).Country.ISOCode = g[ip.String()]
+	return nil
+}
+
+func apiRule(action, kind, value string) IPRuleModel.IPRule {
+	return IPRuleModel.IPRule{ID: value, Group: IPRuleModel.GroupAdmin, Action: action, Kind: kind, Value: value, Source: IPRuleModel.SourceAPI}
+}
+
+// newTestService 以给定的规则创建已加载快照的服务，geo为nil表示未配置地理位置库
+func newTestService(t *testing.T, geo geoDatabase, rules ...IPRuleModel.IPRule) (*IPRuleService, *fakeRepository) {
+	t.Helper()
+	repo := &fakeRepository{rules: rules}
+	s := &IPRuleService{
+		logger: zaplogger.NewServiceLogger(),
+		repo:   repo,
+		cfg:    &config.IPFilterConfig{Enabled: true},
+	}
+	if geo != nil {
+		s.geo = geo
+	}
+	if err := s.reload(context.Background()); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	return s, repo
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		action  string
+		value   string
+		noGeo   bool
+		want    string
+		wantErr bool
+	}{
+		{name: "IPv4地址", kind: IPRuleModel.KindCIDR, value: "10.0.0.1", want: "10.0.0.1/32"},
+		{name: "IPv6地址", kind: IPRuleModel.KindCIDR, value: "2001:db8::1", want: "2001:db8::1/128"},
+		{name: "CIDR去掉主机位", kind: IPRuleModel.KindCIDR, value: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{name: "IPv4映射的IPv6地址", kind: IPRuleModel.KindCIDR, value: "::ffff:10.0.0.1", want: "10.0.0.1/32"},
+		{name: "无效的IP", kind: IPRuleModel.KindCIDR, value: "10.0.0.256", wantErr: true},
+		{name: "无效的前缀长度", kind: IPRuleModel.KindCIDR, value: "10.0.0.0/33", wantErr: true},
+		{name: "主机名", kind: IPRuleModel.KindCIDR, value: "example.com", wantErr: true},
+		{name: "国家代码转为大写", kind: IPRuleModel.KindCountry, value: "cn", want: "CN"},
+		{name: "三位国家代码", kind: IPRuleModel.KindCountry, value: "CHN", wantErr: true},
+		{name: "非字母国家代码", kind: IPRuleModel.KindCountry, value: "C1", wantErr: true},
+		{name: "未配置地理位置库", kind: IPRuleModel.KindCountry, value: "CN", noGeo: true, wantErr: true},
+		{name: "未知的规则类型", kind: "asn", value: "13335", wantErr: true},
+		{name: "未知的动作", action: "log", kind: IPRuleModel.KindCIDR, value: "10.0.0.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &IPRuleService{}
+			if !tt.noGeo {
+				s.geo = fakeGeo{}
+			}
+			action := tt.action
+			if action == "" {
+				action = IPRuleModel.ActionAllow
+			}
+			rule := IPRuleModel.IPRule{Action: action, Kind: tt.kind, Value: tt.value}
+			err := s.normalize(&rule)
+			if tt.wantErr {
+				if !errors.Is(err, constants.ErrInvalidIPRule) {
+					t.Errorf("normalize() error = %v, want ErrInvalidIPRule", err)
+				}
+				return
+			}
+			if err != nil || rule.Value != tt.want {
+				t.Errorf("normalize() = %q, %v, want %q", rule.Value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	geo := fakeGeo{"203.0.113.1": "CN", "198.51.100.1": "US", "192.0.2.1": "DE"}
+	allowIntranet := apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "10.0.0.0/8")
+	denyHost := apiRule(IPRuleModel.ActionDeny, IPRuleModel.KindCIDR, "10.0.0.5")
+	denyCN := apiRule(IPRuleModel.ActionDeny, IPRuleModel.KindCountry, "CN")
+	allowUS := apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCountry, "US")
+	tests := []struct {
+		name        string
+		rules       []IPRuleModel.IPRule
+		group       string
+		ip          string
+		want        bool
+		wantCountry string
+	}{
+		{name: "无规则时允许", ip: "203.0.113.1", want: true},
+		{name: "未配置规则的路由组", rules: []IPRuleModel.IPRule{allowIntranet}, group: "api", ip: "203.0.113.1", want: true},
+		{name: "命中允许规则", rules: []IPRuleModel.IPRule{allowIntranet}, ip: "10.1.2.3", want: true},
+		{name: "存在允许规则时未命中即拒绝", rules: []IPRuleModel.IPRule{allowIntranet}, ip: "192.168.1.1", want: false},
+		{name: "禁止规则优先于允许规则", rules: []IPRuleModel.IPRule{allowIntranet, denyHost}, ip: "10.0.0.5", want: false},
+		{name: "禁止规则只影响命中的地址", rules: []IPRuleModel.IPRule{allowIntranet, denyHost}, ip: "10.0.0.6", want: true},
+		{name: "只有禁止规则时其余地址允许", rules: []IPRuleModel.IPRule{denyHost}, ip: "192.168.1.1", want: true},
+		{name: "无法识别的IP在存在允许规则时拒绝", rules: []IPRuleModel.IPRule{allowIntranet}, ip: "unknown", want: false},
+		{name: "无法识别的IP在只有禁止规则时允许", rules: []IPRuleModel.IPRule{denyHost}, ip: "unknown", want: true},
+		{name: "禁止国家", rules: []IPRuleModel.IPRule{denyCN}, ip: "203.0.113.1", want: false, wantCountry: "CN"},
+		{name: "其他国家不受禁止国家影响", rules: []IPRuleModel.IPRule{denyCN}, ip: "198.51.100.1", want: true, wantCountry: "US"},
+		{name: "允许国家", rules: []IPRuleModel.IPRule{allowUS}, ip: "198.51.100.1", want: true, wantCountry: "US"},
+		{name: "不在允许国家中", rules: []IPRuleModel.IPRule{allowUS}, ip: "192.0.2.1", want: false, wantCountry: "DE"},
+		{name: "查不到国家时不命中允许国家", rules: []IPRuleModel.IPRule{allowUS}, ip: "192.0.2.99", want: false},
+		{name: "允许国家与允许地址任一命中即可", rules: []IPRuleModel.IPRule{allowUS, allowIntranet}, ip: "10.0.0.1", want: true},
+		{name: "禁止国家优先于允许地址", rules: []IPRuleModel.IPRule{denyCN, apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "203.0.113.0/24")}, ip: "203.0.113.1", want: false, wantCountry: "CN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, geo, tt.rules...)
+			group := tt.group
+			if group == "" {
+				group = IPRuleModel.GroupAdmin
+			}
+			got := s.Check(group, tt.ip)
+			if got.Allowed != tt.want || got.Country != tt.wantCountry {
+				t.Errorf("Check(%q) = %+v, want allowed %v country %q", tt.ip, got, tt.want, tt.wantCountry)
+			}
+			if !got.Allowed && got.Reason == "" {
+				t.Error("Check() denied without reason")
+			}
+		})
+	}
+}
+
+func TestCheckDisabled(t *testing.T) {
+	s, _ := newTestService(t, nil, apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "10.0.0.0/8"))
+	s.cfg.Enabled = false
+	if got := s.Check(IPRuleModel.GroupAdmin, "192.168.1.1"); !got.Allowed {
+		t.Errorf("Check() = %+v, want allowed when disabled", got)
+	}
+}
+
+// TestReloadFailsClosed 未配置地理位置库的实例加载到国家规则时无法编译，
+// 跳过允许规则不能让允许列表变空而放行所有请求
+func TestReloadFailsClosed(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []IPRuleModel.IPRule
+		ip    string
+		want  bool
+	}{
+		{
+			name:  "只有被跳过的允许规则时全部拒绝",
+			rules: []IPRuleModel.IPRule{apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCountry, "US")},
+			ip:    "198.51.100.1",
+			want:  false,
+		},
+		{
+			name: "仍允许命中其余允许规则的请求",
+			rules: []IPRuleModel.IPRule{
+				apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCountry, "US"),
+				apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "10.0.0.0/8"),
+			},
+			ip:   "10.0.0.1",
+			want: true,
+		},
+		{
+			name: "未命中其余允许规则时拒绝",
+			rules: []IPRuleModel.IPRule{
+				apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCountry, "US"),
+				apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "10.0.0.0/8"),
+			},
+			ip:   "198.51.100.1",
+			want: false,
+		},
+		{
+			name:  "跳过禁止规则不影响其余请求",
+			rules: []IPRuleModel.IPRule{apiRule(IPRuleModel.ActionDeny, IPRuleModel.KindCountry, "CN")},
+			ip:    "203.0.113.1",
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, nil, tt.rules...)
+			got := s.Check(IPRuleModel.GroupAdmin, tt.ip)
+			if got.Allowed != tt.want {
+				t.Errorf("Check(%q) = %+v, want allowed %v", tt.ip, got, tt.want)
+			}
+			if !got.Allowed && !strings.Contains(got.Reason, "未能加载") {
+				t.Errorf("Check() reason = %q, want mention of skipped rules", got.Reason)
+			}
+		})
+	}
+}
+
+func TestCreateRuleRefusesSelfLockout(t *testing.T) {
+	const operatorIP = "10.0.0.1"
+	tests := []struct {
+		name     string
+		existing []IPRuleModel.IPRule
+		req      structs.IPRuleRequest
+		wantErr  bool
+	}{
+		{
+			name:    "禁止操作者自己的IP",
+			req:     structs.IPRuleRequest{Group: IPRuleModel.GroupAdmin, Action: IPRuleModel.ActionDeny, Kind: IPRuleModel.KindCIDR, Value: operatorIP},
+			wantErr: true,
+		},
+		{
+			name:     "禁止包含操作者的地址段",
+			existing: []IPRuleModel.IPRule{apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "10.0.0.0/8")},
+			req:      structs.IPRuleRequest{Group: IPRuleModel.GroupAdmin, Action: IPRuleModel.ActionDeny, Kind: IPRuleModel.KindCIDR, Value: "10.0.0.0/24"},
+			wantErr:  true,
+		},
+		{
+			name:    "首条允许规则不包含操作者",
+			req:     structs.IPRuleRequest{Group: IPRuleModel.GroupAdmin, Action: IPRuleModel.ActionAllow, Kind: IPRuleModel.KindCIDR, Value: "192.168.0.0/16"},
+			wantErr: true,
+		},
+		{
+			name:     "已有允许规则包含操作者时可追加其他允许规则",
+			existing: []IPRuleModel.IPRule{apiRule(IPRuleModel.ActionAllow, IPRuleModel.KindCIDR, "10.0.0.0/8")},
+			req:      structs.IPRuleRequest{Group: IPRuleModel.GroupAdmin, Action: IPRuleModel.ActionAllow, Kind: IPRuleModel.KindCIDR, Value: "192.168.0.0/16"},
+		},
+		{
+			name: "允许规则包含操作者",
+			req:  structs.IPRuleRequest{Group: IPRuleModel.GroupAdmin, Action: IPRuleModel.ActionAllow, Kind: IPRuleModel.KindCIDR, Value: "10.0.0.0/8"},
+		},
+		{
+			name: "禁止其他地址",
+			req:  structs.IPRuleRequest{Group: IPRuleModel.GroupAdmin, Action: IPRuleModel.ActionDeny, Kind: IPRuleModel.KindCIDR, Value: "203.0.113.0/24"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t, fakeGeo{}, tt.existing...)
+			rule, err := s.CreateRule(context.Background(), &tt.req, 1, operatorIP)
+			if tt.wantErr {
+				if !errors.Is(err, constants.ErrInvalidIPRule) {
+					t.Fatalf("CreateRule() error = %v, want ErrInvalidIPRule", err)
+				}
+				if len(repo.rules) != len(tt.existing) {
+					t.Error("refused rule was saved")
+				}
+				if !s.Check(IPRuleModel.GroupAdmin, operatorIP).Allowed {
+					t.Error("operator locked out after refused rule")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateRule() error = %v", err)
+			}
+			if len(repo.rules) != len(tt.existing)+1 || rule.Source != IPRuleModel.SourceAPI {
+				t.Errorf("rule not saved: %+v", repo.rules)
+			}
+			if !s.Check(IPRuleModel.GroupAdmin, operatorIP).Allowed {
+				t.Error("operator locked out after accepted rule")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package login_history_service 实现登录历史记录与查询
+package login_history_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin-center/infrastructure/metrics"
+	login_history_repo "gin-center/infrastructure/repository/login_history"
+	"gin-center/infrastructure/zaplogger"
+	use_loginHistoryInterface "gin-center/internal/domain/interface/login_history"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LoginHistoryService 登录历史服务
+type LoginHistoryService struct {
+	logger      *zaplogger.ServiceLogger
+	historyRepo *login_history_repo.LoginHistoryRepository
+}
+
+// NewLoginHistoryService 创建新的登录历史服务实例
+func NewLoginHistoryService(historyRepo *login_history_repo.LoginHistoryRepository, logger *zaplogger.ServiceLogger) use_loginHistoryInterface.LoginHistoryServiceInterface {
+	return &LoginHistoryService{
+		logger:      logger,
+		historyRepo: historyRepo,
+	}
+}
+
+// Record 实现LoginHistoryServiceInterface接口
+// 记录失败不影响登录流程，仅输出错误日志
+func (s *LoginHistoryService) Record(ctx context.Context, role string, userID uint, username string, meta *auth.LoginMeta, reason string) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+	history := &LoginHistoryModel.LoginHistory{
+		UserID:    userID,
+		UserType:  userTypeOf(role),
+		Username:  truncate(username, 64),
+		Success:   reason == "",
+		Reason:    reason,
+		IP:        meta.IP,
+		UserAgent: truncate(meta.UserAgent, 512),
+		Device:    meta.Device,
+		CreatedAt: time.Now(),
+	}
+	metrics.RecordLogin(history.UserType, reason)
+	if err := s.historyRepo.Create(ctx, history); err != nil {
+		s.logger.LogError("记录登录历史失败",
+			zap.String("username", username),
+			zap.String("role", role),
+			zap.String("reason", reason),
+			zap.Error(err))
+	}
+}
+
+// ListByUser 实现LoginHistoryServiceInterface接口
+func (s *LoginHistoryService) ListByUser(ctx context.Context, role string, userID uint, success *bool, page, pageSize int) (*type_response.LoginHistoryListResponse, error) {
+	if page < 1 || pageSize < 1 {
+		return nil, errors.New("invalid pagination parameters")
+	}
+
+	histories, total, err := s.historyRepo.ListByUser(ctx, userTypeOf(role), userID, success, page, pageSize)
+	if err != nil {
+		s.logger.LogError("查询登录历史失败", zap.Uint("user_id", userID), zap.String("role", role), zap.Error(err))
+		return nil, fmt.Errorf("查询登录历史失败: %w", err)
+	}
+
+	items := make([]type_response.LoginHistoryResponse, len(histories))
+	for i, h := range histories {
+		items[i] = type_response.LoginHistoryResponse{
+			ID:        h.ID,
+			Username:  h.Username,
+			Success:   h.Success,
+			Reason:    h.Reason,
+			IP:        h.IP,
+			UserAgent: h.UserAgent,
+			Device:    h.Device,
+			CreatedAt: h.CreatedAt,
+		}
+	}
+
+	return &type_response.LoginHistoryListResponse{
+		ListResponse: type_response.ListResponse{
+			Total: total,
+			Page:  page,
+			Size:  pageSize,
+		},
+		Items: items,
+	}, nil
+}
+
+// userTypeOf 将会话角色转换为表中的用户类型
+func userTypeOf(role string) int {
+	if role == SessionModel.RoleAdmin {
+		return LoginHistoryModel.UserTypeAdmin
+	}
+	return LoginHistoryModel.UserTypeNormal
+}
+
+// truncate 按字符截断，避免超出字段长度
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
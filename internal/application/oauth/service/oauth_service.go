@@ -0,0 +1,380 @@
+// Package oauth_service 实现基于OAuth2/OIDC的第三方登录与账号关联
+package oauth_service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	infraErrors "gin-center/infrastructure/errors"
+	"gin-center/infrastructure/metrics"
+	identity_repo "gin-center/infrastructure/repository/identity"
+	user_repo "gin-center/infrastructure/repository/user"
+	"gin-center/infrastructure/zaplogger"
+	use_Baseservice "gin-center/internal/application"
+	use_loginHistoryInterface "gin-center/internal/domain/interface/login_history"
+	use_oauthInterface "gin-center/internal/domain/interface/oauth"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
+	IdentityModel "gin-center/internal/domain/model/identity"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
+	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	type_response "gin-center/internal/types/response"
+	"gin-center/pkg/security/useOAuth"
+	"gin-center/pkg/security/usePassword"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// OAuthService 第三方登录服务
+type OAuthService struct {
+	baseService  *use_Baseservice.BaseService
+	logger       *zaplogger.ServiceLogger
+	registry     *useOAuth.Registry
+	stateTTL     time.Duration
+	identityRepo *identity_repo.IdentityRepository
+	userRepo     *user_repo.UserRepository
+	sessions     use_sessionInterface.SessionServiceInterface
+	history      use_loginHistoryInterface.LoginHistoryServiceInterface
+}
+
+// NewOAuthService 创建新的第三方登录服务实例
+func NewOAuthService(registry *useOAuth.Registry, stateTTL time.Duration, identityRepo *identity_repo.IdentityRepository, userRepo *user_repo.UserRepository, sessions use_sessionInterface.SessionServiceInterface, history use_loginHistoryInterface.LoginHistoryServiceInterface, passwordHasher usePassword.Hasher, logger *zaplogger.ServiceLogger) use_oauthInterface.OAuthServiceInterface {
+	if stateTTL <= 0 {
+		stateTTL = 10 * time.Minute
+	}
+	return &OAuthService{
+		baseService: use_Baseservice.NewBaseService(&use_Baseservice.BaseServiceConfig{
+			Logger:         logger,
+			PasswordHasher: passwordHasher,
+		}),
+		logger:       logger,
+		registry:     registry,
+		stateTTL:     stateTTL,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		sessions:     sessions,
+		history:      history,
+	}
+}
+
+// Providers 实现OAuthServiceInterface接口
+func (s *OAuthService) Providers() []type_response.OAuthProviderResponse {
+	providers := s.registry.List()
+	result := make([]type_response.OAuthProviderResponse, len(providers))
+	for i, p := range providers {
+		result[i] = type_response.OAuthProviderResponse{
+			Name:        p.Name(),
+			DisplayName: p.DisplayName(),
+		}
+	}
+	return result
+}
+
+// Authorize 实现OAuthServiceInterface接口
+func (s *OAuthService) Authorize(ctx context.Context, providerName, mode string, userID uint) (string, string, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return "", "", err
+	}
+	req, err := useOAuth.NewAuthRequest()
+	if err != nil {
+		return "", "", err
+	}
+	binding, err := randomHex(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, req)
+	if err != nil {
+		s.logger.LogError("生成授权地址失败", zap.String("provider", providerName), zap.Error(err))
+		return "", "", err
+	}
+	err = s.identityRepo.SaveState(ctx, req.State, &IdentityModel.OAuthState{
+		Provider:     providerName,
+		Mode:         mode,
+		UserID:       userID,
+		Nonce:        req.Nonce,
+		CodeVerifier: req.CodeVerifier,
+		BindingHash:  hashBinding(binding),
+		CreatedAt:    time.Now(),
+	}, s.stateTTL)
+	if err != nil {
+		s.logger.LogError("保存授权状态失败", zap.String("provider", providerName), zap.Error(err))
+		return "", "", err
+	}
+	return authURL, binding, nil
+}
+
+// Callback 实现OAuthServiceInterface接口
+// state只能证明授权请求由本服务发起，绑定值证明回调来自发起授权的浏览器，
+// 防止攻击者把自己发起的授权回调地址发给受害者，将受害者的第三方身份关联到攻击者的账号
+func (s *OAuthService) Callback(ctx context.Context, providerName, code, state, binding string, userID uint, meta *auth.LoginMeta) (map[string]interface{}, error) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+	stored, err := s.identityRepo.ConsumeState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Provider != providerName {
+		return nil, constants.ErrInvalidOAuthState
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(hashBinding(binding)), []byte(stored.BindingHash)) != 1 {
+		s.logger.LogWarn("授权回调与发起授权的浏览器不一致", zap.String("provider", providerName), zap.String("ip", meta.IP))
+		return nil, constants.ErrInvalidOAuthState
+	}
+	if stored.Mode == IdentityModel.StateModeLink && userID != stored.UserID {
+		s.logger.LogWarn("关联回调的登录用户与发起关联的用户不一致",
+			zap.String("provider", providerName), zap.Uint("user_id", stored.UserID), zap.Uint("current_user_id", userID))
+		return nil, constants.ErrOAuthLinkMismatch
+	}
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, code, &useOAuth.AuthRequest{
+		State:        state,
+		Nonce:        stored.Nonce,
+		CodeVerifier: stored.CodeVerifier,
+	})
+	if err != nil {
+		s.logger.LogWarn("第三方身份校验失败", zap.String("provider", providerName), zap.String("ip", meta.IP), zap.Error(err))
+		return nil, err
+	}
+
+	if stored.Mode == IdentityModel.StateModeLink {
+		return s.link(ctx, stored.UserID, identity)
+	}
+	return s.login(ctx, provider, identity, meta)
+}
+
+// ListIdentities 实现OAuthServiceInterface接口
+func (s *OAuthService) ListIdentities(ctx context.Context, userID uint) ([]type_response.IdentityResponse, error) {
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]type_response.IdentityResponse, len(identities))
+	for i, identity := range identities {
+		result[i] = type_response.IdentityResponse{
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			DisplayName: identity.DisplayName,
+			CreatedAt:   identity.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// Unlink 实现OAuthServiceInterface接口
+func (s *OAuthService) Unlink(ctx context.Context, userID uint, provider string) error {
+	if err := s.identityRepo.DeleteByUserProvider(ctx, userID, provider); err != nil {
+		return err
+	}
+	s.logger.LogInfo("已解除第三方身份关联", zap.Uint("user_id", userID), zap.String("provider", provider))
+	return nil
+}
+
+// login 使用第三方身份登录，未关联时按提供方配置自动注册
+func (s *OAuthService) login(ctx context.Context, provider useOAuth.Provider, identity *useOAuth.Identity, meta *auth.LoginMeta) (map[string]interface{}, error) {
+	historyName := identity.Provider + ":" + identity.Username
+
+	var user *UserModel.User
+	linked, err := s.identityRepo.FindByProviderSubject(ctx, identity.Provider, identity.Subject)
+	switch {
+	case errors.Is(err, constants.ErrIdentityNotLinked):
+		if !provider.AutoRegister() {
+			s.history.Record(ctx, SessionModel.RoleUser, 0, historyName, meta, LoginHistoryModel.ReasonNotLinked)
+			return nil, err
+		}
+		user, err = s.registerUser(ctx, identity)
+		if err != nil {
+			s.history.Record(ctx, SessionModel.RoleUser, 0, historyName, meta, LoginHistoryModel.ReasonInternalError)
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		user, err = s.userRepo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			s.history.Record(ctx, SessionModel.RoleUser, linked.UserID, historyName, meta, LoginHistoryModel.ReasonUserNotFound)
+			return nil, err
+		}
+	}
+
+	if user.Status == 0 {
+		s.history.Record(ctx, SessionModel.RoleUser, user.ID, user.Username, meta, LoginHistoryModel.ReasonAccountDisabled)
+		return nil, constants.ErrUserInactive
+	}
+
+	tokens, err := s.sessions.CreateSession(ctx, SessionModel.RoleUser, user.ID, user.Username, meta)
+	if err != nil {
+		s.history.Record(ctx, SessionModel.RoleUser, user.ID, user.Username, meta, LoginHistoryModel.ReasonInternalError)
+		return nil, err
+	}
+	if err := s.userRepo.UpdateLoginInfo(ctx, user.ID, meta.IP, time.Now()); err != nil {
+		s.logger.LogWarn("更新登录信息失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	s.history.Record(ctx, SessionModel.RoleUser, user.ID, user.Username, meta, "")
+	s.logger.LogInfo("第三方登录成功", zap.String("provider", identity.Provider), zap.Uint("user_id", user.ID))
+
+	return map[string]interface{}{
+		"token":    tokens.AccessToken,
+		"tokens":   tokens,
+		"provider": identity.Provider,
+		"user": map[string]interface{}{
+			"id":       user.ID,
+			"username": user.Username,
+			"nickname": user.Nickname,
+			"avatar":   user.Avatar,
+		},
+	}, nil
+}
+
+// link 将第三方身份关联到已登录的用户
+func (s *OAuthService) link(ctx context.Context, userID uint, identity *useOAuth.Identity) (map[string]interface{}, error) {
+	existing, err := s.identityRepo.FindByProviderSubject(ctx, identity.Provider, identity.Subject)
+	switch {
+	case err == nil && existing.UserID != userID:
+		return nil, constants.ErrIdentityLinked
+	case err == nil:
+		// 重复关联同一身份视为成功
+	case errors.Is(err, constants.ErrIdentityNotLinked):
+		if err := s.identityRepo.Create(ctx, newUserIdentity(userID, identity)); err != nil {
+			return nil, err
+		}
+		s.logger.LogInfo("已关联第三方身份", zap.Uint("user_id", userID), zap.String("provider", identity.Provider))
+	default:
+		return nil, err
+	}
+	return map[string]interface{}{
+		"linked":   true,
+		"provider": identity.Provider,
+	}, nil
+}
+
+// registerUser 为未关联的第三方身份创建普通用户，密码随机生成，用户只能通过第三方登录
+func (s *OAuthService) registerUser(ctx context.Context, identity *useOAuth.Identity) (*UserModel.User, error) {
+	randomPassword, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.baseService.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	nickname := identity.Name
+	if nickname == "" {
+		nickname = identity.Username
+	}
+	base := usernameFrom(identity)
+
+	var user *UserModel.User
+	for attempt := 0; attempt < 5; attempt++ {
+		username := base
+		if attempt > 0 {
+			suffix, err := randomHex(2)
+			if err != nil {
+				return nil, err
+			}
+			username = base + suffix
+		}
+		user = UserModel.NewUser(username, hashedPassword)
+		user.Nickname = truncate(nickname, 32)
+		if len(identity.AvatarURL) <= 255 {
+			user.Avatar = identity.AvatarURL
+		}
+		user.Status = 1
+		err = s.userRepo.Register(ctx, user)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, infraErrors.ErrUsernameExists) {
+			return nil, err
+		}
+		user = nil
+	}
+	if user == nil {
+		return nil, fmt.Errorf("无法为第三方身份生成可用的用户名: %s", base)
+	}
+
+	if err := s.identityRepo.Create(ctx, newUserIdentity(user.ID, identity)); err != nil {
+		if delErr := s.userRepo.Delete(ctx, user.ID); delErr != nil {
+			s.logger.LogError("回滚自动注册用户失败", zap.Uint("user_id", user.ID), zap.Error(delErr))
+		}
+		return nil, err
+	}
+	metrics.RecordRegistrations(LoginHistoryModel.UserTypeNormal, metrics.SourceOAuth, 1)
+	s.logger.LogInfo("第三方身份自动注册用户", zap.String("provider", identity.Provider), zap.Uint("user_id", user.ID))
+	return user, nil
+}
+
+func newUserIdentity(userID uint, identity *useOAuth.Identity) *IdentityModel.UserIdentity {
+	displayName := identity.Name
+	if displayName == "" {
+		displayName = identity.Username
+	}
+	return &IdentityModel.UserIdentity{
+		UserID:      userID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		DisplayName: truncate(displayName, 128),
+	}
+}
+
+// usernameFrom 根据第三方身份生成仅含字母数字的候选用户名，预留冲突时追加的后缀长度
+func usernameFrom(identity *useOAuth.Identity) string {
+	source := identity.Username
+	if source == "" {
+		source, _, _ = strings.Cut(identity.Email, "@")
+	}
+	var b strings.Builder
+	for _, r := range source {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsNumber(r)) {
+			b.WriteRune(r)
+		}
+	}
+	username := b.String()
+	if len(username) < 3 {
+		username = identity.Provider + username
+	}
+	if len(username) > 28 {
+		username = username[:28]
+	}
+	return username
+}
+
+// hashBinding 状态中只保存绑定值的摘要，Redis中的数据泄露后也无法伪造绑定Cookie
+func hashBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
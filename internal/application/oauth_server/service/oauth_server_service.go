@@ -0,0 +1,647 @@
+// Package oauth_server_service 实现面向内部应用的OAuth2授权服务
+package oauth_server_service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gin-center/infrastructure/repository/admin"
+	oauth_client_repo "gin-center/infrastructure/repository/oauth_client"
+	user_repo "gin-center/infrastructure/repository/user"
+	"gin-center/infrastructure/zaplogger"
+	use_oauthServerInterface "gin-center/internal/domain/interface/oauth_server"
+	AdminModel "gin-center/internal/domain/model/admin"
+	OAuthClientModel "gin-center/internal/domain/model/oauth_client"
+	SessionModel "gin-center/internal/domain/model/session"
+	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	"gin-center/pkg/security/useJwt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const tokenTypeBearer = "Bearer"
+
+// oauthClientRepository 客户端、授权码及撤销列表存储，由*oauth_client_repo.OAuthClientRepository实现
+type oauthClientRepository interface {
+	Create(ctx context.Context, client *OAuthClientModel.OAuthClient) error
+	FindByID(ctx context.Context, id uint) (*OAuthClientModel.OAuthClient, error)
+	FindByClientID(ctx context.Context, clientID string) (*OAuthClientModel.OAuthClient, error)
+	List(ctx context.Context, page, pageSize int) ([]OAuthClientModel.OAuthClient, int64, error)
+	Update(ctx context.Context, client *OAuthClientModel.OAuthClient) error
+	Delete(ctx context.Context, id uint) error
+	SaveCode(ctx context.Context, code string, value *OAuthClientModel.AuthorizationCode, ttl time.Duration) error
+	ConsumeCode(ctx context.Context, code string) (*OAuthClientModel.AuthorizationCode, error)
+	RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// userFinder 普通用户查询，由*user_repo.UserRepository实现
+type userFinder interface {
+	FindByID(ctx context.Context, id uint) (*UserModel.User, error)
+}
+
+// adminFinder 管理员查询，由*admin.AdminRepository实现
+type adminFinder interface {
+	FindByID(ctx context.Context, id uint) (*AdminModel.Admin, error)
+}
+
+// OAuthServerService 授权服务
+type OAuthServerService struct {
+	logger     *zaplogger.ServiceLogger
+	clientRepo oauthClientRepository
+	userRepo   userFinder
+	adminRepo  adminFinder
+	jwtConfig  *useJwt.JWTConfig
+	codeTTL    time.Duration
+}
+
+// NewOAuthServerService 创建新的授权服务实例
+func NewOAuthServerService(clientRepo *oauth_client_repo.OAuthClientRepository, userRepo *user_repo.UserRepository, adminRepo *admin.AdminRepository, jwtConfig *useJwt.JWTConfig, codeTTL time.Duration, logger *zaplogger.ServiceLogger) use_oauthServerInterface.OAuthServerServiceInterface {
+	if codeTTL <= 0 {
+		codeTTL = 5 * time.Minute
+	}
+	return &OAuthServerService{
+		logger:     logger,
+		clientRepo: clientRepo,
+		userRepo:   userRepo,
+		adminRepo:  adminRepo,
+		jwtConfig:  jwtConfig,
+		codeTTL:    codeTTL,
+	}
+}
+
+// CreateClient 实现OAuthServerServiceInterface接口
+func (s *OAuthServerService) CreateClient(ctx context.Context, req *structs.OAuthClientRequest, createdBy uint) (*type_response.OAuthClientResponse, error) {
+	client := &OAuthClientModel.OAuthClient{CreatedBy: createdBy, Status: 1}
+	if err := applyClientRequest(client, req); err != nil {
+		return nil, err
+	}
+	clientID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	client.ClientID = clientID
+
+	var secret string
+	if client.Confidential {
+		if secret, err = randomToken(32); err != nil {
+			return nil, err
+		}
+		client.SecretHash = hashSecret(secret)
+	}
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, err
+	}
+	s.logger.LogInfo("已创建OAuth客户端", zap.String("client_id", client.ClientID), zap.Uint("operator", createdBy))
+
+	resp := toClientResponse(client)
+	resp.ClientSecret = secret
+	return resp, nil
+}
+
+// ListClients 实现OAuthServerServiceInterface接口
+func (s *OAuthServerService) ListClients(ctx context.Context, page, pageSize int) (*type_response.OAuthClientListResponse, error) {
+	clients, total, err := s.clientRepo.List(ctx, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]type_response.OAuthClientResponse, len(clients))
+	for i := range clients {
+		items[i] = *toClientResponse(&clients[i])
+	}
+	return &type_response.OAuthClientListResponse{
+		ListResponse: type_response.ListResponse{Total: total, Page: page, Size: pageSize},
+		Items:        items,
+	}, nil
+}
+
+// UpdateClient 实现OAuthServerServiceInterface接口，客户端类型创建后不可修改
+func (s *OAuthServerService) UpdateClient(ctx context.Context, id uint, req *structs.OAuthClientRequest) (*type_response.OAuthClientResponse, error) {
+	client, err := s.clientRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req.Confidential = client.Confidential
+	if err := applyClientRequest(client, req); err != nil {
+		return nil, err
+	}
+	if req.Status != nil {
+		client.Status = *req.Status
+	}
+	if err := s.clientRepo.Update(ctx, client); err != nil {
+		return nil, err
+	}
+	s.logger.LogInfo("已更新OAuth客户端", zap.String("client_id", client.ClientID))
+	return toClientResponse(client), nil
+}
+
+// DeleteClient 实现OAuthServerServiceInterface接口
+func (s *OAuthServerService) DeleteClient(ctx context.Context, id uint) error {
+	if err := s.clientRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.logger.LogInfo("已删除OAuth客户端", zap.Uint("id", id))
+	return nil
+}
+
+// RotateSecret 实现OAuthServerServiceInterface接口，旧密钥立即失效
+func (s *OAuthServerService) RotateSecret(ctx context.Context, id uint) (*type_response.OAuthClientResponse, error) {
+	client, err := s.clientRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !client.Confidential {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "公开客户端没有密钥")
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	client.SecretHash = hashSecret(secret)
+	if err := s.clientRepo.Update(ctx, client); err != nil {
+		return nil, err
+	}
+	s.logger.LogInfo("已重置OAuth客户端密钥", zap.String("client_id", client.ClientID))
+
+	resp := toClientResponse(client)
+	resp.ClientSecret = secret
+	return resp, nil
+}
+
+// PreviewAuthorization 实现OAuthServerServiceInterface接口
+func (s *OAuthServerService) PreviewAuthorization(ctx context.Context, req *structs.AuthorizeRequest) (*type_response.AuthorizationPreviewResponse, error) {
+	client, err := s.authorizationClient(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAuthorizeParams(client, req); err != nil {
+		return nil, err
+	}
+	return &type_response.AuthorizationPreviewResponse{
+		ClientID: client.ClientID,
+		Name:     client.Name,
+		Scopes:   strings.Fields(req.Scope),
+	}, nil
+}
+
+// Authorize 实现OAuthServerServiceInterface接口
+// 客户端或回调地址无效时直接返回错误，其余错误通过回调地址告知客户端(RFC 6749 4.1.2.1节)
+func (s *OAuthServerService) Authorize(ctx context.Context, req *structs.AuthorizeRequest, role string, userID uint, username string) (string, error) {
+	client, err := s.authorizationClient(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := validateAuthorizeParams(client, req); err != nil {
+		var oauthErr *auth.OAuthError
+		if errors.As(err, &oauthErr) {
+			return redirectWith(req.RedirectURI, url.Values{
+				"error":             {oauthErr.Code},
+				"error_description": {oauthErr.Description},
+				"state":             {req.State},
+			}), nil
+		}
+		return "", err
+	}
+	if !req.Approve {
+		return redirectWith(req.RedirectURI, url.Values{
+			"error": {auth.OAuthErrAccessDenied},
+			"state": {req.State},
+		}), nil
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = s.clientRepo.SaveCode(ctx, code, &OAuthClientModel.AuthorizationCode{
+		ClientID:            client.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		UserID:              userID,
+		Username:            username,
+		Role:                role,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		CreatedAt:           time.Now(),
+	}, s.codeTTL)
+	if err != nil {
+		s.logger.LogError("保存授权码失败", zap.String("client_id", client.ClientID), zap.Error(err))
+		return "", err
+	}
+	s.logger.LogInfo("已签发授权码", zap.String("client_id", client.ClientID), zap.String("role", role), zap.Uint("user_id", userID))
+	return redirectWith(req.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {req.State},
+	}), nil
+}
+
+// AuthenticateClient 实现OAuthServerServiceInterface接口
+func (s *OAuthServerService) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*OAuthClientModel.OAuthClient, error) {
+	if clientID == "" {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidClient, "缺少客户端凭证")
+	}
+	client, err := s.clientRepo.FindByClientID(ctx, clientID)
+	if errors.Is(err, constants.ErrOAuthClientNotFound) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidClient, "客户端认证失败")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.Active() {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidClient, "客户端已禁用")
+	}
+	if client.Confidential {
+		if clientSecret == "" || subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+			s.logger.LogWarn("客户端密钥错误", zap.String("client_id", clientID))
+			return nil, auth.NewOAuthError(auth.OAuthErrInvalidClient, "客户端认证失败")
+		}
+	}
+	return client, nil
+}
+
+// Token 实现OAuthServerServiceInterface接口
+func (s *OAuthServerService) Token(ctx context.Context, client *OAuthClientModel.OAuthClient, req *structs.OAuthTokenRequest) (*type_response.OAuthTokenResponse, error) {
+	switch req.GrantType {
+	case OAuthClientModel.GrantAuthorizationCode:
+		if !client.AllowsGrant(req.GrantType) {
+			return nil, auth.NewOAuthError(auth.OAuthErrUnauthorizedClient, "客户端不允许使用授权码模式")
+		}
+		return s.exchangeCode(ctx, client, req)
+	case OAuthClientModel.GrantClientCredentials:
+		if !client.Confidential || !client.AllowsGrant(req.GrantType) {
+			return nil, auth.NewOAuthError(auth.OAuthErrUnauthorizedClient, "客户端不允许使用客户端凭证模式")
+		}
+		return s.clientCredentials(client, req)
+	case "":
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "缺少grant_type")
+	default:
+		return nil, auth.NewOAuthError(auth.OAuthErrUnsupportedGrantType, "")
+	}
+}
+
+// Introspect 实现OAuthServerServiceInterface接口，仅机密客户端可调用
+// 无效、过期、已撤销或非授权服务签发的令牌统一返回active=false
+func (s *OAuthServerService) Introspect(ctx context.Context, client *OAuthClientModel.OAuthClient, token string) (*type_response.IntrospectionResponse, error) {
+	if !client.Confidential {
+		return nil, auth.NewOAuthError(auth.OAuthErrUnauthorizedClient, "公开客户端不能内省令牌")
+	}
+	claims, err := s.parseAccessToken(ctx, token)
+	if err != nil {
+		if isInactive(err) {
+			return &type_response.IntrospectionResponse{Active: false}, nil
+		}
+		return nil, err
+	}
+
+	resp := &type_response.IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		TokenType: tokenTypeBearer,
+		Sub:       subjectOf(claims),
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+		Role:      claims.Role,
+	}
+	if claims.Role != OAuthClientModel.RoleClient {
+		resp.Username = claims.Username
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Time.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Time.Unix()
+	}
+	return resp, nil
+}
+
+// Revoke 实现OAuthServerServiceInterface接口
+// 按RFC 7009，无效令牌或其他客户端的令牌不报错，直接视为撤销成功
+func (s *OAuthServerService) Revoke(ctx context.Context, client *OAuthClientModel.OAuthClient, token string) error {
+	claims, err := s.parseAccessToken(ctx, token)
+	if err != nil {
+		if isInactive(err) {
+			return nil
+		}
+		return err
+	}
+	if claims.ClientID != client.ClientID {
+		return nil
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if err := s.clientRepo.RevokeToken(ctx, claims.ID, ttl); err != nil {
+		return err
+	}
+	s.logger.LogInfo("已撤销访问令牌", zap.String("client_id", client.ClientID), zap.String("jti", claims.ID))
+	return nil
+}
+
+// UserInfo 实现OAuthServerServiceInterface接口，需要openid或profile授权范围
+func (s *OAuthServerService) UserInfo(ctx context.Context, token string) (*type_response.UserInfoResponse, error) {
+	claims, err := s.parseAccessToken(ctx, token)
+	if err != nil {
+		if isInactive(err) {
+			return nil, auth.NewOAuthError(auth.OAuthErrInvalidToken, "访问令牌无效或已过期")
+		}
+		return nil, err
+	}
+	if claims.Role == OAuthClientModel.RoleClient {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidToken, "客户端凭证令牌不代表任何用户")
+	}
+	if !hasScope(claims.Scope, OAuthClientModel.ScopeOpenID) && !hasScope(claims.Scope, OAuthClientModel.ScopeProfile) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidToken, "访问令牌缺少openid或profile授权范围")
+	}
+	userID, err := strconv.ParseUint(claims.UserID, 10, 64)
+	if err != nil {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidToken, "")
+	}
+
+	resp := &type_response.UserInfoResponse{
+		Sub:               subjectOf(claims),
+		PreferredUsername: claims.Username,
+		Role:              claims.Role,
+	}
+	profile, err := s.findSubject(ctx, claims.Role, uint(userID))
+	if errors.Is(err, errInactiveSubject) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidToken, "用户不存在或已禁用")
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.PreferredUsername = profile.Username
+	if hasScope(claims.Scope, OAuthClientModel.ScopeProfile) {
+		resp.Nickname, resp.Picture = profile.Nickname, profile.Avatar
+	}
+	return resp, nil
+}
+
+// exchangeCode 使用授权码兑换访问令牌，并校验回调地址与PKCE
+func (s *OAuthServerService) exchangeCode(ctx context.Context, client *OAuthClientModel.OAuthClient, req *structs.OAuthTokenRequest) (*type_response.OAuthTokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "缺少code或code_verifier")
+	}
+	code, err := s.clientRepo.ConsumeCode(ctx, req.Code)
+	if errors.Is(err, oauth_client_repo.ErrCodeNotFound) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidGrant, "授权码无效或已过期")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidGrant, "授权码与客户端或回调地址不匹配")
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidGrant, "code_verifier校验失败")
+	}
+	// 授权后到兑换前用户可能已被禁用或删除
+	if _, err := s.findSubject(ctx, code.Role, code.UserID); err != nil {
+		if errors.Is(err, errInactiveSubject) {
+			return nil, auth.NewOAuthError(auth.OAuthErrInvalidGrant, "用户不存在或已禁用")
+		}
+		return nil, err
+	}
+
+	return s.issue(&useJwt.TokenSubject{
+		UserID:   strconv.FormatUint(uint64(code.UserID), 10),
+		Username: code.Username,
+		Role:     code.Role,
+		ClientID: client.ClientID,
+		Scope:    code.Scope,
+	})
+}
+
+// clientCredentials 为客户端自身签发访问令牌
+func (s *OAuthServerService) clientCredentials(client *OAuthClientModel.OAuthClient, req *structs.OAuthTokenRequest) (*type_response.OAuthTokenResponse, error) {
+	scope := req.Scope
+	if scope == "" {
+		scope = client.Scopes
+	}
+	if !client.AllowsScope(scope) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidScope, "")
+	}
+	return s.issue(&useJwt.TokenSubject{
+		Username: client.ClientID,
+		Role:     OAuthClientModel.RoleClient,
+		ClientID: client.ClientID,
+		Scope:    scope,
+	})
+}
+
+func (s *OAuthServerService) issue(subject *useJwt.TokenSubject) (*type_response.OAuthTokenResponse, error) {
+	token, claims, err := s.jwtConfig.IssueAccessToken(subject, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &type_response.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   tokenTypeBearer,
+		ExpiresIn:   int64(time.Until(claims.ExpiresAt.Time) / time.Second),
+		Scope:       claims.Scope,
+	}, nil
+}
+
+// parseAccessToken 解析授权服务签发的访问令牌，登录会话令牌不在此列
+func (s *OAuthServerService) parseAccessToken(ctx context.Context, token string) (*structs.JWTUserClaims, error) {
+	claims, err := s.jwtConfig.ParseClaims(token)
+	if err != nil {
+		return nil, errInactiveToken
+	}
+	if claims.ClientID == "" || claims.ExpiresAt == nil {
+		return nil, errInactiveToken
+	}
+	revoked, err := s.clientRepo.IsTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errInactiveToken
+	}
+	return claims, nil
+}
+
+var (
+	errInactiveToken   = errors.New("令牌无效")
+	errInactiveSubject = errors.New("用户不存在或已禁用")
+)
+
+// subjectProfile 令牌主体的当前资料
+type subjectProfile struct {
+	Username string
+	Nickname string
+	Avatar   string
+}
+
+// findSubject 按角色查询管理员或普通用户，不存在或已禁用时返回errInactiveSubject
+func (s *OAuthServerService) findSubject(ctx context.Context, role string, userID uint) (*subjectProfile, error) {
+	if role == SessionModel.RoleAdmin {
+		adminUser, err := s.adminRepo.FindByID(ctx, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInactiveSubject
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询管理员失败: %w", err)
+		}
+		if adminUser.Status == 0 {
+			return nil, errInactiveSubject
+		}
+		return &subjectProfile{Username: adminUser.Username, Nickname: adminUser.Nickname, Avatar: adminUser.Avatar}, nil
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, constants.ErrUserNotFound) {
+		return nil, errInactiveSubject
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == 0 {
+		return nil, errInactiveSubject
+	}
+	return &subjectProfile{Username: user.Username, Nickname: user.Nickname, Avatar: user.Avatar}, nil
+}
+
+func isInactive(err error) bool {
+	return errors.Is(err, errInactiveToken)
+}
+
+// authorizationClient 校验授权请求中的客户端与回调地址，失败时不能重定向
+func (s *OAuthServerService) authorizationClient(ctx context.Context, req *structs.AuthorizeRequest) (*OAuthClientModel.OAuthClient, error) {
+	if req.ClientID == "" || req.RedirectURI == "" {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "缺少client_id或redirect_uri")
+	}
+	client, err := s.clientRepo.FindByClientID(ctx, req.ClientID)
+	if errors.Is(err, constants.ErrOAuthClientNotFound) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidClient, "客户端不存在")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.Active() {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidClient, "客户端已禁用")
+	}
+	if !client.AllowsRedirect(req.RedirectURI) {
+		return nil, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "回调地址未登记")
+	}
+	return client, nil
+}
+
+// validateAuthorizeParams 校验可通过回调地址返回的授权参数，授权码模式强制使用PKCE
+func validateAuthorizeParams(client *OAuthClientModel.OAuthClient, req *structs.AuthorizeRequest) error {
+	if req.ResponseType != "code" {
+		return auth.NewOAuthError(auth.OAuthErrUnsupportedResponseType, "")
+	}
+	if !client.AllowsGrant(OAuthClientModel.GrantAuthorizationCode) {
+		return auth.NewOAuthError(auth.OAuthErrUnauthorizedClient, "")
+	}
+	if req.Scope == "" {
+		req.Scope = client.Scopes
+	}
+	if !client.AllowsScope(req.Scope) {
+		return auth.NewOAuthError(auth.OAuthErrInvalidScope, "")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != OAuthClientModel.CodeChallengeS256 {
+		return auth.NewOAuthError(auth.OAuthErrInvalidRequest, "需要使用S256方式的code_challenge")
+	}
+	return nil
+}
+
+// applyClientRequest 将请求参数写入客户端，授权码模式必须登记回调地址
+func applyClientRequest(client *OAuthClientModel.OAuthClient, req *structs.OAuthClientRequest) error {
+	grants := strings.Join(req.GrantTypes, " ")
+	client.Name = req.Name
+	client.RedirectURIs = strings.Join(req.RedirectURIs, " ")
+	client.GrantTypes = grants
+	client.Scopes = strings.Join(req.Scopes, " ")
+	client.Confidential = req.Confidential
+
+	if client.AllowsGrant(OAuthClientModel.GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return auth.NewOAuthError(auth.OAuthErrInvalidRequest, "授权码模式需要至少一个回调地址")
+	}
+	if client.AllowsGrant(OAuthClientModel.GrantClientCredentials) && !client.Confidential {
+		return auth.NewOAuthError(auth.OAuthErrInvalidRequest, "客户端凭证模式仅支持机密客户端")
+	}
+	return nil
+}
+
+func toClientResponse(client *OAuthClientModel.OAuthClient) *type_response.OAuthClientResponse {
+	return &type_response.OAuthClientResponse{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: strings.Fields(client.RedirectURIs),
+		GrantTypes:   strings.Fields(client.GrantTypes),
+		Scopes:       strings.Fields(client.Scopes),
+		Confidential: client.Confidential,
+		Status:       client.Status,
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+	}
+}
+
+// subjectOf 返回令牌主体标识，管理员加前缀以区分普通用户
+func subjectOf(claims *structs.JWTUserClaims) string {
+	switch claims.Role {
+	case OAuthClientModel.RoleClient:
+		return claims.ClientID
+	case SessionModel.RoleAdmin:
+		return SessionModel.RoleAdmin + ":" + claims.UserID
+	default:
+		return claims.UserID
+	}
+}
+
+func hasScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCodeChallenge 校验PKCE: BASE64URL(SHA256(code_verifier)) == code_challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func redirectWith(redirectURI string, params url.Values) string {
+	for key, values := range params {
+		if len(values) == 0 || values[0] == "" {
+			params.Del(key)
+		}
+	}
+	sep := "?"
+	if strings.Contains(redirectURI, "?") {
+		sep = "&"
+	}
+	return redirectURI + sep + params.Encode()
+}
+
+// hashSecret 客户端密钥为高熵随机串，使用SHA-256摘要存储即可
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,439 @@
+package oauth_server_service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	oauth_client_repo "gin-center/infrastructure/repository/oauth_client"
+	"gin-center/infrastructure/zaplogger"
+	AdminModel "gin-center/internal/domain/model/admin"
+	baseModel "gin-center/internal/domain/model/base"
+	OAuthClientModel "gin-center/internal/domain/model/oauth_client"
+	SessionModel "gin-center/internal/domain/model/session"
+	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	"gin-center/pkg/security/useJwt"
+
+	"gorm.io/gorm"
+)
+
+// RFC 7636附录B中的示例
+const (
+	testVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	testRedirect  = "https://app.example.com/callback"
+)
+
+// fakeClientRepository 内存中的客户端、授权码与撤销列表
+type fakeClientRepository struct {
+	clients map[string]*OAuthClientModel.OAuthClient
+	codes   map[string]*OAuthClientModel.AuthorizationCode
+	revoked map[string]bool
+}
+
+func (r *fakeClientRepository) Create(ctx context.Context, client *OAuthClientModel.OAuthClient) error {
+	r.clients[client.ClientID] = client
+	return nil
+}
+
+func (r *fakeClientRepository) FindByID(ctx context.Context, id uint) (*OAuthClientModel.OAuthClient, error) {
+	for _, client := range r.clients {
+		if client.ID == id {
+			return client, nil
+		}
+	}
+	return nil, constants.ErrOAuthClientNotFound
+}
+
+func (r *fakeClientRepository) FindByClientID(ctx context.Context, clientID string) (*OAuthClientModel.OAuthClient, error) {
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, constants.ErrOAuthClientNotFound
+	}
+	return client, nil
+}
+
+func (r *fakeClientRepository) List(ctx context.Context, page, pageSize int) ([]OAuthClientModel.OAuthClient, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeClientRepository) Update(ctx context.Context, client *OAuthClientModel.OAuthClient) error {
+	return nil
+}
+
+func (r *fakeClientRepository) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (r *fakeClientRepository) SaveCode(ctx context.Context, code string, value *OAuthClientModel.AuthorizationCode, ttl time.Duration) error {
+	r.codes[code] = value
+	return nil
+}
+
+func (r *fakeClientRepository) ConsumeCode(ctx context.Context, code string) (*OAuthClientModel.AuthorizationCode, error) {
+	value, ok := r.codes[code]
+	if !ok {
+		return nil, oauth_client_repo.ErrCodeNotFound
+	}
+	delete(r.codes, code)
+	return value, nil
+}
+
+func (r *fakeClientRepository) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	r.revoked[tokenID] = true
+	return nil
+}
+
+func (r *fakeClientRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return r.revoked[tokenID], nil
+}
+
+type fakeUsers map[uint]*UserModel.User
+
+func (f fakeUsers) FindByID(ctx context.Context, id uint) (*UserModel.User, error) {
+	if user, ok := f[id]; ok {
+		return user, nil
+	}
+	return nil, constants.ErrUserNotFound
+}
+
+type fakeAdmins map[uint]*AdminModel.Admin
+
+func (f fakeAdmins) FindByID(ctx context.Context, id uint) (*AdminModel.Admin, error) {
+	if adminUser, ok := f[id]; ok {
+		return adminUser, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// newTestService 登记一个公开的授权码客户端web和一个机密的客户端凭证客户端backend
+// 用户1启用、用户2禁用，管理员1启用、管理员2禁用
+func newTestService(t *testing.T) (*OAuthServerService, *fakeClientRepository) {
+	t.Helper()
+	jwtConfig := useJwt.NewJWTConfig(&useJwt.JWTConfig{SecretKey: "test-secret"})
+	t.Cleanup(jwtConfig.Close)
+	repo := &fakeClientRepository{
+		clients: map[string]*OAuthClientModel.OAuthClient{
+			"web": {
+				ClientID:     "web",
+				RedirectURIs: testRedirect,
+				GrantTypes:   OAuthClientModel.GrantAuthorizationCode,
+				Scopes:       "openid profile",
+				Status:       1,
+			},
+			"backend": {
+				ClientID:     "backend",
+				SecretHash:   hashSecret("backend-secret"),
+				GrantTypes:   OAuthClientModel.GrantClientCredentials,
+				Scopes:       "reports",
+				Confidential: true,
+				Status:       1,
+			},
+		},
+		codes:   map[string]*OAuthClientModel.AuthorizationCode{},
+		revoked: map[string]bool{},
+	}
+	s := &OAuthServerService{
+		logger:     zaplogger.NewServiceLogger(),
+		clientRepo: repo,
+		userRepo: fakeUsers{
+			1: {BaseModel: baseModel.BaseModel{ID: 1, Status: 1}, Username: "alice"},
+			2: {BaseModel: baseModel.BaseModel{ID: 2, Status: 0}, Username: "bob"},
+		},
+		adminRepo: fakeAdmins{
+			1: {BaseModel: baseModel.BaseModel{ID: 1, Status: 1}, Username: "root"},
+			2: {BaseModel: baseModel.BaseModel{ID: 2, Status: 0}, Username: "former"},
+		},
+		jwtConfig: jwtConfig,
+		codeTTL:   time.Minute,
+	}
+	return s, repo
+}
+
+func authorizeRequest() *structs.AuthorizeRequest {
+	return &structs.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "web",
+		RedirectURI:         testRedirect,
+		Scope:               "openid",
+		State:               "xyz",
+		CodeChallenge:       testChallenge,
+		CodeChallengeMethod: OAuthClientModel.CodeChallengeS256,
+		Approve:             true,
+	}
+}
+
+// issueCode 走完授权流程并返回回调地址中的授权码
+func issueCode(t *testing.T, s *OAuthServerService, role string, userID uint) string {
+	t.Helper()
+	location, err := s.Authorize(context.Background(), authorizeRequest(), role, userID, "someone")
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := u.Query().Get("code")
+	if code == "" {
+		t.Fatalf("Authorize() = %s, want code", location)
+	}
+	return code
+}
+
+func oauthErrorCode(err error) string {
+	var oauthErr *auth.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{name: "S256匹配", verifier: testVerifier, challenge: testChallenge, want: true},
+		{name: "verifier错误", verifier: testVerifier + "x", challenge: testChallenge, want: false},
+		{name: "plain方式不被接受", verifier: testVerifier, challenge: testVerifier, want: false},
+		{name: "带填充的编码不被接受", verifier: testVerifier, challenge: testChallenge + "=", want: false},
+		{name: "challenge为空", verifier: testVerifier, challenge: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.verifier, tt.challenge); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(req *structs.AuthorizeRequest)
+		// wantErr 不能重定向、直接返回的错误码
+		wantErr string
+		// wantRedirectErr 通过回调地址返回的错误码
+		wantRedirectErr string
+	}{
+		{name: "成功", edit: func(req *structs.AuthorizeRequest) {}},
+		{name: "回调地址多出斜杠", edit: func(req *structs.AuthorizeRequest) { req.RedirectURI += "/" }, wantErr: auth.OAuthErrInvalidRequest},
+		{name: "回调地址多出查询参数", edit: func(req *structs.AuthorizeRequest) { req.RedirectURI += "?next=/admin" }, wantErr: auth.OAuthErrInvalidRequest},
+		{name: "回调地址大小写不同", edit: func(req *structs.AuthorizeRequest) { req.RedirectURI = "https://APP.example.com/callback" }, wantErr: auth.OAuthErrInvalidRequest},
+		{name: "回调地址使用http", edit: func(req *structs.AuthorizeRequest) { req.RedirectURI = "http://app.example.com/callback" }, wantErr: auth.OAuthErrInvalidRequest},
+		{name: "未知客户端", edit: func(req *structs.AuthorizeRequest) { req.ClientID = "unknown" }, wantErr: auth.OAuthErrInvalidClient},
+		{name: "缺少code_challenge", edit: func(req *structs.AuthorizeRequest) { req.CodeChallenge = "" }, wantRedirectErr: auth.OAuthErrInvalidRequest},
+		{name: "plain方式", edit: func(req *structs.AuthorizeRequest) { req.CodeChallengeMethod = "plain" }, wantRedirectErr: auth.OAuthErrInvalidRequest},
+		{name: "超出登记的授权范围", edit: func(req *structs.AuthorizeRequest) { req.Scope = "openid admin" }, wantRedirectErr: auth.OAuthErrInvalidScope},
+		{name: "用户拒绝授权", edit: func(req *structs.AuthorizeRequest) { req.Approve = false }, wantRedirectErr: auth.OAuthErrAccessDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			req := authorizeRequest()
+			tt.edit(req)
+			location, err := s.Authorize(context.Background(), req, SessionModel.RoleUser, 1, "alice")
+			if tt.wantErr != "" {
+				if oauthErrorCode(err) != tt.wantErr || location != "" {
+					t.Fatalf("Authorize() = %q, %v, want error %s", location, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+			u, err := url.Parse(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := u.Query()
+			if query.Get("state") != "xyz" {
+				t.Errorf("state = %q, want xyz", query.Get("state"))
+			}
+			if query.Get("error") != tt.wantRedirectErr {
+				t.Errorf("error = %q, want %q", query.Get("error"), tt.wantRedirectErr)
+			}
+			wantCodes := 1
+			if tt.wantRedirectErr != "" {
+				wantCodes = 0
+			}
+			if len(repo.codes) != wantCodes {
+				t.Errorf("saved %d codes, want %d", len(repo.codes), wantCodes)
+			}
+		})
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		userID   uint
+		client   string
+		req      structs.OAuthTokenRequest
+		wantCode string
+	}{
+		{name: "普通用户兑换成功", role: SessionModel.RoleUser, userID: 1, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: testVerifier}},
+		{name: "管理员兑换成功", role: SessionModel.RoleAdmin, userID: 1, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: testVerifier}},
+		{name: "回调地址不一致", role: SessionModel.RoleUser, userID: 1, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect + "/", CodeVerifier: testVerifier}, wantCode: auth.OAuthErrInvalidGrant},
+		{name: "缺少回调地址", role: SessionModel.RoleUser, userID: 1, client: "web", req: structs.OAuthTokenRequest{CodeVerifier: testVerifier}, wantCode: auth.OAuthErrInvalidGrant},
+		{name: "code_verifier错误", role: SessionModel.RoleUser, userID: 1, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: "wrong"}, wantCode: auth.OAuthErrInvalidGrant},
+		{name: "缺少code_verifier", role: SessionModel.RoleUser, userID: 1, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect}, wantCode: auth.OAuthErrInvalidRequest},
+		{name: "其他客户端的授权码", role: SessionModel.RoleUser, userID: 1, client: "other", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: testVerifier}, wantCode: auth.OAuthErrInvalidGrant},
+		{name: "用户已禁用", role: SessionModel.RoleUser, userID: 2, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: testVerifier}, wantCode: auth.OAuthErrInvalidGrant},
+		{name: "用户已删除", role: SessionModel.RoleUser, userID: 9, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: testVerifier}, wantCode: auth.OAuthErrInvalidGrant},
+		{name: "管理员已禁用", role: SessionModel.RoleAdmin, userID: 2, client: "web", req: structs.OAuthTokenRequest{RedirectURI: testRedirect, CodeVerifier: testVerifier}, wantCode: auth.OAuthErrInvalidGrant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			repo.clients["other"] = &OAuthClientModel.OAuthClient{ClientID: "other", GrantTypes: OAuthClientModel.GrantAuthorizationCode, Status: 1}
+			tt.req.GrantType = OAuthClientModel.GrantAuthorizationCode
+			tt.req.Code = issueCode(t, s, tt.role, tt.userID)
+
+			resp, err := s.Token(context.Background(), repo.clients[tt.client], &tt.req)
+			if tt.wantCode != "" {
+				if oauthErrorCode(err) != tt.wantCode {
+					t.Fatalf("Token() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if resp.AccessToken == "" || resp.TokenType != tokenTypeBearer || resp.Scope != "openid" {
+				t.Errorf("Token() = %+v", resp)
+			}
+
+			// 授权码只能兑换一次，重放时返回invalid_grant
+			if _, err := s.Token(context.Background(), repo.clients[tt.client], &tt.req); oauthErrorCode(err) != auth.OAuthErrInvalidGrant {
+				t.Errorf("Token() replay error = %v, want invalid_grant", err)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeConsumedOnFailure(t *testing.T) {
+	s, repo := newTestService(t)
+	code := issueCode(t, s, SessionModel.RoleUser, 1)
+	req := &structs.OAuthTokenRequest{GrantType: OAuthClientModel.GrantAuthorizationCode, Code: code, RedirectURI: testRedirect, CodeVerifier: "wrong"}
+	if _, err := s.Token(context.Background(), repo.clients["web"], req); err == nil {
+		t.Fatal("Token() with wrong verifier succeeded")
+	}
+	// 猜错code_verifier后授权码即失效，不能继续尝试
+	req.CodeVerifier = testVerifier
+	if _, err := s.Token(context.Background(), repo.clients["web"], req); oauthErrorCode(err) != auth.OAuthErrInvalidGrant {
+		t.Errorf("Token() after failed attempt error = %v, want invalid_grant", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   string
+		req      structs.OAuthTokenRequest
+		wantCode string
+	}{
+		{name: "客户端凭证", client: "backend", req: structs.OAuthTokenRequest{GrantType: OAuthClientModel.GrantClientCredentials}},
+		{name: "客户端凭证超出授权范围", client: "backend", req: structs.OAuthTokenRequest{GrantType: OAuthClientModel.GrantClientCredentials, Scope: "reports admin"}, wantCode: auth.OAuthErrInvalidScope},
+		{name: "公开客户端不能使用客户端凭证", client: "web", req: structs.OAuthTokenRequest{GrantType: OAuthClientModel.GrantClientCredentials}, wantCode: auth.OAuthErrUnauthorizedClient},
+		{name: "未登记授权码模式的客户端", client: "backend", req: structs.OAuthTokenRequest{GrantType: OAuthClientModel.GrantAuthorizationCode}, wantCode: auth.OAuthErrUnauthorizedClient},
+		// 授权服务不签发刷新令牌，访问令牌过期后需重新走授权流程
+		{name: "不支持刷新令牌", client: "web", req: structs.OAuthTokenRequest{GrantType: "refresh_token"}, wantCode: auth.OAuthErrUnsupportedGrantType},
+		{name: "不支持密码模式", client: "backend", req: structs.OAuthTokenRequest{GrantType: "password"}, wantCode: auth.OAuthErrUnsupportedGrantType},
+		{name: "缺少grant_type", client: "web", req: structs.OAuthTokenRequest{}, wantCode: auth.OAuthErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			resp, err := s.Token(context.Background(), repo.clients[tt.client], &tt.req)
+			if tt.wantCode != "" {
+				if oauthErrorCode(err) != tt.wantCode {
+					t.Fatalf("Token() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if resp.AccessToken == "" || resp.ExpiresIn <= 0 {
+				t.Errorf("Token() = %+v", resp)
+			}
+		})
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	s, repo := newTestService(t)
+	backend := repo.clients["backend"]
+	issued, err := s.Token(context.Background(), backend, &structs.OAuthTokenRequest{GrantType: OAuthClientModel.GrantClientCredentials})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 登录会话令牌不带client_id，不属于授权服务
+	sessionToken, _, err := s.jwtConfig.IssueAccessToken(&useJwt.TokenSubject{UserID: "1", Username: "alice", Role: SessionModel.RoleUser}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherConfig := useJwt.NewJWTConfig(&useJwt.JWTConfig{SecretKey: "other-secret"})
+	t.Cleanup(otherConfig.Close)
+	other, _, err := otherConfig.IssueAccessToken(&useJwt.TokenSubject{ClientID: "backend", Role: OAuthClientModel.RoleClient}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		revoke func()
+		want   bool
+	}{
+		{name: "有效令牌", token: issued.AccessToken, want: true},
+		{name: "其他客户端撤销时忽略", token: issued.AccessToken, revoke: func() {
+			if err := s.Revoke(context.Background(), repo.clients["web"], issued.AccessToken); err != nil {
+				t.Fatal(err)
+			}
+		}, want: true},
+		{name: "撤销后失效", token: issued.AccessToken, revoke: func() {
+			if err := s.Revoke(context.Background(), backend, issued.AccessToken); err != nil {
+				t.Fatal(err)
+			}
+		}, want: false},
+		{name: "登录会话令牌", token: sessionToken, want: false},
+		{name: "其他密钥签发的令牌", token: other, want: false},
+		{name: "无效令牌", token: "not-a-jwt", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.revoke != nil {
+				tt.revoke()
+			}
+			resp, err := s.Introspect(context.Background(), backend, tt.token)
+			if err != nil {
+				t.Fatalf("Introspect() error = %v", err)
+			}
+			if resp.Active != tt.want {
+				t.Errorf("Introspect() active = %v, want %v", resp.Active, tt.want)
+			}
+			if tt.want && (resp.ClientID != "backend" || resp.Sub != "backend" || resp.Scope != "reports") {
+				t.Errorf("Introspect() = %+v", resp)
+			}
+			if !tt.want && resp.ClientID != "" {
+				t.Errorf("inactive response leaks claims: %+v", resp)
+			}
+		})
+	}
+
+	if _, err := s.Introspect(context.Background(), repo.clients["web"], issued.AccessToken); oauthErrorCode(err) != auth.OAuthErrUnauthorizedClient {
+		t.Errorf("Introspect() by public client error = %v, want unauthorized_client", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package operation_log_service 实现操作审计日志的写入
+package operation_log_service
+
+import (
+	"context"
+	operation_log_repo "gin-center/infrastructure/repository/operation_log"
+	"gin-center/infrastructure/zaplogger"
+	use_operationLogInterface "gin-center/internal/domain/interface/operation_log"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxPathLength 与operation_logs.path列长度一致
+const maxPathLength = 100
+
+// OperationLogService 操作日志服务
+type OperationLogService struct {
+	logger    *zaplogger.ServiceLogger
+	opLogRepo *operation_log_repo.OperationLogRepository
+}
+
+// NewOperationLogService 创建新的操作日志服务实例
+func NewOperationLogService(opLogRepo *operation_log_repo.OperationLogRepository, logger *zaplogger.ServiceLogger) use_operationLogInterface.OperationLogServiceInterface {
+	return &OperationLogService{
+		logger:    logger,
+		opLogRepo: opLogRepo,
+	}
+}
+
+// Record 实现OperationLogServiceInterface接口
+func (s *OperationLogService) Record(ctx context.Context, entry *OperationLogModel.OperationLog) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if len(entry.Path) > maxPathLength {
+		entry.Path = entry.Path[:maxPathLength]
+	}
+	// 请求结束后上下文可能已取消，审计记录不随之丢弃
+	if err := s.opLogRepo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		s.logger.LogError("记录操作日志失败",
+			zap.String("operation", entry.Operation),
+			zap.String("user_id", entry.UserID),
+			zap.String("path", entry.Path),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,144 @@
+// Package permission_service 实现基于permissions/user_permissions的权限校验
+package permission_service
+
+import (
+	"context"
+	permission_repo "gin-center/infrastructure/repository/permission"
+	"gin-center/infrastructure/zaplogger"
+	use_permissionInterface "gin-center/internal/domain/interface/permission"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	PermissionModel "gin-center/internal/domain/model/permission"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/constants"
+	type_response "gin-center/internal/types/response"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// PermissionService 权限服务
+type PermissionService struct {
+	logger         *zaplogger.ServiceLogger
+	permissionRepo *permission_repo.PermissionRepository
+}
+
+// NewPermissionService 创建新的权限服务实例
+func NewPermissionService(permissionRepo *permission_repo.PermissionRepository, logger *zaplogger.ServiceLogger) use_permissionInterface.PermissionServiceInterface {
+	return &PermissionService{
+		logger:         logger,
+		permissionRepo: permissionRepo,
+	}
+}
+
+// ListPermissions 实现PermissionServiceInterface接口
+func (s *PermissionService) ListPermissions(ctx context.Context) ([]type_response.PermissionResponse, error) {
+	permissions, err := s.permissionRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]type_response.PermissionResponse, len(permissions))
+	for i, p := range permissions {
+		result[i] = type_response.PermissionResponse{
+			Code:   p.Code,
+			Name:   p.Name,
+			Path:   p.Path,
+			Remark: p.Remark,
+		}
+	}
+	return result, nil
+}
+
+// ListCodes 实现PermissionServiceInterface接口
+// 管理员默认拥有除ExplicitOnly以外的全部启用权限，显式授予的权限另行合并
+func (s *PermissionService) ListCodes(ctx context.Context, role string, userID uint) ([]string, error) {
+	userType, id := subjectOf(role, userID)
+	granted, err := s.permissionRepo.ListCodesByUser(ctx, userType, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != SessionModel.RoleAdmin {
+		return granted, nil
+	}
+
+	permissions, err := s.permissionRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	explicit := make(map[string]struct{}, len(granted))
+	for _, code := range granted {
+		explicit[code] = struct{}{}
+	}
+	codes := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if _, ok := explicit[p.Code]; ok || !PermissionModel.ExplicitOnly(p.Code) {
+			codes = append(codes, p.Code)
+		}
+	}
+	return codes, nil
+}
+
+// HasPermission 实现PermissionServiceInterface接口
+func (s *PermissionService) HasPermission(ctx context.Context, role string, userID uint, code string) (bool, error) {
+	codes, err := s.ListCodes(ctx, role, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range codes {
+		if c == code {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetUserPermissions 实现PermissionServiceInterface接口
+// 管理员默认拥有的权限无需授予，实际仅ExplicitOnly的权限对管理员生效
+func (s *PermissionService) SetUserPermissions(ctx context.Context, role string, userID uint, codes []string, operatorID uint) error {
+	codes = unique(codes)
+	permissions, err := s.permissionRepo.FindActiveByCodes(ctx, codes)
+	if err != nil {
+		return err
+	}
+	if len(permissions) != len(codes) {
+		return constants.ErrInvalidPermission
+	}
+
+	ids := make([]string, len(permissions))
+	for i, p := range permissions {
+		ids[i] = p.ID
+	}
+	userType, id := subjectOf(role, userID)
+	err = s.permissionRepo.ReplaceUserPermissions(ctx, userType, id, ids, strconv.FormatUint(uint64(operatorID), 10))
+	if err != nil {
+		s.logger.LogError("设置用户权限失败", zap.Uint("user_id", userID), zap.String("role", role), zap.Error(err))
+		return err
+	}
+	s.logger.LogInfo("已设置用户权限",
+		zap.Uint("user_id", userID),
+		zap.String("role", role),
+		zap.Uint("operator", operatorID),
+		zap.Strings("codes", codes))
+	return nil
+}
+
+// subjectOf 将会话角色与用户ID转换为user_permissions中的user_type与user_id
+func subjectOf(role string, userID uint) (int, string) {
+	userType := LoginHistoryModel.UserTypeNormal
+	if role == SessionModel.RoleAdmin {
+		userType = LoginHistoryModel.UserTypeAdmin
+	}
+	return userType, strconv.FormatUint(uint64(userID), 10)
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+// Package service_account_service 将通过校验的客户端证书映射为服务账号，供内部服务以mTLS调用接口
+package service_account_service
+
+import (
+	"context"
+	"crypto/x509"
+	"gin-center/configs/config"
+	"gin-center/infrastructure/repository/admin"
+	user_repo "gin-center/infrastructure/repository/user"
+	"gin-center/infrastructure/zaplogger"
+	use_serviceAccountInterface "gin-center/internal/domain/interface/service_account"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+
+	"go.uber.org/zap"
+)
+
+// ServiceAccountService 服务账号服务
+type ServiceAccountService struct {
+	logger    *zaplogger.ServiceLogger
+	accounts  map[string]config.ServiceAccountConfig
+	userRepo  *user_repo.UserRepository
+	adminRepo *admin.AdminRepository
+}
+
+// NewServiceAccountService 创建新的服务账号服务实例，accounts按身份索引，重复的身份以后配置的为准
+func NewServiceAccountService(accounts []config.ServiceAccountConfig, userRepo *user_repo.UserRepository, adminRepo *admin.AdminRepository, logger *zaplogger.ServiceLogger) use_serviceAccountInterface.ServiceAccountServiceInterface {
+	byIdentity := make(map[string]config.ServiceAccountConfig, len(accounts))
+	for _, account := range accounts {
+		byIdentity[account.Identity] = account
+	}
+	return &ServiceAccountService{
+		logger:    logger,
+		accounts:  byIdentity,
+		userRepo:  userRepo,
+		adminRepo: adminRepo,
+	}
+}
+
+// Authenticate 实现ServiceAccountServiceInterface接口
+func (s *ServiceAccountService) Authenticate(ctx context.Context, cert *x509.Certificate) (*auth.ServiceAccountPrincipal, error) {
+	identity, account, ok := s.match(cert)
+	if !ok {
+		return nil, constants.ErrServiceAccountNotFound
+	}
+
+	principal := &auth.ServiceAccountPrincipal{
+		Identity: identity,
+		Username: account.Username,
+		Scopes:   account.Scopes,
+	}
+	if account.Role == SessionModel.RoleAdmin {
+		owner, err := s.adminRepo.FindByUsername(ctx, account.Username)
+		if err != nil || owner.Status == 0 {
+			s.logger.Ctx(ctx).LogWarn("服务账号不可用", zap.String("identity", identity), zap.String("username", account.Username), zap.Error(err))
+			return nil, constants.ErrServiceAccountNotFound
+		}
+		principal.UserID, principal.Role = owner.ID, SessionModel.RoleAdmin
+	} else {
+		owner, err := s.userRepo.FindByUsername(ctx, account.Username)
+		if err != nil || owner.Status == 0 {
+			s.logger.Ctx(ctx).LogWarn("服务账号不可用", zap.String("identity", identity), zap.String("username", account.Username), zap.Error(err))
+			return nil, constants.ErrServiceAccountNotFound
+		}
+		principal.UserID, principal.Role = owner.ID, SessionModel.RoleUser
+	}
+	return principal, nil
+}
+
+// match 依次用URI、DNS、邮箱SAN和Subject CN查找映射
+func (s *ServiceAccountService) match(cert *x509.Certificate) (string, config.ServiceAccountConfig, bool) {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	for _, identity := range identities {
+		if account, ok := s.accounts[identity]; ok {
+			return identity, account, true
+		}
+	}
+	return "", config.ServiceAccountConfig{}, false
+}
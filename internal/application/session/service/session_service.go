@@ -0,0 +1,348 @@
+// Package session_service 实现登录会话管理
+package session_service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	session_repo "gin-center/infrastructure/repository/session"
+	"gin-center/infrastructure/zaplogger"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	type_response "gin-center/internal/types/response"
+	use_headers "gin-center/pkg/http/headers"
+	security_types "gin-center/pkg/security/types"
+	useJwt "gin-center/pkg/security/useJwt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// touchInterval 最近活跃时间的最小刷新间隔，避免每次请求都写入Redis
+const touchInterval = time.Minute
+
+// SessionService 会话服务，每次登录创建一个会话，会话与刷新令牌一一对应
+type SessionService struct {
+	logger      *zaplogger.ServiceLogger
+	sessionRepo *session_repo.SessionRepository
+	jwtConfig   *useJwt.JWTConfig
+}
+
+// NewSessionService 创建新的会话服务实例
+func NewSessionService(sessionRepo *session_repo.SessionRepository, jwtConfig *useJwt.JWTConfig, logger *zaplogger.ServiceLogger) use_sessionInterface.SessionServiceInterface {
+	return &SessionService{
+		logger:      logger,
+		sessionRepo: sessionRepo,
+		jwtConfig:   jwtConfig,
+	}
+}
+
+// CreateSession 实现SessionServiceInterface接口
+func (s *SessionService) CreateSession(ctx context.Context, role string, userID uint, username string, meta *auth.LoginMeta) (*security_types.TokenPair, error) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+	now := time.Now()
+	session := &SessionModel.Session{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		Device:      meta.Device,
+		UserAgent:   meta.UserAgent,
+		IP:          meta.IP,
+		Fingerprint: hashFingerprint(meta.Fingerprint),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+	}
+	if session.Device == "" {
+		session.Device = use_headers.DetectDevice(meta.UserAgent)
+	}
+
+	tokens, err := s.issueTokens(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		s.logger.LogError("保存会话失败", zap.Uint("user_id", userID), zap.String("role", role), zap.Error(err))
+		return nil, err
+	}
+	s.logger.LogInfo("会话已创建",
+		zap.String("session_id", session.ID),
+		zap.Uint("user_id", userID),
+		zap.String("role", role),
+		zap.String("device", session.Device),
+		zap.String("ip", session.IP))
+	return tokens, nil
+}
+
+// Impersonate 实现SessionServiceInterface接口
+// 模拟会话只签发携带act声明的访问令牌，到期后需重新发起模拟
+func (s *SessionService) Impersonate(ctx context.Context, userID uint, username string, actor *auth.Actor, meta *auth.LoginMeta) (*SessionModel.Session, string, error) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+	now := time.Now()
+	session := &SessionModel.Session{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		Username:      username,
+		Role:          SessionModel.RoleUser,
+		Device:        use_headers.DetectDevice(meta.UserAgent),
+		UserAgent:     meta.UserAgent,
+		IP:            meta.IP,
+		CreatedAt:     now,
+		LastSeenAt:    now,
+		ExpiresAt:     now.Add(s.jwtConfig.ImpersonationTokenLifetime),
+		ActorID:       actor.ID,
+		ActorUsername: actor.Username,
+	}
+	token, _, err := s.jwtConfig.IssueAccessToken(&useJwt.TokenSubject{
+		UserID:    strconv.FormatUint(uint64(userID), 10),
+		Username:  username,
+		Role:      session.Role,
+		SessionID: session.ID,
+		Actor: &security_types.Actor{
+			Sub:      strconv.FormatUint(uint64(actor.ID), 10),
+			Username: actor.Username,
+		},
+	}, s.jwtConfig.ImpersonationTokenLifetime)
+	if err != nil {
+		s.logger.LogError("签发模拟令牌失败", zap.Uint("user_id", userID), zap.Uint("actor_id", actor.ID), zap.Error(err))
+		return nil, "", fmt.Errorf("签发令牌失败: %w", err)
+	}
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		s.logger.LogError("保存模拟会话失败", zap.Uint("user_id", userID), zap.Uint("actor_id", actor.ID), zap.Error(err))
+		return nil, "", err
+	}
+	s.logger.LogInfo("模拟会话已创建",
+		zap.String("session_id", session.ID),
+		zap.Uint("user_id", userID),
+		zap.Uint("actor_id", actor.ID),
+		zap.String("actor", actor.Username))
+	return session, token, nil
+}
+
+// Refresh 实现SessionServiceInterface接口
+// 刷新令牌仅能使用一次，旧令牌被重复使用时视为泄露并注销整个会话
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string, meta *auth.LoginMeta) (*security_types.TokenPair, error) {
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
+	claims, err := s.jwtConfig.ParseClaims(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != security_types.TokenTypeRefresh || claims.SessionID == "" {
+		return nil, useJwt.ErrInvalidToken
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Impersonated() || session.RefreshTokenID != claims.ID {
+		s.logger.LogWarn("检测到刷新令牌重复使用，注销会话",
+			zap.String("session_id", session.ID),
+			zap.Uint("user_id", session.UserID),
+			zap.String("ip", meta.IP))
+		if err := s.sessionRepo.Delete(ctx, session); err != nil {
+			s.logger.LogError("注销会话失败", zap.String("session_id", session.ID), zap.Error(err))
+		}
+		return nil, useJwt.ErrRevokedToken
+	}
+	if session.Fingerprint != "" && session.Fingerprint != hashFingerprint(meta.Fingerprint) {
+		s.logger.LogWarn("刷新令牌指纹不匹配", zap.String("session_id", session.ID), zap.String("ip", meta.IP))
+		return nil, useJwt.ErrInvalidToken
+	}
+
+	session.LastSeenAt = time.Now()
+	if meta.IP != "" {
+		session.IP = meta.IP
+	}
+	tokens, err := s.issueTokens(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		s.logger.LogError("更新会话失败", zap.String("session_id", session.ID), zap.Error(err))
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Authenticate 实现SessionServiceInterface接口
+// 令牌类型不符或会话不存在时统一返回ErrSessionNotFound
+func (s *SessionService) Authenticate(ctx context.Context, claims *security_types.JWTUserClaims, ip string) (*SessionModel.Session, error) {
+	if claims.TokenType != security_types.TokenTypeAccess || claims.SessionID == "" {
+		return nil, constants.ErrSessionNotFound
+	}
+	session, err := s.sessionRepo.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if strconv.FormatUint(uint64(session.UserID), 10) != claims.UserID || session.Role != claims.Role {
+		return nil, constants.ErrSessionNotFound
+	}
+	if actorOf(claims) != session.ActorID {
+		return nil, constants.ErrSessionNotFound
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= touchInterval {
+		session.LastSeenAt = now
+		if ip != "" {
+			session.IP = ip
+		}
+		if err := s.sessionRepo.Save(ctx, session); err != nil {
+			s.logger.LogWarn("更新会话活跃时间失败", zap.String("session_id", session.ID), zap.Error(err))
+		}
+	}
+	return session, nil
+}
+
+// ListSessions 实现SessionServiceInterface接口，按最近活跃时间倒序返回
+func (s *SessionService) ListSessions(ctx context.Context, role string, userID uint, currentSessionID string) ([]type_response.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, role, userID)
+	if err != nil {
+		s.logger.LogError("查询会话列表失败", zap.Uint("user_id", userID), zap.String("role", role), zap.Error(err))
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	result := make([]type_response.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		result[i] = type_response.SessionResponse{
+			ID:             session.ID,
+			Device:         session.Device,
+			UserAgent:      session.UserAgent,
+			IP:             session.IP,
+			CreatedAt:      session.CreatedAt,
+			LastSeenAt:     session.LastSeenAt,
+			ExpiresAt:      session.ExpiresAt,
+			Current:        session.ID == currentSessionID,
+			ImpersonatedBy: session.ActorUsername,
+		}
+	}
+	return result, nil
+}
+
+// RevokeSession 实现SessionServiceInterface接口，只能注销属于该用户的会话
+func (s *SessionService) RevokeSession(ctx context.Context, role string, userID uint, sessionID string) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.Role != role || session.UserID != userID {
+		return constants.ErrSessionNotFound
+	}
+	if err := s.sessionRepo.Delete(ctx, session); err != nil {
+		s.logger.LogError("注销会话失败", zap.String("session_id", sessionID), zap.Error(err))
+		return err
+	}
+	s.logger.LogInfo("会话已注销", zap.String("session_id", sessionID), zap.Uint("user_id", userID), zap.String("role", role))
+	return nil
+}
+
+// RevokeAllSessions 实现SessionServiceInterface接口
+func (s *SessionService) RevokeAllSessions(ctx context.Context, role string, userID uint) (int, error) {
+	count, err := s.sessionRepo.DeleteByUser(ctx, role, userID)
+	if err != nil {
+		s.logger.LogError("注销全部会话失败", zap.Uint("user_id", userID), zap.String("role", role), zap.Error(err))
+		return 0, err
+	}
+	s.logger.LogInfo("已注销全部会话", zap.Uint("user_id", userID), zap.String("role", role), zap.Int("count", count))
+	return count, nil
+}
+
+// ListOnlineUsers 实现SessionServiceInterface接口
+// 会话活跃时间按touchInterval刷新，window应大于该间隔
+func (s *SessionService) ListOnlineUsers(ctx context.Context, role string, window time.Duration) ([]type_response.OnlineUserResponse, error) {
+	now := time.Now()
+	if err := s.sessionRepo.PruneActivity(ctx, role, now.Add(-s.jwtConfig.RefreshTokenLifetime)); err != nil {
+		s.logger.LogWarn("清理会话活动记录失败", zap.String("role", role), zap.Error(err))
+	}
+	sessions, err := s.sessionRepo.ListActive(ctx, role, now.Add(-window))
+	if err != nil {
+		s.logger.LogError("查询在线用户失败", zap.String("role", role), zap.Error(err))
+		return nil, err
+	}
+
+	// 会话已按活跃时间倒序排列，每个用户取最近一次活动的会话信息
+	result := make([]type_response.OnlineUserResponse, 0)
+	index := make(map[uint]int)
+	for _, session := range sessions {
+		if i, ok := index[session.UserID]; ok {
+			result[i].SessionCount++
+			continue
+		}
+		index[session.UserID] = len(result)
+		result = append(result, type_response.OnlineUserResponse{
+			UserID:       session.UserID,
+			Username:     session.Username,
+			Role:         session.Role,
+			SessionCount: 1,
+			LastSeenAt:   session.LastSeenAt,
+			IP:           session.IP,
+			Device:       session.Device,
+		})
+	}
+	return result, nil
+}
+
+// issueTokens 为会话签发新的令牌对，并记录刷新令牌ID与会话过期时间
+func (s *SessionService) issueTokens(session *SessionModel.Session) (*security_types.TokenPair, error) {
+	tokens, err := s.jwtConfig.IssueTokenPair(&useJwt.TokenSubject{
+		UserID:      strconv.FormatUint(uint64(session.UserID), 10),
+		Username:    session.Username,
+		Role:        session.Role,
+		SessionID:   session.ID,
+		Fingerprint: session.Fingerprint,
+	})
+	if err != nil {
+		s.logger.LogError("签发令牌失败", zap.String("session_id", session.ID), zap.Error(err))
+		return nil, fmt.Errorf("签发令牌失败: %w", err)
+	}
+	session.RefreshTokenID = tokens.RefreshTokenID
+	session.ExpiresAt = time.Now().Add(s.jwtConfig.RefreshTokenLifetime)
+	return tokens, nil
+}
+
+// IsSessionError 判断错误是否表示会话或令牌失效，用于区分401与500
+func IsSessionError(err error) bool {
+	return errors.Is(err, constants.ErrSessionNotFound) ||
+		errors.Is(err, useJwt.ErrInvalidToken) ||
+		errors.Is(err, useJwt.ErrExpiredToken) ||
+		errors.Is(err, useJwt.ErrRevokedToken) ||
+		errors.Is(err, useJwt.ErrEmptyToken)
+}
+
+// actorOf 解析令牌act声明中的管理员ID，未携带时返回0
+func actorOf(claims *security_types.JWTUserClaims) uint {
+	if claims.Actor == nil {
+		return 0
+	}
+	id, err := strconv.ParseUint(claims.Actor.Sub, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// hashFingerprint 对客户端指纹做哈希，避免在令牌和存储中保留原始值
+func hashFingerprint(fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
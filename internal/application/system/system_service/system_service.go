@@ -5,12 +5,12 @@ import (
 	"fmt"
 	use_Baseservice "gin-center/internal/application"
 	"gin-center/internal/types/system"
+	"gin-center/pkg/health"
 	"runtime"
 	"time"
 
 	"gin-center/infrastructure/zaplogger"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/spf13/viper"
@@ -20,17 +20,17 @@ import (
 type SystemService struct {
 	*use_Baseservice.BaseService
 	config *viper.Viper
-	redis  *redis.Client
+	health *health.Registry
 }
 
-func NewSystemService(redis *redis.Client, logger *zaplogger.ServiceLogger) *SystemService {
+func NewSystemService(health *health.Registry, logger *zaplogger.ServiceLogger) *SystemService {
 	baseService := use_Baseservice.NewBaseService(&use_Baseservice.BaseServiceConfig{
 		Logger: logger,
 	})
 	return &SystemService{
 		BaseService: baseService,
 		config:      viper.GetViper(),
-		redis:       redis,
+		health:      health,
 	}
 }
 func (s *SystemService) getConfigValue(key string, defaultValue interface{}) interface{} {
@@ -93,16 +93,14 @@ func (s *SystemService) validateConfig(config system.SystemConfig) error {
 	}
 	return nil
 }
-func (s *SystemService) GetSystemHealth() map[string]interface{} {
-	metrics, err := s.GetSystemMetrics()
+
+// GetSystemHealth 汇总就绪检查结果与资源使用情况，依赖检查结果与/readyz共用缓存
+func (s *SystemService) GetSystemHealth(ctx context.Context) map[string]interface{} {
+	report := s.health.Ready(ctx)
 	status := "up"
-	details := map[string]string{
-		"database":   "up",
-		"redis":      s.checkRedisHealth(),
-		"jwt_auth":   "up",
-		"log_system": "up",
-	}
+	details := map[string]string{}
 
+	metrics, err := s.GetSystemMetrics(ctx)
 	if err != nil {
 		status = "degraded"
 		details["metrics"] = "unavailable"
@@ -124,13 +122,25 @@ func (s *SystemService) GetSystemHealth() map[string]interface{} {
 		}
 	}
 
+	// 依赖不可用比资源紧张更严重，覆盖上面的warning
+	for name, result := range report.Checks {
+		details[name] = result.Status
+		if result.Status != health.StatusUp {
+			status = "degraded"
+			s.Logger.Ctx(ctx).LogWarn("健康检查失败", zap.String("check", name), zap.String("error", result.Error))
+		}
+	}
+	if report.Status == health.StatusShuttingDown {
+		status = health.StatusShuttingDown
+	}
+
 	result := map[string]interface{}{
 		"status":    status,
 		"timestamp": time.Now().Format(time.RFC3339),
 		"details":   details,
 	}
 
-	info, err := s.GetSystemInfo()
+	info, err := s.GetSystemInfo(ctx)
 	if err == nil && info != nil {
 		result["system_info"] = info
 	}
@@ -138,8 +148,8 @@ func (s *SystemService) GetSystemHealth() map[string]interface{} {
 	return result
 }
 
-func (s *SystemService) GetSystemInfo() (*system.SystemConfig, error) {
-	metrics, err := s.GetSystemMetrics()
+func (s *SystemService) GetSystemInfo(ctx context.Context) (*system.SystemConfig, error) {
+	metrics, err := s.GetSystemMetrics(ctx)
 	if err != nil {
 		s.Logger.LogError("获取系统指标失败", zap.Skip(), zap.Error(err))
 		return nil, err
@@ -163,8 +173,8 @@ func (s *SystemService) GetSystemInfo() (*system.SystemConfig, error) {
 	return info, nil
 }
 
-func (s *SystemService) GetSystemMetrics() (map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (s *SystemService) GetSystemMetrics(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	vmStat, err := mem.VirtualMemoryWithContext(ctx)
@@ -173,15 +183,8 @@ func (s *SystemService) GetSystemMetrics() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("获取内存信息失败: %w", err)
 	}
 	s.BaseService.Logger.LogInfo("系统服务初始化完成", zap.String("service", "system"))
-	var cpuPercent []float64
-	for i := 0; i < 3; i++ {
-		cpuPercent, err = cpu.PercentWithContext(ctx, time.Second, false)
-		if err == nil {
-			break
-		}
-		s.Logger.LogWarn("获取CPU信息失败,正在重试", zap.Skip(), zap.Error(err))
-		time.Sleep(time.Second)
-	}
+	// 间隔为0时返回距上次调用的CPU使用率，不阻塞请求；时序指标见Prometheus端点
+	cpuPercent, err := cpu.PercentWithContext(ctx, 0, false)
 	if err != nil {
 		s.Logger.LogError("获取CPU信息失败", zap.Skip(), zap.Error(err))
 		return nil, fmt.Errorf("获取CPU信息失败: %w", err)
@@ -209,13 +212,18 @@ func (s *SystemService) GetSystemMetrics() (map[string]interface{}, error) {
 		},
 	}, nil
 }
-func (s *SystemService) checkRedisHealth() string {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-	result := s.redis.Ping(ctx)
-	if result.Err() != nil {
-		s.Logger.LogWarn("Redis连接异常", zap.Error(result.Err()))
-		return "down"
+
+// GetLogLevel 返回当前的全局日志级别
+func (s *SystemService) GetLogLevel() *system.LogLevel {
+	return &system.LogLevel{Level: zaplogger.Level()}
+}
+
+// SetLogLevel 在运行时调整全局日志级别，重启后恢复为配置文件中的级别
+func (s *SystemService) SetLogLevel(level string) error {
+	previous := zaplogger.Level()
+	if err := zaplogger.SetLevel(level); err != nil {
+		return err
 	}
-	return "up"
+	s.Logger.LogWarn("日志级别已调整", zap.String("from", previous), zap.String("to", level))
+	return nil
 }
@@ -0,0 +1,476 @@
+// Package upload_service 实现分片上传：任务状态保存在Redis中，分片暂存于对象存储，全部上传后合并为用户文件
+package upload_service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gin-center/configs/config"
+	upload_repo "gin-center/infrastructure/repository/upload"
+	"gin-center/infrastructure/zaplogger"
+	use_uploadInterface "gin-center/internal/domain/interface/upload"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
+	UploadModel "gin-center/internal/domain/model/upload"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	"gin-center/pkg/storage/useStorage"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// cleanupBatchSize 每批清理的过期任务数量
+	cleanupBatchSize = 100
+	// downloadURLTTL 文件下载地址的有效期
+	downloadURLTTL = 15 * time.Minute
+)
+
+// uploadRepository 服务使用的上传任务与文件存储，由*upload_repo.UploadRepository实现
+type uploadRepository interface {
+	LockUser(ctx context.Context, userType int, userID uint) (func(), error)
+	SaveUpload(ctx context.Context, upload *UploadModel.Upload) error
+	FindUpload(ctx context.Context, id string) (*UploadModel.Upload, error)
+	ListUploads(ctx context.Context, userType int, userID uint) ([]*UploadModel.Upload, error)
+	MarkChunk(ctx context.Context, upload *UploadModel.Upload, index int) error
+	ReceivedChunks(ctx context.Context, id string) ([]int, error)
+	ClaimUpload(ctx context.Context, id string) (bool, error)
+	DeleteUpload(ctx context.Context, upload *UploadModel.Upload) error
+	ExpiredUploads(ctx context.Context, before time.Time, limit int64) ([]string, error)
+	CreateFile(ctx context.Context, file *UploadModel.File) error
+	FindFile(ctx context.Context, userType int, userID, id uint) (*UploadModel.File, error)
+	ListFiles(ctx context.Context, userType int, userID uint, page, pageSize int) ([]UploadModel.File, int64, error)
+	UsedBytes(ctx context.Context, userType int, userID uint) (int64, error)
+	DeleteFile(ctx context.Context, userType int, userID, id uint) error
+}
+
+// UploadService 分片上传服务
+type UploadService struct {
+	repo    uploadRepository
+	storage useStorage.Storage
+	cfg     *config.UploadConfig
+	logger  *zaplogger.ServiceLogger
+}
+
+// NewUploadService 创建新的分片上传服务实例
+func NewUploadService(repo *upload_repo.UploadRepository, storage useStorage.Storage, cfg *config.UploadConfig, logger *zaplogger.ServiceLogger) use_uploadInterface.UploadServiceInterface {
+	return &UploadService{
+		repo:    repo,
+		storage: storage,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// Create 实现UploadServiceInterface接口，配额按已保存文件与进行中任务声明的大小之和计算
+// 配额检查与保存任务在用户锁内完成，同一用户的并发请求不会同时通过检查
+func (s *UploadService) Create(ctx context.Context, role string, userID uint, req *structs.CreateUploadRequest) (*type_response.UploadResponse, error) {
+	if req.Size > s.cfg.MaxFileSize {
+		return nil, fmt.Errorf("%w: 文件大小超过%d字节", constants.ErrQuotaExceeded, s.cfg.MaxFileSize)
+	}
+	userType := userTypeOf(role)
+	unlock, err := s.repo.LockUser(ctx, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	pending, err := s.repo.ListUploads(ctx, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) >= s.cfg.MaxPending {
+		return nil, fmt.Errorf("%w: 进行中的上传任务不能超过%d个", constants.ErrQuotaExceeded, s.cfg.MaxPending)
+	}
+	used, err := s.repo.UsedBytes(ctx, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, upload := range pending {
+		used += upload.Size
+	}
+	if used+req.Size > s.cfg.UserQuota {
+		return nil, fmt.Errorf("%w: 剩余%d字节", constants.ErrQuotaExceeded, max(s.cfg.UserQuota-used, 0))
+	}
+
+	now := time.Now()
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	upload := &UploadModel.Upload{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		UserType:    userType,
+		Filename:    path.Base(strings.ReplaceAll(req.Filename, "\\", "/")),
+		ContentType: contentType,
+		Size:        req.Size,
+		ChunkSize:   s.cfg.ChunkSize,
+		TotalChunks: int((req.Size + s.cfg.ChunkSize - 1) / s.cfg.ChunkSize),
+		SHA256:      strings.ToLower(req.SHA256),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.cfg.TTL),
+	}
+	if err := s.repo.SaveUpload(ctx, upload); err != nil {
+		s.logger.Ctx(ctx).LogError("创建上传任务失败", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	s.logger.Ctx(ctx).LogInfo("已创建上传任务", zap.String("upload_id", upload.ID), zap.Uint("user_id", userID), zap.Int64("size", upload.Size))
+	return toUploadResponse(upload, []int{}), nil
+}
+
+// Get 实现UploadServiceInterface接口
+func (s *UploadService) Get(ctx context.Context, role string, userID uint, id string) (*type_response.UploadResponse, error) {
+	upload, err := s.findOwn(ctx, role, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := s.repo.ReceivedChunks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toUploadResponse(upload, chunks), nil
+}
+
+// UploadChunk 实现UploadServiceInterface接口，分片长度须与任务约定一致，收到分片后顺延任务有效期
+func (s *UploadService) UploadChunk(ctx context.Context, role string, userID uint, id string, index int, checksum string, chunk io.Reader) (*type_response.UploadResponse, error) {
+	upload, err := s.findOwn(ctx, role, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= upload.TotalChunks {
+		return nil, fmt.Errorf("%w: 分片序号超出范围", constants.ErrInvalidChunk)
+	}
+
+	expected := upload.ChunkLength(index)
+	data, err := io.ReadAll(io.LimitReader(chunk, expected+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", constants.ErrInvalidChunk, err)
+	}
+	if int64(len(data)) != expected {
+		return nil, fmt.Errorf("%w: 分片长度应为%d字节", constants.ErrInvalidChunk, expected)
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), checksum) {
+		return nil, constants.ErrChecksumMismatch
+	}
+
+	if err := s.storage.Put(ctx, upload.ChunkKey(index), bytes.NewReader(data), expected, "application/octet-stream"); err != nil {
+		s.logger.Ctx(ctx).LogError("保存分片失败", zap.String("upload_id", id), zap.Int("index", index), zap.Error(err))
+		return nil, err
+	}
+	upload.ExpiresAt = time.Now().Add(s.cfg.TTL)
+	if err := s.repo.SaveUpload(ctx, upload); err != nil {
+		return nil, err
+	}
+	if err := s.repo.MarkChunk(ctx, upload, index); err != nil {
+		return nil, err
+	}
+	chunks, err := s.repo.ReceivedChunks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toUploadResponse(upload, chunks), nil
+}
+
+// Complete 实现UploadServiceInterface接口
+// 按顺序读取分片写入最终对象并同时计算摘要，摘要不一致时删除文件并取消任务；
+// 存储或数据库写入失败时保留任务，客户端可重试
+func (s *UploadService) Complete(ctx context.Context, role string, userID uint, id string) (*type_response.FileResponse, error) {
+	upload, err := s.findOwn(ctx, role, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := s.repo.ReceivedChunks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) != upload.TotalChunks {
+		return nil, fmt.Errorf("%w: 已接收%d/%d", constants.ErrUploadIncomplete, len(chunks), upload.TotalChunks)
+	}
+	claimed, err := s.repo.ClaimUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, constants.ErrUploadNotFound
+	}
+
+	key := upload.FileKey()
+	hasher := sha256.New()
+	reader := &chunkReader{ctx: ctx, storage: s.storage, upload: upload}
+	err = s.storage.Put(ctx, key, io.TeeReader(reader, hasher), upload.Size, upload.ContentType)
+	reader.Close()
+	if err != nil {
+		s.logger.Ctx(ctx).LogError("合并分片失败", zap.String("upload_id", id), zap.Error(err))
+		s.release(ctx, upload)
+		return nil, err
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != upload.SHA256 {
+		s.logger.Ctx(ctx).LogWarn("文件摘要不一致", zap.String("upload_id", id), zap.Uint("user_id", userID))
+		s.deleteObject(ctx, key)
+		s.discard(ctx, upload)
+		return nil, constants.ErrChecksumMismatch
+	}
+
+	file := &UploadModel.File{
+		UserID:      upload.UserID,
+		UserType:    upload.UserType,
+		Filename:    upload.Filename,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		SHA256:      upload.SHA256,
+		StorageKey:  key,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.CreateFile(ctx, file); err != nil {
+		s.logger.Ctx(ctx).LogError("保存文件记录失败", zap.String("upload_id", id), zap.Error(err))
+		s.deleteObject(ctx, key)
+		s.release(ctx, upload)
+		return nil, err
+	}
+	s.discard(ctx, upload)
+	s.logger.Ctx(ctx).LogInfo("上传完成", zap.String("upload_id", id), zap.Uint("file_id", file.ID), zap.Uint("user_id", userID))
+	return s.toFileResponse(ctx, file), nil
+}
+
+// Abort 实现UploadServiceInterface接口
+func (s *UploadService) Abort(ctx context.Context, role string, userID uint, id string) error {
+	upload, err := s.findOwn(ctx, role, userID, id)
+	if err != nil {
+		return err
+	}
+	claimed, err := s.repo.ClaimUpload(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return constants.ErrUploadNotFound
+	}
+	s.discard(ctx, upload)
+	return nil
+}
+
+// ListFiles 实现UploadServiceInterface接口，列表中不包含下载地址
+func (s *UploadService) ListFiles(ctx context.Context, role string, userID uint, query *structs.FileQuery) (*type_response.FileListResponse, error) {
+	userType := userTypeOf(role)
+	files, total, err := s.repo.ListFiles(ctx, userType, userID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.repo.UsedBytes(ctx, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]type_response.FileResponse, len(files))
+	for i := range files {
+		items[i] = toFileResponse(&files[i])
+	}
+	return &type_response.FileListResponse{
+		ListResponse: type_response.ListResponse{Total: total, Page: query.Page, Size: query.PageSize},
+		Items:        items,
+		UsedBytes:    used,
+		Quota:        s.cfg.UserQuota,
+	}, nil
+}
+
+// GetFile 实现UploadServiceInterface接口
+func (s *UploadService) GetFile(ctx context.Context, role string, userID, id uint) (*type_response.FileResponse, error) {
+	file, err := s.repo.FindFile(ctx, userTypeOf(role), userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toFileResponse(ctx, file), nil
+}
+
+// DeleteFile 实现UploadServiceInterface接口，先删除记录再删除对象，对象删除失败只留下无记录的孤立文件
+func (s *UploadService) DeleteFile(ctx context.Context, role string, userID, id uint) error {
+	userType := userTypeOf(role)
+	file, err := s.repo.FindFile(ctx, userType, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteFile(ctx, userType, userID, id); err != nil {
+		return err
+	}
+	s.deleteObject(ctx, file.StorageKey)
+	return nil
+}
+
+// CleanupExpired 实现UploadServiceInterface接口，多个实例同时清理时通过认领避免重复处理
+func (s *UploadService) CleanupExpired(ctx context.Context) (int, error) {
+	cleaned := 0
+	for {
+		ids, err := s.repo.ExpiredUploads(ctx, time.Now(), cleanupBatchSize)
+		if err != nil {
+			return cleaned, err
+		}
+		for _, id := range ids {
+			claimed, err := s.repo.ClaimUpload(ctx, id)
+			if err != nil {
+				return cleaned, err
+			}
+			if !claimed {
+				continue
+			}
+			if err := s.storage.DeletePrefix(ctx, UploadModel.TempPrefix(id)); err != nil {
+				s.logger.Ctx(ctx).LogWarn("删除过期分片失败", zap.String("upload_id", id), zap.Error(err))
+				continue
+			}
+			cleaned++
+		}
+		if len(ids) < cleanupBatchSize {
+			return cleaned, nil
+		}
+	}
+}
+
+// RunCleanup 按间隔清理过期上传任务，阻塞至ctx取消
+func RunCleanup(ctx context.Context, service use_uploadInterface.UploadServiceInterface, interval time.Duration, logger *zaplogger.ServiceLogger) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cleaned, err := service.CleanupExpired(ctx)
+			if err != nil {
+				logger.LogError("清理过期上传任务失败", zap.Error(err))
+			}
+			if cleaned > 0 {
+				logger.LogInfo("已清理过期上传任务", zap.Int("count", cleaned))
+			}
+		}
+	}
+}
+
+// findOwn 查询当前用户的上传任务，其他用户的任务同样返回ErrUploadNotFound
+func (s *UploadService) findOwn(ctx context.Context, role string, userID uint, id string) (*UploadModel.Upload, error) {
+	upload, err := s.repo.FindUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userID || upload.UserType != userTypeOf(role) {
+		return nil, constants.ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// release 合并失败后重新保存任务，使其可重试并重新进入过期清理
+func (s *UploadService) release(ctx context.Context, upload *UploadModel.Upload) {
+	if err := s.repo.SaveUpload(ctx, upload); err != nil {
+		s.logger.Ctx(ctx).LogWarn("恢复上传任务失败", zap.String("upload_id", upload.ID), zap.Error(err))
+	}
+}
+
+// discard 删除上传任务及其分片
+func (s *UploadService) discard(ctx context.Context, upload *UploadModel.Upload) {
+	if err := s.storage.DeletePrefix(ctx, upload.TempPrefix()); err != nil {
+		s.logger.Ctx(ctx).LogWarn("删除分片失败", zap.String("upload_id", upload.ID), zap.Error(err))
+	}
+	if err := s.repo.DeleteUpload(ctx, upload); err != nil {
+		s.logger.Ctx(ctx).LogWarn("删除上传任务失败", zap.String("upload_id", upload.ID), zap.Error(err))
+	}
+}
+
+func (s *UploadService) deleteObject(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		s.logger.Ctx(ctx).LogWarn("删除文件失败", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (s *UploadService) toFileResponse(ctx context.Context, file *UploadModel.File) *type_response.FileResponse {
+	result := toFileResponse(file)
+	url, err := s.storage.SignedURL(ctx, file.StorageKey, downloadURLTTL)
+	if err != nil {
+		s.logger.Ctx(ctx).LogWarn("生成下载地址失败", zap.Uint("file_id", file.ID), zap.Error(err))
+	}
+	result.URL = url
+	return &result
+}
+
+// chunkReader 按序号依次读取分片，读完一个分片后再打开下一个
+type chunkReader struct {
+	ctx     context.Context
+	storage useStorage.Storage
+	upload  *UploadModel.Upload
+	index   int
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.index >= r.upload.TotalChunks {
+				return 0, io.EOF
+			}
+			current, err := r.storage.Open(r.ctx, r.upload.ChunkKey(r.index))
+			if err != nil {
+				return 0, err
+			}
+			r.current = current
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			r.index++
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+func toUploadResponse(upload *UploadModel.Upload, chunks []int) *type_response.UploadResponse {
+	sort.Ints(chunks)
+	return &type_response.UploadResponse{
+		ID:             upload.ID,
+		Filename:       upload.Filename,
+		Size:           upload.Size,
+		ChunkSize:      upload.ChunkSize,
+		TotalChunks:    upload.TotalChunks,
+		ReceivedChunks: chunks,
+		ExpiresAt:      upload.ExpiresAt,
+	}
+}
+
+func toFileResponse(file *UploadModel.File) type_response.FileResponse {
+	return type_response.FileResponse{
+		ID:          file.ID,
+		Filename:    file.Filename,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		SHA256:      file.SHA256,
+		CreatedAt:   file.CreatedAt,
+	}
+}
+
+func userTypeOf(role string) int {
+	if role == SessionModel.RoleAdmin {
+		return LoginHistoryModel.UserTypeAdmin
+	}
+	return LoginHistoryModel.UserTypeNormal
+}
@@ -0,0 +1,369 @@
+package upload_service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin-center/configs/config"
+	"gin-center/infrastructure/zaplogger"
+	SessionModel "gin-center/internal/domain/model/session"
+	UploadModel "gin-center/internal/domain/model/upload"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	"gin-center/pkg/storage/useStorage"
+)
+
+// fakeRepository 内存中的上传任务与文件存储，用户锁以互斥锁实现
+type fakeRepository struct {
+	mu      sync.Mutex
+	locks   sync.Map
+	uploads map[string]*UploadModel.Upload
+	chunks  map[string]map[int]bool
+	files   []UploadModel.File
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{uploads: map[string]*UploadModel.Upload{}, chunks: map[string]map[int]bool{}}
+}
+
+func (r *fakeRepository) LockUser(ctx context.Context, userType int, userID uint) (func(), error) {
+	lock, _ := r.locks.LoadOrStore([2]uint{uint(userType), userID}, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock, nil
+}
+
+func (r *fakeRepository) SaveUpload(ctx context.Context, upload *UploadModel.Upload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *upload
+	r.uploads[upload.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepository) FindUpload(ctx context.Context, id string) (*UploadModel.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload, ok := r.uploads[id]
+	if !ok {
+		return nil, constants.ErrUploadNotFound
+	}
+	copied := *upload
+	return &copied, nil
+}
+
+func (r *fakeRepository) ListUploads(ctx context.Context, userType int, userID uint) ([]*UploadModel.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var uploads []*UploadModel.Upload
+	for _, upload := range r.uploads {
+		if upload.UserType == userType && upload.UserID == userID {
+			copied := *upload
+			uploads = append(uploads, &copied)
+		}
+	}
+	return uploads, nil
+}
+
+func (r *fakeRepository) MarkChunk(ctx context.Context, upload *UploadModel.Upload, index int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.chunks[upload.ID] == nil {
+		r.chunks[upload.ID] = map[int]bool{}
+	}
+	r.chunks[upload.ID][index] = true
+	return nil
+}
+
+func (r *fakeRepository) ReceivedChunks(ctx context.Context, id string) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	chunks := []int{}
+	for index := range r.chunks[id] {
+		chunks = append(chunks, index)
+	}
+	return chunks, nil
+}
+
+func (r *fakeRepository) ClaimUpload(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.uploads[id]
+	return ok, nil
+}
+
+func (r *fakeRepository) DeleteUpload(ctx context.Context, upload *UploadModel.Upload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.uploads, upload.ID)
+	delete(r.chunks, upload.ID)
+	return nil
+}
+
+func (r *fakeRepository) ExpiredUploads(ctx context.Context, before time.Time, limit int64) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) CreateFile(ctx context.Context, file *UploadModel.File) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file.ID = uint(len(r.files) + 1)
+	r.files = append(r.files, *file)
+	return nil
+}
+
+func (r *fakeRepository) FindFile(ctx context.Context, userType int, userID, id uint) (*UploadModel.File, error) {
+	return nil, constants.ErrFileNotFound
+}
+
+func (r *fakeRepository) ListFiles(ctx context.Context, userType int, userID uint, page, pageSize int) ([]UploadModel.File, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeRepository) UsedBytes(ctx context.Context, userType int, userID uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var used int64
+	for _, file := range r.files {
+		if file.UserType == userType && file.UserID == userID {
+			used += file.Size
+		}
+	}
+	return used, nil
+}
+
+func (r *fakeRepository) DeleteFile(ctx context.Context, userType int, userID, id uint) error {
+	return constants.ErrFileNotFound
+}
+
+func newTestService(t *testing.T, cfg *config.UploadConfig) (*UploadService, *fakeRepository) {
+	t.Helper()
+	storage, err := useStorage.NewStorage(context.Background(), &useStorage.Config{
+		Local: useStorage.LocalConfig{Root: t.TempDir(), SigningKey: "test"},
+	})
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	repo := newFakeRepository()
+	return &UploadService{repo: repo, storage: storage, cfg: cfg, logger: zaplogger.NewServiceLogger()}, repo
+}
+
+func testConfig() *config.UploadConfig {
+	return &config.UploadConfig{MaxFileSize: 100, ChunkSize: 4, UserQuota: 200, MaxPending: 2, TTL: time.Hour}
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreate(t *testing.T) {
+	const userID = 7
+	tests := []struct {
+		name       string
+		files      []int64
+		pending    []int64
+		size       int64
+		wantChunks int
+		wantErr    error
+	}{
+		{name: "分片数向上取整", size: 10, wantChunks: 3},
+		{name: "恰好整除", size: 8, wantChunks: 2},
+		{name: "超过单文件上限", size: 101, wantErr: constants.ErrQuotaExceeded},
+		{name: "进行中任务数达到上限", pending: []int64{1, 1}, size: 1, wantErr: constants.ErrQuotaExceeded},
+		{name: "已保存文件与进行中任务计入配额", files: []int64{100}, pending: []int64{60}, size: 41, wantErr: constants.ErrQuotaExceeded},
+		{name: "恰好用满配额", files: []int64{100}, pending: []int64{60}, size: 40, wantChunks: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t, testConfig())
+			for _, size := range tt.files {
+				repo.files = append(repo.files, UploadModel.File{UserID: userID, UserType: userTypeOf(SessionModel.RoleUser), Size: size})
+			}
+			for i, size := range tt.pending {
+				repo.uploads[string(rune('a'+i))] = &UploadModel.Upload{ID: string(rune('a' + i)), UserID: userID, UserType: userTypeOf(SessionModel.RoleUser), Size: size}
+			}
+
+			got, err := service.Create(context.Background(), SessionModel.RoleUser, userID, &structs.CreateUploadRequest{
+				Filename: `C:\docs\report.pdf`,
+				Size:     tt.size,
+				SHA256:   strings.Repeat("A", 64),
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if got.TotalChunks != tt.wantChunks || got.ChunkSize != 4 {
+				t.Errorf("Create() chunks = %d×%d, want %d×4", got.TotalChunks, got.ChunkSize, tt.wantChunks)
+			}
+			saved := repo.uploads[got.ID]
+			if saved.Filename != "report.pdf" || saved.SHA256 != strings.Repeat("a", 64) {
+				t.Errorf("saved upload = %q %q, want report.pdf and lowercase checksum", saved.Filename, saved.SHA256)
+			}
+		})
+	}
+}
+
+func TestCreateConcurrentRequestsRespectQuota(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxPending = 100
+	service, repo := newTestService(t, cfg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = service.Create(context.Background(), SessionModel.RoleUser, 1, &structs.CreateUploadRequest{
+				Filename: "a.bin",
+				Size:     30,
+				SHA256:   strings.Repeat("0", 64),
+			})
+		}()
+	}
+	wg.Wait()
+
+	var reserved int64
+	for _, upload := range repo.uploads {
+		reserved += upload.Size
+	}
+	if reserved > cfg.UserQuota {
+		t.Errorf("reserved %d bytes, quota %d", reserved, cfg.UserQuota)
+	}
+	if len(repo.uploads) != int(cfg.UserQuota/30) {
+		t.Errorf("created %d uploads, want %d", len(repo.uploads), cfg.UserQuota/30)
+	}
+}
+
+func TestUploadChunk(t *testing.T) {
+	data := []byte("0123456789")
+	chunk := func(index int) []byte {
+		end := min((index+1)*4, len(data))
+		return data[index*4 : end]
+	}
+	tests := []struct {
+		name     string
+		role     string
+		userID   uint
+		index    int
+		body     []byte
+		checksum string
+		wantErr  error
+	}{
+		{name: "首个分片", role: SessionModel.RoleUser, userID: 1, index: 0, body: chunk(0), checksum: checksum(chunk(0))},
+		{name: "最后一个分片长度较短", role: SessionModel.RoleUser, userID: 1, index: 2, body: chunk(2), checksum: checksum(chunk(2))},
+		{name: "校验和不区分大小写", role: SessionModel.RoleUser, userID: 1, index: 1, body: chunk(1), checksum: strings.ToUpper(checksum(chunk(1)))},
+		{name: "分片过短", role: SessionModel.RoleUser, userID: 1, index: 0, body: chunk(0)[:3], checksum: checksum(chunk(0)[:3]), wantErr: constants.ErrInvalidChunk},
+		{name: "分片过长", role: SessionModel.RoleUser, userID: 1, index: 2, body: chunk(1), checksum: checksum(chunk(1)), wantErr: constants.ErrInvalidChunk},
+		{name: "校验和不匹配", role: SessionModel.RoleUser, userID: 1, index: 0, body: chunk(0), checksum: checksum(chunk(1)), wantErr: constants.ErrChecksumMismatch},
+		{name: "序号为负数", role: SessionModel.RoleUser, userID: 1, index: -1, body: chunk(0), checksum: checksum(chunk(0)), wantErr: constants.ErrInvalidChunk},
+		{name: "序号超出范围", role: SessionModel.RoleUser, userID: 1, index: 3, body: chunk(0), checksum: checksum(chunk(0)), wantErr: constants.ErrInvalidChunk},
+		{name: "其他用户的任务", role: SessionModel.RoleUser, userID: 2, index: 0, body: chunk(0), checksum: checksum(chunk(0)), wantErr: constants.ErrUploadNotFound},
+		{name: "管理员与普通用户ID相同", role: SessionModel.RoleAdmin, userID: 1, index: 0, body: chunk(0), checksum: checksum(chunk(0)), wantErr: constants.ErrUploadNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t, testConfig())
+			created, err := service.Create(context.Background(), SessionModel.RoleUser, 1, &structs.CreateUploadRequest{
+				Filename: "a.txt",
+				Size:     int64(len(data)),
+				SHA256:   checksum(data),
+			})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			got, err := service.UploadChunk(context.Background(), tt.role, tt.userID, created.ID, tt.index, tt.checksum, bytes.NewReader(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UploadChunk() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.chunks[created.ID]) != 0 {
+					t.Errorf("rejected chunk was recorded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadChunk() error = %v", err)
+			}
+			if len(got.ReceivedChunks) != 1 || got.ReceivedChunks[0] != tt.index {
+				t.Errorf("received chunks = %v, want [%d]", got.ReceivedChunks, tt.index)
+			}
+			stored, err := service.storage.Open(context.Background(), repo.uploads[created.ID].ChunkKey(tt.index))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer stored.Close()
+			if content, _ := io.ReadAll(stored); !bytes.Equal(content, tt.body) {
+				t.Errorf("stored chunk = %q, want %q", content, tt.body)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	data := []byte("0123456789")
+	tests := []struct {
+		name     string
+		declared string
+		chunks   []int
+		wantErr  error
+	}{
+		{name: "合并成功", declared: checksum(data), chunks: []int{0, 1, 2}},
+		{name: "分片不完整", declared: checksum(data), chunks: []int{0, 2}, wantErr: constants.ErrUploadIncomplete},
+		{name: "整个文件的摘要不一致", declared: checksum([]byte("other")), chunks: []int{0, 1, 2}, wantErr: constants.ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t, testConfig())
+			ctx := context.Background()
+			created, err := service.Create(ctx, SessionModel.RoleUser, 1, &structs.CreateUploadRequest{Filename: "a.txt", Size: int64(len(data)), SHA256: tt.declared})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			for _, index := range tt.chunks {
+				part := data[index*4 : min((index+1)*4, len(data))]
+				if _, err := service.UploadChunk(ctx, SessionModel.RoleUser, 1, created.ID, index, checksum(part), bytes.NewReader(part)); err != nil {
+					t.Fatalf("UploadChunk(%d) error = %v", index, err)
+				}
+			}
+
+			file, err := service.Complete(ctx, SessionModel.RoleUser, 1, created.ID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Complete() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.files) != 0 {
+					t.Errorf("file record created on failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if file.Size != int64(len(data)) || len(repo.uploads) != 0 {
+				t.Errorf("Complete() size = %d, pending uploads = %d", file.Size, len(repo.uploads))
+			}
+			stored, err := service.storage.Open(ctx, repo.files[0].StorageKey)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer stored.Close()
+			if content, _ := io.ReadAll(stored); !bytes.Equal(content, data) {
+				t.Errorf("merged file = %q, want %q", content, data)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package UserAdapter
 
 import (
+	"context"
+
 	use_userInterface "gin-center/internal/domain/interface/user"
 	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/models/structs"
 	type_response "gin-center/internal/types/response"
 
 	"github.com/gin-gonic/gin"
@@ -33,8 +37,8 @@ func NewUserServiceAdapter(userService use_userInterface.UserServiceInterface) *
 // @Success 200 {object} map[string]interface{} "登录成功"
 // @Failure 401 {object} error "登录失败"
 // @Router /user/login [post]
-func (a *userServiceAdapter) Login(username, password string) (map[string]interface{}, error) {
-	return a.userService.Login(username, password)
+func (a *userServiceAdapter) Login(ctx *gin.Context, username, password string, meta *auth.LoginMeta) (map[string]interface{}, error) {
+	return a.userService.Login(ctx.Request.Context(), username, password, meta)
 }
 
 // Register 用户注册
@@ -48,8 +52,8 @@ func (a *userServiceAdapter) Login(username, password string) (map[string]interf
 // @Success 200 {string} string "注册成功"
 // @Failure 400 {object} error "注册失败"
 // @Router /user/register [post]
-func (a *userServiceAdapter) Register(username, password string) error {
-	return a.userService.Register(username, password)
+func (a *userServiceAdapter) Register(ctx context.Context, username, password string) error {
+	return a.userService.Register(ctx, username, password)
 }
 
 // GetUserByID 获取用户信息
@@ -107,13 +111,11 @@ func (a *userServiceAdapter) ChangePassword(ctx *gin.Context, userID uint, oldPa
 // @Tags User
 // @Accept json
 // @Produce json
-// @Param page query int true "页码"
-// @Param pageSize query int true "每页数量"
-// @Param query query object false "查询条件"
+// @Param query query structs.UserQuery false "查询条件"
 // @Success 200 {object} type_response.UserListResponse "用户列表"
-// @Router /user/list [get]
-func (a *userServiceAdapter) ListUsers(ctx *gin.Context, page, pageSize int, query map[string]interface{}) (*type_response.UserListResponse, error) {
-	return a.userService.ListUsers(ctx.Request.Context(), page, pageSize, query)
+// @Router /admin/users [get]
+func (a *userServiceAdapter) ListUsers(ctx *gin.Context, query *structs.UserQuery) (*type_response.UserListResponse, error) {
+	return a.userService.ListUsers(ctx.Request.Context(), query)
 }
 
 // UpdateUserAvatar 更新用户头像
@@ -1,42 +1,79 @@
 package UserService
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	infraErrors "gin-center/infrastructure/errors"
+	"gin-center/infrastructure/metrics"
 	user_repo "gin-center/infrastructure/repository/user"
 	use_Baseservice "gin-center/internal/application"
+	use_loginHistoryInterface "gin-center/internal/domain/interface/login_history"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
 	use_userInterface "gin-center/internal/domain/interface/user"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
 	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
 	"gin-center/internal/types/models/structs"
 	type_response "gin-center/internal/types/response"
 	useJwt "gin-center/pkg/security/useJwt"
+	"gin-center/pkg/security/usePassword"
+	"gin-center/pkg/storage/useStorage"
+	"gin-center/pkg/utils/imageproc"
+	"gin-center/pkg/utils/validator"
+	"io"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	"gin-center/infrastructure/zaplogger"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// avatarOptions 头像处理参数，像素上限约为4096×4096
+var avatarOptions = imageproc.Options{
+	MaxPixels:   16 << 20,
+	MaxSize:     1024,
+	Sizes:       []int{64, 128, 256},
+	JPEGQuality: 85,
+}
+
 // UserService 实现用户服务接口
 type UserService struct {
 	baseService *use_Baseservice.BaseService
 	userRepo    *user_repo.UserRepository
 	logger      *zaplogger.ServiceLogger
 	jwtConfig   *useJwt.JWTConfig
+	sessions    use_sessionInterface.SessionServiceInterface
+	history     use_loginHistoryInterface.LoginHistoryServiceInterface
+	storage     useStorage.Storage
 }
 
 // NewUserService 创建新的用户服务实例
-func NewUserService(userRepo *user_repo.UserRepository, logger *zaplogger.ServiceLogger, jwtConfig *useJwt.JWTConfig) use_userInterface.UserServiceInterface {
+func NewUserService(userRepo *user_repo.UserRepository, logger *zaplogger.ServiceLogger, jwtConfig *useJwt.JWTConfig, passwordHasher usePassword.Hasher, sessions use_sessionInterface.SessionServiceInterface, history use_loginHistoryInterface.LoginHistoryServiceInterface, storage useStorage.Storage) use_userInterface.UserServiceInterface {
 	return &UserService{
+		baseService: use_Baseservice.NewBaseService(&use_Baseservice.BaseServiceConfig{
+			Logger:         logger,
+			PasswordHasher: passwordHasher,
+		}),
 		userRepo:  userRepo,
 		jwtConfig: jwtConfig,
 		logger:    logger,
+		sessions:  sessions,
+		history:   history,
+		storage:   storage,
 	}
 }
 
 // Register 用户注册
-func (s *UserService) Register(username, password string, extraFields ...interface{}) error {
+func (s *UserService) Register(ctx context.Context, username, password string, extraFields ...interface{}) error {
 	s.logger.LogInfo("Processing user registration", zap.String("username", username))
 
 	if err := s.baseService.ValidateUserInput(username, password); err != nil {
@@ -44,7 +81,7 @@ func (s *UserService) Register(username, password string, extraFields ...interfa
 		return err
 	}
 
-	existingUser, err := s.userRepo.FindByUsername(context.Background(), username)
+	existingUser, err := s.userRepo.FindByUsername(ctx, username)
 	if err == nil && existingUser != nil {
 		s.logger.LogWarn("Username already exists", zap.String("username", username))
 		return errors.New("username already exists")
@@ -61,40 +98,64 @@ func (s *UserService) Register(username, password string, extraFields ...interfa
 		Password: hashedPassword,
 	}
 
-	return s.userRepo.Register(context.Background(), user)
+	if err := s.userRepo.Register(ctx, user); err != nil {
+		return err
+	}
+	metrics.RecordRegistrations(LoginHistoryModel.UserTypeNormal, metrics.SourceRegister, 1)
+	return nil
 }
 
-// Login 用户登录
-func (s *UserService) Login(username, password string) (map[string]interface{}, error) {
-	s.logger.LogInfo("User login attempt", zap.String("username", username))
+// Login 用户登录，验证通过后为本次登录创建会话
+func (s *UserService) Login(ctx context.Context, username, password string, meta *auth.LoginMeta) (map[string]interface{}, error) {
+	s.logger.Ctx(ctx).LogInfo("User login attempt", zap.String("username", username))
+
+	if meta == nil {
+		meta = &auth.LoginMeta{}
+	}
 
 	if err := s.baseService.ValidateUserInput(username, password); err != nil {
-		s.logger.LogWarn("Invalid login input", zap.String("username", username), zap.Error(err))
+		s.logger.Ctx(ctx).LogWarn("Invalid login input", zap.String("username", username), zap.Error(err))
+		s.history.Record(ctx, SessionModel.RoleUser, 0, username, meta, LoginHistoryModel.ReasonInvalidInput)
 		return nil, err
 	}
 
-	user, err := s.userRepo.FindByUsername(context.Background(), username)
+	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
-		s.logger.LogWarn("User not found", zap.String("username", username), zap.Error(err))
+		s.logger.Ctx(ctx).LogWarn("User not found", zap.String("username", username), zap.Error(err))
+		s.history.Record(ctx, SessionModel.RoleUser, 0, username, meta, LoginHistoryModel.ReasonUserNotFound)
 		return nil, errors.New("invalid username or password")
 	}
 
 	if err := s.baseService.ComparePassword(password, user.Password); err != nil {
-		s.logger.LogWarn("Invalid password attempt", zap.String("username", username))
+		s.logger.Ctx(ctx).LogWarn("Invalid password attempt", zap.String("username", username))
+		s.history.Record(ctx, SessionModel.RoleUser, user.ID, username, meta, LoginHistoryModel.ReasonInvalidPassword)
 		return nil, errors.New("invalid username or password")
 	}
-	userClaims := &structs.UserClaims{
-		UserID: strconv.FormatUint(uint64(user.ID), 10),
+	if user.Status == 0 {
+		s.logger.Ctx(ctx).LogWarn("Inactive user login attempt", zap.String("username", username))
+		s.history.Record(ctx, SessionModel.RoleUser, user.ID, username, meta, LoginHistoryModel.ReasonAccountDisabled)
+		return nil, constants.ErrUserInactive
 	}
-	tokenString, err := s.jwtConfig.GenerateTokenWithClaims(userClaims)
+	s.upgradePasswordHash(ctx, user, password)
+	tokens, err := s.sessions.CreateSession(ctx, SessionModel.RoleUser, user.ID, user.Username, meta)
 	if err != nil {
-		s.logger.LogError("Failed to generate token", zap.String("username", username), zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to create session", zap.String("username", username), zap.Error(err))
+		s.history.Record(ctx, SessionModel.RoleUser, user.ID, username, meta, LoginHistoryModel.ReasonInternalError)
 		return nil, err
 	}
 
-	s.logger.LogInfo("User login successful", zap.String("username", username))
+	now := time.Now()
+	if err := s.userRepo.UpdateLoginInfo(ctx, user.ID, meta.IP, now); err != nil {
+		s.logger.Ctx(ctx).LogWarn("Failed to update login info", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	user.LastLoginAt = &now
+	user.LastLoginIP = meta.IP
+	s.history.Record(ctx, SessionModel.RoleUser, user.ID, username, meta, "")
+
+	s.logger.Ctx(ctx).LogInfo("User login successful", zap.String("username", username))
 	response := map[string]interface{}{
-		"token": tokenString,
+		"token":  tokens.AccessToken,
+		"tokens": tokens,
 		"user": map[string]interface{}{
 			"id":       user.ID,
 			"username": user.Username,
@@ -106,6 +167,25 @@ func (s *UserService) Login(username, password string) (map[string]interface{},
 	return response, nil
 }
 
+// upgradePasswordHash 登录成功后透明升级过时的密码哈希
+// 升级失败不影响本次登录，仅记录警告日志
+func (s *UserService) upgradePasswordHash(ctx context.Context, user *UserModel.User, password string) {
+	rehashed, err := s.baseService.RehashPassword(password, user.Password)
+	if err != nil {
+		s.logger.Ctx(ctx).LogWarn("Failed to rehash password", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+	if rehashed == "" {
+		return
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, rehashed); err != nil {
+		s.logger.Ctx(ctx).LogWarn("Failed to upgrade password hash", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+	user.Password = rehashed
+	s.logger.Ctx(ctx).LogInfo("Password hash upgraded", zap.Uint("user_id", user.ID))
+}
+
 // ValidateToken 验证JWT令牌
 func (s *UserService) ValidateToken(tokenString string) (*structs.UserClaims, error) {
 	securityClaims, err := s.jwtConfig.ParseToken(tokenString)
@@ -125,70 +205,225 @@ func (s *UserService) ValidateToken(tokenString string) (*structs.UserClaims, er
 
 // GetUserByID 根据ID获取用户信息
 func (s *UserService) GetUserByID(ctx context.Context, id uint) (*UserModel.User, error) {
-	s.logger.LogDebug("Getting user by ID", zap.Uint("user_id", id))
+	s.logger.Ctx(ctx).LogDebug("Getting user by ID", zap.Uint("user_id", id))
 	return s.userRepo.FindByID(ctx, id)
 }
 
 // UpdateUser 更新用户信息
 func (s *UserService) UpdateUser(ctx context.Context, user *UserModel.User) error {
-	s.logger.LogInfo("Updating user information", zap.Uint("user_id", user.ID))
+	s.logger.Ctx(ctx).LogInfo("Updating user information", zap.Uint("user_id", user.ID))
 	_, err := s.userRepo.Update(ctx, user)
 	return err
 }
 
-// ListUsers 分页获取用户列表
-func (s *UserService) ListUsers(ctx context.Context, page, pageSize int, query map[string]interface{}) (*type_response.UserListResponse, error) {
-	s.logger.LogInfo("Listing users", zap.Int("page", page), zap.Int("page_size", pageSize))
-
-	if page < 1 || pageSize < 1 {
-		err := errors.New("invalid pagination parameters")
-		s.logger.LogWarn("Invalid pagination parameters", zap.Int("page", page), zap.Int("pageSize", pageSize))
-		return nil, err
+// ListUsers 分页查询普通用户，keyword匹配用户名、昵称或手机号
+func (s *UserService) ListUsers(ctx context.Context, query *structs.UserQuery) (*type_response.UserListResponse, error) {
+	filters := map[string]interface{}{}
+	if query.Keyword != "" {
+		filters["keyword"] = query.Keyword
+	}
+	if query.Status != nil {
+		filters["status"] = *query.Status
 	}
 
-	users, total, err := s.userRepo.ListUsers(ctx, page, pageSize, query)
+	users, total, err := s.userRepo.ListUsers(ctx, query.Page, query.PageSize, filters)
 	if err != nil {
-		s.logger.LogError("Failed to list users", zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to list users", zap.Error(err))
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
-	if users == nil {
-		users = make([]*UserModel.User, 0)
-	}
-
-	userResponses := make([]type_response.UserResponse, len(users))
+	items := make([]type_response.UserResponse, len(users))
 	for i, u := range users {
-		userResponses[i] = type_response.UserResponse{
-			ID:       strconv.FormatUint(uint64(u.ID), 10),
-			Username: u.Username,
-			Nickname: u.Nickname,
-			Avatar:   u.Avatar,
-		}
+		items[i] = toUserResponse(u)
 	}
-
 	return &type_response.UserListResponse{
 		ListResponse: type_response.ListResponse{
-			Total: int64(total),
-			Page:  page,
-			Size:  pageSize,
+			Total: total,
+			Page:  query.Page,
+			Size:  query.PageSize,
 		},
-		Items: userResponses,
+		Items: items,
 	}, nil
 }
 
+// GetUser 获取用户详情
+func (s *UserService) GetUser(ctx context.Context, id uint) (*type_response.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := toUserResponse(user)
+	return &result, nil
+}
+
+// CreateUser 管理员创建普通用户，未指定状态时默认启用
+func (s *UserService) CreateUser(ctx context.Context, req *structs.CreateUserRequest) (*type_response.UserResponse, error) {
+	if err := s.baseService.ValidateUserInput(req.Username, req.Password); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.baseService.HashPassword(req.Password)
+	if err != nil {
+		s.logger.Ctx(ctx).LogError("Failed to hash password", zap.Error(err))
+		return nil, err
+	}
+
+	user := UserModel.NewUser(req.Username, hashedPassword)
+	user.Nickname = req.Nickname
+	user.Phone = req.Phone
+	user.Status = 1
+	if req.Status != nil {
+		user.Status = *req.Status
+	}
+	if err := s.userRepo.Register(ctx, user); err != nil {
+		if errors.Is(err, infraErrors.ErrUsernameExists) || errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, constants.ErrUserExists
+		}
+		s.logger.Ctx(ctx).LogError("Failed to create user", zap.String("username", req.Username), zap.Error(err))
+		return nil, err
+	}
+	// status为0时GORM会使用列默认值，创建后单独更新
+	if user.Status == 0 {
+		if err := s.userRepo.UpdateStatus(ctx, user.ID, 0); err != nil {
+			return nil, err
+		}
+	}
+	metrics.RecordRegistrations(LoginHistoryModel.UserTypeNormal, metrics.SourceAdmin, 1)
+	s.logger.Ctx(ctx).LogInfo("User created", zap.Uint("user_id", user.ID), zap.String("username", user.Username))
+	result := toUserResponse(user)
+	return &result, nil
+}
+
+// SetUserStatus 启用或禁用用户，禁用后立即注销其全部会话
+func (s *UserService) SetUserStatus(ctx context.Context, id uint, status int) error {
+	if err := s.userRepo.UpdateStatus(ctx, id, status); err != nil {
+		return err
+	}
+	s.logger.Ctx(ctx).LogInfo("User status updated", zap.Uint("user_id", id), zap.Int("status", status))
+	if status == 0 {
+		s.revokeSessions(ctx, id)
+	}
+	return nil
+}
+
+// ResetPassword 管理员重置用户密码，重置后用户需重新登录
+func (s *UserService) ResetPassword(ctx context.Context, id uint, password string) error {
+	if err := validator.ValidatePassword(password); err != nil {
+		return err
+	}
+	hashedPassword, err := s.baseService.HashPassword(password)
+	if err != nil {
+		s.logger.Ctx(ctx).LogError("Failed to hash password", zap.Uint("user_id", id), zap.Error(err))
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, id, hashedPassword); err != nil {
+		return err
+	}
+	s.logger.Ctx(ctx).LogInfo("User password reset", zap.Uint("user_id", id))
+	s.revokeSessions(ctx, id)
+	return nil
+}
+
+// DeleteUser 删除用户及其关联数据，并注销全部会话
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.DeleteWithRelations(ctx, id, LoginHistoryModel.UserTypeNormal); err != nil {
+		return err
+	}
+	s.logger.Ctx(ctx).LogInfo("User deleted", zap.Uint("user_id", id))
+	s.revokeSessions(ctx, id)
+	s.deleteAvatar(ctx, id, user.Avatar)
+	return nil
+}
+
+// revokeSessions 注销用户的全部会话，失败时仅记录日志，会话随令牌过期自然失效
+func (s *UserService) revokeSessions(ctx context.Context, id uint) {
+	if _, err := s.sessions.RevokeAllSessions(ctx, SessionModel.RoleUser, id); err != nil {
+		s.logger.Ctx(ctx).LogWarn("Failed to revoke user sessions", zap.Uint("user_id", id), zap.Error(err))
+	}
+}
+
 // UpdateUserAvatar 更新用户头像
 func (s *UserService) UpdateUserAvatar(ctx context.Context, userID uint, avatarPath string) error {
-	s.logger.LogInfo("Updating user avatar", zap.Uint("user_id", userID), zap.String("avatar_path", avatarPath))
+	s.logger.Ctx(ctx).LogInfo("Updating user avatar", zap.Uint("user_id", userID), zap.String("avatar_path", avatarPath))
 	return s.userRepo.UpdateAvatar(ctx, userID, avatarPath)
 }
 
+// UploadAvatar 上传头像并更新用户资料，主图与缩略图保存在同一目录下，成功后删除旧头像
+func (s *UserService) UploadAvatar(ctx context.Context, userID uint, file io.Reader) (*type_response.AvatarResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := imageproc.SquareImages(file, avatarOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := fmt.Sprintf("avatars/%d/%s/", userID, uuid.New().String())
+	result := &type_response.AvatarResponse{Variants: make(map[string]string, len(variants))}
+	for _, v := range variants {
+		key := dir + v.Name + v.Ext
+		if err := s.storage.Put(ctx, key, bytes.NewReader(v.Data), int64(len(v.Data)), v.ContentType); err != nil {
+			s.logger.Ctx(ctx).LogError("Failed to store avatar", zap.Uint("user_id", userID), zap.String("key", key), zap.Error(err))
+			s.removeAvatarDir(ctx, dir)
+			return nil, err
+		}
+		result.Variants[v.Name] = s.storage.URL(key)
+	}
+	result.AvatarURL = result.Variants["original"]
+
+	if err := s.userRepo.UpdateAvatar(ctx, userID, result.AvatarURL); err != nil {
+		s.removeAvatarDir(ctx, dir)
+		return nil, err
+	}
+
+	s.deleteAvatar(ctx, userID, user.Avatar)
+	s.logger.Ctx(ctx).LogInfo("User avatar updated", zap.Uint("user_id", userID), zap.String("dir", dir))
+	return result, nil
+}
+
+// removeAvatarDir 清理写入失败时已上传的部分文件
+func (s *UserService) removeAvatarDir(ctx context.Context, dir string) {
+	if err := s.storage.DeletePrefix(ctx, dir); err != nil {
+		s.logger.Ctx(ctx).LogWarn("Failed to remove orphaned avatar", zap.String("dir", dir), zap.Error(err))
+	}
+}
+
+// deleteAvatar 删除存储中的头像文件，第三方登录同步的外部头像不属于本存储，直接跳过
+// 只删除avatars/{用户ID}/下的文件，避免被改写的头像地址指向其他用户或其他用途的文件
+// 以avatars/{用户ID}/{uuid}/目录保存的头像连同缩略图一并删除，早期单文件头像只删除文件本身
+func (s *UserService) deleteAvatar(ctx context.Context, userID uint, avatarURL string) {
+	key, ok := s.storage.KeyOf(avatarURL)
+	if !ok {
+		return
+	}
+	key = path.Clean(key)
+	if !strings.HasPrefix(key, fmt.Sprintf("avatars/%d/", userID)) {
+		s.logger.Ctx(ctx).LogWarn("Skipped deleting avatar outside user directory", zap.Uint("user_id", userID), zap.String("key", key))
+		return
+	}
+	var err error
+	if dir := path.Dir(key); uuid.Validate(path.Base(dir)) == nil {
+		err = s.storage.DeletePrefix(ctx, dir+"/")
+	} else {
+		err = s.storage.Delete(ctx, key)
+	}
+	if err != nil {
+		s.logger.Ctx(ctx).LogWarn("Failed to delete avatar", zap.Uint("user_id", userID), zap.String("key", key), zap.Error(err))
+	}
+}
+
 // UpdateUserProfile 更新用户个人资料
 func (s *UserService) UpdateUserProfile(ctx context.Context, userID uint, profile *type_response.UpdateUserProfileRequest) error {
-	s.logger.LogInfo("Updating user profile", zap.Uint("user_id", userID))
+	s.logger.Ctx(ctx).LogInfo("Updating user profile", zap.Uint("user_id", userID))
 
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		s.logger.LogError("Failed to find user for profile update", zap.Uint("user_id", userID), zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to find user for profile update", zap.Uint("user_id", userID), zap.Error(err))
 		return err
 	}
 
@@ -199,36 +434,51 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID uint, profil
 
 	_, err = s.userRepo.Update(ctx, user)
 	if err != nil {
-		s.logger.LogError("Failed to update user profile", zap.Uint("user_id", userID), zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to update user profile", zap.Uint("user_id", userID), zap.Error(err))
 	}
 	return err
 }
 
 // ChangePassword 修改用户密码
 func (s *UserService) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error {
-	s.logger.LogInfo("Changing user password", zap.Uint("user_id", userID))
+	s.logger.Ctx(ctx).LogInfo("Changing user password", zap.Uint("user_id", userID))
 
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		s.logger.LogError("Failed to find user for password change", zap.Uint("user_id", userID), zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to find user for password change", zap.Uint("user_id", userID), zap.Error(err))
 		return err
 	}
 
 	if err := s.baseService.ComparePassword(oldPassword, user.Password); err != nil {
-		s.logger.LogWarn("Invalid old password", zap.Uint("user_id", userID))
+		s.logger.Ctx(ctx).LogWarn("Invalid old password", zap.Uint("user_id", userID))
 		return errors.New("old password is incorrect")
 	}
 
 	hashedPassword, err := s.baseService.HashPassword(newPassword)
 	if err != nil {
-		s.logger.LogError("Failed to hash new password", zap.Uint("user_id", userID), zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to hash new password", zap.Uint("user_id", userID), zap.Error(err))
 		return err
 	}
 
 	user.Password = hashedPassword
 	_, err = s.userRepo.Update(ctx, user)
 	if err != nil {
-		s.logger.LogError("Failed to update password", zap.Uint("user_id", userID), zap.Error(err))
+		s.logger.Ctx(ctx).LogError("Failed to update password", zap.Uint("user_id", userID), zap.Error(err))
 	}
 	return err
 }
+
+func toUserResponse(user *UserModel.User) type_response.UserResponse {
+	return type_response.UserResponse{
+		ID:          strconv.FormatUint(uint64(user.ID), 10),
+		Username:    user.Username,
+		Nickname:    user.Nickname,
+		Avatar:      user.Avatar,
+		Phone:       user.Phone,
+		Status:      user.Status,
+		LastLoginAt: user.LastLoginAt,
+		LastLoginIP: user.LastLoginIP,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
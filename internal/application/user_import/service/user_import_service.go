@@ -0,0 +1,561 @@
+// Package user_import_service 实现普通用户的批量导入与导出
+package user_import_service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gin-center/infrastructure/metrics"
+	user_repo "gin-center/infrastructure/repository/user"
+	user_import_repo "gin-center/infrastructure/repository/user_import"
+
+	"gin-center/infrastructure/zaplogger"
+	use_userImportInterface "gin-center/internal/domain/interface/user_import"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	UserModel "gin-center/internal/domain/model/user"
+	UserImportModel "gin-center/internal/domain/model/user_import"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	"gin-center/pkg/security/usePassword"
+	"gin-center/pkg/utils/validator"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxRows 单个文件允许的最大数据行数
+	maxRows = 10000
+	// maxReportedErrors 报告中保留的最大错误条数，避免任务数据过大
+	maxReportedErrors = 1000
+	// syncLimit 有效行不超过该数量时同步写入，否则转为异步任务
+	syncLimit = 100
+	// chunkSize 每个事务写入的行数
+	chunkSize = 100
+	// lookupSize 查询已存在用户名和手机号时每批的数量
+	lookupSize = 500
+	// exportBatchSize 导出时每批查询的行数
+	exportBatchSize = 500
+	// jobTTL 导入任务在Redis中的保留时间
+	jobTTL = 24 * time.Hour
+)
+
+// exportHeader 导出文件的列，导入文件使用username、password、nickname、phone、status列
+var exportHeader = []string{"id", "username", "nickname", "phone", "status", "last_login_at", "last_login_ip", "created_at"}
+
+// UserImportService 用户导入导出服务
+type UserImportService struct {
+	logger         *zaplogger.ServiceLogger
+	userRepo       *user_repo.UserRepository
+	importRepo     *user_import_repo.UserImportRepository
+	passwordHasher usePassword.Hasher
+	// running 进行中的异步导入任务
+	running sync.WaitGroup
+}
+
+// NewUserImportService 创建新的用户导入导出服务实例
+func NewUserImportService(userRepo *user_repo.UserRepository, importRepo *user_import_repo.UserImportRepository, passwordHasher usePassword.Hasher, logger *zaplogger.ServiceLogger) use_userImportInterface.UserImportServiceInterface {
+	return &UserImportService{
+		logger:         logger,
+		userRepo:       userRepo,
+		importRepo:     importRepo,
+		passwordHasher: passwordHasher,
+	}
+}
+
+// candidate 通过校验的待导入行
+type candidate struct {
+	row      int
+	username string
+	password string
+	nickname string
+	phone    string
+	status   int
+}
+
+// Import 实现UserImportServiceInterface接口
+// 无效行不会写入，有效行按chunkSize分事务写入，单个事务失败不影响其他批次
+func (s *UserImportService) Import(ctx context.Context, filename string, file io.Reader, dryRun bool, operatorID uint) (*type_response.UserImportResponse, error) {
+	rows, err := readRows(filename, file)
+	if err != nil {
+		return nil, err
+	}
+	job := &UserImportModel.Job{
+		ID:         uuid.New().String(),
+		Status:     UserImportModel.StatusPending,
+		Filename:   filepath.Base(filename),
+		OperatorID: operatorID,
+		CreatedAt:  time.Now(),
+	}
+	candidates, err := s.validate(ctx, rows, job)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		job.ID = ""
+		job.Status = UserImportModel.StatusCompleted
+		result := toResponse(job)
+		result.DryRun = true
+		return result, nil
+	}
+
+	if len(candidates) <= syncLimit {
+		s.run(ctx, job, candidates)
+		return toResponse(job), nil
+	}
+	if err := s.importRepo.SaveJob(ctx, job, jobTTL); err != nil {
+		s.logger.LogError("保存导入任务失败", zap.String("job_id", job.ID), zap.Error(err))
+		return nil, err
+	}
+	result := toResponse(job)
+	// 异步任务不随请求结束而取消
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(context.WithoutCancel(ctx), job, candidates)
+	}()
+	s.logger.LogInfo("已创建用户导入任务", zap.String("job_id", result.JobID), zap.Int("valid", result.Valid), zap.Uint("operator", operatorID))
+	return result, nil
+}
+
+// Wait 实现UserImportServiceInterface接口
+func (s *UserImportService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetJob 实现UserImportServiceInterface接口
+func (s *UserImportService) GetJob(ctx context.Context, jobID string) (*type_response.UserImportResponse, error) {
+	job, err := s.importRepo.FindJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return toResponse(job), nil
+}
+
+// Export 实现UserImportServiceInterface接口，w支持Flush时每批写入后立即刷新
+func (s *UserImportService) Export(ctx context.Context, query *structs.UserExportQuery, w io.Writer) error {
+	filters := map[string]interface{}{}
+	if query.Keyword != "" {
+		filters["keyword"] = query.Keyword
+	}
+	if query.Status != nil {
+		filters["status"] = *query.Status
+	}
+	if query.Format == "xlsx" {
+		return s.exportXLSX(ctx, filters, w)
+	}
+	return s.exportCSV(ctx, filters, w)
+}
+
+func (s *UserImportService) exportCSV(ctx context.Context, filters map[string]interface{}, w io.Writer) error {
+	flusher, _ := w.(interface{ Flush() })
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader); err != nil {
+		return err
+	}
+	err := s.userRepo.EachBatch(ctx, filters, exportBatchSize, func(users []*UserModel.User) error {
+		for _, user := range users {
+			if err := writer.Write(exportRow(user)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportXLSX 使用流式写入器逐行生成工作表，XLSX需完整生成后才能输出
+func (s *UserImportService) exportXLSX(ctx context.Context, filters map[string]interface{}, w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+	sheet := file.GetSheetName(0)
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	line := 1
+	writeRow := func(values []string) error {
+		cells := make([]interface{}, len(values))
+		for i, v := range values {
+			cells[i] = v
+		}
+		cell, err := excelize.CoordinatesToCellName(1, line)
+		if err != nil {
+			return err
+		}
+		line++
+		return stream.SetRow(cell, cells)
+	}
+	if err := writeRow(exportHeader); err != nil {
+		return err
+	}
+	err = s.userRepo.EachBatch(ctx, filters, exportBatchSize, func(users []*UserModel.User) error {
+		for _, user := range users {
+			if err := writeRow(exportRow(user)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+// validate 校验每一行并检查文件内及数据库中重复的用户名和手机号，结果写入job
+func (s *UserImportService) validate(ctx context.Context, rows [][]string, job *UserImportModel.Job) ([]candidate, error) {
+	columns, err := parseHeader(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	addError := func(row int, field, message string) {
+		if len(job.Errors) < maxReportedErrors {
+			job.Errors = append(job.Errors, UserImportModel.RowError{Row: row, Field: field, Message: message})
+		}
+	}
+
+	candidates := make([]candidate, 0, len(rows)-1)
+	seenUsernames := make(map[string]int)
+	seenPhones := make(map[string]int)
+	for i, values := range rows[1:] {
+		row := i + 2
+		if blank(values) {
+			continue
+		}
+		job.Total++
+		c := candidate{
+			row:      row,
+			username: cellOf(values, columns, "username"),
+			password: cellOf(values, columns, "password"),
+			nickname: cellOf(values, columns, "nickname"),
+			phone:    cellOf(values, columns, "phone"),
+			status:   1,
+		}
+		valid := true
+		if err := validator.ValidateUsername(c.username); err != nil || len(c.username) > 32 {
+			addError(row, "username", "用户名需为3-32位字母或数字")
+			valid = false
+		} else if first, ok := seenUsernames[strings.ToLower(c.username)]; ok {
+			addError(row, "username", fmt.Sprintf("用户名与第%d行重复", first))
+			valid = false
+		} else {
+			seenUsernames[strings.ToLower(c.username)] = row
+		}
+		if err := validator.ValidatePassword(c.password); err != nil {
+			addError(row, "password", "密码需为8-72位且包含大小写字母、数字和特殊字符")
+			valid = false
+		}
+		if utf8.RuneCountInString(c.nickname) > 32 {
+			addError(row, "nickname", "昵称不能超过32个字符")
+			valid = false
+		}
+		if c.phone != "" {
+			if !isPhone(c.phone) {
+				addError(row, "phone", "手机号需为11位数字")
+				valid = false
+			} else if first, ok := seenPhones[c.phone]; ok {
+				addError(row, "phone", fmt.Sprintf("手机号与第%d行重复", first))
+				valid = false
+			} else {
+				seenPhones[c.phone] = row
+			}
+		}
+		switch status := cellOf(values, columns, "status"); status {
+		case "", "1":
+		case "0":
+			c.status = 0
+		default:
+			addError(row, "status", "状态只能为0或1")
+			valid = false
+		}
+		if valid {
+			candidates = append(candidates, c)
+		}
+	}
+
+	existingUsernames, existingPhones, err := s.findExisting(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	result := candidates[:0]
+	for _, c := range candidates {
+		valid := true
+		if _, ok := existingUsernames[strings.ToLower(c.username)]; ok {
+			addError(c.row, "username", "用户名已存在")
+			valid = false
+		}
+		if _, ok := existingPhones[c.phone]; ok && c.phone != "" {
+			addError(c.row, "phone", "手机号已被使用")
+			valid = false
+		}
+		if valid {
+			result = append(result, c)
+		}
+	}
+
+	job.Valid = len(result)
+	if len(job.Errors) == maxReportedErrors {
+		job.Message = fmt.Sprintf("错误过多，仅保留前%d条", maxReportedErrors)
+	}
+	return result, nil
+}
+
+// findExisting 分批查询数据库中已存在的用户名（小写）和手机号
+func (s *UserImportService) findExisting(ctx context.Context, candidates []candidate) (map[string]struct{}, map[string]struct{}, error) {
+	usernames := make(map[string]struct{})
+	phones := make(map[string]struct{})
+	for start := 0; start < len(candidates); start += lookupSize {
+		end := min(start+lookupSize, len(candidates))
+		names := make([]string, 0, end-start)
+		numbers := make([]string, 0, end-start)
+		for _, c := range candidates[start:end] {
+			names = append(names, c.username)
+			if c.phone != "" {
+				numbers = append(numbers, c.phone)
+			}
+		}
+		existingNames, existingNumbers, err := s.userRepo.FindExisting(ctx, names, numbers)
+		if err != nil {
+			s.logger.LogError("查询已存在用户失败", zap.Error(err))
+			return nil, nil, err
+		}
+		for _, name := range existingNames {
+			usernames[strings.ToLower(name)] = struct{}{}
+		}
+		for _, number := range existingNumbers {
+			phones[number] = struct{}{}
+		}
+	}
+	return usernames, phones, nil
+}
+
+// run 分批哈希密码并写入用户，每批完成后更新任务进度
+func (s *UserImportService) run(ctx context.Context, job *UserImportModel.Job, candidates []candidate) {
+	job.Status = UserImportModel.StatusRunning
+	for start := 0; start < len(candidates); start += chunkSize {
+		chunk := candidates[start:min(start+chunkSize, len(candidates))]
+		if err := s.insertChunk(ctx, chunk); err != nil {
+			s.logger.LogError("批量写入用户失败", zap.String("job_id", job.ID), zap.Int("row", chunk[0].row), zap.Error(err))
+			for _, c := range chunk {
+				if len(job.Errors) < maxReportedErrors {
+					job.Errors = append(job.Errors, UserImportModel.RowError{Row: c.row, Message: "写入失败，该批次已回滚"})
+				}
+			}
+		} else {
+			job.Inserted += len(chunk)
+		}
+		s.saveProgress(ctx, job)
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = UserImportModel.StatusCompleted
+	if job.Inserted == 0 && job.Valid > 0 {
+		job.Status = UserImportModel.StatusFailed
+	}
+	s.saveProgress(ctx, job)
+	metrics.RecordRegistrations(LoginHistoryModel.UserTypeNormal, metrics.SourceImport, job.Inserted)
+	s.logger.LogInfo("用户导入完成",
+		zap.String("job_id", job.ID),
+		zap.Int("total", job.Total),
+		zap.Int("inserted", job.Inserted),
+		zap.Uint("operator", job.OperatorID))
+}
+
+func (s *UserImportService) insertChunk(ctx context.Context, chunk []candidate) error {
+	users := make([]*UserModel.User, len(chunk))
+	for i, c := range chunk {
+		hash, err := s.passwordHasher.Hash(c.password)
+		if err != nil {
+			return fmt.Errorf("密码加密失败: %w", err)
+		}
+		user := UserModel.NewUser(c.username, hash)
+		user.Nickname = c.nickname
+		user.Phone = c.phone
+		user.Status = c.status
+		users[i] = user
+	}
+	return s.userRepo.CreateBatch(ctx, users)
+}
+
+// saveProgress 保存任务进度，失败仅记录日志，不影响导入本身
+func (s *UserImportService) saveProgress(ctx context.Context, job *UserImportModel.Job) {
+	if err := s.importRepo.SaveJob(ctx, job, jobTTL); err != nil {
+		s.logger.LogWarn("更新导入任务进度失败", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// readRows 按扩展名读取CSV或XLSX第一个工作表的所有行，至少包含表头
+func readRows(filename string, file io.Reader) ([][]string, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", constants.ErrInvalidImportFile, err)
+			}
+			if len(rows) > maxRows {
+				return nil, fmt.Errorf("%w: 数据行数超过%d", constants.ErrInvalidImportFile, maxRows)
+			}
+			rows = append(rows, record)
+		}
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		}
+	case ".xlsx":
+		book, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", constants.ErrInvalidImportFile, err)
+		}
+		defer book.Close()
+		iter, err := book.Rows(book.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", constants.ErrInvalidImportFile, err)
+		}
+		defer iter.Close()
+		for iter.Next() {
+			if len(rows) > maxRows {
+				return nil, fmt.Errorf("%w: 数据行数超过%d", constants.ErrInvalidImportFile, maxRows)
+			}
+			record, err := iter.Columns()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", constants.ErrInvalidImportFile, err)
+			}
+			rows = append(rows, record)
+		}
+	default:
+		return nil, fmt.Errorf("%w: 仅支持csv和xlsx文件", constants.ErrInvalidImportFile)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: 文件为空", constants.ErrInvalidImportFile)
+	}
+	return rows, nil
+}
+
+// parseHeader 解析表头，列名不区分大小写，username与password列必须存在
+func parseHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"username", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: 缺少%s列", constants.ErrInvalidImportFile, required)
+		}
+	}
+	return columns, nil
+}
+
+func cellOf(values []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(values) {
+		return ""
+	}
+	return strings.TrimSpace(values[i])
+}
+
+func blank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isPhone(phone string) bool {
+	if len(phone) != 11 {
+		return false
+	}
+	for _, ch := range phone {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func exportRow(user *UserModel.User) []string {
+	lastLoginAt := ""
+	if user.LastLoginAt != nil {
+		lastLoginAt = user.LastLoginAt.Format(time.DateTime)
+	}
+	return []string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		user.Username,
+		sanitizeCell(user.Nickname),
+		user.Phone,
+		strconv.Itoa(user.Status),
+		lastLoginAt,
+		user.LastLoginIP,
+		user.CreatedAt.Format(time.DateTime),
+	}
+}
+
+// sanitizeCell 为以公式字符开头的文本加前缀，防止在表格软件中被当作公式执行
+func sanitizeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func toResponse(job *UserImportModel.Job) *type_response.UserImportResponse {
+	errs := make([]type_response.UserImportRowError, len(job.Errors))
+	for i, e := range job.Errors {
+		errs[i] = type_response.UserImportRowError{Row: e.Row, Field: e.Field, Message: e.Message}
+	}
+	return &type_response.UserImportResponse{
+		JobID:      job.ID,
+		Status:     job.Status,
+		Filename:   job.Filename,
+		Total:      job.Total,
+		Valid:      job.Valid,
+		Invalid:    job.Total - job.Valid,
+		Inserted:   job.Inserted,
+		Errors:     errs,
+		Message:    job.Message,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -1,9 +1,16 @@
 package use_AdminInterface
 
+import (
+	"context"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+	security_types "gin-center/pkg/security/types"
+)
+
 type AdminServiceInterface interface {
-	Register(username, password string) error
-	Login(username string, password string) (string, map[string]interface{}, error)
-	GetAdminInfo(username string) (*map[string]interface{}, error)
-	UpdateAdmin(username string, updates map[string]interface{}) error
-	PaginateAdmins(page, pageSize int) ([]map[string]interface{}, int64, error)
+	Register(ctx context.Context, username, password string) error
+	Login(ctx context.Context, username string, password string, meta *auth.LoginMeta) (*security_types.TokenPair, map[string]interface{}, error)
+	GetAdminInfo(ctx context.Context, username string) (*map[string]interface{}, error)
+	UpdateAdmin(ctx context.Context, username string, updates map[string]interface{}) error
+	PaginateAdmins(ctx context.Context, page, pageSize int) (*type_response.AdminListResponse, error)
 }
@@ -0,0 +1,19 @@
+package use_apiKeyInterface
+
+import (
+	"context"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+)
+
+type APIKeyServiceInterface interface {
+	// Create 创建密钥，明文仅在返回值中出现一次
+	Create(ctx context.Context, role string, userID uint, req *structs.CreateAPIKeyRequest) (*type_response.APIKeyCreatedResponse, error)
+	List(ctx context.Context, role string, userID uint) ([]type_response.APIKeyResponse, error)
+	Revoke(ctx context.Context, role string, userID, id uint) error
+	// Authenticate 校验密钥明文，密钥无效、过期、已撤销或所有者不可用时返回ErrAPIKeyNotFound
+	Authenticate(ctx context.Context, raw, ip string) (*auth.APIKeyPrincipal, error)
+	// RecordUsage 将密钥的一次调用写入操作日志
+	RecordUsage(ctx context.Context, principal *auth.APIKeyPrincipal, method, path, ip string, status int)
+}
@@ -0,0 +1,12 @@
+package use_impersonationInterface
+
+import (
+	"context"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+)
+
+type ImpersonationServiceInterface interface {
+	// Start 以管理员身份模拟普通用户，目标用户不存在时返回ErrUserNotFound，被禁用时返回ErrUserInactive
+	Start(ctx context.Context, actor *auth.Actor, userID uint, meta *auth.LoginMeta) (*type_response.ImpersonationResponse, error)
+}
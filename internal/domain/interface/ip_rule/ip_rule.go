@@ -0,0 +1,21 @@
+package use_ipRuleInterface
+
+import (
+	"context"
+	IPRuleModel "gin-center/internal/domain/model/ip_rule"
+	"gin-center/internal/types/models/structs"
+)
+
+type IPRuleServiceInterface interface {
+	// Check 判断客户端IP能否访问路由组，未启用访问控制或路由组没有规则时允许
+	Check(group, ip string) IPRuleModel.Decision
+	// ListRules 返回路由组的全部规则，包括配置中的规则，group为空时返回所有路由组
+	ListRules(ctx context.Context, group string) ([]IPRuleModel.IPRule, error)
+	// CreateRule 添加规则，规则格式错误、未配置地理位置库时使用国家规则，
+	// 或管理端规则生效后operatorIP将无法访问管理端时返回ErrInvalidIPRule
+	CreateRule(ctx context.Context, req *structs.IPRuleRequest, operatorID uint, operatorIP string) (*IPRuleModel.IPRule, error)
+	// DeleteRule 删除通过接口添加的规则，规则不存在时返回ErrIPRuleNotFound
+	DeleteRule(ctx context.Context, id string) error
+	// Run 订阅其他实例的规则变更并定期全量刷新，阻塞至ctx取消
+	Run(ctx context.Context)
+}
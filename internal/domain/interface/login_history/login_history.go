@@ -0,0 +1,13 @@
+package use_loginHistoryInterface
+
+import (
+	"context"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+)
+
+type LoginHistoryServiceInterface interface {
+	// Record 记录一次登录尝试，reason为空表示登录成功
+	Record(ctx context.Context, role string, userID uint, username string, meta *auth.LoginMeta, reason string)
+	ListByUser(ctx context.Context, role string, userID uint, success *bool, page, pageSize int) (*type_response.LoginHistoryListResponse, error)
+}
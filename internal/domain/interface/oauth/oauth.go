@@ -0,0 +1,18 @@
+package use_oauthInterface
+
+import (
+	"context"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+)
+
+type OAuthServiceInterface interface {
+	Providers() []type_response.OAuthProviderResponse
+	// Authorize 创建授权请求，返回提供方的授权地址与须写入浏览器Cookie的绑定值，mode为link时关联到userID
+	Authorize(ctx context.Context, provider, mode string, userID uint) (authURL, binding string, err error)
+	// Callback 处理提供方回调，binding为回调请求携带的绑定值，userID为回调请求的登录用户，未登录时为0
+	// 登录模式下返回与密码登录相同结构的结果；关联模式要求userID为发起关联的用户
+	Callback(ctx context.Context, provider, code, state, binding string, userID uint, meta *auth.LoginMeta) (map[string]interface{}, error)
+	ListIdentities(ctx context.Context, userID uint) ([]type_response.IdentityResponse, error)
+	Unlink(ctx context.Context, userID uint, provider string) error
+}
@@ -0,0 +1,29 @@
+package use_oauthServerInterface
+
+import (
+	"context"
+	OAuthClientModel "gin-center/internal/domain/model/oauth_client"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+)
+
+// OAuthServerServiceInterface 授权服务接口，协议错误均以*auth.OAuthError返回
+type OAuthServerServiceInterface interface {
+	CreateClient(ctx context.Context, req *structs.OAuthClientRequest, createdBy uint) (*type_response.OAuthClientResponse, error)
+	ListClients(ctx context.Context, page, pageSize int) (*type_response.OAuthClientListResponse, error)
+	UpdateClient(ctx context.Context, id uint, req *structs.OAuthClientRequest) (*type_response.OAuthClientResponse, error)
+	DeleteClient(ctx context.Context, id uint) error
+	RotateSecret(ctx context.Context, id uint) (*type_response.OAuthClientResponse, error)
+
+	// PreviewAuthorization 校验授权请求并返回授权确认页所需信息
+	PreviewAuthorization(ctx context.Context, req *structs.AuthorizeRequest) (*type_response.AuthorizationPreviewResponse, error)
+	// Authorize 为当前登录主体签发授权码，返回携带授权码或错误的回调地址
+	Authorize(ctx context.Context, req *structs.AuthorizeRequest, role string, userID uint, username string) (string, error)
+
+	// AuthenticateClient 校验客户端凭证，公开客户端的secret为空
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*OAuthClientModel.OAuthClient, error)
+	Token(ctx context.Context, client *OAuthClientModel.OAuthClient, req *structs.OAuthTokenRequest) (*type_response.OAuthTokenResponse, error)
+	Introspect(ctx context.Context, client *OAuthClientModel.OAuthClient, token string) (*type_response.IntrospectionResponse, error)
+	Revoke(ctx context.Context, client *OAuthClientModel.OAuthClient, token string) error
+	UserInfo(ctx context.Context, token string) (*type_response.UserInfoResponse, error)
+}
@@ -0,0 +1,11 @@
+package use_operationLogInterface
+
+import (
+	"context"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+)
+
+type OperationLogServiceInterface interface {
+	// Record 写入一条操作审计日志，自动补全ID与时间，写入失败仅记录错误日志
+	Record(ctx context.Context, entry *OperationLogModel.OperationLog)
+}
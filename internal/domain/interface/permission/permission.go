@@ -0,0 +1,15 @@
+package use_permissionInterface
+
+import (
+	"context"
+	type_response "gin-center/internal/types/response"
+)
+
+// PermissionServiceInterface 权限服务接口，管理员默认拥有除需显式授予以外的全部启用权限
+type PermissionServiceInterface interface {
+	ListPermissions(ctx context.Context) ([]type_response.PermissionResponse, error)
+	ListCodes(ctx context.Context, role string, userID uint) ([]string, error)
+	HasPermission(ctx context.Context, role string, userID uint, code string) (bool, error)
+	// SetUserPermissions 覆盖用户或管理员的权限，codes中存在无效编码时返回ErrInvalidPermission
+	SetUserPermissions(ctx context.Context, role string, userID uint, codes []string, operatorID uint) error
+}
@@ -0,0 +1,13 @@
+package use_serviceAccountInterface
+
+import (
+	"context"
+	"crypto/x509"
+	"gin-center/internal/types/auth"
+)
+
+type ServiceAccountServiceInterface interface {
+	// Authenticate 按客户端证书的身份查找服务账号，未映射或账号不可用时返回ErrServiceAccountNotFound
+	// 证书须已通过TLS握手校验
+	Authenticate(ctx context.Context, cert *x509.Certificate) (*auth.ServiceAccountPrincipal, error)
+}
@@ -0,0 +1,26 @@
+package use_sessionInterface
+
+import (
+	"context"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/auth"
+	type_response "gin-center/internal/types/response"
+	security_types "gin-center/pkg/security/types"
+	"time"
+)
+
+type SessionServiceInterface interface {
+	// CreateSession 登录成功后创建会话并签发令牌
+	CreateSession(ctx context.Context, role string, userID uint, username string, meta *auth.LoginMeta) (*security_types.TokenPair, error)
+	// Impersonate 创建管理员模拟普通用户的短期会话，返回会话与访问令牌
+	Impersonate(ctx context.Context, userID uint, username string, actor *auth.Actor, meta *auth.LoginMeta) (*SessionModel.Session, string, error)
+	// Refresh 使用刷新令牌轮换令牌对
+	Refresh(ctx context.Context, refreshToken string, meta *auth.LoginMeta) (*security_types.TokenPair, error)
+	// Authenticate 校验访问令牌对应的会话仍然有效
+	Authenticate(ctx context.Context, claims *security_types.JWTUserClaims, ip string) (*SessionModel.Session, error)
+	ListSessions(ctx context.Context, role string, userID uint, currentSessionID string) ([]type_response.SessionResponse, error)
+	RevokeSession(ctx context.Context, role string, userID uint, sessionID string) error
+	RevokeAllSessions(ctx context.Context, role string, userID uint) (int, error)
+	// ListOnlineUsers 按用户聚合window时间内有活动的会话
+	ListOnlineUsers(ctx context.Context, role string, window time.Duration) ([]type_response.OnlineUserResponse, error)
+}
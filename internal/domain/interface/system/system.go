@@ -1,11 +1,13 @@
 package system_config
 
 import (
+	"context"
+
 	"gin-center/internal/types/system"
 )
 
 type SystemServiceInterface interface {
 	GetSystemConfig() map[string]any
 	UpdateSystemConfig(config system.SystemConfig) error
-	GetSystemInfo() (*system.SystemConfig, error)
+	GetSystemInfo(ctx context.Context) (*system.SystemConfig, error)
 }
@@ -0,0 +1,29 @@
+package use_uploadInterface
+
+import (
+	"context"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	"io"
+)
+
+type UploadServiceInterface interface {
+	// Create 创建分片上传任务，超出文件大小上限或用户配额时返回ErrQuotaExceeded
+	Create(ctx context.Context, role string, userID uint, req *structs.CreateUploadRequest) (*type_response.UploadResponse, error)
+	// Get 查询上传任务及已接收的分片，用于断点续传
+	Get(ctx context.Context, role string, userID uint, id string) (*type_response.UploadResponse, error)
+	// UploadChunk 上传单个分片，checksum为分片的SHA-256摘要，重复上传同一分片会覆盖
+	UploadChunk(ctx context.Context, role string, userID uint, id string, index int, checksum string, chunk io.Reader) (*type_response.UploadResponse, error)
+	// Complete 合并全部分片并校验整个文件的摘要，成功后删除分片
+	Complete(ctx context.Context, role string, userID uint, id string) (*type_response.FileResponse, error)
+	// Abort 取消上传任务并删除已上传的分片
+	Abort(ctx context.Context, role string, userID uint, id string) error
+
+	ListFiles(ctx context.Context, role string, userID uint, query *structs.FileQuery) (*type_response.FileListResponse, error)
+	// GetFile 查询文件，返回带有效期的下载地址
+	GetFile(ctx context.Context, role string, userID, id uint) (*type_response.FileResponse, error)
+	DeleteFile(ctx context.Context, role string, userID, id uint) error
+
+	// CleanupExpired 删除过期未完成任务的分片，返回清理的任务数量
+	CleanupExpired(ctx context.Context) (int, error)
+}
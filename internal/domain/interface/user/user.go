@@ -3,19 +3,32 @@ package use_userInterface
 import (
 	"context"
 	UserModel "gin-center/internal/domain/model/user"
+	"gin-center/internal/types/auth"
 	"gin-center/internal/types/models/structs"
 	type_response "gin-center/internal/types/response"
+	"io"
 )
 
 type UserServiceInterface interface {
-	Register(username, password string, extraFields ...interface{}) error
+	Register(ctx context.Context, username, password string, extraFields ...interface{}) error
 	// Login 用户登录
-	Login(username, password string) (map[string]interface{}, error)
+	Login(ctx context.Context, username, password string, meta *auth.LoginMeta) (map[string]interface{}, error)
 	ValidateToken(tokenString string) (*structs.UserClaims, error)
 	GetUserByID(ctx context.Context, id uint) (*UserModel.User, error)
 	UpdateUser(ctx context.Context, user *UserModel.User) error
-	ListUsers(ctx context.Context, page, pageSize int, query map[string]interface{}) (*type_response.UserListResponse, error)
 	UpdateUserAvatar(ctx context.Context, userID uint, avatarPath string) error
+	// UploadAvatar 处理并保存头像及缩略图，删除旧头像，图片无效时返回imageproc中定义的错误
+	UploadAvatar(ctx context.Context, userID uint, file io.Reader) (*type_response.AvatarResponse, error)
 	UpdateUserProfile(ctx context.Context, userID uint, profile *type_response.UpdateUserProfileRequest) error
 	ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error
+
+	// 以下为管理端用户管理
+	ListUsers(ctx context.Context, query *structs.UserQuery) (*type_response.UserListResponse, error)
+	GetUser(ctx context.Context, id uint) (*type_response.UserResponse, error)
+	// CreateUser 用户名或手机号已存在时返回ErrUserExists
+	CreateUser(ctx context.Context, req *structs.CreateUserRequest) (*type_response.UserResponse, error)
+	// SetUserStatus 设置用户状态，禁用时注销其全部会话
+	SetUserStatus(ctx context.Context, id uint, status int) error
+	ResetPassword(ctx context.Context, id uint, password string) error
+	DeleteUser(ctx context.Context, id uint) error
 }
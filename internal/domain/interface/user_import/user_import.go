@@ -0,0 +1,20 @@
+package use_userImportInterface
+
+import (
+	"context"
+	"gin-center/internal/types/models/structs"
+	type_response "gin-center/internal/types/response"
+	"io"
+)
+
+type UserImportServiceInterface interface {
+	// Import 校验并导入CSV或XLSX文件中的普通用户，dryRun为true时只返回校验报告
+	// 有效行较多时异步写入，返回的任务ID可用于查询进度；文件无法解析时返回ErrInvalidImportFile
+	Import(ctx context.Context, filename string, file io.Reader, dryRun bool, operatorID uint) (*type_response.UserImportResponse, error)
+	// GetJob 查询导入任务，任务不存在或已过期时返回ErrImportJobNotFound
+	GetJob(ctx context.Context, jobID string) (*type_response.UserImportResponse, error)
+	// Export 按条件分批查询用户并写入w
+	Export(ctx context.Context, query *structs.UserExportQuery, w io.Writer) error
+	// Wait 等待进行中的异步导入任务完成，ctx到期时返回ctx的错误
+	Wait(ctx context.Context) error
+}
@@ -0,0 +1,60 @@
+// Package api_key_model 定义API密钥与个人访问令牌领域模型
+package api_key_model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// TypeAPIKey API密钥，通常通过X-API-Key请求头传递
+	TypeAPIKey = 1
+	// TypePersonalToken 个人访问令牌，通常通过Bearer传递
+	TypePersonalToken = 2
+
+	// PrefixAPIKey API密钥明文前缀
+	PrefixAPIKey = "gck_"
+	// PrefixPersonalToken 个人访问令牌明文前缀
+	PrefixPersonalToken = "gcp_"
+)
+
+// APIKey 机器客户端使用的长期凭证，仅保存明文的SHA-256摘要
+// Scopes为空格分隔的权限编码，实际权限为Scopes与所有者当前权限的交集
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id"`
+	UserType   int        `json:"user_type"`
+	Type       int        `json:"type"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     string     `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName 返回数据库表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Valid 判断密钥在指定时间是否可用
+func (k *APIKey) Valid(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// ScopeList 返回授权的权限编码列表
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// IsToken 判断明文是否具有API密钥或个人访问令牌的前缀
+func IsToken(raw string) bool {
+	return strings.HasPrefix(raw, PrefixAPIKey) || strings.HasPrefix(raw, PrefixPersonalToken)
+}
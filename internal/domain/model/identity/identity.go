@@ -0,0 +1,40 @@
+// Package identity_model 定义第三方登录身份领域模型
+package identity_model
+
+import "time"
+
+const (
+	// StateModeLogin 使用第三方身份登录
+	StateModeLogin = "login"
+	// StateModeLink 为已登录用户关联第三方身份
+	StateModeLink = "link"
+)
+
+// UserIdentity 普通用户关联的第三方身份，同一提供方的subject全局唯一
+type UserIdentity struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id"`
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 返回数据库表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OAuthState 授权请求在回调前暂存的状态
+// BindingHash为发起授权的浏览器所持绑定Cookie的SHA-256摘要，回调须来自同一浏览器
+type OAuthState struct {
+	Provider     string    `json:"provider"`
+	Mode         string    `json:"mode"`
+	UserID       uint      `json:"user_id,omitempty"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	BindingHash  string    `json:"binding_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
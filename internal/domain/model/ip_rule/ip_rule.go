@@ -0,0 +1,50 @@
+// Package ip_rule_model 定义路由组访问控制规则领域模型
+package ip_rule_model
+
+import "time"
+
+// GroupAdmin 管理端路由组，包括管理员登录
+const GroupAdmin = "admin"
+
+// 规则动作
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+)
+
+// 规则类型
+const (
+	// KindCIDR 按IP或地址段匹配
+	KindCIDR = "cidr"
+	// KindCountry 按地理位置库中的国家/地区代码匹配
+	KindCountry = "country"
+)
+
+// 规则来源
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+// IPRule 访问控制规则，通过管理接口添加的规则保存在Redis中
+type IPRule struct {
+	ID     string `json:"id"`
+	Group  string `json:"group"`
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	// Value CIDR或ISO 3166-1两位国家代码
+	Value     string    `json:"value"`
+	Remark    string    `json:"remark,omitempty"`
+	Source    string    `json:"source"`
+	CreatedBy uint      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Decision 访问控制的判定结果
+type Decision struct {
+	Allowed bool
+	// Reason 被拒绝的原因，包含命中的规则
+	Reason string
+	// Country 查询到的国家/地区代码，未查询时为空
+	Country string
+}
@@ -0,0 +1,40 @@
+// Package login_history_model 定义登录历史领域模型
+package login_history_model
+
+import "time"
+
+const (
+	// UserTypeNormal 普通用户
+	UserTypeNormal = 0
+	// UserTypeAdmin 管理员
+	UserTypeAdmin = 1
+)
+
+// 登录失败原因
+const (
+	ReasonInvalidInput    = "invalid_input"
+	ReasonUserNotFound    = "user_not_found"
+	ReasonInvalidPassword = "invalid_password"
+	ReasonAccountDisabled = "account_disabled"
+	ReasonNotLinked       = "identity_not_linked"
+	ReasonInternalError   = "internal_error"
+)
+
+// LoginHistory 登录历史记录，成功与失败的登录尝试均会记录
+type LoginHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id"`
+	UserType  int       `json:"user_type"`
+	Username  string    `json:"username"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Device    string    `json:"device"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 返回数据库表名
+func (LoginHistory) TableName() string {
+	return "login_history"
+}
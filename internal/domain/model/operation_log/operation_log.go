@@ -0,0 +1,37 @@
+// Package operation_log_model 定义操作审计日志领域模型
+package operation_log_model
+
+import "time"
+
+const (
+	// OperationAPIKey 通过API密钥发起的请求
+	OperationAPIKey = "api_key"
+	// OperationImpersonate 管理员发起模拟登录
+	OperationImpersonate = "impersonate"
+	// OperationImpersonation 模拟登录期间以目标用户身份发起的请求
+	OperationImpersonation = "impersonation"
+	// OperationIPBlocked 客户端IP或所在地区被访问控制规则拒绝
+	OperationIPBlocked = "ip_blocked"
+)
+
+// OperationLog 操作日志
+type OperationLog struct {
+	ID        string `json:"id" gorm:"primaryKey"`
+	UserID    string `json:"user_id"`
+	UserType  int    `json:"user_type"`
+	Operation string `json:"operation"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Params    string `json:"params"`
+	IP        string `json:"ip"`
+	Status    int    `json:"status"`
+	// ActorID 模拟登录时实际操作的管理员ID，UserID为被模拟的用户
+	ActorID   string    `json:"actor_id,omitempty"`
+	ActorName string    `json:"actor_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 返回数据库表名
+func (OperationLog) TableName() string {
+	return "operation_logs"
+}
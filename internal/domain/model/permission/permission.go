@@ -0,0 +1,58 @@
+// Package permission_model 定义权限及用户权限关联领域模型
+package permission_model
+
+import "time"
+
+// 内置接口权限编码，对应schema.sql中初始化的权限数据
+const (
+	PermUserRead          = "user:read"
+	PermUserWrite         = "user:write"
+	PermOAuthClientManage = "oauth_client:manage"
+	PermPermissionManage  = "permission:manage"
+	PermSystemRead        = "system:read"
+	PermSystemWrite       = "system:write"
+	PermUserImpersonate   = "user:impersonate"
+	PermIPRuleManage      = "ip_rule:manage"
+)
+
+// ExplicitOnly 判断权限是否需要显式授予，管理员不会默认拥有此类权限
+func ExplicitOnly(code string) bool {
+	return code == PermUserImpersonate
+}
+
+// TypeAPI 接口类型权限
+const TypeAPI = 3
+
+// Permission 权限
+type Permission struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	Type      int       `json:"type"`
+	ParentID  string    `json:"parent_id"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	Remark    string    `json:"remark"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 返回数据库表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// UserPermission 用户与权限的关联，普通用户和管理员通过user_type区分
+type UserPermission struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	UserID       string    `json:"user_id"`
+	UserType     int       `json:"user_type"`
+	PermissionID string    `json:"permission_id"`
+	OperatorID   string    `json:"operator_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 返回数据库表名
+func (UserPermission) TableName() string {
+	return "user_permissions"
+}
@@ -0,0 +1,40 @@
+// Package session_model 定义登录会话领域模型
+package session_model
+
+import "time"
+
+const (
+	// RoleUser 普通用户会话
+	RoleUser = "user"
+	// RoleAdmin 管理员会话
+	RoleAdmin = "admin"
+)
+
+// Session 登录会话，每个会话对应一个有效的刷新令牌
+type Session struct {
+	ID             string    `json:"id"`
+	UserID         uint      `json:"user_id"`
+	Username       string    `json:"username"`
+	Role           string    `json:"role"`
+	Device         string    `json:"device"`
+	UserAgent      string    `json:"user_agent"`
+	IP             string    `json:"ip"`
+	Fingerprint    string    `json:"fingerprint,omitempty"`
+	RefreshTokenID string    `json:"refresh_token_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	// ActorID 模拟登录时为发起模拟的管理员ID，此类会话不签发刷新令牌
+	ActorID       uint   `json:"actor_id,omitempty"`
+	ActorUsername string `json:"actor_username,omitempty"`
+}
+
+// Expired 判断会话是否已过期
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.After(now)
+}
+
+// Impersonated 判断是否为管理员模拟登录创建的会话
+func (s *Session) Impersonated() bool {
+	return s.ActorID != 0
+}
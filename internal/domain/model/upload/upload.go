@@ -0,0 +1,71 @@
+// Package upload_model 定义分片上传任务与用户文件领域模型
+package upload_model
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+// Upload 进行中的分片上传，保存在Redis中，过期后由后台任务清理已上传的分片
+// 除最后一个分片外，每个分片的长度均为ChunkSize
+type Upload struct {
+	ID          string `json:"id"`
+	UserID      uint   `json:"user_id"`
+	UserType    int    `json:"user_type"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	ChunkSize   int64  `json:"chunk_size"`
+	TotalChunks int    `json:"total_chunks"`
+	// SHA256 客户端声明的整个文件的SHA-256摘要（十六进制）
+	SHA256    string    `json:"sha256"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ChunkLength 返回指定分片应有的长度
+func (u *Upload) ChunkLength(index int) int64 {
+	if index == u.TotalChunks-1 {
+		return u.Size - int64(index)*u.ChunkSize
+	}
+	return u.ChunkSize
+}
+
+// TempPrefix 分片在存储中的目录
+func (u *Upload) TempPrefix() string {
+	return TempPrefix(u.ID)
+}
+
+// ChunkKey 分片在存储中的对象键
+func (u *Upload) ChunkKey(index int) string {
+	return fmt.Sprintf("%s%06d", u.TempPrefix(), index)
+}
+
+// FileKey 合并后文件在存储中的对象键，仅保留原文件名的扩展名
+func (u *Upload) FileKey() string {
+	return fmt.Sprintf("files/%d/%d/%s%s", u.UserType, u.UserID, u.ID, path.Ext(u.Filename))
+}
+
+// TempPrefix 返回上传任务的分片目录，上传状态过期后仍可据此清理分片
+func TempPrefix(uploadID string) string {
+	return "tmp/uploads/" + uploadID + "/"
+}
+
+// File 上传完成的用户文件
+type File struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id"`
+	UserType    int       `json:"user_type"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256" gorm:"column:sha256"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 返回数据库表名
+func (File) TableName() string {
+	return "user_files"
+}
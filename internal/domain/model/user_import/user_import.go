@@ -0,0 +1,39 @@
+// Package user_import_model 定义普通用户批量导入任务领域模型
+package user_import_model
+
+import "time"
+
+// 导入任务状态
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// RowError 单行校验错误，Row为文件中的行号（含表头，从1开始）
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Job 导入任务，保存在Redis中供异步查询进度
+type Job struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Filename   string     `json:"filename"`
+	OperatorID uint       `json:"operator_id"`
+	Total      int        `json:"total"`
+	Valid      int        `json:"valid"`
+	Inserted   int        `json:"inserted"`
+	Errors     []RowError `json:"errors"`
+	Message    string     `json:"message,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished 判断任务是否已结束
+func (j *Job) Finished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed
+}
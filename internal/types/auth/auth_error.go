@@ -44,3 +44,35 @@ type LoginRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Password string `json:"password" validate:"required,min=8,max=72"`
 }
+
+// LoginMeta 登录时采集的客户端信息，用于建立会话
+type LoginMeta struct {
+	IP          string
+	UserAgent   string
+	Device      string
+	Fingerprint string
+}
+
+// APIKeyPrincipal 通过API密钥认证的调用主体
+type APIKeyPrincipal struct {
+	KeyID    uint
+	UserID   uint
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// ServiceAccountPrincipal 通过客户端证书认证的服务账号
+type ServiceAccountPrincipal struct {
+	Identity string
+	UserID   uint
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// Actor 模拟登录时的实际操作者，即发起模拟的管理员
+type Actor struct {
+	ID       uint
+	Username string
+}
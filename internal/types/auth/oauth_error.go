@@ -0,0 +1,46 @@
+package auth
+
+import "net/http"
+
+// OAuth2协议错误码(RFC 6749 5.2节)
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+	OAuthErrInvalidToken            = "invalid_token"
+	OAuthErrServerError             = "server_error"
+)
+
+// OAuthError 授权服务返回给客户端的协议错误
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// Status 返回错误对应的HTTP状态码
+func (e *OAuthError) Status() int {
+	switch e.Code {
+	case OAuthErrInvalidClient, OAuthErrInvalidToken:
+		return http.StatusUnauthorized
+	case OAuthErrAccessDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
@@ -7,11 +7,31 @@ import (
 )
 
 var (
-	ErrUserExists         = errors.New("用户已存在")
-	ErrUserNotFound       = errors.New("用户不存在")
-	ErrInvalidCredentials = errors.New("无效的凭证")
-	ErrUserInactive       = errors.New("账号已禁用")
-	ErrUnauthorized       = errors.New("未授权的访问")
+	ErrUserExists             = errors.New("用户已存在")
+	ErrUserNotFound           = errors.New("用户不存在")
+	ErrInvalidCredentials     = errors.New("无效的凭证")
+	ErrUserInactive           = errors.New("账号已禁用")
+	ErrUnauthorized           = errors.New("未授权的访问")
+	ErrSessionNotFound        = errors.New("会话不存在或已失效")
+	ErrIdentityNotLinked      = errors.New("第三方身份未关联账号")
+	ErrIdentityLinked         = errors.New("第三方身份已关联其他账号")
+	ErrInvalidOAuthState      = errors.New("授权请求无效或已过期")
+	ErrOAuthLinkMismatch      = errors.New("须由发起关联的用户完成关联")
+	ErrOAuthClientNotFound    = errors.New("OAuth客户端不存在")
+	ErrAPIKeyNotFound         = errors.New("API密钥不存在或已失效")
+	ErrServiceAccountNotFound = errors.New("客户端证书未映射到可用的服务账号")
+	ErrInvalidPermission      = errors.New("包含无效的权限编码")
+	ErrImportJobNotFound      = errors.New("导入任务不存在或已过期")
+	ErrInvalidImportFile      = errors.New("导入文件格式错误")
+	ErrUploadNotFound         = errors.New("上传任务不存在或已过期")
+	ErrFileNotFound           = errors.New("文件不存在")
+	ErrInvalidChunk           = errors.New("无效的分片")
+	ErrChecksumMismatch       = errors.New("校验和不匹配")
+	ErrUploadIncomplete       = errors.New("分片尚未全部上传")
+	ErrQuotaExceeded          = errors.New("存储空间不足")
+	ErrUploadBusy             = errors.New("正在创建其他上传任务，请稍后重试")
+	ErrIPRuleNotFound         = errors.New("访问控制规则不存在")
+	ErrInvalidIPRule          = errors.New("无效的访问控制规则")
 )
 
 const DefaultJWTSecret = "gin-center-default-secret"
@@ -0,0 +1,9 @@
+package structs
+
+// CreateAPIKeyRequest 创建API密钥或个人访问令牌的请求参数
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=64"`
+	Type          string   `json:"type" binding:"required,oneof=api_key personal_token"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,max=50"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,min=1,max=365"`
+}
@@ -19,6 +19,7 @@ type LoginRequest struct {
 	Username    string `json:"username" binding:"required,min=3,max=50"`
 	Password    string `json:"password" binding:"required,min=8,max=72"`
 	Fingerprint string `json:"fingerprint,omitempty"`
+	Device      string `json:"device,omitempty" binding:"omitempty,max=64"`
 }
 
 type RefreshTokenRequest struct {
@@ -0,0 +1,10 @@
+package structs
+
+// IPRuleRequest 添加访问控制规则的请求参数，kind为cidr时value为IP或CIDR，为country时为两位国家代码
+type IPRuleRequest struct {
+	Group  string `json:"group" binding:"required,oneof=admin"`
+	Action string `json:"action" binding:"required,oneof=allow deny"`
+	Kind   string `json:"kind" binding:"required,oneof=cidr country"`
+	Value  string `json:"value" binding:"required,max=64"`
+	Remark string `json:"remark" binding:"max=255"`
+}
@@ -0,0 +1,39 @@
+package structs
+
+// OAuthClientRequest 创建或更新授权服务客户端的请求参数
+type OAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=64"`
+	RedirectURIs []string `json:"redirect_uris" binding:"omitempty,dive,url,max=255"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code client_credentials"`
+	Scopes       []string `json:"scopes" binding:"omitempty,dive,oneof=openid profile"`
+	Confidential bool     `json:"confidential"`
+	Status       *int     `json:"status,omitempty" binding:"omitempty,oneof=0 1"`
+}
+
+// AuthorizeRequest 授权端点请求参数，GET时来自查询参数，POST时来自JSON
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	// Approve 用户是否同意授权，仅POST时有效
+	Approve bool `form:"-" json:"approve"`
+}
+
+// OAuthTokenRequest 令牌端点请求参数(application/x-www-form-urlencoded)
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	Scope        string `form:"scope"`
+}
+
+// OAuthTokenActionRequest 令牌内省与撤销的请求参数
+type OAuthTokenActionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+}
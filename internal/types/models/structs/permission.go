@@ -0,0 +1,6 @@
+package structs
+
+// SetPermissionsRequest 设置用户权限的请求参数
+type SetPermissionsRequest struct {
+	Codes []string `json:"codes" binding:"omitempty,dive,max=50"`
+}
@@ -0,0 +1,15 @@
+package structs
+
+// CreateUploadRequest 创建分片上传任务的请求参数，SHA256为整个文件的十六进制摘要
+type CreateUploadRequest struct {
+	Filename    string `json:"filename" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"omitempty,max=100"`
+	Size        int64  `json:"size" binding:"required,min=1"`
+	SHA256      string `json:"sha256" binding:"required,len=64,hexadecimal"`
+}
+
+// FileQuery 查询用户文件的分页参数
+type FileQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=10" binding:"min=1,max=100"`
+}
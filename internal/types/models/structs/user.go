@@ -0,0 +1,35 @@
+package structs
+
+// UserQuery 管理端分页查询普通用户的参数
+type UserQuery struct {
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"page_size,default=10" binding:"min=1,max=100"`
+	Keyword  string `form:"keyword" binding:"omitempty,max=32"`
+	Status   *int   `form:"status" binding:"omitempty,oneof=0 1"`
+}
+
+// CreateUserRequest 管理员创建普通用户的请求参数
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=32"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Nickname string `json:"nickname" binding:"omitempty,max=32"`
+	Phone    string `json:"phone" binding:"omitempty,len=11,numeric"`
+	Status   *int   `json:"status,omitempty" binding:"omitempty,oneof=0 1"`
+}
+
+// UpdateUserStatusRequest 启用或禁用用户的请求参数
+type UpdateUserStatusRequest struct {
+	Status *int `json:"status" binding:"required,oneof=0 1"`
+}
+
+// ResetPasswordRequest 管理员重置用户密码的请求参数
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// UserExportQuery 导出普通用户的参数，筛选条件与列表查询一致
+type UserExportQuery struct {
+	Format  string `form:"format,default=csv" binding:"oneof=csv xlsx"`
+	Keyword string `form:"keyword" binding:"omitempty,max=32"`
+	Status  *int   `form:"status" binding:"omitempty,oneof=0 1"`
+}
@@ -26,6 +26,7 @@ type UserResponse struct {
 	Nickname    string     `json:"nickname"`
 	Avatar      string     `json:"avatar"`
 	Phone       string     `json:"phone" validate:"omitempty,len=11"`
+	Status      int        `json:"status"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
 	LastLoginIP string     `json:"last_login_ip,omitempty"`
 	CreatedAt   time.Time  `json:"created_at" validate:"required"`
@@ -35,6 +36,33 @@ type UserListResponse struct {
 	ListResponse
 	Items []UserResponse `json:"items"`
 }
+
+// AvatarResponse 头像上传结果，variants键为original或缩略图边长
+type AvatarResponse struct {
+	AvatarURL string            `json:"avatar_url"`
+	Variants  map[string]string `json:"variants"`
+}
+
+// AdminResponse 管理员信息
+type AdminResponse struct {
+	ID          uint      `json:"id"`
+	Username    string    `json:"username"`
+	Nickname    string    `json:"nickname"`
+	Avatar      string    `json:"avatar"`
+	Status      int       `json:"status"`
+	IsAdmin     int       `json:"is_admin"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	LastLoginIP string    `json:"last_login_ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AdminListResponse 管理员分页列表
+type AdminListResponse struct {
+	ListResponse
+	Items []AdminResponse `json:"items"`
+}
+
 type UpdateUserProfileRequest struct {
 	Nickname string `json:"nickname" binding:"required" validate:"required,min=2,max=32"`
 }
@@ -42,3 +70,220 @@ type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required" validate:"required,min=6"`
 	NewPassword string `json:"new_password" binding:"required" validate:"required,min=6"`
 }
+
+// SessionResponse 登录会话信息
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+	// ImpersonatedBy 管理员模拟登录创建的会话对应的管理员用户名
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
+}
+
+// OnlineUserResponse 在线用户信息，按用户聚合其活跃会话
+type OnlineUserResponse struct {
+	UserID       uint      `json:"user_id"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	SessionCount int       `json:"session_count"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	IP           string    `json:"ip"`
+	Device       string    `json:"device"`
+}
+
+// LoginHistoryResponse 登录历史记录
+type LoginHistoryResponse struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Device    string    `json:"device"`
+	CreatedAt time.Time `json:"created_at"`
+}
+type LoginHistoryListResponse struct {
+	ListResponse
+	Items []LoginHistoryResponse `json:"items"`
+}
+
+// OAuthProviderResponse 可用的第三方登录提供方
+type OAuthProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// IdentityResponse 已关联的第三方身份
+type IdentityResponse struct {
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OAuthClientResponse 授权服务客户端信息，ClientSecret仅在创建和重置密钥时返回一次
+type OAuthClientResponse struct {
+	ID           uint      `json:"id"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	Status       int       `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+type OAuthClientListResponse struct {
+	ListResponse
+	Items []OAuthClientResponse `json:"items"`
+}
+
+// AuthorizationPreviewResponse 授权确认页展示的客户端信息
+type AuthorizationPreviewResponse struct {
+	ClientID string   `json:"client_id"`
+	Name     string   `json:"name"`
+	Scopes   []string `json:"scopes"`
+}
+
+// OAuthTokenResponse 令牌端点响应(RFC 6749 5.1节)
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// IntrospectionResponse 令牌内省响应(RFC 7662 2.2节)
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	Role      string `json:"role,omitempty"`
+}
+
+// UserInfoResponse 用户信息端点响应
+type UserInfoResponse struct {
+	Sub               string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Role              string `json:"role"`
+	Nickname          string `json:"nickname,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+}
+
+// PermissionResponse 权限信息
+type PermissionResponse struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`
+	Remark string `json:"remark,omitempty"`
+}
+
+// APIKeyResponse API密钥信息，不包含明文
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse 新建密钥的响应，Token为明文，之后无法再次获取
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Token string `json:"token"`
+}
+
+// ActorResponse 模拟登录的实际操作者
+type ActorResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// ImpersonationResponse 模拟登录签发的访问令牌，不包含刷新令牌
+type ImpersonationResponse struct {
+	AccessToken string        `json:"access_token"`
+	ExpiresIn   int64         `json:"expires_in"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	User        UserResponse  `json:"user"`
+	Actor       ActorResponse `json:"actor"`
+}
+
+// WhoAmIResponse 当前请求的认证主体，Actor非空表示处于模拟登录状态，可据此展示提示横幅
+type WhoAmIResponse struct {
+	UserID     uint           `json:"user_id"`
+	Username   string         `json:"username"`
+	Role       string         `json:"role"`
+	AuthMethod string         `json:"auth_method"`
+	Actor      *ActorResponse `json:"actor,omitempty"`
+}
+
+// UserImportRowError 导入文件中单行的校验错误，Row为文件行号（含表头）
+type UserImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// UserImportResponse 导入结果或异步导入任务的进度，预检时JobID为空
+type UserImportResponse struct {
+	JobID      string               `json:"job_id,omitempty"`
+	Status     string               `json:"status"`
+	DryRun     bool                 `json:"dry_run"`
+	Filename   string               `json:"filename"`
+	Total      int                  `json:"total"`
+	Valid      int                  `json:"valid"`
+	Invalid    int                  `json:"invalid"`
+	Inserted   int                  `json:"inserted"`
+	Errors     []UserImportRowError `json:"errors"`
+	Message    string               `json:"message,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+}
+
+// UploadResponse 分片上传任务状态，ReceivedChunks用于断点续传时跳过已上传的分片
+type UploadResponse struct {
+	ID             string    `json:"id"`
+	Filename       string    `json:"filename"`
+	Size           int64     `json:"size"`
+	ChunkSize      int64     `json:"chunk_size"`
+	TotalChunks    int       `json:"total_chunks"`
+	ReceivedChunks []int     `json:"received_chunks"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// FileResponse 用户文件信息，URL为带有效期的下载地址
+type FileResponse struct {
+	ID          uint      `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	URL         string    `json:"url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FileListResponse 用户文件列表，附带存储配额使用情况
+type FileListResponse struct {
+	ListResponse
+	Items     []FileResponse `json:"items"`
+	UsedBytes int64          `json:"used_bytes"`
+	Quota     int64          `json:"quota"`
+}
@@ -0,0 +1,6 @@
+package system
+
+// LogLevel 运行时日志级别
+type LogLevel struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error"`
+}
@@ -3,6 +3,8 @@
 package main
 
 import (
+	"os"
+
 	"gin-center/infrastructure/bootstrap"
 	use_routes "gin-center/web/routes"
 
@@ -10,26 +12,27 @@ import (
 )
 
 // main 函数是应用程序的入口点
-// 负责初始化应用、设置路由、启动服务器并在程序结束时进行清理
+// 负责初始化应用、设置路由、运行服务器直至收到停止信号，并在程序结束时进行清理
 func main() {
 	// 初始化应用程序
 	app, err := bootstrap.InitializeApp()
 	if err != nil {
 		panic(err)
 	}
-	// 确保在程序结束时进行资源清理
-	defer app.Cleanup()
 
 	// 设置路由
-	use_routes.SetupRoutes(app.Engine, app.Container)
-
-	// 启动HTTP服务器
-	if err := app.Server.Start(app.Engine); err != nil {
-		zap.L().Fatal("Server error", zap.Error(err))
+	if err := use_routes.SetupRoutes(app.Engine, app.Container); err != nil {
+		app.Cleanup()
+		panic(err)
 	}
 
-	// 优雅关闭服务器
-	if err := app.Server.Shutdown(app.Context); err != nil {
-		zap.L().Fatal("Server shutdown error", zap.Error(err))
+	// 运行HTTP服务器，收到停止信号后按顺序关闭各组件
+	err = app.Run()
+	if err != nil {
+		zap.L().Error("Server error", zap.Error(err))
+	}
+	app.Cleanup()
+	if err != nil {
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/shirou/gopsutil/v3/disk"
+	"gorm.io/gorm"
+)
+
+// DatabaseCheck 通过连接池ping数据库
+func DatabaseCheck(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// RedisCheck ping Redis
+func RedisCheck(client *redis.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// DiskCheck 检查path所在分区的剩余空间不低于minFreeMB
+func DiskCheck(path string, minFreeMB uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		usage, err := disk.UsageWithContext(ctx, path)
+		if err != nil {
+			return err
+		}
+		freeMB := usage.Free / 1024 / 1024
+		if freeMB < minFreeMB {
+			return fmt.Errorf("磁盘剩余空间不足: %dMB，下限%dMB", freeMB, minFreeMB)
+		}
+		return nil
+	}
+}
+
+var createTablePattern = regexp.MustCompile("(?i)CREATE\\s+TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?`?(\\w+)`?")
+
+// SchemaCheck 检查建表脚本中的表在当前数据库中均已存在
+// 脚本在创建检查时读取一次，读取失败返回错误
+func SchemaCheck(db *gorm.DB, schemaFile string) (CheckFunc, error) {
+	content, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取建表脚本失败: %w", err)
+	}
+	var tables []string
+	for _, match := range createTablePattern.FindAllStringSubmatch(string(content), -1) {
+		tables = append(tables, match[1])
+	}
+	return func(ctx context.Context) error {
+		var existing []string
+		err := db.WithContext(ctx).
+			Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()").
+			Scan(&existing).Error
+		if err != nil {
+			return err
+		}
+		found := make(map[string]bool, len(existing))
+		for _, name := range existing {
+			found[strings.ToLower(name)] = true
+		}
+		var missing []string
+		for _, table := range tables {
+			if !found[strings.ToLower(table)] {
+				missing = append(missing, table)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("缺少数据表: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,211 @@
+// Package health 健康检查注册表，供存活探针与就绪探针使用
+// 每项检查有独立的超时时间，结果在缓存有效期内复用，避免探针频繁访问依赖
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 检查状态
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Config 健康检查配置
+type Config struct {
+	// Timeout 单项检查的默认超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CacheTTL 检查结果的缓存时间，为0时每次探测都重新检查
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// DiskPath 检查剩余空间的目录，为空时不检查磁盘
+	DiskPath string `mapstructure:"disk_path"`
+	// DiskMinFreeMB 磁盘剩余空间下限（MB）
+	DiskMinFreeMB uint64 `mapstructure:"disk_min_free_mb"`
+	// SchemaFile 建表脚本，检查其中的表是否均已创建，为空时不检查
+	SchemaFile string `mapstructure:"schema_file"`
+}
+
+// CheckFunc 执行一项检查，返回nil表示正常
+type CheckFunc func(ctx context.Context) error
+
+// Result 单项检查结果
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report 就绪检查结果
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Healthy 全部检查通过且未在关闭中
+func (r *Report) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+type check struct {
+	name    string
+	fn      CheckFunc
+	timeout time.Duration
+
+	mu     sync.Mutex
+	result *Result
+	err    error
+}
+
+// Registry 具名健康检查的集合
+type Registry struct {
+	timeout      time.Duration
+	cacheTTL     time.Duration
+	mu           sync.RWMutex
+	checks       []*check
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry 创建健康检查注册表，未配置超时时默认2秒
+func NewRegistry(cfg *Config) *Registry {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Registry{timeout: timeout, cacheTTL: cfg.CacheTTL}
+}
+
+// Register 注册一项检查，timeout为0时使用注册表的默认超时；同名检查会被替换
+func (r *Registry) Register(name string, timeout time.Duration, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = r.timeout
+	}
+	c := &check{name: name, fn: fn, timeout: timeout}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.checks {
+		if existing.name == name {
+			r.checks[i] = c
+			return
+		}
+	}
+	r.checks = append(r.checks, c)
+}
+
+// Names 返回已注册检查的名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for _, c := range r.checks {
+		names = append(names, c.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetShuttingDown 标记服务正在关闭，此后就绪检查始终失败，负载均衡据此摘除实例
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// ShuttingDown 服务是否正在关闭
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// Ready 并发执行全部检查，任一检查失败或服务正在关闭时返回的Report不健康
+func (r *Registry) Ready(ctx context.Context) *Report {
+	if r.ShuttingDown() {
+		return &Report{Status: StatusShuttingDown}
+	}
+	r.mu.RLock()
+	checks := append([]*check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i], _ = c.run(ctx, r.cacheTTL)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Check 执行指定名称的检查，返回nil表示正常；未注册的名称返回错误
+func (r *Registry) Check(ctx context.Context, name string) error {
+	r.mu.RLock()
+	var target *check
+	for _, c := range r.checks {
+		if c.name == name {
+			target = c
+			break
+		}
+	}
+	r.mu.RUnlock()
+	if target == nil {
+		return errors.New("未注册的健康检查: " + name)
+	}
+	_, err := target.run(ctx, r.cacheTTL)
+	return err
+}
+
+// run 缓存未过期时直接返回上次结果；同一检查同时只执行一次，其余调用等待其结果
+func (c *check) run(ctx context.Context, ttl time.Duration) (Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.result != nil && ttl > 0 && time.Since(c.result.CheckedAt) < ttl {
+		return *c.result, c.err
+	}
+
+	// 结果会被缓存并被其他探测复用，不随发起本次探测的请求一同取消
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+	start := time.Now()
+	err := c.call(checkCtx)
+	result := Result{
+		Status:    StatusUp,
+		Duration:  time.Since(start).Round(time.Microsecond).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	c.result = &result
+	c.err = err
+	return result, err
+}
+
+// call 检查函数未响应上下文取消时，超时后不再等待其返回
+func (c *check) call(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
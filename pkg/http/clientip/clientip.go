@@ -0,0 +1,185 @@
+// Package clientip 根据可信代理解析真实客户端IP
+// 登录记录、审计日志、访问日志等均通过Get或FromContext取得同一个结果，避免各处解析方式不一致
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 支持的转发头
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+	// HeaderForwarded RFC 7239定义的Forwarded头，取其中的for参数
+	HeaderForwarded = "Forwarded"
+)
+
+// Config 客户端IP解析配置
+type Config struct {
+	// TrustedProxies 可信代理的地址或地址段，只有来自这些地址的请求才读取转发头，为空时直接使用连接地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// Header 读取的转发头，默认X-Forwarded-For
+	Header string `mapstructure:"header" validate:"omitempty,oneof=X-Forwarded-For X-Real-IP Forwarded"`
+}
+
+// ginKey 解析结果在gin上下文中的键
+const ginKey = "client_ip"
+
+type contextKey struct{}
+
+// Resolver 客户端IP解析器
+type Resolver struct {
+	header  string
+	trusted []*net.IPNet
+}
+
+// NewResolver 创建解析器，可信代理配置有误时返回错误
+func NewResolver(cfg *Config) (*Resolver, error) {
+	header := cfg.Header
+	if header == "" {
+		header = HeaderXForwardedFor
+	}
+	switch header {
+	case HeaderXForwardedFor, HeaderXRealIP, HeaderForwarded:
+	default:
+		return nil, fmt.Errorf("不支持的客户端IP转发头: %s", header)
+	}
+	r := &Resolver{header: header}
+	for _, proxy := range cfg.TrustedProxies {
+		cidr := proxy
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的可信代理地址: %s", proxy)
+		}
+		r.trusted = append(r.trusted, network)
+	}
+	return r, nil
+}
+
+// Apply 让gin的ClientIP与解析器采用同样的可信代理，并注册记录解析结果的中间件
+// gin不支持Forwarded头，此时gin的ClientIP只返回连接地址，业务代码应使用Get
+func (r *Resolver) Apply(engine *gin.Engine) error {
+	proxies := make([]string, 0, len(r.trusted))
+	for _, network := range r.trusted {
+		proxies = append(proxies, network.String())
+	}
+	if err := engine.SetTrustedProxies(proxies); err != nil {
+		return err
+	}
+	engine.RemoteIPHeaders = []string{r.header}
+	engine.Use(r.middleware)
+	return nil
+}
+
+func (r *Resolver) middleware(c *gin.Context) {
+	ip := r.Resolve(c.Request)
+	c.Set(ginKey, ip)
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, ip))
+	c.Next()
+}
+
+// Resolve 连接来自可信代理时，从转发头中由右向左取第一个非可信代理的地址；否则返回连接地址
+// 配置了可信代理时，经Unix域套接字接入的连接视为来自本机代理
+func (r *Resolver) Resolve(req *http.Request) string {
+	remote := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	remoteIP := net.ParseIP(remote)
+	if remoteIP != nil && !r.isTrusted(remoteIP) {
+		return remote
+	}
+	if remoteIP == nil && len(r.trusted) == 0 {
+		return remote
+	}
+
+	var hops []string
+	switch r.header {
+	case HeaderXRealIP:
+		hops = []string{strings.TrimSpace(req.Header.Get(HeaderXRealIP))}
+	case HeaderForwarded:
+		hops = forwardedFor(req.Header.Values(HeaderForwarded))
+	default:
+		for _, value := range req.Header.Values(HeaderXForwardedFor) {
+			for _, hop := range strings.Split(value, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// 无法识别的地址之前的内容不可信
+			break
+		}
+		client = ip.String()
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return client
+}
+
+func (r *Resolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor 按顺序取出Forwarded头中各节点的for参数，去掉引号、方括号与端口
+// unknown与混淆标识无法解析为IP，调用方会在此处停止
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			node := ""
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					node = strings.Trim(val, `"`)
+				}
+			}
+			if strings.HasPrefix(node, "[") {
+				if end := strings.Index(node, "]"); end > 0 {
+					node = node[1:end]
+				}
+			} else if host, _, err := net.SplitHostPort(node); err == nil {
+				node = host
+			}
+			hops = append(hops, node)
+		}
+	}
+	return hops
+}
+
+// Get 返回当前请求的客户端IP，未经过解析中间件时退回gin的ClientIP
+func Get(c *gin.Context) string {
+	if ip := c.GetString(ginKey); ip != "" {
+		return ip
+	}
+	return c.ClientIP()
+}
+
+// FromContext 从请求上下文中取得客户端IP，供服务层使用，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
@@ -0,0 +1,154 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		trusted []string
+		remote  string
+		values  []string
+		want    string
+	}{
+		{
+			name:   "未配置可信代理时忽略转发头",
+			remote: "203.0.113.7:5000",
+			values: []string{"198.51.100.1"},
+			want:   "203.0.113.7",
+		},
+		{
+			name:    "连接不来自可信代理时忽略转发头",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "203.0.113.7:5000",
+			values:  []string{"198.51.100.1"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "取最右侧非可信代理的地址",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.1:5000",
+			values:  []string{"1.1.1.1, 198.51.100.1, 10.0.0.2"},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "多个转发头按顺序拼接",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.1:5000",
+			values:  []string{"1.1.1.1", "198.51.100.1, 10.0.0.2"},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "全部为可信代理时取最左侧地址",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.1:5000",
+			values:  []string{"10.0.0.3, 10.0.0.2"},
+			want:    "10.0.0.3",
+		},
+		{
+			name:    "遇到无法解析的地址时停止",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.1:5000",
+			values:  []string{"198.51.100.1, garbage, 10.0.0.2"},
+			want:    "10.0.0.2",
+		},
+		{
+			name:    "缺少转发头时使用连接地址",
+			trusted: []string{"10.0.0.1"},
+			remote:  "10.0.0.1:5000",
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "X-Real-IP",
+			header:  HeaderXRealIP,
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.1:5000",
+			values:  []string{" 198.51.100.1 "},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "Forwarded",
+			header:  HeaderForwarded,
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.1:5000",
+			values:  []string{`for=198.51.100.1;proto=https, for="[2001:db8::1]:4711"`},
+			want:    "2001:db8::1",
+		},
+		{
+			name:    "Unix域套接字视为本机代理",
+			trusted: []string{"127.0.0.1"},
+			remote:  "@",
+			values:  []string{"198.51.100.1"},
+			want:    "198.51.100.1",
+		},
+		{
+			name:   "未配置可信代理时Unix域套接字不读取转发头",
+			remote: "@",
+			values: []string{"198.51.100.1"},
+			want:   "@",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver(&Config{Header: tt.header, TrustedProxies: tt.trusted})
+			if err != nil {
+				t.Fatalf("NewResolver() error = %v", err)
+			}
+			header := r.header
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, value := range tt.values {
+				req.Header.Add(header, value)
+			}
+			if got := r.Resolve(req); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolverRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "不支持的转发头", cfg: Config{Header: "X-Client-IP"}},
+		{name: "无效的可信代理", cfg: Config{TrustedProxies: []string{"10.0.0.0/33"}}},
+		{name: "非IP地址", cfg: Config{TrustedProxies: []string{"proxy.local"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewResolver(&tt.cfg); err == nil {
+				t.Error("NewResolver() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestForwardedFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{name: "IPv4", values: []string{"for=192.0.2.60"}, want: []string{"192.0.2.60"}},
+		{name: "带端口", values: []string{`for="192.0.2.60:8080"`}, want: []string{"192.0.2.60"}},
+		{name: "IPv6", values: []string{`for="[2001:db8:cafe::17]:4711"`}, want: []string{"2001:db8:cafe::17"}},
+		{name: "参数名不区分大小写", values: []string{"proto=http;For=192.0.2.43;by=203.0.113.43"}, want: []string{"192.0.2.43"}},
+		{name: "多个节点", values: []string{"for=192.0.2.43, for=198.51.100.17"}, want: []string{"192.0.2.43", "198.51.100.17"}},
+		{name: "多个头", values: []string{"for=192.0.2.43", "for=198.51.100.17"}, want: []string{"192.0.2.43", "198.51.100.17"}},
+		{name: "unknown与混淆标识原样返回", values: []string{"for=unknown, for=_hidden"}, want: []string{"unknown", "_hidden"}},
+		{name: "缺少for参数", values: []string{"proto=https"}, want: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forwardedFor(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("forwardedFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
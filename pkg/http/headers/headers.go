@@ -2,6 +2,8 @@
 package use_headers
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,6 +14,9 @@ const (
 	Accept          = "Accept"
 	XRequestID      = "X-Request-ID"
 	XTraceID        = "X-Trace-ID"
+	XAPIKey         = "X-API-Key"
+	XImpersonator   = "X-Impersonator"
+	XChunkSHA256    = "X-Chunk-SHA256"
 	BearerPrefix    = "Bearer "
 	ApplicationJSON = "application/json"
 	ApplicationForm = "application/x-www-form-urlencoded"
@@ -26,6 +31,11 @@ func GetAuthorizationToken(c *gin.Context) string {
 	return ""
 }
 
+// GetAPIKey 从X-API-Key请求头中获取API密钥
+func GetAPIKey(c *gin.Context) string {
+	return strings.TrimSpace(c.GetHeader(XAPIKey))
+}
+
 // SetTraceHeaders 设置追踪相关的响应头
 func SetTraceHeaders(c *gin.Context, requestID, traceID string) {
 	if requestID != "" {
@@ -46,3 +56,42 @@ func GetRequestHeaders(c *gin.Context) map[string]string {
 	}
 	return headers
 }
+
+// DetectDevice 根据User-Agent粗略识别设备类型，如"Chrome on Windows"
+func DetectDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	var platform string
+	switch {
+	case ua == "":
+		return "unknown"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		platform = "iOS"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	default:
+		platform = "Other"
+	}
+
+	var client string
+	switch {
+	case strings.Contains(ua, "edg/"):
+		client = "Edge"
+	case strings.Contains(ua, "chrome/"):
+		client = "Chrome"
+	case strings.Contains(ua, "firefox/"):
+		client = "Firefox"
+	case strings.Contains(ua, "safari/"):
+		client = "Safari"
+	case strings.Contains(ua, "curl/"), strings.Contains(ua, "postman"), strings.Contains(ua, "okhttp"):
+		client = "API Client"
+	default:
+		return platform
+	}
+	return client + " on " + platform
+}
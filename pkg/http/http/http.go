@@ -3,113 +3,205 @@ package use_http
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"sync"
+	"sync/atomic"
 
 	use_config "gin-center/configs/config"
 
-	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // HTTPServer 定义HTTP服务器的接口
 type HTTPServer interface {
-	// Start 启动HTTP服务器
-	Start(router *gin.Engine) error
-	// Shutdown 优雅关闭HTTP服务器
+	// Start 监听端口并处理请求，阻塞至服务器关闭；经Shutdown或Close关闭时返回nil
+	Start(handler http.Handler) error
+	// Shutdown 停止接收新连接并等待进行中的请求完成，ctx到期时返回ctx的错误
 	Shutdown(ctx context.Context) error
+	// Close 立即关闭所有连接，用于优雅关闭超时后的兜底
+	Close() error
 }
 
 // httpServer 实现HTTPServer接口的服务器结构体
 type httpServer struct {
 	config *use_config.ServerConfig
-	server *http.Server
 	logger *zap.Logger
+
+	mu sync.Mutex
+	// servers 主服务器，启用HTTPS重定向时另有重定向服务器
+	servers []*http.Server
+	// closed Start之前已调用Shutdown或Close时，Start不再开始服务
+	closed bool
 }
 
 // NewHTTPServer 创建一个新的HTTP服务器实例
-func NewHTTPServer(
-	port int,
-	readTimeout, writeTimeout, idleTimeout, readHeaderTimeout, shutdownTimeout time.Duration,
-	maxHeaderBytes, maxRequestsPerConn int,
-) HTTPServer {
-	cfg := &use_config.ServerConfig{
-		Port:               port,
-		ReadTimeout:        readTimeout,
-		WriteTimeout:       writeTimeout,
-		IdleTimeout:        idleTimeout,
-		ReadHeaderTimeout:  readHeaderTimeout,
-		ShutdownTimeout:    shutdownTimeout,
-		MaxHeaderBytes:     maxHeaderBytes,
-		MaxRequestsPerConn: maxRequestsPerConn,
-	}
-
+func NewHTTPServer(cfg *use_config.ServerConfig) HTTPServer {
 	return &httpServer{
 		config: cfg,
 		logger: zap.L(),
 	}
 }
 
+// connRequestsKey 连接上下文中记录已处理请求数的键
+type connRequestsKey struct{}
+
 // Start 实现HTTPServer接口的Start方法
-func (s *httpServer) Start(router *gin.Engine) error {
-	// 配置HTTP服务器
-	s.server = &http.Server{
+// 主服务器的连接先解析PROXY头再按客户端IP限制连接数，最后进行TLS握手
+// 启用TLS时主端口只接受HTTPS，证书文件变化后自动重新加载；任一服务器异常退出时关闭全部服务器并返回错误
+func (s *httpServer) Start(handler http.Handler) error {
+	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", s.config.Port),
-		Handler:           router,
+		Handler:           s.limitRequestsPerConn(handler),
 		ReadTimeout:       s.config.ReadTimeout,
 		WriteTimeout:      s.config.WriteTimeout,
 		IdleTimeout:       s.config.IdleTimeout,
 		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
 		MaxHeaderBytes:    s.config.MaxHeaderBytes,
+		ConnContext: func(ctx context.Context, _ net.Conn) context.Context {
+			return context.WithValue(ctx, connRequestsKey{}, new(atomic.Int64))
+		},
 	}
 
-	// 异步启动服务器
-	go s.startServer()
-	return nil
-}
-
-// startServer 启动HTTP服务器并处理错误
-func (s *httpServer) startServer() {
-	s.logger.Info("服务器正在启动", zap.Int("端口", s.config.Port))
+	tlsCfg := &s.config.TLS
+	var reloader *tlsReloader
+	if tlsCfg.Enabled {
+		var err error
+		if reloader, err = newTLSReloader(tlsCfg, s.logger); err != nil {
+			return err
+		}
+		server.TLSConfig = reloader.serverConfig()
+		if tlsCfg.DisableHTTP2 {
+			// 非nil的空映射阻止http.Server自动启用HTTP/2
+			server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		}
+	}
 
-	// 监听系统信号
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	servers := []*http.Server{server}
+	if tlsCfg.Enabled && tlsCfg.RedirectPort > 0 {
+		servers = append(servers, &http.Server{
+			Addr:              fmt.Sprintf(":%d", tlsCfg.RedirectPort),
+			Handler:           redirectHandler(s.config.Port),
+			ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+			IdleTimeout:       s.config.IdleTimeout,
+		})
+	}
 
-	// 启动服务器
-	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Error("服务器启动失败", zap.Error(err))
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	listeners := make([]net.Listener, 0, len(servers))
+	for i, srv := range servers {
+		var listener net.Listener
+		var err error
+		if i == 0 {
+			listener, err = s.listen()
+		} else {
+			listener, err = net.Listen("tcp", srv.Addr)
 		}
-	}()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			s.mu.Unlock()
+			return fmt.Errorf("监听端口失败: %w", err)
+		}
+		listeners = append(listeners, listener)
+	}
+	s.servers = servers
+	s.mu.Unlock()
 
-	// 等待关闭信号
-	sig := <-sigChan
-	s.logger.Info("收到关闭信号", zap.String("信号", sig.String()))
+	if reloader != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go reloader.watch(stop)
+	}
 
-	// 执行关闭操作
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
-	defer cancel()
-	if err := s.server.Shutdown(ctx); err != nil {
-		s.logger.Error("服务器关闭失败", zap.Error(err))
+	results := make(chan error, len(servers))
+	for i, srv := range servers {
+		go func(srv *http.Server, listener net.Listener, useTLS bool) {
+			var err error
+			if useTLS {
+				err = srv.ServeTLS(listener, "", "")
+			} else {
+				err = srv.Serve(listener)
+			}
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			results <- err
+		}(srv, listeners[i], i == 0 && tlsCfg.Enabled)
+	}
+	s.logger.Info("服务器已启动",
+		zap.String("地址", listeners[0].Addr().String()),
+		zap.Bool("tls", tlsCfg.Enabled),
+		zap.Bool("proxy_protocol", s.config.ProxyProtocol.Enabled),
+		zap.Int("重定向端口", tlsCfg.RedirectPort))
+
+	var serveErr error
+	for range servers {
+		if err := <-results; err != nil && serveErr == nil {
+			serveErr = fmt.Errorf("服务器异常退出: %w", err)
+			_ = s.Close()
+		}
 	}
+	return serveErr
 }
 
 // Shutdown 实现HTTPServer接口的Shutdown方法
 func (s *httpServer) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
-}
-
-// shutdownServer 执行服务器关闭操作
-func (s *httpServer) shutdownServer(ctx context.Context) error {
-	s.logger.Info("正在关闭服务器...")
-	if err := s.server.Shutdown(ctx); err != nil {
-		s.logger.Error("服务器关闭失败", zap.Error(err))
+	servers := s.markClosed()
+	if len(servers) == 0 {
+		return nil
+	}
+	s.logger.Info("正在关闭服务器，等待进行中的请求完成")
+	var errs []error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
 		return fmt.Errorf("服务器关闭失败: %w", err)
 	}
 	return nil
 }
+
+// Close 实现HTTPServer接口的Close方法
+func (s *httpServer) Close() error {
+	var errs []error
+	for _, server := range s.markClosed() {
+		if err := server.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// markClosed 标记服务器已关闭，返回正在运行的服务器，尚未启动时返回nil
+func (s *httpServer) markClosed() []*http.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.servers
+}
+
+// limitRequestsPerConn 连接处理的请求数达到MaxRequestsPerConn时，在响应中要求客户端关闭连接
+// 使长连接定期重建，负载均衡能在实例间重新分配连接；HTTP/2连接会收到GOAWAY
+func (s *httpServer) limitRequestsPerConn(next http.Handler) http.Handler {
+	limit := int64(s.config.MaxRequestsPerConn)
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served, ok := r.Context().Value(connRequestsKey{}).(*atomic.Int64); ok && served.Add(1) >= limit {
+			w.Header().Set("Connection", "close")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
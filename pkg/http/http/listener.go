@@ -0,0 +1,179 @@
+package use_http
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	use_config "gin-center/configs/config"
+
+	"github.com/pires/go-proxyproto"
+	"go.uber.org/zap"
+)
+
+// errTooManyConns 客户端IP的连接数已达上限
+var errTooManyConns = errors.New("客户端连接数超过上限")
+
+// listen 按配置监听TCP端口或Unix域套接字，依次叠加PROXY协议解析与连接数限制
+func (s *httpServer) listen() (net.Listener, error) {
+	network, address := "tcp", fmt.Sprintf(":%d", s.config.Port)
+	if s.config.UnixSocket != "" {
+		network, address = "unix", s.config.UnixSocket
+		// 上次异常退出遗留的套接字文件会导致监听失败
+		if info, err := os.Stat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(address)
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if err := os.Chmod(address, 0660); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("设置套接字权限失败: %w", err)
+		}
+	}
+
+	if proxy := &s.config.ProxyProtocol; proxy.Enabled {
+		policy, err := proxyPolicy(proxy, network == "unix")
+		if err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+		listener = &proxyproto.Listener{
+			Listener:          listener,
+			Policy:            policy,
+			ReadHeaderTimeout: proxy.ReadHeaderTimeout,
+		}
+	}
+	return &limitListener{
+		Listener: listener,
+		maxPerIP: s.config.MaxConnsPerIP,
+		conns:    map[string]int{},
+		logger:   s.logger,
+	}, nil
+}
+
+// proxyPolicy 可信来源的PROXY头生效，其他来源发送PROXY头时拒绝连接；Unix域套接字的连接视为可信
+// 监听TCP端口时必须配置可信地址段，否则任何客户端都能以PROXY头伪造来源IP
+func proxyPolicy(cfg *use_config.ProxyProtocolConfig, unixSocket bool) (proxyproto.PolicyFunc, error) {
+	if len(cfg.TrustedCIDRs) == 0 && !unixSocket {
+		return nil, errors.New("启用PROXY协议时必须配置trusted_cidrs")
+	}
+	trusted := make([]*net.IPNet, 0, len(cfg.TrustedCIDRs))
+	for _, cidr := range cfg.TrustedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的PROXY协议可信地址段: %s", cidr)
+		}
+		trusted = append(trusted, network)
+	}
+	allowed := proxyproto.USE
+	if cfg.Required {
+		allowed = proxyproto.REQUIRE
+	}
+	return func(upstream net.Addr) (proxyproto.Policy, error) {
+		addr, ok := upstream.(*net.TCPAddr)
+		if !ok {
+			return allowed, nil
+		}
+		for _, network := range trusted {
+			if network.Contains(addr.IP) {
+				return allowed, nil
+			}
+		}
+		return proxyproto.REJECT, nil
+	}, nil
+}
+
+// limitListener 限制每个客户端IP的并发连接数
+type limitListener struct {
+	net.Listener
+	maxPerIP int
+	logger   *zap.Logger
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &trackedConn{Conn: conn, listener: l}, nil
+}
+
+func (l *limitListener) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conns[ip] >= l.maxPerIP {
+		return false
+	}
+	l.conns[ip]++
+	return true
+}
+
+func (l *limitListener) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conns[ip] <= 1 {
+		delete(l.conns, ip)
+		return
+	}
+	l.conns[ip]--
+}
+
+// trackedConn 首次读取时才确定客户端IP并占用名额
+// 取得PROXY头中的地址需要读取连接，放在Accept中会让慢速客户端阻塞其他连接的接入
+type trackedConn struct {
+	net.Conn
+	listener *limitListener
+
+	acquireOnce sync.Once
+	acquireErr  error
+	ip          string
+	closeOnce   sync.Once
+}
+
+func (c *trackedConn) Read(b []byte) (int, error) {
+	c.acquireOnce.Do(c.acquire)
+	if c.acquireErr != nil {
+		return 0, c.acquireErr
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *trackedConn) acquire() {
+	if c.listener.maxPerIP <= 0 {
+		return
+	}
+	addr, ok := c.Conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		// Unix域套接字且没有PROXY头，无法区分客户端
+		return
+	}
+	ip := addr.IP.String()
+	if !c.listener.acquire(ip) {
+		// 以读错误返回，http.Server直接关闭连接而不是响应400
+		c.acquireErr = &net.OpError{Op: "read", Net: "tcp", Source: c.LocalAddr(), Addr: addr, Err: errTooManyConns}
+		c.listener.logger.Warn("客户端连接数超过上限，已关闭连接", zap.String("ip", ip), zap.Int("limit", c.listener.maxPerIP))
+		return
+	}
+	c.ip = ip
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		// 确保未读取过的连接不会在关闭之后再占用名额
+		c.acquireOnce.Do(func() { c.acquireErr = net.ErrClosed })
+		if c.ip != "" {
+			c.listener.release(c.ip)
+		}
+	})
+	return err
+}
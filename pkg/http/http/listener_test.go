@@ -0,0 +1,85 @@
+package use_http
+
+import (
+	"net"
+	"testing"
+
+	use_config "gin-center/configs/config"
+
+	"github.com/pires/go-proxyproto"
+)
+
+func TestProxyPolicy(t *testing.T) {
+	unixAddr := &net.UnixAddr{Name: "/run/gin-center.sock", Net: "unix"}
+	tests := []struct {
+		name       string
+		cfg        use_config.ProxyProtocolConfig
+		unixSocket bool
+		upstream   net.Addr
+		want       proxyproto.Policy
+		wantErr    bool
+	}{
+		{
+			name:    "TCP端口未配置可信地址段",
+			cfg:     use_config.ProxyProtocolConfig{Enabled: true},
+			wantErr: true,
+		},
+		{
+			name:    "无效的地址段",
+			cfg:     use_config.ProxyProtocolConfig{Enabled: true, TrustedCIDRs: []string{"10.0.0.1"}},
+			wantErr: true,
+		},
+		{
+			name:     "可信地址使用PROXY头",
+			cfg:      use_config.ProxyProtocolConfig{Enabled: true, TrustedCIDRs: []string{"10.0.0.0/8"}},
+			upstream: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 40000},
+			want:     proxyproto.USE,
+		},
+		{
+			name:     "可信地址必须携带PROXY头",
+			cfg:      use_config.ProxyProtocolConfig{Enabled: true, Required: true, TrustedCIDRs: []string{"10.0.0.0/8"}},
+			upstream: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 40000},
+			want:     proxyproto.REQUIRE,
+		},
+		{
+			name:     "不可信地址拒绝PROXY头",
+			cfg:      use_config.ProxyProtocolConfig{Enabled: true, Required: true, TrustedCIDRs: []string{"10.0.0.0/8"}},
+			upstream: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 40000},
+			want:     proxyproto.REJECT,
+		},
+		{
+			name:     "IPv6可信地址段",
+			cfg:      use_config.ProxyProtocolConfig{Enabled: true, TrustedCIDRs: []string{"fd00::/8"}},
+			upstream: &net.TCPAddr{IP: net.ParseIP("fd00::1"), Port: 40000},
+			want:     proxyproto.USE,
+		},
+		{
+			name:       "Unix域套接字可不配置可信地址段",
+			cfg:        use_config.ProxyProtocolConfig{Enabled: true},
+			unixSocket: true,
+			upstream:   unixAddr,
+			want:       proxyproto.USE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := proxyPolicy(&tt.cfg, tt.unixSocket)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("proxyPolicy() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("proxyPolicy() error = %v", err)
+			}
+			got, err := policy(tt.upstream)
+			if err != nil {
+				t.Fatalf("policy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("policy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
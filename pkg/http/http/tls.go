@@ -0,0 +1,235 @@
+package use_http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	use_config "gin-center/configs/config"
+
+	"go.uber.org/zap"
+)
+
+// defaultReloadInterval 未配置reload_interval时检查证书文件的间隔
+const defaultReloadInterval = 30 * time.Second
+
+// tlsReloader 持有当前的证书与客户端CA，文件修改后重新加载，已建立的连接不受影响
+type tlsReloader struct {
+	cfg        *use_config.TLSConfig
+	minVersion uint16
+	ciphers    []uint16
+	clientAuth tls.ClientAuthType
+	nextProtos []string
+	logger     *zap.Logger
+
+	current  atomic.Pointer[tls.Config]
+	modTimes map[string]time.Time
+}
+
+func newTLSReloader(cfg *use_config.TLSConfig, logger *zap.Logger) (*tlsReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("启用TLS时须配置cert_file和key_file")
+	}
+	minVersion, err := tlsVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	ciphers, err := cipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := clientAuthType(cfg.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, errors.New("校验客户端证书时须配置client_ca_file")
+	}
+	nextProtos := []string{"h2", "http/1.1"}
+	if cfg.DisableHTTP2 {
+		nextProtos = []string{"http/1.1"}
+	}
+
+	r := &tlsReloader{
+		cfg:        cfg,
+		minVersion: minVersion,
+		ciphers:    ciphers,
+		clientAuth: clientAuth,
+		nextProtos: nextProtos,
+		logger:     logger,
+		modTimes:   map[string]time.Time{},
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// serverConfig 返回交给http.Server的配置，每次握手时取当前加载的配置
+func (r *tlsReloader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: r.nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+		// 握手时使用GetConfigForClient返回的配置，此处仅让http.Server识别已配置证书
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &r.current.Load().Certificates[0], nil
+		},
+	}
+}
+
+// load 读取证书、私钥与客户端CA，全部成功后才替换当前配置
+func (r *tlsReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("加载TLS证书失败: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   r.minVersion,
+		CipherSuites: r.ciphers,
+		NextProtos:   r.nextProtos,
+		ClientAuth:   r.clientAuth,
+	}
+	if r.clientAuth != tls.NoClientCert {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端CA证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("客户端CA文件中没有有效的证书: %s", r.cfg.ClientCAFile)
+		}
+		config.ClientCAs = pool
+	}
+	r.current.Store(config)
+	for _, file := range r.files() {
+		if info, err := os.Stat(file); err == nil {
+			r.modTimes[file] = info.ModTime()
+		}
+	}
+	return nil
+}
+
+func (r *tlsReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.clientAuth != tls.NoClientCert {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// changed 任一文件的修改时间与上次加载时不同即视为变化，可覆盖证书被原子替换的情况
+func (r *tlsReloader) changed() bool {
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch 定期检查文件变化，重新加载失败时继续使用原证书
+func (r *tlsReloader) watch(stop <-chan struct{}) {
+	interval := r.cfg.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				r.logger.Error("重新加载TLS证书失败，继续使用原证书", zap.Error(err))
+				continue
+			}
+			r.logger.Info("已重新加载TLS证书")
+		}
+	}
+}
+
+func tlsVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("不支持的TLS版本: %s", version)
+	}
+}
+
+// cipherSuites 只接受Go认为安全的TLS 1.2套件，TLS 1.3的套件由Go固定选择，配置了也不会生效
+func cipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	available := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		if slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			available[suite.Name] = suite.ID
+		}
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("不支持或不安全的密码套件: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func clientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "optional":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("不支持的客户端证书校验方式: %s", mode)
+	}
+}
+
+// redirectHandler 将HTTP请求永久重定向到同一主机的HTTPS端口，保留路径与查询参数
+func redirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
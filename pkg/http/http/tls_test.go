@@ -0,0 +1,259 @@
+package use_http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	use_config "gin-center/configs/config"
+
+	"go.uber.org/zap"
+)
+
+// writeCert 生成自签名证书并写入dir，返回证书与私钥文件路径
+func writeCert(t *testing.T, dir string, serial int64) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "gin-center.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// touch 将文件修改时间设为指定时间，避免依赖文件系统的时间精度
+func touch(t *testing.T, at time.Time, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		if err := os.Chtimes(file, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func currentSerial(t *testing.T, r *tlsReloader) int64 {
+	t.Helper()
+	config, err := r.serverConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestTLSReloaderReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, 1)
+	base := time.Now().Add(-time.Hour)
+	touch(t, base, certFile, keyFile)
+
+	r, err := newTLSReloader(&use_config.TLSConfig{CertFile: certFile, KeyFile: keyFile}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("newTLSReloader() error = %v", err)
+	}
+	if r.changed() {
+		t.Error("changed() = true right after load")
+	}
+
+	// 证书被替换后重新加载
+	writeCert(t, dir, 2)
+	touch(t, base.Add(time.Minute), certFile, keyFile)
+	if !r.changed() {
+		t.Fatal("changed() = false after certificate replaced")
+	}
+	if err := r.load(); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if got := currentSerial(t, r); got != 2 {
+		t.Errorf("serial after reload = %d, want 2", got)
+	}
+	if r.changed() {
+		t.Error("changed() = true after reload")
+	}
+
+	// 写入了无效的证书时保留原证书
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, base.Add(2*time.Minute), certFile)
+	if err := r.load(); err == nil {
+		t.Fatal("load() with invalid certificate succeeded")
+	}
+	if got := currentSerial(t, r); got != 2 {
+		t.Errorf("serial after failed reload = %d, want 2", got)
+	}
+	if !r.changed() {
+		t.Error("changed() = false after failed reload, want retry on next tick")
+	}
+}
+
+func TestNewTLSReloaderValidation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, 1)
+	tests := []struct {
+		name string
+		cfg  use_config.TLSConfig
+	}{
+		{name: "缺少私钥", cfg: use_config.TLSConfig{CertFile: certFile}},
+		{name: "证书文件不存在", cfg: use_config.TLSConfig{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}},
+		{name: "校验客户端证书但缺少CA", cfg: use_config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: "require"}},
+		{name: "CA文件中没有证书", cfg: use_config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: "optional", ClientCAFile: keyFile}},
+		{name: "不支持的TLS版本", cfg: use_config.TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTLSReloader(&tt.cfg, zap.NewNop()); err == nil {
+				t.Error("newTLSReloader() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestTLSVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{version: "", want: tls.VersionTLS12},
+		{version: "1.2", want: tls.VersionTLS12},
+		{version: "1.3", want: tls.VersionTLS13},
+		{version: "1.1", wantErr: true},
+		{version: "TLS1.3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := tlsVersion(tt.version)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("tlsVersion(%q) = %v, %v, want %v, wantErr %v", tt.version, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCipherSuites(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		want    []uint16
+		wantErr bool
+	}{
+		{name: "未配置时使用默认套件", names: nil, want: nil},
+		{
+			name:  "按配置顺序返回",
+			names: []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+			want:  []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		},
+		{name: "不安全的套件", names: []string{"TLS_RSA_WITH_RC4_128_SHA"}, wantErr: true},
+		{name: "TLS 1.3套件不可配置", names: []string{"TLS_AES_128_GCM_SHA256"}, wantErr: true},
+		{name: "未知名称", names: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "AES128"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cipherSuites(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cipherSuites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("cipherSuites() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("cipherSuites()[%d] = %#04x, want %#04x", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClientAuthType(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    tls.ClientAuthType
+		wantErr bool
+	}{
+		{mode: "", want: tls.NoClientCert},
+		{mode: "none", want: tls.NoClientCert},
+		{mode: "optional", want: tls.VerifyClientCertIfGiven},
+		{mode: "require", want: tls.RequireAndVerifyClientCert},
+		{mode: "request", wantErr: true},
+		{mode: "Require", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := clientAuthType(tt.mode)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("clientAuthType(%q) = %v, %v, want %v, wantErr %v", tt.mode, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort int
+		host      string
+		target    string
+		want      string
+	}{
+		{name: "默认端口", httpsPort: 443, host: "example.com", target: "/login", want: "https://example.com/login"},
+		{name: "去掉HTTP端口", httpsPort: 443, host: "example.com:80", target: "/", want: "https://example.com/"},
+		{name: "非默认HTTPS端口", httpsPort: 8443, host: "example.com:8080", target: "/", want: "https://example.com:8443/"},
+		{name: "保留查询参数", httpsPort: 443, host: "example.com", target: "/search?q=a+b&page=2", want: "https://example.com/search?q=a+b&page=2"},
+		{name: "保留编码的路径", httpsPort: 443, host: "example.com", target: "/files/a%2Fb", want: "https://example.com/files/a%2Fb"},
+		{name: "IPv6默认端口", httpsPort: 443, host: "[::1]:80", target: "/", want: "https://[::1]/"},
+		{name: "IPv6非默认端口", httpsPort: 8443, host: "[::1]", target: "/", want: "https://[::1]:8443/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			redirectHandler(tt.httpsPort).ServeHTTP(w, req)
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusPermanentRedirect)
+			}
+			if got := w.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -70,7 +70,7 @@ func getValidationErrorMessage(err validator.FieldError) string {
 		}
 		return fmt.Sprintf(template, err.Field())
 	}
-	return fmt.Sprintf("%s", err.Field(), err.Tag())
+	return fmt.Sprintf("%s: %s", err.Field(), err.Tag())
 }
 func GetParam(c *gin.Context, key string, defaultValue string) string {
 	value := c.Query(key)
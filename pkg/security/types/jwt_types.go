@@ -3,15 +3,27 @@ package security_types
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+const (
+	// TokenTypeAccess 访问令牌
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh 刷新令牌
+	TokenTypeRefresh = "refresh"
+)
+
 // TokenPair 定义了访问令牌和刷新令牌的结构
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn 访问令牌有效期，单位秒
+	ExpiresIn int64 `json:"expires_in"`
+	// RefreshTokenID 刷新令牌的唯一标识，仅用于服务端轮换校验
+	RefreshTokenID string `json:"-"`
 }
 
 // Claims 定义了JWT令牌的基本接口
@@ -24,8 +36,22 @@ type Claims interface {
 type JWTUserClaims struct {
 	BaseClaims
 	UserID      string `json:"user_id"`
+	Role        string `json:"role,omitempty"`
 	TokenType   string `json:"token_type"`
+	SessionID   string `json:"sid,omitempty"`
 	Fingerprint string `json:"fingerprint,omitempty"`
+	// ClientID 通过授权服务签发时对应的客户端
+	ClientID string `json:"client_id,omitempty"`
+	// Scope 授权范围，空格分隔
+	Scope string `json:"scope,omitempty"`
+	// Actor 代为操作的真实主体，仅模拟登录签发的令牌携带
+	Actor *Actor `json:"act,omitempty"`
+}
+
+// Actor 令牌的实际操作者，参见RFC 8693的act声明
+type Actor struct {
+	Sub      string `json:"sub"`
+	Username string `json:"username"`
 }
 
 // Valid 实现jwt.Claims接口
@@ -78,6 +104,8 @@ func (c *AdminClaims) GenerateToken(secretKey string) (string, error) {
 type BaseClaims struct {
 	ID        string     `json:"id"`
 	Username  string     `json:"username"`
+	Issuer    string     `json:"iss,omitempty"`
+	IssuedAt  *TimeStamp `json:"iat,omitempty"`
 	ExpiresAt *TimeStamp `json:"exp,omitempty"`
 }
 
@@ -96,11 +124,31 @@ func (c *BaseClaims) GenerateToken(secretKey string) (string, error) {
 	return tokenString, nil
 }
 
-// TimeStamp 用于JWT的时间戳
+// TimeStamp 用于JWT的时间戳，序列化为秒级Unix时间
 type TimeStamp struct {
 	Time time.Time
 }
 
+// NewTimeStamp 创建时间戳，精度截断到秒
+func NewTimeStamp(t time.Time) *TimeStamp {
+	return &TimeStamp{Time: t.Truncate(time.Second)}
+}
+
+// MarshalJSON 实现json.Marshaler接口
+func (t TimeStamp) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.Time.Unix(), 10)), nil
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口
+func (t *TimeStamp) UnmarshalJSON(data []byte) error {
+	seconds, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("无效的时间戳: %w", err)
+	}
+	t.Time = time.Unix(seconds, 0)
+	return nil
+}
+
 // Valid 验证时间戳是否有效
 func (t *TimeStamp) Valid() error {
 	if t == nil {
@@ -132,4 +180,4 @@ type UserInfo interface {
 	GetID() string
 	GetUsername() string
 	GetPhone() string
-}
\ No newline at end of file
+}
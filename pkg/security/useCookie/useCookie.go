@@ -0,0 +1,194 @@
+// Package useCookie 以HttpOnly Cookie下发令牌，并以双重提交Cookie防御CSRF
+// 浏览器端不必将令牌保存在localStorage中，脚本无法读取访问令牌与刷新令牌
+package useCookie
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	security_types "gin-center/pkg/security/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransportHeader 客户端在登录与刷新请求中携带该请求头且值为TransportCookie时，令牌通过Cookie下发
+const (
+	TransportHeader = "X-Token-Transport"
+	TransportCookie = "cookie"
+)
+
+// Config 令牌Cookie配置
+type Config struct {
+	// Enabled 是否允许客户端选择Cookie传输
+	Enabled bool `mapstructure:"enabled"`
+	// AccessName 访问令牌Cookie名称，默认access_token
+	AccessName string `mapstructure:"access_name"`
+	// RefreshName 刷新令牌Cookie名称，默认refresh_token
+	RefreshName string `mapstructure:"refresh_name"`
+	// CSRFName CSRF令牌Cookie名称，默认csrf_token，该Cookie可被脚本读取
+	CSRFName string `mapstructure:"csrf_name"`
+	// CSRFHeader 客户端回传CSRF令牌的请求头，默认X-CSRF-Token
+	CSRFHeader string `mapstructure:"csrf_header"`
+	// Path 访问令牌与CSRF令牌Cookie的路径，默认/
+	Path string `mapstructure:"path"`
+	// RefreshPath 刷新令牌Cookie的路径，只随刷新请求发送，默认/api/v1/auth/refresh
+	RefreshPath string `mapstructure:"refresh_path"`
+	// Domain Cookie的域，为空时仅限当前主机
+	Domain string `mapstructure:"domain"`
+	// Secure 是否仅通过HTTPS发送
+	Secure bool `mapstructure:"secure"`
+	// SameSite lax、strict或none，默认lax；none要求secure
+	SameSite string `mapstructure:"same_site" validate:"omitempty,oneof=lax strict none"`
+}
+
+// Transport 读写令牌Cookie
+type Transport struct {
+	cfg             Config
+	sameSite        http.SameSite
+	refreshLifetime time.Duration
+}
+
+// New 创建令牌Cookie传输，refreshLifetime为刷新令牌有效期，用作刷新令牌与CSRF令牌Cookie的有效期
+func New(cfg *Config, refreshLifetime time.Duration) (*Transport, error) {
+	t := &Transport{cfg: *cfg, refreshLifetime: refreshLifetime}
+	if t.cfg.AccessName == "" {
+		t.cfg.AccessName = "access_token"
+	}
+	if t.cfg.RefreshName == "" {
+		t.cfg.RefreshName = "refresh_token"
+	}
+	if t.cfg.CSRFName == "" {
+		t.cfg.CSRFName = "csrf_token"
+	}
+	if t.cfg.CSRFHeader == "" {
+		t.cfg.CSRFHeader = "X-CSRF-Token"
+	}
+	if t.cfg.Path == "" {
+		t.cfg.Path = "/"
+	}
+	if t.cfg.RefreshPath == "" {
+		t.cfg.RefreshPath = "/api/v1/auth/refresh"
+	}
+	switch strings.ToLower(t.cfg.SameSite) {
+	case "", "lax":
+		t.sameSite = http.SameSiteLaxMode
+	case "strict":
+		t.sameSite = http.SameSiteStrictMode
+	case "none":
+		if !t.cfg.Secure {
+			return nil, errors.New("same_site为none时必须启用secure")
+		}
+		t.sameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("不支持的same_site: %s", t.cfg.SameSite)
+	}
+	return t, nil
+}
+
+// Enabled 是否启用Cookie传输
+func (t *Transport) Enabled() bool {
+	return t != nil && t.cfg.Enabled
+}
+
+// Requested 当前请求是否以请求头选择以Cookie下发令牌
+// 跨站页面不经预检无法设置该请求头，跨站表单无法借此在受害者浏览器中写入攻击者的令牌
+func (t *Transport) Requested(c *gin.Context) bool {
+	if !t.Enabled() {
+		return false
+	}
+	return strings.EqualFold(c.GetHeader(TransportHeader), TransportCookie)
+}
+
+// RequestedOrNavigated 同Requested，另将浏览器顶层导航的GET请求视为选择Cookie传输
+// 仅用于第三方登录回调，回调由提供方经浏览器跳转，无法设置请求头
+func (t *Transport) RequestedOrNavigated(c *gin.Context) bool {
+	if !t.Enabled() {
+		return false
+	}
+	return t.Requested(c) || (c.Request.Method == http.MethodGet && c.GetHeader("Sec-Fetch-Mode") == "navigate")
+}
+
+// SetTokens 写入访问令牌、刷新令牌与新的CSRF令牌，返回CSRF令牌
+func (t *Transport) SetTokens(c *gin.Context, tokens *security_types.TokenPair) (string, error) {
+	csrf, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	refreshMaxAge := int(t.refreshLifetime / time.Second)
+	t.set(c, t.cfg.AccessName, tokens.AccessToken, t.cfg.Path, int(tokens.ExpiresIn), true)
+	t.set(c, t.cfg.RefreshName, tokens.RefreshToken, t.cfg.RefreshPath, refreshMaxAge, true)
+	t.set(c, t.cfg.CSRFName, csrf, t.cfg.Path, refreshMaxAge, false)
+	return csrf, nil
+}
+
+// Clear 删除全部令牌Cookie
+func (t *Transport) Clear(c *gin.Context) {
+	t.set(c, t.cfg.AccessName, "", t.cfg.Path, -1, true)
+	t.set(c, t.cfg.RefreshName, "", t.cfg.RefreshPath, -1, true)
+	t.set(c, t.cfg.CSRFName, "", t.cfg.Path, -1, false)
+}
+
+// AccessToken 返回Cookie中的访问令牌，未启用或不存在时返回空字符串
+func (t *Transport) AccessToken(c *gin.Context) string {
+	return t.get(c, t.cfg.AccessName)
+}
+
+// RefreshToken 返回Cookie中的刷新令牌，未启用或不存在时返回空字符串
+func (t *Transport) RefreshToken(c *gin.Context) string {
+	return t.get(c, t.cfg.RefreshName)
+}
+
+// VerifyCSRF 校验请求头中的CSRF令牌与Cookie中的一致
+// 跨站页面能让浏览器携带Cookie，但无法读取Cookie的值来设置请求头
+func (t *Transport) VerifyCSRF(c *gin.Context) bool {
+	cookie := t.get(c, t.cfg.CSRFName)
+	header := c.GetHeader(t.cfg.CSRFHeader)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// SafeMethod 不修改状态的请求方法无需校验CSRF令牌
+func SafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func (t *Transport) get(c *gin.Context, name string) string {
+	if !t.Enabled() {
+		return ""
+	}
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (t *Transport) set(c *gin.Context, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   t.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   t.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: t.sameSite,
+	})
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成CSRF令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
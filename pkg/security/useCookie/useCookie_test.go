@@ -0,0 +1,151 @@
+package useCookie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newContext(method string, header http.Header, cookies ...*http.Cookie) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(method, "/", nil)
+	for key, values := range header {
+		c.Request.Header[key] = values
+	}
+	for _, cookie := range cookies {
+		c.Request.AddCookie(cookie)
+	}
+	return c
+}
+
+func TestVerifyCSRF(t *testing.T) {
+	transport, err := New(&Config{Enabled: true}, time.Hour)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+		want   bool
+	}{
+		{name: "一致", cookie: "token", header: "token", want: true},
+		{name: "不一致", cookie: "token", header: "other", want: false},
+		{name: "缺少请求头", cookie: "token", want: false},
+		{name: "缺少Cookie", header: "token", want: false},
+		{name: "均为空", want: false},
+		{name: "前缀相同", cookie: "token", header: "token2", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("X-CSRF-Token", tt.header)
+			}
+			var cookies []*http.Cookie
+			if tt.cookie != "" {
+				cookies = append(cookies, &http.Cookie{Name: "csrf_token", Value: tt.cookie})
+			}
+			if got := transport.VerifyCSRF(newContext(http.MethodPost, header, cookies...)); got != tt.want {
+				t.Errorf("VerifyCSRF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyCSRFDisabled(t *testing.T) {
+	transport, err := New(&Config{}, time.Hour)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c := newContext(http.MethodPost, http.Header{"X-Csrf-Token": {"token"}}, &http.Cookie{Name: "csrf_token", Value: "token"})
+	if transport.VerifyCSRF(c) {
+		t.Error("VerifyCSRF() = true, want false when cookie transport is disabled")
+	}
+}
+
+func TestRequested(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		method        string
+		header        http.Header
+		wantRequested bool
+		wantNavigated bool
+	}{
+		{
+			name:          "请求头选择Cookie",
+			enabled:       true,
+			method:        http.MethodPost,
+			header:        http.Header{"X-Token-Transport": {"Cookie"}},
+			wantRequested: true,
+			wantNavigated: true,
+		},
+		{
+			name:    "未启用",
+			method:  http.MethodPost,
+			header:  http.Header{"X-Token-Transport": {"cookie"}},
+			enabled: false,
+		},
+		{
+			name:    "其他传输方式",
+			enabled: true,
+			method:  http.MethodPost,
+			header:  http.Header{"X-Token-Transport": {"body"}},
+		},
+		{
+			name:          "顶层导航的GET请求",
+			enabled:       true,
+			method:        http.MethodGet,
+			header:        http.Header{"Sec-Fetch-Mode": {"navigate"}},
+			wantNavigated: true,
+		},
+		{
+			name:    "顶层导航的POST请求",
+			enabled: true,
+			method:  http.MethodPost,
+			header:  http.Header{"Sec-Fetch-Mode": {"navigate"}},
+		},
+		{
+			name:    "脚本发起的GET请求",
+			enabled: true,
+			method:  http.MethodGet,
+			header:  http.Header{"Sec-Fetch-Mode": {"cors"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := New(&Config{Enabled: tt.enabled}, time.Hour)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			c := newContext(tt.method, tt.header)
+			if got := transport.Requested(c); got != tt.wantRequested {
+				t.Errorf("Requested() = %v, want %v", got, tt.wantRequested)
+			}
+			if got := transport.RequestedOrNavigated(c); got != tt.wantNavigated {
+				t.Errorf("RequestedOrNavigated() = %v, want %v", got, tt.wantNavigated)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidSameSite(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "none未启用secure", cfg: Config{SameSite: "none"}},
+		{name: "不支持的取值", cfg: Config{SameSite: "relaxed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(&tt.cfg, time.Hour); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 var (
@@ -29,29 +30,64 @@ type JWTConfig struct {
 	AccessTokenLifetime time.Duration `mapstructure:"access_token_lifetime"`
 	// RefreshTokenLifetime 刷新令牌生命周期
 	RefreshTokenLifetime time.Duration `mapstructure:"refresh_token_lifetime"`
+	// ImpersonationTokenLifetime 模拟登录令牌生命周期
+	ImpersonationTokenLifetime time.Duration `mapstructure:"impersonation_token_lifetime"`
 	// BlacklistCleanupTick 黑名单清理间隔
 	BlacklistCleanupTick time.Duration `mapstructure:"blacklist_cleanup_tick"`
 	// 签名方法配置
 	SigningMethod     jwt.SigningMethod
 	blacklistedTokens sync.Map
+	stopCleanup       chan struct{}
+	stopOnce          sync.Once
 }
 
 type BlacklistedToken struct {
 	Expiry time.Time
 }
 
-// NewJWTConfig 创建新的JWT配置实例
+// TokenSubject 令牌签发主体
+type TokenSubject struct {
+	UserID      string
+	Username    string
+	Role        string
+	SessionID   string
+	Fingerprint string
+	ClientID    string
+	Scope       string
+	Actor       *security_types.Actor
+}
+
+// NewJWTConfig 创建新的JWT配置实例，未配置的有效期等参数使用默认值
+// 密钥为空时返回nil
 func NewJWTConfig(cfg *JWTConfig) *JWTConfig {
-	if cfg == nil || cfg.SecretKey == "" || cfg.Issuer == "" || cfg.AccessTokenLifetime <= 0 || cfg.RefreshTokenLifetime <= 0 || cfg.BlacklistCleanupTick <= 0 {
+	if cfg == nil || cfg.SecretKey == "" {
 		return nil
 	}
 	jwtConfig := &JWTConfig{
-		SecretKey:            cfg.SecretKey,
-		Issuer:               cfg.Issuer,
-		AccessTokenLifetime:  cfg.AccessTokenLifetime,
-		RefreshTokenLifetime: cfg.RefreshTokenLifetime,
-		BlacklistCleanupTick: cfg.BlacklistCleanupTick,
-		SigningMethod:        jwt.SigningMethodHS256,
+		SecretKey:                  cfg.SecretKey,
+		Expiration:                 cfg.Expiration,
+		Issuer:                     cfg.Issuer,
+		AccessTokenLifetime:        cfg.AccessTokenLifetime,
+		RefreshTokenLifetime:       cfg.RefreshTokenLifetime,
+		ImpersonationTokenLifetime: cfg.ImpersonationTokenLifetime,
+		BlacklistCleanupTick:       cfg.BlacklistCleanupTick,
+		SigningMethod:              jwt.SigningMethodHS256,
+		stopCleanup:                make(chan struct{}),
+	}
+	if jwtConfig.Issuer == "" {
+		jwtConfig.Issuer = "gin-center"
+	}
+	if jwtConfig.AccessTokenLifetime <= 0 {
+		jwtConfig.AccessTokenLifetime = 2 * time.Hour
+	}
+	if jwtConfig.RefreshTokenLifetime <= 0 {
+		jwtConfig.RefreshTokenLifetime = 7 * 24 * time.Hour
+	}
+	if jwtConfig.ImpersonationTokenLifetime <= 0 {
+		jwtConfig.ImpersonationTokenLifetime = 15 * time.Minute
+	}
+	if jwtConfig.BlacklistCleanupTick <= 0 {
+		jwtConfig.BlacklistCleanupTick = 10 * time.Minute
 	}
 	go jwtConfig.cleanupBlacklist()
 	return jwtConfig
@@ -67,33 +103,74 @@ func (c *JWTConfig) GenerateTokenWithClaims(claims jwt.Claims) (string, error) {
 	return tokenString, nil
 }
 
-func (c *JWTConfig) GenerateTokenPair(userID string, username, role string, fingerprint string) (*security_types.TokenPair, error) {
-	accessClaims := &security_types.UserClaims{
-		BaseClaims: security_types.BaseClaims{
-			Username: username,
-		},
-	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(c.SecretKey))
+// IssueTokenPair 为主体签发一对访问令牌和刷新令牌
+func (c *JWTConfig) IssueTokenPair(subject *TokenSubject) (*security_types.TokenPair, error) {
+	now := time.Now()
+	accessClaims := c.newClaims(subject, security_types.TokenTypeAccess, now, c.AccessTokenLifetime)
+	accessToken, err := c.GenerateTokenWithClaims(accessClaims)
 	if err != nil {
 		return nil, err
 	}
-	refreshClaims := &security_types.UserClaims{
-		BaseClaims: security_types.BaseClaims{
-			Username: username,
-		},
-	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(c.SecretKey))
+	refreshClaims := c.newClaims(subject, security_types.TokenTypeRefresh, now, c.RefreshTokenLifetime)
+	refreshToken, err := c.GenerateTokenWithClaims(refreshClaims)
 	if err != nil {
 		return nil, err
 	}
 	return &security_types.TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
+		AccessToken:    accessToken,
+		RefreshToken:   refreshToken,
+		ExpiresIn:      int64(c.AccessTokenLifetime / time.Second),
+		RefreshTokenID: refreshClaims.ID,
 	}, nil
 }
-func (c *JWTConfig) ParseToken(tokenString string) (*security_types.UserClaims, error) {
+
+// IssueAccessToken 签发不带刷新令牌的单独访问令牌，用于授权服务和模拟登录
+// lifetime不大于0时使用AccessTokenLifetime
+func (c *JWTConfig) IssueAccessToken(subject *TokenSubject, lifetime time.Duration) (string, *security_types.JWTUserClaims, error) {
+	if lifetime <= 0 {
+		lifetime = c.AccessTokenLifetime
+	}
+	claims := c.newClaims(subject, security_types.TokenTypeAccess, time.Now(), lifetime)
+	token, err := c.GenerateTokenWithClaims(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// newClaims 构造指定类型的令牌声明
+func (c *JWTConfig) newClaims(subject *TokenSubject, tokenType string, now time.Time, lifetime time.Duration) *security_types.JWTUserClaims {
+	return &security_types.JWTUserClaims{
+		BaseClaims: security_types.BaseClaims{
+			ID:        uuid.New().String(),
+			Username:  subject.Username,
+			Issuer:    c.Issuer,
+			IssuedAt:  security_types.NewTimeStamp(now),
+			ExpiresAt: security_types.NewTimeStamp(now.Add(lifetime)),
+		},
+		UserID:      subject.UserID,
+		Role:        subject.Role,
+		TokenType:   tokenType,
+		SessionID:   subject.SessionID,
+		Fingerprint: subject.Fingerprint,
+		ClientID:    subject.ClientID,
+		Scope:       subject.Scope,
+		Actor:       subject.Actor,
+	}
+}
+
+// GenerateTokenPair 生成不绑定会话的令牌对
+func (c *JWTConfig) GenerateTokenPair(userID string, username, role string, fingerprint string) (*security_types.TokenPair, error) {
+	return c.IssueTokenPair(&TokenSubject{
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		Fingerprint: fingerprint,
+	})
+}
+
+// ParseClaims 解析并校验令牌，返回完整声明
+func (c *JWTConfig) ParseClaims(tokenString string) (*security_types.JWTUserClaims, error) {
 	if tokenString == "" {
 		return nil, ErrEmptyToken
 	}
@@ -118,38 +195,69 @@ func (c *JWTConfig) ParseToken(tokenString string) (*security_types.UserClaims,
 	if !ok || !token.Valid {
 		return nil, ErrInvalidToken
 	}
-	
+	return jwtClaims, nil
+}
+
+func (c *JWTConfig) ParseToken(tokenString string) (*security_types.UserClaims, error) {
+	jwtClaims, err := c.ParseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
 	// 将JWTUserClaims转换为UserClaims
 	userClaims := &security_types.UserClaims{
 		BaseClaims: jwtClaims.BaseClaims,
-		UserID: jwtClaims.UserID,
+		UserID:     jwtClaims.UserID,
 	}
 	return userClaims, nil
 }
 
 func (c *JWTConfig) RevokeToken(tokenString string) error {
 	claims, err := c.ParseToken(tokenString)
-	if err != nil && err != ErrRevokedToken {
+	if err != nil {
 		return err
 	}
+	expiry := time.Now().Add(c.RefreshTokenLifetime)
+	if claims.ExpiresAt != nil {
+		expiry = claims.ExpiresAt.Time
+	}
 	c.blacklistedTokens.Store(tokenString, BlacklistedToken{
-		Expiry: claims.ExpiresAt.Time,
+		Expiry: expiry,
 	})
 	return nil
 }
 
+// RefreshToken 使用刷新令牌换发新的令牌对，指纹不一致时拒绝
 func (c *JWTConfig) RefreshToken(refreshToken string, fingerprint string) (*security_types.TokenPair, error) {
-	claims, err := c.ParseToken(refreshToken)
+	claims, err := c.ParseClaims(refreshToken)
 	if err != nil {
 		return nil, err
 	}
+	if claims.TokenType != security_types.TokenTypeRefresh {
+		return nil, ErrInvalidToken
+	}
+	if claims.Fingerprint != "" && claims.Fingerprint != fingerprint {
+		return nil, ErrInvalidToken
+	}
 
-	return c.GenerateTokenPair(claims.BaseClaims.ID, claims.Username, "", fingerprint)
+	return c.IssueTokenPair(&TokenSubject{
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		Role:        claims.Role,
+		SessionID:   claims.SessionID,
+		Fingerprint: fingerprint,
+	})
 }
+
 func (c *JWTConfig) cleanupBlacklist() {
 	ticker := time.NewTicker(c.BlacklistCleanupTick)
 	defer ticker.Stop()
-	for range ticker.C {
+	for {
+		select {
+		case <-c.stopCleanup:
+			return
+		case <-ticker.C:
+		}
 		now := time.Now()
 		c.blacklistedTokens.Range(func(key, value interface{}) bool {
 			if token, ok := value.(BlacklistedToken); ok {
@@ -161,3 +269,10 @@ func (c *JWTConfig) cleanupBlacklist() {
 		})
 	}
 }
+
+// Close 停止黑名单清理任务
+func (c *JWTConfig) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stopCleanup)
+	})
+}
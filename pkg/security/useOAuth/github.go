@@ -0,0 +1,111 @@
+package useOAuth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const defaultGitHubUserInfoURL = "https://api.github.com/user"
+
+// githubProvider GitHub OAuth应用，GitHub不签发ID Token，通过用户接口获取身份
+type githubProvider struct {
+	name        string
+	cfg         ProviderConfig
+	oauth       *oauth2.Config
+	userInfoURL string
+	httpClient  *http.Client
+}
+
+// githubUser GitHub用户接口返回的字段
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+func newGitHubProvider(name string, cfg ProviderConfig, redirectURL string, httpClient *http.Client) *githubProvider {
+	endpoint := github.Endpoint
+	if cfg.AuthURL != "" {
+		endpoint.AuthURL = cfg.AuthURL
+	}
+	if cfg.TokenURL != "" {
+		endpoint.TokenURL = cfg.TokenURL
+	}
+	userInfoURL := cfg.UserInfoURL
+	if userInfoURL == "" {
+		userInfoURL = defaultGitHubUserInfoURL
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+	return &githubProvider{
+		name: name,
+		cfg:  cfg,
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     endpoint,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		},
+		userInfoURL: userInfoURL,
+		httpClient:  httpClient,
+	}
+}
+
+func (p *githubProvider) Name() string        { return p.name }
+func (p *githubProvider) DisplayName() string { return p.cfg.DisplayName }
+func (p *githubProvider) AutoRegister() bool  { return p.cfg.AutoRegister }
+
+// AuthCodeURL 实现Provider接口
+func (p *githubProvider) AuthCodeURL(ctx context.Context, req *AuthRequest) (string, error) {
+	return p.oauth.AuthCodeURL(req.State, oauth2.S256ChallengeOption(req.CodeVerifier)), nil
+}
+
+// Exchange 实现Provider接口
+func (p *githubProvider) Exchange(ctx context.Context, code string, req *AuthRequest) (*Identity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("授权码换取令牌失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := p.oauth.Client(ctx, token).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("获取GitHub用户信息失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取GitHub用户信息失败: HTTP %d", resp.StatusCode)
+	}
+
+	var user githubUser
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("解析GitHub用户信息失败: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("GitHub用户信息缺少id")
+	}
+	return &Identity{
+		Provider:  p.name,
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Username:  user.Login,
+		Name:      user.Name,
+		Email:     user.Email,
+		AvatarURL: user.AvatarURL,
+	}, nil
+}
@@ -0,0 +1,121 @@
+package useOAuth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcProvider 标准OIDC提供方
+type oidcProvider struct {
+	name        string
+	cfg         ProviderConfig
+	redirectURL string
+	httpClient  *http.Client
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// oidcClaims ID Token中使用到的声明
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+func newOIDCProvider(name string, cfg ProviderConfig, redirectURL string, httpClient *http.Client) *oidcProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	return &oidcProvider{
+		name:        name,
+		cfg:         cfg,
+		redirectURL: redirectURL,
+		httpClient:  httpClient,
+	}
+}
+
+func (p *oidcProvider) Name() string        { return p.name }
+func (p *oidcProvider) DisplayName() string { return p.cfg.DisplayName }
+func (p *oidcProvider) AutoRegister() bool  { return p.cfg.AutoRegister }
+
+// discover 通过discovery初始化端点和ID Token校验器，失败时下次调用重试
+func (p *oidcProvider) discover() (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	// JWKS会在后续校验时按需刷新，因此不能使用请求级的上下文
+	ctx := oidc.ClientContext(context.Background(), p.httpClient)
+	provider, err := oidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("OIDC discovery失败(%s): %w", p.name, err)
+	}
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.cfg.Scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	return p.oauth, p.verifier, nil
+}
+
+// AuthCodeURL 实现Provider接口
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, req *AuthRequest) (string, error) {
+	oauthConfig, _, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	return oauthConfig.AuthCodeURL(req.State, oidc.Nonce(req.Nonce), oauth2.S256ChallengeOption(req.CodeVerifier)), nil
+}
+
+// Exchange 实现Provider接口，校验ID Token的签名、受众、有效期和nonce
+func (p *oidcProvider) Exchange(ctx context.Context, code string, req *AuthRequest) (*Identity, error) {
+	oauthConfig, verifier, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, p.httpClient)
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("授权码换取令牌失败: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("ID Token校验失败: %w", err)
+	}
+	if idToken.Nonce != req.Nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("解析ID Token声明失败: %w", err)
+	}
+	return &Identity{
+		Provider:      p.name,
+		Subject:       idToken.Subject,
+		Username:      claims.PreferredUsername,
+		Name:          claims.Name,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		AvatarURL:     claims.Picture,
+	}, nil
+}
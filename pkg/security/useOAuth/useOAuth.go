@@ -0,0 +1,195 @@
+// Package useOAuth 提供OAuth2/OIDC客户端，统一封装授权码+PKCE登录流程
+// OIDC提供方通过discovery获取端点并使用JWKS校验ID Token，GitHub等纯OAuth2提供方通过用户信息接口获取身份
+package useOAuth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// ProviderTypeOIDC 标准OIDC提供方
+	ProviderTypeOIDC = "oidc"
+	// ProviderTypeGitHub GitHub OAuth应用
+	ProviderTypeGitHub = "github"
+
+	// CallbackPathFormat 回调路径格式，参数为提供方名称
+	CallbackPathFormat = "/api/v1/auth/oauth/%s/callback"
+)
+
+var (
+	// ErrUnknownProvider 表示未配置或未启用的提供方
+	ErrUnknownProvider = errors.New("未知的登录提供方")
+	// ErrMissingIDToken 表示令牌响应中缺少id_token
+	ErrMissingIDToken = errors.New("令牌响应缺少id_token")
+	// ErrNonceMismatch 表示ID Token中的nonce与授权请求不一致
+	ErrNonceMismatch = errors.New("ID Token的nonce不匹配")
+)
+
+// Config OAuth客户端配置
+type Config struct {
+	// RedirectBaseURL 回调地址前缀，如https://api.example.com
+	RedirectBaseURL string `mapstructure:"redirect_base_url"`
+	// StateTTL 授权请求有效期
+	StateTTL time.Duration `mapstructure:"state_ttl"`
+	// HTTPTimeout 访问提供方接口的超时时间
+	HTTPTimeout time.Duration `mapstructure:"http_timeout"`
+	// Providers 提供方配置，键为提供方名称
+	Providers map[string]ProviderConfig `mapstructure:"providers"`
+}
+
+// ProviderConfig 单个提供方配置
+type ProviderConfig struct {
+	// Type 提供方类型，可选值：oidc/github
+	Type         string `mapstructure:"type"`
+	DisplayName  string `mapstructure:"display_name"`
+	Enabled      bool   `mapstructure:"enabled"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	// Issuer OIDC签发者地址，用于discovery
+	Issuer string   `mapstructure:"issuer"`
+	Scopes []string `mapstructure:"scopes"`
+	// AutoRegister 未关联账号的身份首次登录时自动创建普通用户
+	AutoRegister bool `mapstructure:"auto_register"`
+	// AuthURL、TokenURL、UserInfoURL 覆盖GitHub默认端点，用于GitHub Enterprise或本地测试桩
+	AuthURL     string `mapstructure:"auth_url"`
+	TokenURL    string `mapstructure:"token_url"`
+	UserInfoURL string `mapstructure:"userinfo_url"`
+}
+
+// Identity 提供方返回的用户身份
+type Identity struct {
+	Provider      string
+	Subject       string
+	Username      string
+	Name          string
+	Email         string
+	EmailVerified bool
+	AvatarURL     string
+}
+
+// AuthRequest 单次授权请求的防伪参数，需在回调前保存在服务端
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// Provider 登录提供方
+type Provider interface {
+	Name() string
+	DisplayName() string
+	AutoRegister() bool
+	// AuthCodeURL 生成跳转到提供方的授权地址
+	AuthCodeURL(ctx context.Context, req *AuthRequest) (string, error)
+	// Exchange 使用授权码换取令牌并解析用户身份
+	Exchange(ctx context.Context, code string, req *AuthRequest) (*Identity, error)
+}
+
+// Registry 提供方注册表
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewAuthRequest 生成随机的state、nonce和PKCE校验码
+func NewAuthRequest() (*AuthRequest, error) {
+	state, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthRequest{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}, nil
+}
+
+// NewRegistry 根据配置创建提供方注册表，只注册已启用的提供方
+// OIDC的discovery在首次使用时进行，提供方暂不可用不影响服务启动
+func NewRegistry(cfg *Config) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]Provider)}
+	if cfg == nil {
+		return registry, nil
+	}
+
+	timeout := cfg.HTTPTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	// 请求提供方时注入traceparent，链路追踪未启用时不产生span
+	httpClient := &http.Client{Timeout: timeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	baseURL := strings.TrimRight(cfg.RedirectBaseURL, "/")
+
+	for name, pc := range cfg.Providers {
+		if !pc.Enabled {
+			continue
+		}
+		if pc.ClientID == "" {
+			return nil, fmt.Errorf("登录提供方%s缺少client_id", name)
+		}
+		if pc.DisplayName == "" {
+			pc.DisplayName = name
+		}
+		redirectURL := baseURL + fmt.Sprintf(CallbackPathFormat, name)
+
+		switch strings.ToLower(pc.Type) {
+		case ProviderTypeOIDC:
+			if pc.Issuer == "" {
+				return nil, fmt.Errorf("OIDC提供方%s缺少issuer", name)
+			}
+			registry.providers[name] = newOIDCProvider(name, pc, redirectURL, httpClient)
+		case ProviderTypeGitHub:
+			registry.providers[name] = newGitHubProvider(name, pc, redirectURL, httpClient)
+		default:
+			return nil, fmt.Errorf("登录提供方%s的类型不受支持: %s", name, pc.Type)
+		}
+	}
+	return registry, nil
+}
+
+// Get 获取指定名称的提供方
+func (r *Registry) Get(name string) (Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// List 按名称顺序返回全部已启用的提供方
+func (r *Registry) List() []Provider {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]Provider, len(names))
+	for i, name := range names {
+		providers[i] = r.providers[name]
+	}
+	return providers
+}
+
+// randomString 生成URL安全的随机字符串
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,76 @@
+package usePassword
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeArgon2id(t *testing.T) {
+	const (
+		salt = "c29tZXNhbHRzb21lc2FsdA"                      // 16字节
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U" // 32字节
+	)
+	tests := []struct {
+		name    string
+		encoded string
+		want    Argon2Params
+		wantErr bool
+	}{
+		{
+			name:    "有效哈希",
+			encoded: "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$" + key,
+			want:    Argon2Params{Memory: 65536, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32},
+		},
+		{name: "空字符串", encoded: "", wantErr: true},
+		{name: "其他算法", encoded: "$argon2i$v=19$m=65536,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "段数不足", encoded: "$argon2id$v=19$m=65536,t=3,p=2$" + salt, wantErr: true},
+		{name: "版本不兼容", encoded: "$argon2id$v=16$m=65536,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "版本后有多余内容", encoded: "$argon2id$v=19x$m=65536,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "参数缺失", encoded: "$argon2id$v=19$m=65536,t=3$" + salt + "$" + key, wantErr: true},
+		{name: "参数后有多余内容", encoded: "$argon2id$v=19$m=65536,t=3,p=2,k=1$" + salt + "$" + key, wantErr: true},
+		{name: "并行度溢出", encoded: "$argon2id$v=19$m=65536,t=3,p=256$" + salt + "$" + key, wantErr: true},
+		{name: "负数参数", encoded: "$argon2id$v=19$m=-1,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "迭代次数为0", encoded: "$argon2id$v=19$m=65536,t=0,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "并行度为0", encoded: "$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key, wantErr: true},
+		{name: "内存小于8倍并行度", encoded: "$argon2id$v=19$m=15,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "盐不是base64", encoded: "$argon2id$v=19$m=65536,t=3,p=2$!!!$" + key, wantErr: true},
+		{name: "密钥带填充", encoded: "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$" + key + "=", wantErr: true},
+		{name: "密钥为空", encoded: "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$", wantErr: true},
+		{name: "盐过短", encoded: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$" + key, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _, _, err := decodeArgon2id(tt.encoded)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHash) {
+					t.Errorf("decodeArgon2id() error = %v, want ErrInvalidHash", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeArgon2id() error = %v", err)
+			}
+			if params != tt.want {
+				t.Errorf("decodeArgon2id() params = %+v, want %+v", params, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	params := Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	encoded, err := hashArgon2id("secret", params)
+	if err != nil {
+		t.Fatalf("hashArgon2id() error = %v", err)
+	}
+	decoded, _, _, err := decodeArgon2id(encoded)
+	if err != nil || decoded != params {
+		t.Fatalf("decodeArgon2id() = %+v, %v, want %+v", decoded, err, params)
+	}
+	if err := verifyArgon2id("secret", encoded); err != nil {
+		t.Errorf("verifyArgon2id() error = %v", err)
+	}
+	if err := verifyArgon2id("Secret", encoded); !errors.Is(err, ErrMismatch) {
+		t.Errorf("verifyArgon2id() error = %v, want ErrMismatch", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package usePassword 提供密码哈希抽象，统一使用PHC格式字符串存储密码
+// 支持bcrypt与argon2id两种算法，并可判断已存储的哈希是否需要升级
+package usePassword
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// AlgorithmBcrypt bcrypt算法
+	AlgorithmBcrypt = "bcrypt"
+	// AlgorithmArgon2id argon2id算法
+	AlgorithmArgon2id = "argon2id"
+)
+
+var (
+	// ErrMismatch 表示密码与哈希不匹配
+	ErrMismatch = errors.New("密码不匹配")
+	// ErrUnsupportedHash 表示无法识别的哈希格式
+	ErrUnsupportedHash = errors.New("不支持的密码哈希格式")
+	// ErrInvalidHash 表示哈希字符串已损坏
+	ErrInvalidHash = errors.New("无效的密码哈希")
+)
+
+// Config 密码哈希配置
+type Config struct {
+	// Algorithm 新密码使用的哈希算法，可选值：bcrypt/argon2id
+	Algorithm string `mapstructure:"algorithm"`
+	// BcryptCost bcrypt计算成本
+	BcryptCost int `mapstructure:"bcrypt_cost"`
+	// Argon2 argon2id参数
+	Argon2 Argon2Params `mapstructure:"argon2"`
+}
+
+// Argon2Params argon2id哈希参数
+type Argon2Params struct {
+	// Memory 内存开销，单位KiB
+	Memory uint32 `mapstructure:"memory"`
+	// Iterations 迭代次数
+	Iterations uint32 `mapstructure:"iterations"`
+	// Parallelism 并行度
+	Parallelism uint8 `mapstructure:"parallelism"`
+	// SaltLength 盐长度，单位字节
+	SaltLength uint32 `mapstructure:"salt_length"`
+	// KeyLength 输出密钥长度，单位字节
+	KeyLength uint32 `mapstructure:"key_length"`
+}
+
+// Hasher 定义密码哈希器接口
+type Hasher interface {
+	// Hash 使用当前配置的算法和参数生成密码哈希
+	Hash(password string) (string, error)
+	// Verify 校验密码，不匹配时返回ErrMismatch
+	Verify(password, encoded string) error
+	// NeedsRehash 判断哈希的算法或参数是否已过时
+	NeedsRehash(encoded string) bool
+}
+
+// hasher 根据哈希前缀分派到具体算法的哈希器实现
+type hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     Argon2Params
+}
+
+// DefaultConfig 返回默认的密码哈希配置
+func DefaultConfig() *Config {
+	return &Config{
+		Algorithm:  AlgorithmArgon2id,
+		BcryptCost: bcrypt.DefaultCost,
+		Argon2: Argon2Params{
+			Memory:      64 * 1024,
+			Iterations:  3,
+			Parallelism: 2,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	}
+}
+
+// NewHasher 创建密码哈希器，未配置的参数使用默认值
+func NewHasher(cfg *Config) (Hasher, error) {
+	defaults := DefaultConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+
+	h := &hasher{
+		algorithm:  strings.ToLower(cfg.Algorithm),
+		bcryptCost: cfg.BcryptCost,
+		argon2:     cfg.Argon2,
+	}
+	if h.algorithm == "" {
+		h.algorithm = defaults.Algorithm
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = defaults.BcryptCost
+	}
+	if h.argon2.Memory == 0 {
+		h.argon2.Memory = defaults.Argon2.Memory
+	}
+	if h.argon2.Iterations == 0 {
+		h.argon2.Iterations = defaults.Argon2.Iterations
+	}
+	if h.argon2.Parallelism == 0 {
+		h.argon2.Parallelism = defaults.Argon2.Parallelism
+	}
+	if h.argon2.SaltLength == 0 {
+		h.argon2.SaltLength = defaults.Argon2.SaltLength
+	}
+	if h.argon2.KeyLength == 0 {
+		h.argon2.KeyLength = defaults.Argon2.KeyLength
+	}
+
+	switch h.algorithm {
+	case AlgorithmBcrypt:
+		if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt成本必须在%d-%d之间", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case AlgorithmArgon2id:
+		if h.argon2.SaltLength < minArgon2SaltLength || h.argon2.KeyLength < minArgon2KeyLength {
+			return nil, fmt.Errorf("argon2盐长度不能小于%d，密钥长度不能小于%d", minArgon2SaltLength, minArgon2KeyLength)
+		}
+		if h.argon2.Memory < 8*uint32(h.argon2.Parallelism) {
+			return nil, errors.New("argon2内存开销不能小于8×并行度KiB")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的密码哈希算法: %s", cfg.Algorithm)
+	}
+	return h, nil
+}
+
+// Hash 实现Hasher接口
+func (h *hasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("密码加密失败: %w", err)
+		}
+		return string(hash), nil
+	}
+	return hashArgon2id(password, h.argon2)
+}
+
+// Verify 实现Hasher接口
+func (h *hasher) Verify(password, encoded string) error {
+	switch identify(encoded) {
+	case AlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatch
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidHash, err)
+		}
+		return nil
+	case AlgorithmArgon2id:
+		return verifyArgon2id(password, encoded)
+	default:
+		return ErrUnsupportedHash
+	}
+}
+
+// NeedsRehash 实现Hasher接口
+func (h *hasher) NeedsRehash(encoded string) bool {
+	algorithm := identify(encoded)
+	if algorithm != h.algorithm {
+		return true
+	}
+	switch algorithm {
+	case AlgorithmBcrypt:
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != h.bcryptCost
+	case AlgorithmArgon2id:
+		params, _, _, err := decodeArgon2id(encoded)
+		return err != nil || params != h.argon2
+	}
+	return true
+}
+
+// identify 根据PHC前缀识别哈希算法
+func identify(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,211 @@
+package useStorage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage 本地文件系统存储，通过ServeHTTP对外提供文件访问
+type LocalStorage struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+	options
+}
+
+func newLocalStorage(cfg *LocalConfig, o options) (*LocalStorage, error) {
+	root := cfg.Root
+	if root == "" {
+		root = "uploads"
+	}
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+	signingKey := []byte(cfg.SigningKey)
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+		}
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	return &LocalStorage{root: root, baseURL: baseURL, signingKey: signingKey, options: o}, nil
+}
+
+// BasePath 静态文件路由的前缀
+func (s *LocalStorage) BasePath() string {
+	if u, err := url.Parse(s.baseURL); err == nil {
+		return u.Path
+	}
+	return s.baseURL
+}
+
+// Put 实现Storage接口，先写入临时文件再重命名，避免读到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("设置文件权限失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Open 实现Storage接口
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	return file, nil
+}
+
+// Delete 实现Storage接口
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+	return nil
+}
+
+// DeletePrefix 实现Storage接口
+func (s *LocalStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	prefix, err := cleanPrefix(prefix)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(s.root, filepath.FromSlash(prefix))); err != nil {
+		return fmt.Errorf("删除目录失败: %w", err)
+	}
+	return nil
+}
+
+// URL 实现Storage接口
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+// SignedURL 实现Storage接口，签名覆盖对象键和过期时间
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(s.ttl(ttl)).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.URL(key) + "?" + query.Encode(), nil
+}
+
+// KeyOf 实现Storage接口
+func (s *LocalStorage) KeyOf(rawURL string) (string, bool) {
+	if !strings.HasPrefix(rawURL, s.baseURL+"/") {
+		return "", false
+	}
+	key, _, _ := strings.Cut(strings.TrimPrefix(rawURL, s.baseURL+"/"), "?")
+	key, err := CleanKey(key)
+	return key, err == nil
+}
+
+// ServeHTTP 提供文件访问，请求路径需已去除BasePath前缀
+// 公开前缀下的文件直接返回，其余文件需携带有效签名
+// 非公开文件的对象键保留了客户端提供的扩展名，不能据此推断类型，一律以附件形式下载，
+// 避免上传的HTML、SVG等文件在API的源下被浏览器渲染
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, err := CleanKey(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.isPublic(key) && !s.verify(key, r.URL.Query()) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	name, err := s.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	h := w.Header()
+	h.Set("X-Content-Type-Options", "nosniff")
+	if !s.isPublic(key) {
+		h.Set("Content-Type", "application/octet-stream")
+		h.Set("Content-Disposition", "attachment")
+		h.Set("Content-Security-Policy", "sandbox")
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) verify(key string, query url.Values) bool {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(query.Get("signature")), []byte(s.sign(key, expires)))
+}
@@ -0,0 +1,96 @@
+package useStorage
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	s, err := newLocalStorage(&LocalConfig{Root: t.TempDir(), BaseURL: "/uploads/", SigningKey: "test"}, newOptions(&Config{}))
+	if err != nil {
+		t.Fatalf("newLocalStorage() error = %v", err)
+	}
+	return s
+}
+
+func TestLocalStorage(t *testing.T) {
+	testStorage(t, newTestLocalStorage(t))
+}
+
+func TestLocalStorageKeyOf(t *testing.T) {
+	s := newTestLocalStorage(t)
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{url: "/uploads/avatars/1/a.png", want: "avatars/1/a.png", wantOK: true},
+		{url: "/uploads/files/1/a.pdf?expires=1&signature=x", want: "files/1/a.pdf", wantOK: true},
+		{url: "/uploads/../configs/app.yaml", wantOK: false},
+		{url: "/uploadsx/avatars/1/a.png", wantOK: false},
+		{url: "https://cdn.example.com/uploads/avatars/1/a.png", wantOK: false},
+		{url: "/uploads/", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, ok := s.KeyOf(tt.url)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("KeyOf() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLocalStorageServeHTTP(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+	for _, key := range []string{"avatars/1/a.png", "files/1/page.html"} {
+		if err := s.Put(ctx, key, bytes.NewReader([]byte("content")), 7, "text/html"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	signed, err := s.SignedURL(ctx, "files/1/page.html", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL() error = %v", err)
+	}
+	signedQuery := signed[strings.Index(signed, "?"):]
+	expired := url.Values{"expires": {"1"}, "signature": {s.sign("files/1/page.html", "1")}}
+
+	tests := []struct {
+		name           string
+		target         string
+		wantStatus     int
+		wantAttachment bool
+	}{
+		{name: "公开文件无需签名", target: "/avatars/1/a.png", wantStatus: http.StatusOK},
+		{name: "非公开文件缺少签名", target: "/files/1/page.html", wantStatus: http.StatusForbidden},
+		{name: "有效签名以附件下载", target: "/files/1/page.html" + signedQuery, wantStatus: http.StatusOK, wantAttachment: true},
+		{name: "签名与对象键不符", target: "/files/1/other.html" + signedQuery, wantStatus: http.StatusForbidden},
+		{name: "签名已过期", target: "/files/1/page.html?" + expired.Encode(), wantStatus: http.StatusForbidden},
+		{name: "目录", target: "/avatars/1", wantStatus: http.StatusNotFound},
+		{name: "不存在的文件", target: "/avatars/1/missing.png", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			attachment := rec.Header().Get("Content-Disposition") == "attachment"
+			if attachment != tt.wantAttachment {
+				t.Errorf("Content-Disposition = %q, want attachment %v", rec.Header().Get("Content-Disposition"), tt.wantAttachment)
+			}
+			if tt.wantAttachment && rec.Header().Get("Content-Type") != "application/octet-stream" {
+				t.Errorf("Content-Type = %q, want application/octet-stream", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
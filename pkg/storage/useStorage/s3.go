@@ -0,0 +1,133 @@
+package useStorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage S3兼容存储，公开前缀下的对象需在存储桶策略中允许匿名读取
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+	options
+}
+
+func newS3Storage(ctx context.Context, cfg *S3Config, o options) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3存储缺少endpoint或bucket配置")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建S3客户端失败: %w", err)
+	}
+	if cfg.CreateBucket {
+		exists, err := client.BucketExists(ctx, cfg.Bucket)
+		if err != nil {
+			return nil, fmt.Errorf("检查存储桶失败: %w", err)
+		}
+		if !exists {
+			if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+				return nil, fmt.Errorf("创建存储桶失败: %w", err)
+			}
+		}
+	}
+	publicURL := strings.TrimRight(cfg.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + cfg.Bucket
+	}
+	return &S3Storage{client: client, bucket: cfg.Bucket, publicURL: publicURL, options: o}, nil
+}
+
+// Put 实现Storage接口
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	if _, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType}); err != nil {
+		return fmt.Errorf("上传对象失败: %w", err)
+	}
+	return nil
+}
+
+// Open 实现Storage接口，对象不存在的错误在首次读取时返回
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("读取对象失败: %w", err)
+	}
+	return object, nil
+}
+
+// Delete 实现Storage接口，S3删除不存在的对象同样返回成功
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("删除对象失败: %w", err)
+	}
+	return nil
+}
+
+// DeletePrefix 实现Storage接口
+func (s *S3Storage) DeletePrefix(ctx context.Context, prefix string) error {
+	prefix, err := cleanPrefix(prefix)
+	if err != nil {
+		return err
+	}
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true})
+	for result := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return fmt.Errorf("删除对象失败: %w", result.Err)
+		}
+	}
+	return nil
+}
+
+// URL 实现Storage接口
+func (s *S3Storage) URL(key string) string {
+	return s.publicURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+// SignedURL 实现Storage接口，生成预签名的GET地址
+// 响应的Content-Disposition由签名参数固定为attachment，用户上传的HTML、SVG等文件不会在浏览器中渲染
+func (s *S3Storage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{"response-content-disposition": {"attachment"}}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, s.ttl(ttl), params)
+	if err != nil {
+		return "", fmt.Errorf("生成签名地址失败: %w", err)
+	}
+	return u.String(), nil
+}
+
+// KeyOf 实现Storage接口
+func (s *S3Storage) KeyOf(rawURL string) (string, bool) {
+	if !strings.HasPrefix(rawURL, s.publicURL+"/") {
+		return "", false
+	}
+	key, _, _ := strings.Cut(strings.TrimPrefix(rawURL, s.publicURL+"/"), "?")
+	key, err := CleanKey(key)
+	return key, err == nil
+}
@@ -0,0 +1,36 @@
+package useStorage
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// TestS3Storage 需要可访问的S3兼容存储，如本地MinIO：
+// docker run -p 9000:9000 minio/minio server /data
+// STORAGE_TEST_S3_ENDPOINT=localhost:9000 go test ./pkg/storage/useStorage
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("未设置STORAGE_TEST_S3_ENDPOINT，跳过S3存储测试")
+	}
+	cfg := &S3Config{
+		Endpoint:     endpoint,
+		Bucket:       envOr("STORAGE_TEST_S3_BUCKET", "gin-center-test"),
+		AccessKey:    envOr("STORAGE_TEST_S3_ACCESS_KEY", "minioadmin"),
+		SecretKey:    envOr("STORAGE_TEST_S3_SECRET_KEY", "minioadmin"),
+		CreateBucket: true,
+	}
+	s, err := newS3Storage(context.Background(), cfg, newOptions(&Config{}))
+	if err != nil {
+		t.Fatalf("newS3Storage() error = %v", err)
+	}
+	testStorage(t, s)
+}
+
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
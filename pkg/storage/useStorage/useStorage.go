@@ -0,0 +1,147 @@
+// Package useStorage 提供对象存储抽象，支持本地文件系统和S3兼容存储（如MinIO）
+// 公开前缀下的对象可直接通过URL访问，其余对象需使用带有效期的签名URL
+package useStorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverLocal 本地文件系统
+	DriverLocal = "local"
+	// DriverS3 S3兼容存储
+	DriverS3 = "s3"
+
+	defaultSignedURLTTL = 15 * time.Minute
+)
+
+// ErrInvalidKey 表示对象键为空或包含非法路径
+var ErrInvalidKey = errors.New("无效的对象键")
+
+// Config 对象存储配置
+type Config struct {
+	// Driver 存储驱动，可选值：local/s3
+	Driver string `mapstructure:"driver"`
+	// PublicPrefixes 无需签名即可访问的对象键前缀，默认仅头像公开
+	PublicPrefixes []string `mapstructure:"public_prefixes"`
+	// SignedURLTTL 签名URL的默认有效期
+	SignedURLTTL time.Duration `mapstructure:"signed_url_ttl"`
+	Local        LocalConfig   `mapstructure:"local"`
+	S3           S3Config      `mapstructure:"s3"`
+}
+
+// LocalConfig 本地文件系统存储配置
+type LocalConfig struct {
+	// Root 文件存放目录
+	Root string `mapstructure:"root"`
+	// BaseURL 对外访问路径，同时作为静态文件路由的前缀
+	BaseURL string `mapstructure:"base_url"`
+	// SigningKey 签名URL的HMAC密钥，为空时启动时随机生成，重启后已签发的URL失效
+	SigningKey string `mapstructure:"signing_key"`
+}
+
+// S3Config S3兼容存储配置
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+	// PublicURL 公开对象的访问地址前缀，如CDN地址，为空时使用endpoint/bucket
+	PublicURL string `mapstructure:"public_url"`
+	// CreateBucket 启动时存储桶不存在则自动创建，便于本地MinIO开发
+	CreateBucket bool `mapstructure:"create_bucket"`
+}
+
+// Storage 对象存储
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open 读取对象内容，调用方负责关闭
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix 删除指定前缀下的所有对象，前缀需以/结尾
+	DeletePrefix(ctx context.Context, prefix string) error
+	// URL 返回对象的公开访问地址，仅对公开前缀下的对象有效
+	URL(key string) string
+	// SignedURL 返回带有效期的访问地址，ttl<=0时使用默认有效期
+	// 经签名地址访问的对象以附件形式下载，不在浏览器中直接打开
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// KeyOf 从URL解析对象键，URL不属于当前存储时返回false
+	KeyOf(url string) (string, bool)
+}
+
+// NewStorage 根据配置创建对象存储
+func NewStorage(ctx context.Context, cfg *Config) (Storage, error) {
+	options := newOptions(cfg)
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return newLocalStorage(&cfg.Local, options)
+	case DriverS3:
+		return newS3Storage(ctx, &cfg.S3, options)
+	default:
+		return nil, fmt.Errorf("不支持的存储驱动: %s", cfg.Driver)
+	}
+}
+
+// options 各驱动共用的访问策略
+type options struct {
+	publicPrefixes []string
+	signedURLTTL   time.Duration
+}
+
+func newOptions(cfg *Config) options {
+	o := options{publicPrefixes: cfg.PublicPrefixes, signedURLTTL: cfg.SignedURLTTL}
+	if o.publicPrefixes == nil {
+		o.publicPrefixes = []string{"avatars/"}
+	}
+	if o.signedURLTTL <= 0 {
+		o.signedURLTTL = defaultSignedURLTTL
+	}
+	return o
+}
+
+func (o options) isPublic(key string) bool {
+	for _, prefix := range o.publicPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o options) ttl(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return o.signedURLTTL
+	}
+	return ttl
+}
+
+// cleanPrefix 校验目录前缀，避免误删整个存储
+func cleanPrefix(prefix string) (string, error) {
+	if !strings.HasSuffix(prefix, "/") {
+		return "", ErrInvalidKey
+	}
+	cleaned, err := CleanKey(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return "", err
+	}
+	return cleaned + "/", nil
+}
+
+// CleanKey 规范化对象键，拒绝空键以及跳出根目录的路径
+func CleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
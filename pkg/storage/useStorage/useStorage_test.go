@@ -0,0 +1,135 @@
+package useStorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "avatars/1/a.png", want: "avatars/1/a.png"},
+		{key: "/avatars/1/a.png", want: "avatars/1/a.png"},
+		{key: "", wantErr: true},
+		{key: "/", wantErr: true},
+		{key: "../etc/passwd", wantErr: true},
+		{key: "avatars/../../etc/passwd", wantErr: true},
+		{key: "avatars/./a.png", wantErr: true},
+		{key: "avatars//a.png", wantErr: true},
+		{key: "avatars/1/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := CleanKey(tt.key)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("CleanKey() error = %v, want ErrInvalidKey", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("CleanKey() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		want    string
+		wantErr bool
+	}{
+		{prefix: "avatars/1/", want: "avatars/1/"},
+		{prefix: "avatars/1", wantErr: true},
+		{prefix: "/", wantErr: true},
+		{prefix: "", wantErr: true},
+		{prefix: "../", wantErr: true},
+		{prefix: "avatars/../", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := cleanPrefix(tt.prefix)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("cleanPrefix() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("cleanPrefix() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// testStorage 各驱动共用的读写与删除测试
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+	put := func(key, content string) {
+		t.Helper()
+		if err := s.Put(ctx, key, bytes.NewReader([]byte(content)), int64(len(content)), "text/plain"); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+	read := func(key string) (string, error) {
+		t.Helper()
+		r, err := s.Open(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		b, err := io.ReadAll(r)
+		return string(b), err
+	}
+
+	put("avatars/1/a/original.png", "original")
+	put("avatars/1/a/64.png", "thumbnail")
+	put("avatars/1/b.png", "other")
+	put("avatars/1/b.png", "overwritten")
+
+	if got, err := read("avatars/1/b.png"); err != nil || got != "overwritten" {
+		t.Errorf("Open() = %q, %v, want %q", got, err, "overwritten")
+	}
+	if err := s.Put(ctx, "../escape", bytes.NewReader(nil), 0, "text/plain"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put() error = %v, want ErrInvalidKey", err)
+	}
+
+	if err := s.DeletePrefix(ctx, "avatars/1/a/"); err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+	for _, key := range []string{"avatars/1/a/original.png", "avatars/1/a/64.png"} {
+		if _, err := read(key); err == nil {
+			t.Errorf("Open(%q) error = nil after DeletePrefix", key)
+		}
+	}
+	if got, err := read("avatars/1/b.png"); err != nil || got != "overwritten" {
+		t.Errorf("DeletePrefix() removed object outside prefix: %q, %v", got, err)
+	}
+	if err := s.DeletePrefix(ctx, "avatars/1"); err == nil {
+		t.Error("DeletePrefix() without trailing slash error = nil, want error")
+	}
+
+	if err := s.Delete(ctx, "avatars/1/b.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := read("avatars/1/b.png"); err == nil {
+		t.Error("Open() error = nil after Delete")
+	}
+	if err := s.Delete(ctx, "avatars/1/missing.png"); err != nil {
+		t.Errorf("Delete() missing object error = %v, want nil", err)
+	}
+
+	if key, ok := s.KeyOf(s.URL("avatars/2/c.png")); !ok || key != "avatars/2/c.png" {
+		t.Errorf("KeyOf(URL()) = %q, %v, want %q, true", key, ok, "avatars/2/c.png")
+	}
+	if _, ok := s.KeyOf("https://avatars.example.com/u/1"); ok {
+		t.Error("KeyOf() external URL ok = true, want false")
+	}
+}
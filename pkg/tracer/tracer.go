@@ -0,0 +1,130 @@
+// Package tracer 基于OpenTelemetry的链路追踪
+// 按W3C Trace Context在进出的HTTP请求间传播追踪上下文，并为GORM和go-redis生成span
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 本包创建的span所属的instrumentation
+const instrumentationName = "gin-center/pkg/tracer"
+
+// 导出方式
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+// Config 链路追踪配置
+type Config struct {
+	// Enabled 是否创建span，关闭时仍透传请求中的追踪上下文
+	Enabled bool `mapstructure:"enabled"`
+	// Exporter 导出方式，可选值：otlp/stdout/none；none时只生成trace_id用于关联日志
+	Exporter string `mapstructure:"exporter" validate:"omitempty,oneof=otlp stdout none"`
+	// Endpoint OTLP/HTTP接收地址，如localhost:4318，为空时读取OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure 是否使用HTTP而非HTTPS连接OTLP接收端
+	Insecure bool `mapstructure:"insecure"`
+	// Headers 发送给OTLP接收端的额外请求头，如认证信息
+	Headers map[string]string `mapstructure:"headers"`
+	// SampleRatio 采样比例，取值(0,1)时按比例采样，其余值全部采样；上游已决定采样时沿用上游决定
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	// HeaderName 返回trace_id的响应头，默认X-Trace-ID
+	HeaderName string `mapstructure:"header_name"`
+	// SkipPaths 不创建span的请求路径
+	SkipPaths []string `mapstructure:"skip_paths"`
+}
+
+// Setup 设置全局传播器，启用时按配置创建导出器和TracerProvider并设为全局
+// 未启用时返回nil；调用方需在退出前调用返回值的Shutdown以导出剩余的span
+func Setup(ctx context.Context, cfg *Config, serviceName, version, environment string) (*sdktrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version),
+			semconv.DeploymentEnvironment(environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建追踪资源失败: %w", err)
+	}
+	provider := NewProvider(cfg, res, exporter)
+	otel.SetTracerProvider(provider)
+	return provider, nil
+}
+
+// NewProvider 创建TracerProvider，exporter为nil时不导出span
+// 测试中可传入tracetest.NewInMemoryExporter()检查生成的span
+func NewProvider(cfg *Config, res *resource.Resource, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(res),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(options...)
+}
+
+func newExporter(ctx context.Context, cfg *Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("创建OTLP导出器失败: %w", err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("创建stdout导出器失败: %w", err)
+		}
+		return exporter, nil
+	case ExporterNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("不支持的追踪导出方式: %s", cfg.Exporter)
+	}
+}
+
+// tracer 返回本包使用的Tracer，每次从全局TracerProvider获取，Setup前后创建的钩子均可生效
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
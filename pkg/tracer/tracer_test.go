@@ -0,0 +1,136 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupInMemory 将全局TracerProvider替换为导出到内存的实现，测试结束后恢复
+// 返回的函数导出已结束的span并返回全部span
+func setupInMemory(t *testing.T, cfg *Config) func() tracetest.SpanStubs {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(cfg, resource.Empty(), exporter)
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+	return func() tracetest.SpanStubs {
+		if err := provider.ForceFlush(context.Background()); err != nil {
+			t.Fatalf("ForceFlush() error = %v", err)
+		}
+		return exporter.GetSpans()
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "成功", wantStatus: codes.Unset},
+		{name: "键不存在不视为错误", err: redis.Nil, wantStatus: codes.Unset},
+		{name: "命令失败", err: errors.New("READONLY"), wantStatus: codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := setupInMemory(t, &Config{Enabled: true})
+			hook := RedisHook()
+			cmd := redis.NewStringCmd(context.Background(), "get", "key")
+			cmd.SetErr(tt.err)
+
+			ctx, err := hook.BeforeProcess(context.Background(), cmd)
+			if err != nil {
+				t.Fatalf("BeforeProcess() error = %v", err)
+			}
+			if err := hook.AfterProcess(ctx, cmd); err != nil {
+				t.Fatalf("AfterProcess() error = %v", err)
+			}
+
+			got := spans()
+			if len(got) != 1 {
+				t.Fatalf("got %d spans, want 1", len(got))
+			}
+			if got[0].Name != "redis.get" || got[0].SpanKind != trace.SpanKindClient {
+				t.Errorf("span = %s %v, want redis.get client", got[0].Name, got[0].SpanKind)
+			}
+			if got[0].Status.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", got[0].Status.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRedisHookPipeline(t *testing.T) {
+	spans := setupInMemory(t, &Config{Enabled: true})
+	hook := RedisHook()
+	get := redis.NewStringCmd(context.Background(), "get", "key")
+	get.SetErr(redis.Nil)
+	incr := redis.NewIntCmd(context.Background(), "incr", "counter")
+	cmds := []redis.Cmder{get, incr}
+
+	ctx, err := hook.BeforeProcessPipeline(context.Background(), cmds)
+	if err != nil {
+		t.Fatalf("BeforeProcessPipeline() error = %v", err)
+	}
+	if err := hook.AfterProcessPipeline(ctx, cmds); err != nil {
+		t.Fatalf("AfterProcessPipeline() error = %v", err)
+	}
+
+	got := spans()
+	if len(got) != 1 || got[0].Name != "redis.pipeline" {
+		t.Fatalf("got %d spans, want one redis.pipeline span", len(got))
+	}
+	for _, attr := range got[0].Attributes {
+		if attr.Key == "db.operation" && attr.Value.AsString() != "get incr" {
+			t.Errorf("db.operation = %q, want %q", attr.Value.AsString(), "get incr")
+		}
+	}
+}
+
+func TestNewProviderSampling(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	parent := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: flags,
+			Remote:     true,
+		}))
+	}
+	tests := []struct {
+		name        string
+		ratio       float64
+		ctx         context.Context
+		wantSampled bool
+	}{
+		{name: "未配置比例时全部采样", ctx: context.Background(), wantSampled: true},
+		{name: "比例超出范围时全部采样", ratio: 2, ctx: context.Background(), wantSampled: true},
+		{name: "沿用上游的采样决定", ratio: 0.000001, ctx: parent(trace.FlagsSampled), wantSampled: true},
+		{name: "上游未采样时不采样", ctx: parent(0), wantSampled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(&Config{Enabled: true, SampleRatio: tt.ratio}, resource.Empty(), nil)
+			defer provider.Shutdown(context.Background())
+
+			_, span := provider.Tracer("test").Start(tt.ctx, "span")
+			defer span.End()
+			if got := span.SpanContext().IsSampled(); got != tt.wantSampled {
+				t.Errorf("IsSampled() = %v, want %v", got, tt.wantSampled)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package imageproc 处理用户上传的图片：校验尺寸、按EXIF方向矫正、裁剪为正方形并生成多个尺寸
+// 输出图片均重新编码，EXIF等元数据（包括GPS位置）不会保留
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册GIF解码器
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+var (
+	// ErrUnsupportedFormat 表示无法识别的图片格式
+	ErrUnsupportedFormat = errors.New("不支持的图片格式")
+	// ErrImageTooLarge 表示图片像素尺寸超过限制，用于拒绝解压炸弹
+	ErrImageTooLarge = errors.New("图片尺寸过大")
+)
+
+// Options 处理参数
+type Options struct {
+	// MaxPixels 允许解码的最大像素数（宽×高），在完整解码前通过文件头校验
+	MaxPixels int
+	// MaxSize 主图边长上限，超过时等比缩小
+	MaxSize int
+	// Sizes 需要生成的缩略图边长
+	Sizes []int
+	// JPEGQuality JPEG编码质量
+	JPEGQuality int
+}
+
+// Variant 处理后的单个图片
+type Variant struct {
+	// Name 主图为original，缩略图为边长
+	Name        string
+	Size        int
+	Data        []byte
+	ContentType string
+	Ext         string
+}
+
+// SquareImages 将图片处理为正方形主图及缩略图，JPEG输出为JPEG，其他格式输出为PNG以保留透明度
+func SquareImages(r io.Reader, opts Options) ([]Variant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取图片失败: %w", err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > opts.MaxPixels/cfg.Height {
+		return nil, ErrImageTooLarge
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	side := min(img.Bounds().Dx(), img.Bounds().Dy(), opts.MaxSize)
+	square := imaging.Fill(img, side, side, imaging.Center, imaging.Lanczos)
+
+	variants := make([]Variant, 0, len(opts.Sizes)+1)
+	original, err := encode(square, format, opts.JPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+	original.Name, original.Size = "original", side
+	variants = append(variants, original)
+
+	for _, size := range opts.Sizes {
+		thumbnail := square
+		if size < side {
+			thumbnail = imaging.Resize(square, size, size, imaging.Lanczos)
+		}
+		v, err := encode(thumbnail, format, opts.JPEGQuality)
+		if err != nil {
+			return nil, err
+		}
+		v.Name, v.Size = strconv.Itoa(size), min(size, side)
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+func encode(img image.Image, format string, quality int) (Variant, error) {
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return Variant{}, fmt.Errorf("编码图片失败: %w", err)
+		}
+		return Variant{Data: buf.Bytes(), ContentType: "image/jpeg", Ext: ".jpg"}, nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return Variant{}, fmt.Errorf("编码图片失败: %w", err)
+	}
+	return Variant{Data: buf.Bytes(), ContentType: "image/png", Ext: ".png"}, nil
+}
@@ -0,0 +1,136 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func newImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, newImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// forgedPNG 返回文件头声明为w×h、实际只有1×1像素数据的PNG
+func forgedPNG(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	data := encodePNG(t, 1, 1)
+	// 8字节签名后是IHDR块：长度(4) 类型(4) 宽(4) 高(4) ... CRC(4)
+	binary.BigEndian.PutUint32(data[16:20], w)
+	binary.BigEndian.PutUint32(data[20:24], h)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestSquareImagesRejectsInvalidInput(t *testing.T) {
+	opts := Options{MaxPixels: 1000 * 1000, MaxSize: 512, Sizes: []int{64}, JPEGQuality: 85}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "文件头尺寸超过限制", data: forgedPNG(t, 50000, 50000), wantErr: ErrImageTooLarge},
+		{name: "单边超长", data: forgedPNG(t, 1000*1000+1, 1), wantErr: ErrImageTooLarge},
+		{name: "单边刚好超过限制", data: forgedPNG(t, 1001, 1000), wantErr: ErrImageTooLarge},
+		{name: "截断的PNG", data: encodePNG(t, 64, 64)[:60], wantErr: ErrUnsupportedFormat},
+		{name: "截断的JPEG", data: encodeJPEG(t, 64, 64)[:200], wantErr: ErrUnsupportedFormat},
+		{name: "非图片内容", data: []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"), wantErr: ErrUnsupportedFormat},
+		{name: "空内容", data: nil, wantErr: ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SquareImages(bytes.NewReader(tt.data), opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SquareImages() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSquareImages(t *testing.T) {
+	type want struct {
+		name string
+		side int
+	}
+	tests := []struct {
+		name            string
+		data            []byte
+		opts            Options
+		wantContentType string
+		want            []want
+	}{
+		{
+			name:            "横向PNG裁剪为短边",
+			data:            encodePNG(t, 300, 200),
+			opts:            Options{MaxPixels: 300 * 200, MaxSize: 512, Sizes: []int{64, 128}},
+			wantContentType: "image/png",
+			want:            []want{{"original", 200}, {"64", 64}, {"128", 128}},
+		},
+		{
+			name:            "纵向JPEG超过边长上限时缩小",
+			data:            encodeJPEG(t, 200, 300),
+			opts:            Options{MaxPixels: 1000 * 1000, MaxSize: 150, Sizes: []int{48}, JPEGQuality: 85},
+			wantContentType: "image/jpeg",
+			want:            []want{{"original", 150}, {"48", 48}},
+		},
+		{
+			name:            "缩略图大于主图时不放大",
+			data:            encodePNG(t, 90, 120),
+			opts:            Options{MaxPixels: 1000 * 1000, MaxSize: 512, Sizes: []int{256}},
+			wantContentType: "image/png",
+			want:            []want{{"original", 90}, {"256", 90}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, err := SquareImages(bytes.NewReader(tt.data), tt.opts)
+			if err != nil {
+				t.Fatalf("SquareImages() error = %v", err)
+			}
+			if len(variants) != len(tt.want) {
+				t.Fatalf("SquareImages() returned %d variants, want %d", len(variants), len(tt.want))
+			}
+			for i, v := range variants {
+				if v.Name != tt.want[i].name || v.Size != tt.want[i].side || v.ContentType != tt.wantContentType {
+					t.Errorf("variant %d = {%s %d %s}, want {%s %d %s}", i, v.Name, v.Size, v.ContentType, tt.want[i].name, tt.want[i].side, tt.wantContentType)
+				}
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(v.Data))
+				if err != nil {
+					t.Fatalf("decode variant %s: %v", v.Name, err)
+				}
+				if cfg.Width != tt.want[i].side || cfg.Height != tt.want[i].side {
+					t.Errorf("variant %s = %dx%d, want %dx%d", v.Name, cfg.Width, cfg.Height, tt.want[i].side, tt.want[i].side)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package api_key_controller
+
+import (
+	"errors"
+	"strconv"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_apiKeyInterface "gin-center/internal/domain/interface/api_key"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// APIKeyController API密钥控制器，管理当前登录主体的API密钥与个人访问令牌
+type APIKeyController struct {
+	base_controller.BaseController
+	apiKeyService use_apiKeyInterface.APIKeyServiceInterface
+}
+
+// NewAPIKeyController 创建新的API密钥控制器实例
+func NewAPIKeyController(apiKeyService use_apiKeyInterface.APIKeyServiceInterface, logger *zaplogger.ServiceLogger) *APIKeyController {
+	return &APIKeyController{
+		BaseController: *base_controller.NewBaseController(logger),
+		apiKeyService:  apiKeyService,
+	}
+}
+
+// @Summary 获取我的API密钥
+// @Description 列出未撤销的API密钥与个人访问令牌，不包含明文
+// @Tags API密钥
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.APIKeyResponse} "获取成功"
+// @Router /api/v1/user/api-keys [get]
+func (c *APIKeyController) List(ctx *gin.Context) {
+	keys, err := c.apiKeyService.List(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"))
+	if err != nil {
+		use_response.ServerError(ctx, "获取API密钥失败")
+		return
+	}
+	use_response.Success(ctx, keys)
+}
+
+// @Summary 创建API密钥
+// @Description 创建API密钥或个人访问令牌，权限范围不能超出当前用户的权限，明文仅返回一次
+// @Tags API密钥
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body structs.CreateAPIKeyRequest true "密钥参数"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.APIKeyCreatedResponse} "创建成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误或权限范围无效"
+// @Router /api/v1/user/api-keys [post]
+func (c *APIKeyController) Create(ctx *gin.Context) {
+	var req structs.CreateAPIKeyRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	key, err := c.apiKeyService.Create(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), &req)
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidPermission) {
+			use_response.BadRequest(ctx, "权限范围超出当前用户的权限")
+			return
+		}
+		c.Logger.LogError("创建API密钥失败", zap.Error(err))
+		use_response.ServerError(ctx, "创建API密钥失败")
+		return
+	}
+	use_response.Success(ctx, key)
+}
+
+// @Summary 撤销API密钥
+// @Tags API密钥
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "密钥ID"
+// @Success 200 {object} type_response.BaseResponse "撤销成功"
+// @Failure 404 {object} type_response.BaseResponse "密钥不存在"
+// @Router /api/v1/user/api-keys/{id} [delete]
+func (c *APIKeyController) Revoke(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		use_response.BadRequest(ctx, "无效的密钥ID")
+		return
+	}
+	err = c.apiKeyService.Revoke(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		if errors.Is(err, constants.ErrAPIKeyNotFound) {
+			use_response.NotFound(ctx, "API密钥不存在")
+			return
+		}
+		use_response.ServerError(ctx, "撤销API密钥失败")
+		return
+	}
+	use_response.Success(ctx, nil)
+}
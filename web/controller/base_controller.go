@@ -1,23 +1,31 @@
 package base_controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
 
+	"gin-center/internal/types/auth"
 	"gin-center/internal/types/constants"
+	"gin-center/pkg/http/clientip"
+	use_headers "gin-center/pkg/http/headers"
 	use_response "gin-center/pkg/http/response"
+	security_types "gin-center/pkg/security/types"
+	"gin-center/pkg/security/useCookie"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	infraErrors "gin-center/infrastructure/errors"
 	zaplogger "gin-center/infrastructure/zaplogger"
 	type_response "gin-center/internal/types/response"
 )
 
 // BaseController 基础控制器结构体，提供通用的控制器功能
 type BaseController struct {
-	Logger *zaplogger.ServiceLogger // 使用封装后的日志记录器
+	Logger  *zaplogger.ServiceLogger // 使用封装后的日志记录器
+	Cookies *useCookie.Transport     // 令牌Cookie传输，下发令牌的控制器设置
 }
 
 // ParsePaginationParams 解析分页参数
@@ -49,10 +57,7 @@ func (c *BaseController) SendResponse(ctx *gin.Context, code int, message string
 
 // HandleError 统一错误处理方法
 func (c *BaseController) HandleError(ctx *gin.Context, err error) {
-	c.Logger.LogError("请求处理发生错误",
-		zap.Error(err),
-		zap.String("request_id", ctx.GetString("request_id")),
-	)
+	c.Logger.Ctx(ctx.Request.Context()).LogError("请求处理发生错误", zap.Error(err))
 	c.SendResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
 }
 
@@ -88,31 +93,95 @@ func (c *BaseController) SendNotFound(ctx *gin.Context, message string) {
 
 // HandleLogin 通用登录处理方法
 func (c *BaseController) HandleLogin(ctx *gin.Context, authService interface {
-	Login(username, password string) (string, map[string]interface{}, error)
+	Login(ctx context.Context, username, password string, meta *auth.LoginMeta) (*security_types.TokenPair, map[string]interface{}, error)
 }) {
 	var req struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
+		Username    string `json:"username" binding:"required"`
+		Password    string `json:"password" binding:"required"`
+		Fingerprint string `json:"fingerprint"`
+		Device      string `json:"device" binding:"omitempty,max=64"`
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
+		if c.PayloadTooLarge(ctx, err) {
+			return
+		}
 		c.SendBadRequest(ctx, "无效的请求参数")
 		return
 	}
 
-	token, data, err := authService.Login(req.Username, req.Password)
+	tokens, data, err := authService.Login(ctx.Request.Context(), req.Username, req.Password, c.LoginMeta(ctx, req.Fingerprint, req.Device))
 	if err != nil {
 		c.SendUnauthorized(ctx, "认证失败")
 		return
 	}
 
-	c.SendSuccess(ctx, gin.H{
-		"token": token,
-		"data":  data,
+	result := gin.H{
+		"token":  tokens.AccessToken,
+		"tokens": tokens,
+		"data":   data,
 		"user": gin.H{
 			"username": req.Username,
 		},
-	})
+	}
+	if err := c.UseTokenCookies(ctx, result); err != nil {
+		c.HandleError(ctx, err)
+		return
+	}
+	c.SendSuccess(ctx, result)
+}
+
+// UseTokenCookies 客户端选择Cookie传输时，将登录结果中的令牌改为Cookie下发并从响应中移除
+func (c *BaseController) UseTokenCookies(ctx *gin.Context, result map[string]interface{}) error {
+	return c.useTokenCookies(ctx, result, c.Cookies.Requested(ctx))
+}
+
+// UseTokenCookiesOnNavigation 同UseTokenCookies，浏览器顶层导航同样以Cookie下发，仅用于第三方登录回调
+func (c *BaseController) UseTokenCookiesOnNavigation(ctx *gin.Context, result map[string]interface{}) error {
+	return c.useTokenCookies(ctx, result, c.Cookies.RequestedOrNavigated(ctx))
+}
+
+func (c *BaseController) useTokenCookies(ctx *gin.Context, result map[string]interface{}, requested bool) error {
+	tokens, ok := result["tokens"].(*security_types.TokenPair)
+	if !ok || !requested {
+		return nil
+	}
+	fields, err := c.TokenCookies(ctx, tokens)
+	if err != nil {
+		return err
+	}
+	delete(result, "token")
+	delete(result, "tokens")
+	for k, v := range fields {
+		result[k] = v
+	}
+	return nil
+}
+
+// TokenCookies 以Cookie下发令牌对，返回代替令牌写入响应的访问令牌有效期与CSRF令牌
+func (c *BaseController) TokenCookies(ctx *gin.Context, tokens *security_types.TokenPair) (gin.H, error) {
+	csrf, err := c.Cookies.SetTokens(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"expires_in": tokens.ExpiresIn,
+		"csrf_token": csrf,
+	}, nil
+}
+
+// LoginMeta 采集登录请求的客户端信息，未指定设备名时根据User-Agent识别
+func (c *BaseController) LoginMeta(ctx *gin.Context, fingerprint, device string) *auth.LoginMeta {
+	userAgent := ctx.Request.UserAgent()
+	if device == "" {
+		device = use_headers.DetectDevice(userAgent)
+	}
+	return &auth.LoginMeta{
+		IP:          clientip.Get(ctx),
+		UserAgent:   userAgent,
+		Device:      device,
+		Fingerprint: fingerprint,
+	}
 }
 
 // 从上下文获取当前用户名
@@ -129,9 +198,23 @@ func (c *BaseController) GetCurrentUsername(ctx *gin.Context) (string, error) {
 // 请求参数验证方法
 func (c *BaseController) ValidateRequest(ctx *gin.Context, req interface{}) error {
 	if err := ctx.ShouldBindJSON(req); err != nil {
+		if c.PayloadTooLarge(ctx, err) {
+			return err
+		}
 		c.Logger.LogError("请求参数绑定失败", zap.Error(err))
 		use_response.BadRequest(ctx, "无效的请求参数")
 		return err
 	}
 	return nil
 }
+
+// PayloadTooLarge 读取请求体超出server.limits的大小限制时返回413，已响应时返回true
+func (c *BaseController) PayloadTooLarge(ctx *gin.Context, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	c.Logger.Ctx(ctx.Request.Context()).LogWarn("请求体超出大小限制", zap.Int64("limit", maxBytesErr.Limit))
+	infraErrors.Abort(ctx, infraErrors.ErrPayloadTooLarge)
+	return true
+}
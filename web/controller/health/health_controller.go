@@ -0,0 +1,60 @@
+package health_controller
+
+import (
+	"net/http"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	"gin-center/pkg/health"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HealthController 存活与就绪探针
+type HealthController struct {
+	base_controller.BaseController
+	registry *health.Registry
+}
+
+// NewHealthController 创建新的探针控制器实例
+func NewHealthController(registry *health.Registry, logger *zaplogger.ServiceLogger) *HealthController {
+	return &HealthController{
+		BaseController: *base_controller.NewBaseController(logger),
+		registry:       registry,
+	}
+}
+
+// @Summary 存活探针
+// @Description 进程能够处理请求即返回200，不检查外部依赖，服务关闭期间仍返回200
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} map[string]string "进程存活"
+// @Router /livez [get]
+func (c *HealthController) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// @Summary 就绪探针
+// @Description 执行全部依赖检查，任一失败或服务正在关闭时返回503；失败原因只记录日志，不在响应中返回
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} health.Report "可以接收流量"
+// @Failure 503 {object} health.Report "依赖不可用或服务正在关闭"
+// @Router /readyz [get]
+func (c *HealthController) Ready(ctx *gin.Context) {
+	report := c.registry.Ready(ctx.Request.Context())
+	for name, result := range report.Checks {
+		if result.Status != health.StatusUp {
+			c.Logger.Ctx(ctx.Request.Context()).LogWarn("就绪检查失败", zap.String("check", name), zap.String("error", result.Error))
+		}
+		// 探针无需认证，错误信息可能包含数据库、Redis等内部地址，不返回
+		result.Error = ""
+		report.Checks[name] = result
+	}
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
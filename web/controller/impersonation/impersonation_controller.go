@@ -0,0 +1,87 @@
+package impersonation_controller
+
+import (
+	"errors"
+	"strconv"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_impersonationInterface "gin-center/internal/domain/interface/impersonation"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	type_response "gin-center/internal/types/response"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ImpersonationController 模拟登录控制器
+type ImpersonationController struct {
+	base_controller.BaseController
+	impersonationService use_impersonationInterface.ImpersonationServiceInterface
+}
+
+// NewImpersonationController 创建新的模拟登录控制器实例
+func NewImpersonationController(impersonationService use_impersonationInterface.ImpersonationServiceInterface, logger *zaplogger.ServiceLogger) *ImpersonationController {
+	return &ImpersonationController{
+		BaseController:       *base_controller.NewBaseController(logger),
+		impersonationService: impersonationService,
+	}
+}
+
+// @Summary 模拟用户登录
+// @Description 为目标用户签发短期访问令牌，令牌的act声明记录发起模拟的管理员，期间的请求均写入审计日志
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.ImpersonationResponse} "模拟成功"
+// @Failure 403 {object} type_response.BaseResponse "用户已被禁用"
+// @Failure 404 {object} type_response.BaseResponse "用户不存在"
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (c *ImpersonationController) Impersonate(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		use_response.BadRequest(ctx, "无效的用户ID")
+		return
+	}
+	actor := &auth.Actor{ID: ctx.GetUint("user_id"), Username: ctx.GetString("username")}
+	result, err := c.impersonationService.Start(ctx.Request.Context(), actor, uint(userID), c.LoginMeta(ctx, "", ""))
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrUserNotFound):
+			use_response.NotFound(ctx, "用户不存在")
+		case errors.Is(err, constants.ErrUserInactive):
+			use_response.Forbidden(ctx, "用户已被禁用")
+		default:
+			c.Logger.LogError("模拟用户登录失败", zap.Uint("user_id", uint(userID)), zap.Uint("actor_id", actor.ID), zap.Error(err))
+			use_response.ServerError(ctx, "模拟用户登录失败")
+		}
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 获取当前认证主体
+// @Description 返回当前请求的认证主体，actor非空表示处于模拟登录状态
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=type_response.WhoAmIResponse} "获取成功"
+// @Router /api/v1/auth/me [get]
+func (c *ImpersonationController) WhoAmI(ctx *gin.Context) {
+	result := type_response.WhoAmIResponse{
+		UserID:     ctx.GetUint("user_id"),
+		Username:   ctx.GetString("username"),
+		Role:       ctx.GetString("role"),
+		AuthMethod: ctx.GetString("auth_method"),
+	}
+	if actorID := ctx.GetUint("actor_id"); actorID != 0 {
+		result.Actor = &type_response.ActorResponse{
+			ID:       actorID,
+			Username: ctx.GetString("actor_username"),
+		}
+	}
+	use_response.Success(ctx, result)
+}
@@ -0,0 +1,98 @@
+package ip_rule_controller
+
+import (
+	"errors"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_ipRuleInterface "gin-center/internal/domain/interface/ip_rule"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	"gin-center/pkg/http/clientip"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IPRuleController 路由组访问控制规则管理
+type IPRuleController struct {
+	base_controller.BaseController
+	ipRuleService use_ipRuleInterface.IPRuleServiceInterface
+}
+
+// NewIPRuleController 创建新的访问控制规则控制器实例
+func NewIPRuleController(ipRuleService use_ipRuleInterface.IPRuleServiceInterface, logger *zaplogger.ServiceLogger) *IPRuleController {
+	return &IPRuleController{
+		BaseController: *base_controller.NewBaseController(logger),
+		ipRuleService:  ipRuleService,
+	}
+}
+
+// @Summary 获取访问控制规则
+// @Description 包括配置文件中的规则（source为config，不可删除）与通过接口添加的规则
+// @Tags 访问控制
+// @Produce json
+// @Security ApiKeyAuth
+// @Param group query string false "路由组，为空时返回全部"
+// @Success 200 {object} type_response.BaseResponse{data=[]ip_rule_model.IPRule} "获取成功"
+// @Router /api/v1/admin/ip-rules [get]
+func (c *IPRuleController) List(ctx *gin.Context) {
+	rules, err := c.ipRuleService.ListRules(ctx.Request.Context(), ctx.Query("group"))
+	if err != nil {
+		c.Logger.LogError("获取访问控制规则失败", zap.Error(err))
+		use_response.ServerError(ctx, "获取访问控制规则失败")
+		return
+	}
+	use_response.Success(ctx, rules)
+}
+
+// @Summary 添加访问控制规则
+// @Description 规则立即在所有实例生效；先匹配禁止规则，存在允许规则时只有命中允许规则的地址可以访问
+// @Tags 访问控制
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body structs.IPRuleRequest true "规则参数"
+// @Success 200 {object} type_response.BaseResponse{data=ip_rule_model.IPRule} "添加成功"
+// @Failure 400 {object} type_response.BaseResponse "规则格式错误、未配置地理位置库或规则会阻止当前IP访问管理端"
+// @Router /api/v1/admin/ip-rules [post]
+func (c *IPRuleController) Create(ctx *gin.Context) {
+	var req structs.IPRuleRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	rule, err := c.ipRuleService.CreateRule(ctx.Request.Context(), &req, ctx.GetUint("user_id"), clientip.Get(ctx))
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidIPRule) {
+			use_response.BadRequest(ctx, err.Error())
+			return
+		}
+		c.Logger.LogError("添加访问控制规则失败", zap.Error(err))
+		use_response.ServerError(ctx, "添加访问控制规则失败")
+		return
+	}
+	use_response.Success(ctx, rule)
+}
+
+// @Summary 删除访问控制规则
+// @Description 只能删除通过接口添加的规则
+// @Tags 访问控制
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "规则ID"
+// @Success 200 {object} type_response.BaseResponse "删除成功"
+// @Failure 404 {object} type_response.BaseResponse "规则不存在"
+// @Router /api/v1/admin/ip-rules/{id} [delete]
+func (c *IPRuleController) Delete(ctx *gin.Context) {
+	if err := c.ipRuleService.DeleteRule(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		if errors.Is(err, constants.ErrIPRuleNotFound) {
+			use_response.NotFound(ctx, "规则不存在")
+			return
+		}
+		c.Logger.LogError("删除访问控制规则失败", zap.Error(err))
+		use_response.ServerError(ctx, "删除访问控制规则失败")
+		return
+	}
+	use_response.Success(ctx, nil)
+}
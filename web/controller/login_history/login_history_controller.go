@@ -0,0 +1,69 @@
+package login_history_controller
+
+import (
+	"strconv"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_loginHistoryInterface "gin-center/internal/domain/interface/login_history"
+	SessionModel "gin-center/internal/domain/model/session"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginHistoryController 登录历史控制器
+type LoginHistoryController struct {
+	base_controller.BaseController
+	historyService use_loginHistoryInterface.LoginHistoryServiceInterface
+}
+
+// NewLoginHistoryController 创建新的登录历史控制器实例
+func NewLoginHistoryController(historyService use_loginHistoryInterface.LoginHistoryServiceInterface, logger *zaplogger.ServiceLogger) *LoginHistoryController {
+	return &LoginHistoryController{
+		BaseController: *base_controller.NewBaseController(logger),
+		historyService: historyService,
+	}
+}
+
+// @Summary 获取用户登录历史
+// @Description 管理员分页查看指定用户的登录历史，包括失败的登录尝试
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param page query int false "页码，默认1" default(1)
+// @Param page_size query int false "每页数量，默认10" default(10)
+// @Param success query bool false "按登录结果筛选"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.LoginHistoryListResponse} "获取成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Router /api/v1/admin/users/{id}/login-history [get]
+func (c *LoginHistoryController) ListUserLoginHistory(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		use_response.BadRequest(ctx, "无效的用户ID")
+		return
+	}
+	page, pageSize, err := c.ParsePaginationParams(ctx)
+	if err != nil {
+		use_response.BadRequest(ctx, "无效的分页参数")
+		return
+	}
+
+	var success *bool
+	if raw := ctx.Query("success"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			use_response.BadRequest(ctx, "无效的success参数")
+			return
+		}
+		success = &value
+	}
+
+	result, err := c.historyService.ListByUser(ctx.Request.Context(), SessionModel.RoleUser, uint(userID), success, page, pageSize)
+	if err != nil {
+		use_response.ServerError(ctx, "获取登录历史失败")
+		return
+	}
+	use_response.Success(ctx, result)
+}
@@ -0,0 +1,232 @@
+package oauth_controller
+
+import (
+	"errors"
+	"net/http"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_oauthInterface "gin-center/internal/domain/interface/oauth"
+	IdentityModel "gin-center/internal/domain/model/identity"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/constants"
+	use_response "gin-center/pkg/http/response"
+	"gin-center/pkg/security/useCookie"
+	"gin-center/pkg/security/useOAuth"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// bindingCookie 授权请求的浏览器绑定Cookie，只随回调请求发送
+// 提供方经浏览器顶层跳转回调，SameSite须为Lax才会携带该Cookie
+const (
+	bindingCookie     = "oauth_binding"
+	bindingCookiePath = "/api/v1/auth/oauth"
+)
+
+// OAuthController 第三方登录控制器，处理授权跳转、回调及身份关联请求
+type OAuthController struct {
+	base_controller.BaseController
+	oauthService use_oauthInterface.OAuthServiceInterface
+}
+
+// NewOAuthController 创建新的第三方登录控制器实例
+func NewOAuthController(oauthService use_oauthInterface.OAuthServiceInterface, cookies *useCookie.Transport, logger *zaplogger.ServiceLogger) *OAuthController {
+	c := &OAuthController{
+		BaseController: *base_controller.NewBaseController(logger),
+		oauthService:   oauthService,
+	}
+	c.Cookies = cookies
+	return c
+}
+
+// @Summary 获取第三方登录提供方
+// @Description 获取已启用的第三方登录提供方列表
+// @Tags 第三方登录
+// @Produce json
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.OAuthProviderResponse} "获取成功"
+// @Router /api/v1/auth/oauth/providers [get]
+func (c *OAuthController) ListProviders(ctx *gin.Context) {
+	use_response.Success(ctx, c.oauthService.Providers())
+}
+
+// @Summary 发起第三方登录
+// @Description 重定向到提供方的授权页面，使用授权码模式并启用PKCE
+// @Tags 第三方登录
+// @Param provider path string true "提供方名称"
+// @Success 302 "重定向到授权页面"
+// @Failure 404 {object} type_response.BaseResponse "提供方不存在"
+// @Router /api/v1/auth/oauth/{provider}/authorize [get]
+func (c *OAuthController) Authorize(ctx *gin.Context) {
+	authURL, binding, err := c.oauthService.Authorize(ctx.Request.Context(), ctx.Param("provider"), IdentityModel.StateModeLogin, 0)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	setBinding(ctx, binding)
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary 第三方登录回调
+// @Description 处理提供方回调；登录模式返回令牌，关联模式返回关联结果
+// @Description 回调须携带发起授权时下发的oauth_binding Cookie；关联模式还须以发起关联的用户登录
+// @Tags 第三方登录
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Param code query string true "授权码"
+// @Param state query string true "授权状态"
+// @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "登录成功"
+// @Failure 400 {object} type_response.BaseResponse "授权状态无效"
+// @Failure 401 {object} type_response.BaseResponse "第三方身份校验失败"
+// @Failure 403 {object} type_response.BaseResponse "第三方身份未关联、账户已禁用或关联用户不一致"
+// @Failure 409 {object} type_response.BaseResponse "第三方身份已关联其他用户"
+// @Router /api/v1/auth/oauth/{provider}/callback [get]
+func (c *OAuthController) Callback(ctx *gin.Context) {
+	if idpErr := ctx.Query("error"); idpErr != "" {
+		c.Logger.LogWarn("第三方授权被拒绝",
+			zap.String("provider", ctx.Param("provider")),
+			zap.String("error", idpErr),
+			zap.String("description", ctx.Query("error_description")))
+		use_response.BadRequest(ctx, "第三方授权失败: "+idpErr)
+		return
+	}
+	code, state := ctx.Query("code"), ctx.Query("state")
+	if code == "" || state == "" {
+		use_response.BadRequest(ctx, "缺少授权码或授权状态")
+		return
+	}
+
+	binding, _ := ctx.Cookie(bindingCookie)
+	setBinding(ctx, "")
+	var currentUserID uint
+	if ctx.GetString("role") == SessionModel.RoleUser {
+		currentUserID = ctx.GetUint("user_id")
+	}
+
+	result, err := c.oauthService.Callback(ctx.Request.Context(), ctx.Param("provider"), code, state, binding, currentUserID, c.LoginMeta(ctx, "", ""))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	if err := c.UseTokenCookiesOnNavigation(ctx, result); err != nil {
+		c.Logger.LogError("下发令牌Cookie失败", zap.Error(err))
+		use_response.ServerError(ctx, "登录失败")
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 获取已关联的第三方身份
+// @Tags 第三方登录
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.IdentityResponse} "获取成功"
+// @Router /api/v1/user/identities [get]
+func (c *OAuthController) ListIdentities(ctx *gin.Context) {
+	if !c.requireUser(ctx) {
+		return
+	}
+	identities, err := c.oauthService.ListIdentities(ctx.Request.Context(), ctx.GetUint("user_id"))
+	if err != nil {
+		use_response.ServerError(ctx, "获取第三方身份失败")
+		return
+	}
+	use_response.Success(ctx, identities)
+}
+
+// @Summary 关联第三方身份
+// @Description 返回提供方授权地址并下发浏览器绑定Cookie，用户在同一浏览器完成授权且回调时仍以该用户登录即完成关联
+// @Tags 第三方登录
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "获取成功"
+// @Failure 404 {object} type_response.BaseResponse "提供方不存在"
+// @Router /api/v1/user/identities/{provider} [post]
+func (c *OAuthController) Link(ctx *gin.Context) {
+	if !c.requireUser(ctx) {
+		return
+	}
+	authURL, binding, err := c.oauthService.Authorize(ctx.Request.Context(), ctx.Param("provider"), IdentityModel.StateModeLink, ctx.GetUint("user_id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	setBinding(ctx, binding)
+	use_response.Success(ctx, gin.H{"authorization_url": authURL})
+}
+
+// @Summary 解除第三方身份关联
+// @Tags 第三方登录
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} type_response.BaseResponse "解除成功"
+// @Failure 404 {object} type_response.BaseResponse "未关联该提供方"
+// @Router /api/v1/user/identities/{provider} [delete]
+func (c *OAuthController) Unlink(ctx *gin.Context) {
+	if !c.requireUser(ctx) {
+		return
+	}
+	err := c.oauthService.Unlink(ctx.Request.Context(), ctx.GetUint("user_id"), ctx.Param("provider"))
+	if errors.Is(err, constants.ErrIdentityNotLinked) {
+		use_response.NotFound(ctx, "未关联该第三方身份")
+		return
+	}
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+// requireUser 第三方身份仅支持关联到普通用户
+func (c *OAuthController) requireUser(ctx *gin.Context) bool {
+	if ctx.GetString("role") != SessionModel.RoleUser {
+		use_response.Forbidden(ctx, "仅普通用户可关联第三方身份")
+		return false
+	}
+	return true
+}
+
+// setBinding 写入浏览器绑定Cookie，value为空时删除
+// 请求经HTTPS到达时（包括TLS终止代理转发的请求）设置Secure
+func setBinding(ctx *gin.Context, value string) {
+	maxAge := -1
+	if value != "" {
+		maxAge = 0
+	}
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     bindingCookie,
+		Value:    value,
+		Path:     bindingCookiePath,
+		MaxAge:   maxAge,
+		Secure:   ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// handleError 将第三方登录相关错误映射为HTTP响应
+func (c *OAuthController) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, useOAuth.ErrUnknownProvider):
+		use_response.NotFound(ctx, "第三方登录提供方不存在")
+	case errors.Is(err, constants.ErrInvalidOAuthState):
+		use_response.BadRequest(ctx, "授权状态无效或已过期")
+	case errors.Is(err, constants.ErrIdentityNotLinked):
+		use_response.Forbidden(ctx, "第三方身份未关联任何用户")
+	case errors.Is(err, constants.ErrOAuthLinkMismatch):
+		use_response.Forbidden(ctx, "须以发起关联的用户登录后完成关联")
+	case errors.Is(err, constants.ErrIdentityLinked):
+		c.SendConflict(ctx, "第三方身份已关联其他用户")
+	case errors.Is(err, constants.ErrUserInactive):
+		use_response.Forbidden(ctx, "账户已禁用")
+	case errors.Is(err, useOAuth.ErrMissingIDToken), errors.Is(err, useOAuth.ErrNonceMismatch):
+		use_response.Unauthorized(ctx, "第三方身份校验失败")
+	default:
+		c.Logger.LogError("第三方登录处理失败", zap.String("provider", ctx.Param("provider")), zap.Error(err))
+		use_response.ServerError(ctx, "第三方登录失败")
+	}
+}
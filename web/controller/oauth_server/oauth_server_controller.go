@@ -0,0 +1,377 @@
+package oauth_server_controller
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_oauthServerInterface "gin-center/internal/domain/interface/oauth_server"
+	OAuthClientModel "gin-center/internal/domain/model/oauth_client"
+	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	use_headers "gin-center/pkg/http/headers"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OAuthServerController 授权服务控制器
+// 令牌、内省、撤销及用户信息端点按OAuth2规范返回，不使用统一响应结构
+type OAuthServerController struct {
+	base_controller.BaseController
+	oauthServer use_oauthServerInterface.OAuthServerServiceInterface
+}
+
+// NewOAuthServerController 创建新的授权服务控制器实例
+func NewOAuthServerController(oauthServer use_oauthServerInterface.OAuthServerServiceInterface, logger *zaplogger.ServiceLogger) *OAuthServerController {
+	return &OAuthServerController{
+		BaseController: *base_controller.NewBaseController(logger),
+		oauthServer:    oauthServer,
+	}
+}
+
+// @Summary 创建OAuth客户端
+// @Description 登记接入授权服务的应用，机密客户端的密钥仅在本次返回
+// @Tags 授权服务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body structs.OAuthClientRequest true "客户端信息"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.OAuthClientResponse} "创建成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Router /api/v1/admin/oauth/clients [post]
+func (c *OAuthServerController) CreateClient(ctx *gin.Context) {
+	var req structs.OAuthClientRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	client, err := c.oauthServer.CreateClient(ctx.Request.Context(), &req, ctx.GetUint("user_id"))
+	if err != nil {
+		c.handleError(ctx, err, "创建客户端失败")
+		return
+	}
+	use_response.Success(ctx, client)
+}
+
+// @Summary 获取OAuth客户端列表
+// @Tags 授权服务
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "页码，默认1" default(1)
+// @Param page_size query int false "每页数量，默认10" default(10)
+// @Success 200 {object} type_response.BaseResponse{data=type_response.OAuthClientListResponse} "获取成功"
+// @Router /api/v1/admin/oauth/clients [get]
+func (c *OAuthServerController) ListClients(ctx *gin.Context) {
+	page, pageSize, err := c.ParsePaginationParams(ctx)
+	if err != nil {
+		use_response.BadRequest(ctx, "无效的分页参数")
+		return
+	}
+	result, err := c.oauthServer.ListClients(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		use_response.ServerError(ctx, "获取客户端列表失败")
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 更新OAuth客户端
+// @Description 客户端类型创建后不可修改
+// @Tags 授权服务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "客户端记录ID"
+// @Param request body structs.OAuthClientRequest true "客户端信息"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.OAuthClientResponse} "更新成功"
+// @Failure 404 {object} type_response.BaseResponse "客户端不存在"
+// @Router /api/v1/admin/oauth/clients/{id} [put]
+func (c *OAuthServerController) UpdateClient(ctx *gin.Context) {
+	id, ok := c.parseID(ctx)
+	if !ok {
+		return
+	}
+	var req structs.OAuthClientRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	client, err := c.oauthServer.UpdateClient(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.handleError(ctx, err, "更新客户端失败")
+		return
+	}
+	use_response.Success(ctx, client)
+}
+
+// @Summary 删除OAuth客户端
+// @Tags 授权服务
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "客户端记录ID"
+// @Success 200 {object} type_response.BaseResponse "删除成功"
+// @Failure 404 {object} type_response.BaseResponse "客户端不存在"
+// @Router /api/v1/admin/oauth/clients/{id} [delete]
+func (c *OAuthServerController) DeleteClient(ctx *gin.Context) {
+	id, ok := c.parseID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.oauthServer.DeleteClient(ctx.Request.Context(), id); err != nil {
+		c.handleError(ctx, err, "删除客户端失败")
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+// @Summary 重置OAuth客户端密钥
+// @Description 生成新的客户端密钥，旧密钥立即失效
+// @Tags 授权服务
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "客户端记录ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.OAuthClientResponse} "重置成功"
+// @Failure 404 {object} type_response.BaseResponse "客户端不存在"
+// @Router /api/v1/admin/oauth/clients/{id}/secret [post]
+func (c *OAuthServerController) RotateSecret(ctx *gin.Context) {
+	id, ok := c.parseID(ctx)
+	if !ok {
+		return
+	}
+	client, err := c.oauthServer.RotateSecret(ctx.Request.Context(), id)
+	if err != nil {
+		c.handleError(ctx, err, "重置客户端密钥失败")
+		return
+	}
+	use_response.Success(ctx, client)
+}
+
+// @Summary 获取授权确认信息
+// @Description 校验授权请求，返回授权确认页需要展示的客户端名称与授权范围
+// @Tags 授权服务
+// @Produce json
+// @Security ApiKeyAuth
+// @Param response_type query string true "固定为code"
+// @Param client_id query string true "客户端ID"
+// @Param redirect_uri query string true "回调地址"
+// @Param scope query string false "授权范围，空格分隔"
+// @Param state query string false "客户端状态"
+// @Param code_challenge query string true "PKCE挑战值"
+// @Param code_challenge_method query string true "固定为S256"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.AuthorizationPreviewResponse} "校验通过"
+// @Failure 400 {object} type_response.BaseResponse "授权请求无效"
+// @Router /api/v1/oauth/authorize [get]
+func (c *OAuthServerController) PreviewAuthorization(ctx *gin.Context) {
+	var req structs.AuthorizeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		use_response.BadRequest(ctx, "无效的请求参数")
+		return
+	}
+	preview, err := c.oauthServer.PreviewAuthorization(ctx.Request.Context(), &req)
+	if err != nil {
+		c.handleError(ctx, err, "校验授权请求失败")
+		return
+	}
+	use_response.Success(ctx, preview)
+}
+
+// @Summary 确认授权
+// @Description 当前登录用户同意或拒绝授权，返回浏览器需要跳转的回调地址
+// @Tags 授权服务
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body structs.AuthorizeRequest true "授权请求参数"
+// @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "处理成功"
+// @Failure 400 {object} type_response.BaseResponse "授权请求无效"
+// @Router /api/v1/oauth/authorize [post]
+func (c *OAuthServerController) Authorize(ctx *gin.Context) {
+	var req structs.AuthorizeRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	redirectTo, err := c.oauthServer.Authorize(ctx.Request.Context(), &req, ctx.GetString("role"), ctx.GetUint("user_id"), ctx.GetString("username"))
+	if err != nil {
+		c.handleError(ctx, err, "授权失败")
+		return
+	}
+	use_response.Success(ctx, gin.H{"redirect_to": redirectTo})
+}
+
+// @Summary 令牌端点
+// @Description 支持authorization_code(需PKCE)与client_credentials两种授权模式
+// @Tags 授权服务
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "授权模式"
+// @Param code formData string false "授权码"
+// @Param redirect_uri formData string false "回调地址"
+// @Param code_verifier formData string false "PKCE校验值"
+// @Param scope formData string false "授权范围"
+// @Success 200 {object} type_response.OAuthTokenResponse "签发成功"
+// @Failure 400 {object} auth.OAuthError "请求无效"
+// @Failure 401 {object} auth.OAuthError "客户端认证失败"
+// @Router /api/v1/oauth/token [post]
+func (c *OAuthServerController) Token(ctx *gin.Context) {
+	var req structs.OAuthTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.protocolError(ctx, auth.NewOAuthError(auth.OAuthErrInvalidRequest, ""))
+		return
+	}
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+	token, err := c.oauthServer.Token(ctx.Request.Context(), client, &req)
+	if err != nil {
+		c.protocolError(ctx, err)
+		return
+	}
+	noStore(ctx)
+	ctx.JSON(http.StatusOK, token)
+}
+
+// @Summary 令牌内省
+// @Description RFC 7662，仅机密客户端可调用
+// @Tags 授权服务
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "访问令牌"
+// @Success 200 {object} type_response.IntrospectionResponse "内省结果"
+// @Failure 401 {object} auth.OAuthError "客户端认证失败"
+// @Router /api/v1/oauth/introspect [post]
+func (c *OAuthServerController) Introspect(ctx *gin.Context) {
+	var req structs.OAuthTokenActionRequest
+	if err := ctx.ShouldBind(&req); err != nil || req.Token == "" {
+		c.protocolError(ctx, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "缺少token"))
+		return
+	}
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.oauthServer.Introspect(ctx.Request.Context(), client, req.Token)
+	if err != nil {
+		c.protocolError(ctx, err)
+		return
+	}
+	noStore(ctx)
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @Summary 撤销令牌
+// @Description RFC 7009，令牌无效或不属于该客户端时同样返回成功
+// @Tags 授权服务
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "访问令牌"
+// @Success 200 "撤销成功"
+// @Failure 401 {object} auth.OAuthError "客户端认证失败"
+// @Router /api/v1/oauth/revoke [post]
+func (c *OAuthServerController) Revoke(ctx *gin.Context) {
+	var req structs.OAuthTokenActionRequest
+	if err := ctx.ShouldBind(&req); err != nil || req.Token == "" {
+		c.protocolError(ctx, auth.NewOAuthError(auth.OAuthErrInvalidRequest, "缺少token"))
+		return
+	}
+	client, ok := c.authenticateClient(ctx)
+	if !ok {
+		return
+	}
+	if err := c.oauthServer.Revoke(ctx.Request.Context(), client, req.Token); err != nil {
+		c.protocolError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary 获取授权用户信息
+// @Description 使用授权服务签发的访问令牌获取用户信息，需要openid或profile授权范围
+// @Tags 授权服务
+// @Produce json
+// @Param Authorization header string true "Bearer 访问令牌"
+// @Success 200 {object} type_response.UserInfoResponse "获取成功"
+// @Failure 401 {object} auth.OAuthError "令牌无效"
+// @Router /api/v1/oauth/userinfo [get]
+func (c *OAuthServerController) UserInfo(ctx *gin.Context) {
+	token := use_headers.GetAuthorizationToken(ctx)
+	if token == "" {
+		ctx.Header("WWW-Authenticate", `Bearer realm="gin-center"`)
+		ctx.Status(http.StatusUnauthorized)
+		return
+	}
+	info, err := c.oauthServer.UserInfo(ctx.Request.Context(), token)
+	if err != nil {
+		var oauthErr *auth.OAuthError
+		if errors.As(err, &oauthErr) {
+			ctx.Header("WWW-Authenticate", `Bearer error="`+oauthErr.Code+`"`)
+		}
+		c.protocolError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, info)
+}
+
+// authenticateClient 从Basic认证头或表单中读取客户端凭证(RFC 6749 2.3.1节)
+func (c *OAuthServerController) authenticateClient(ctx *gin.Context) (*OAuthClientModel.OAuthClient, bool) {
+	clientID, clientSecret, basic := ctx.Request.BasicAuth()
+	if basic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = ctx.PostForm("client_id"), ctx.PostForm("client_secret")
+	}
+
+	client, err := c.oauthServer.AuthenticateClient(ctx.Request.Context(), clientID, clientSecret)
+	if err != nil {
+		if basic {
+			ctx.Header("WWW-Authenticate", `Basic realm="gin-center"`)
+		}
+		c.protocolError(ctx, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// protocolError 按OAuth2规范输出错误，非协议错误记录日志后返回server_error
+func (c *OAuthServerController) protocolError(ctx *gin.Context, err error) {
+	noStore(ctx)
+	var oauthErr *auth.OAuthError
+	if errors.As(err, &oauthErr) {
+		ctx.JSON(oauthErr.Status(), oauthErr)
+		return
+	}
+	c.Logger.LogError("授权服务处理失败", zap.String("path", ctx.FullPath()), zap.Error(err))
+	ctx.JSON(http.StatusInternalServerError, auth.NewOAuthError(auth.OAuthErrServerError, ""))
+}
+
+// handleError 将错误映射为统一响应结构
+func (c *OAuthServerController) handleError(ctx *gin.Context, err error, message string) {
+	var oauthErr *auth.OAuthError
+	switch {
+	case errors.As(err, &oauthErr):
+		use_response.BadRequest(ctx, oauthErr.Error())
+	case errors.Is(err, constants.ErrOAuthClientNotFound):
+		use_response.NotFound(ctx, "客户端不存在")
+	default:
+		c.Logger.LogError(message, zap.Error(err))
+		use_response.ServerError(ctx, message)
+	}
+}
+
+func (c *OAuthServerController) parseID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		use_response.BadRequest(ctx, "无效的客户端ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func noStore(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+}
@@ -0,0 +1,157 @@
+package permission_controller
+
+import (
+	"errors"
+	"strconv"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_permissionInterface "gin-center/internal/domain/interface/permission"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionController 权限控制器
+type PermissionController struct {
+	base_controller.BaseController
+	permissionService use_permissionInterface.PermissionServiceInterface
+}
+
+// NewPermissionController 创建新的权限控制器实例
+func NewPermissionController(permissionService use_permissionInterface.PermissionServiceInterface, logger *zaplogger.ServiceLogger) *PermissionController {
+	return &PermissionController{
+		BaseController:    *base_controller.NewBaseController(logger),
+		permissionService: permissionService,
+	}
+}
+
+// @Summary 获取权限列表
+// @Description 获取所有启用的权限
+// @Tags 权限管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.PermissionResponse} "获取成功"
+// @Router /api/v1/admin/permissions [get]
+func (c *PermissionController) ListPermissions(ctx *gin.Context) {
+	permissions, err := c.permissionService.ListPermissions(ctx.Request.Context())
+	if err != nil {
+		use_response.ServerError(ctx, "获取权限列表失败")
+		return
+	}
+	use_response.Success(ctx, permissions)
+}
+
+// @Summary 获取我的权限
+// @Description 获取当前登录主体拥有的权限编码，可用于选择API密钥的权限范围
+// @Tags 权限管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=[]string} "获取成功"
+// @Router /api/v1/user/permissions [get]
+func (c *PermissionController) ListMyPermissions(ctx *gin.Context) {
+	c.listCodes(ctx, ctx.GetString("role"), ctx.GetUint("user_id"))
+}
+
+// @Summary 获取用户权限
+// @Tags 权限管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} type_response.BaseResponse{data=[]string} "获取成功"
+// @Router /api/v1/admin/users/{id}/permissions [get]
+func (c *PermissionController) ListUserPermissions(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	c.listCodes(ctx, SessionModel.RoleUser, userID)
+}
+
+// @Summary 设置用户权限
+// @Description 以提交的权限编码覆盖普通用户现有的权限
+// @Tags 权限管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param request body structs.SetPermissionsRequest true "权限编码"
+// @Success 200 {object} type_response.BaseResponse "设置成功"
+// @Failure 400 {object} type_response.BaseResponse "包含无效的权限编码"
+// @Router /api/v1/admin/users/{id}/permissions [put]
+func (c *PermissionController) SetUserPermissions(ctx *gin.Context) {
+	c.setCodes(ctx, SessionModel.RoleUser)
+}
+
+// @Summary 获取管理员权限
+// @Description 获取管理员实际拥有的权限编码，包含默认权限与显式授予的权限
+// @Tags 权限管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "管理员ID"
+// @Success 200 {object} type_response.BaseResponse{data=[]string} "获取成功"
+// @Router /api/v1/admin/admins/{id}/permissions [get]
+func (c *PermissionController) ListAdminPermissions(ctx *gin.Context) {
+	adminID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	c.listCodes(ctx, SessionModel.RoleAdmin, adminID)
+}
+
+// @Summary 设置管理员权限
+// @Description 以提交的权限编码覆盖管理员显式授予的权限，如模拟登录等管理员默认不具备的权限
+// @Tags 权限管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "管理员ID"
+// @Param request body structs.SetPermissionsRequest true "权限编码"
+// @Success 200 {object} type_response.BaseResponse "设置成功"
+// @Failure 400 {object} type_response.BaseResponse "包含无效的权限编码"
+// @Router /api/v1/admin/admins/{id}/permissions [put]
+func (c *PermissionController) SetAdminPermissions(ctx *gin.Context) {
+	c.setCodes(ctx, SessionModel.RoleAdmin)
+}
+
+func (c *PermissionController) setCodes(ctx *gin.Context, role string) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	var req structs.SetPermissionsRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	err := c.permissionService.SetUserPermissions(ctx.Request.Context(), role, userID, req.Codes, ctx.GetUint("user_id"))
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidPermission) {
+			use_response.BadRequest(ctx, "包含无效的权限编码")
+			return
+		}
+		use_response.ServerError(ctx, "设置权限失败")
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+func (c *PermissionController) listCodes(ctx *gin.Context, role string, userID uint) {
+	codes, err := c.permissionService.ListCodes(ctx.Request.Context(), role, userID)
+	if err != nil {
+		use_response.ServerError(ctx, "获取权限失败")
+		return
+	}
+	use_response.Success(ctx, codes)
+}
+
+func (c *PermissionController) parseUserID(ctx *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		use_response.BadRequest(ctx, "无效的用户ID")
+		return 0, false
+	}
+	return uint(userID), true
+}
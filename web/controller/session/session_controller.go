@@ -0,0 +1,256 @@
+package session_controller
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	session_service "gin-center/internal/application/session/service"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
+	SessionModel "gin-center/internal/domain/model/session"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	"gin-center/pkg/http/clientip"
+	use_response "gin-center/pkg/http/response"
+	"gin-center/pkg/security/useCookie"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionController 会话控制器，处理令牌刷新、登出及会话管理请求
+type SessionController struct {
+	base_controller.BaseController
+	sessionService use_sessionInterface.SessionServiceInterface
+}
+
+// NewSessionController 创建新的会话控制器实例
+func NewSessionController(sessionService use_sessionInterface.SessionServiceInterface, cookies *useCookie.Transport, logger *zaplogger.ServiceLogger) *SessionController {
+	c := &SessionController{
+		BaseController: *base_controller.NewBaseController(logger),
+		sessionService: sessionService,
+	}
+	c.Cookies = cookies
+	return c
+}
+
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换发新的令牌对，旧的刷新令牌随即失效
+// @Description 携带X-Token-Transport: cookie时从Cookie读取刷新令牌并以Cookie下发新令牌，须同时携带X-CSRF-Token
+// @Tags 会话管理
+// @Accept json
+// @Produce json
+// @Param request body structs.RefreshTokenRequest true "刷新令牌请求参数"
+// @Success 200 {object} type_response.BaseResponse{data=structs.TokenPair} "刷新成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Failure 401 {object} type_response.BaseResponse "刷新令牌无效"
+// @Failure 403 {object} type_response.BaseResponse "CSRF令牌无效"
+// @Router /api/v1/auth/refresh [post]
+func (c *SessionController) Refresh(ctx *gin.Context) {
+	var req structs.RefreshTokenRequest
+	viaCookie := c.Cookies.Requested(ctx)
+	if viaCookie {
+		if !c.Cookies.VerifyCSRF(ctx) {
+			c.Logger.LogWarn("刷新令牌CSRF校验失败", zap.String("ip", clientip.Get(ctx)))
+			use_response.Forbidden(ctx, "CSRF令牌无效")
+			return
+		}
+		// 请求体可省略，其中的刷新令牌被忽略
+		_ = ctx.ShouldBindJSON(&req)
+		if req.RefreshToken = c.Cookies.RefreshToken(ctx); req.RefreshToken == "" {
+			use_response.Unauthorized(ctx, "刷新令牌无效或已过期")
+			return
+		}
+	} else if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	tokens, err := c.sessionService.Refresh(ctx.Request.Context(), req.RefreshToken, c.LoginMeta(ctx, req.Fingerprint, ""))
+	if err != nil {
+		if session_service.IsSessionError(err) {
+			c.Logger.LogWarn("刷新令牌失败", zap.String("ip", clientip.Get(ctx)), zap.Error(err))
+			use_response.Unauthorized(ctx, "刷新令牌无效或已过期")
+			return
+		}
+		c.Logger.LogError("刷新令牌异常", zap.Error(err))
+		use_response.ServerError(ctx, "刷新令牌失败")
+		return
+	}
+	if viaCookie {
+		fields, err := c.TokenCookies(ctx, tokens)
+		if err != nil {
+			c.Logger.LogError("下发令牌Cookie失败", zap.Error(err))
+			use_response.ServerError(ctx, "刷新令牌失败")
+			return
+		}
+		use_response.Success(ctx, fields)
+		return
+	}
+	use_response.Success(ctx, tokens)
+}
+
+// @Summary 退出登录
+// @Description 注销当前会话，启用令牌Cookie时同时清除Cookie
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse "退出成功"
+// @Router /api/v1/auth/logout [post]
+func (c *SessionController) Logout(ctx *gin.Context) {
+	if c.Cookies.Enabled() {
+		c.Cookies.Clear(ctx)
+	}
+	c.revoke(ctx, ctx.GetString("role"), ctx.GetUint("user_id"), ctx.GetString("session_id"))
+}
+
+// @Summary 获取我的会话列表
+// @Description 获取当前登录主体的所有有效会话（登录设备）
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.SessionResponse} "获取成功"
+// @Router /api/v1/user/sessions [get]
+func (c *SessionController) ListMySessions(ctx *gin.Context) {
+	c.list(ctx, ctx.GetString("role"), ctx.GetUint("user_id"), ctx.GetString("session_id"))
+}
+
+// @Summary 注销我的会话
+// @Description 注销当前登录主体的指定会话，可用于下线其他设备
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "会话ID"
+// @Success 200 {object} type_response.BaseResponse "注销成功"
+// @Failure 404 {object} type_response.BaseResponse "会话不存在"
+// @Router /api/v1/user/sessions/{id} [delete]
+func (c *SessionController) RevokeMySession(ctx *gin.Context) {
+	c.revoke(ctx, ctx.GetString("role"), ctx.GetUint("user_id"), ctx.Param("id"))
+}
+
+// @Summary 获取用户会话列表
+// @Description 管理员查看指定用户的所有有效会话
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.SessionResponse} "获取成功"
+// @Router /api/v1/admin/users/{id}/sessions [get]
+func (c *SessionController) ListUserSessions(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	c.list(ctx, SessionModel.RoleUser, userID, "")
+}
+
+// @Summary 强制下线用户会话
+// @Description 管理员注销指定用户的单个会话
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param sid path string true "会话ID"
+// @Success 200 {object} type_response.BaseResponse "注销成功"
+// @Failure 404 {object} type_response.BaseResponse "会话不存在"
+// @Router /api/v1/admin/users/{id}/sessions/{sid} [delete]
+func (c *SessionController) RevokeUserSession(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	c.Logger.LogInfo("管理员强制下线会话",
+		zap.String("operator", ctx.GetString("username")),
+		zap.Uint("user_id", userID),
+		zap.String("session_id", ctx.Param("sid")))
+	c.revoke(ctx, SessionModel.RoleUser, userID, ctx.Param("sid"))
+}
+
+// @Summary 强制下线用户
+// @Description 管理员注销指定用户的全部会话
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "注销成功"
+// @Router /api/v1/admin/users/{id}/sessions [delete]
+func (c *SessionController) RevokeUserSessions(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	count, err := c.sessionService.RevokeAllSessions(ctx.Request.Context(), SessionModel.RoleUser, userID)
+	if err != nil {
+		use_response.ServerError(ctx, "强制下线失败")
+		return
+	}
+	c.Logger.LogInfo("管理员强制下线用户",
+		zap.String("operator", ctx.GetString("username")),
+		zap.Uint("user_id", userID),
+		zap.Int("count", count))
+	use_response.Success(ctx, gin.H{"revoked": count})
+}
+
+// @Summary 获取在线用户
+// @Description 根据会话活跃时间统计在线用户，window为判定在线的时间窗口
+// @Tags 会话管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param role query string false "角色：user或admin" default(user)
+// @Param window query string false "时间窗口，如5m、1h" default(5m)
+// @Success 200 {object} type_response.BaseResponse{data=[]type_response.OnlineUserResponse} "获取成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Router /api/v1/admin/online-users [get]
+func (c *SessionController) ListOnlineUsers(ctx *gin.Context) {
+	role := ctx.DefaultQuery("role", SessionModel.RoleUser)
+	if role != SessionModel.RoleUser && role != SessionModel.RoleAdmin {
+		use_response.BadRequest(ctx, "无效的角色")
+		return
+	}
+	window, err := time.ParseDuration(ctx.DefaultQuery("window", "5m"))
+	if err != nil || window < time.Minute || window > 24*time.Hour {
+		use_response.BadRequest(ctx, "无效的时间窗口，取值范围1m-24h")
+		return
+	}
+
+	users, err := c.sessionService.ListOnlineUsers(ctx.Request.Context(), role, window)
+	if err != nil {
+		use_response.ServerError(ctx, "获取在线用户失败")
+		return
+	}
+	use_response.Success(ctx, users)
+}
+
+// list 返回指定主体的会话列表
+func (c *SessionController) list(ctx *gin.Context, role string, userID uint, currentSessionID string) {
+	sessions, err := c.sessionService.ListSessions(ctx.Request.Context(), role, userID, currentSessionID)
+	if err != nil {
+		use_response.ServerError(ctx, "获取会话列表失败")
+		return
+	}
+	use_response.Success(ctx, sessions)
+}
+
+// revoke 注销指定主体的单个会话
+func (c *SessionController) revoke(ctx *gin.Context, role string, userID uint, sessionID string) {
+	err := c.sessionService.RevokeSession(ctx.Request.Context(), role, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, constants.ErrSessionNotFound) {
+			use_response.NotFound(ctx, "会话不存在或已失效")
+			return
+		}
+		use_response.ServerError(ctx, "注销会话失败")
+		return
+	}
+	use_response.Success(ctx, gin.H{"message": "会话已注销"})
+}
+
+// parseUserID 解析路径中的用户ID
+func (c *SessionController) parseUserID(ctx *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		use_response.BadRequest(ctx, "无效的用户ID")
+		return 0, false
+	}
+	return uint(userID), true
+}
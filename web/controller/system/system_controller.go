@@ -31,7 +31,7 @@ func NewSystemController(systemService *systemService.SystemService, logger *zap
 // @Failure 500 {object} type_response.BaseResponse "服务器错误"
 // @Router /api/v1/system/info [get]
 func (c *SystemController) GetSystemInfo(ctx *gin.Context) {
-	data, err := c.systemService.GetSystemInfo()
+	data, err := c.systemService.GetSystemInfo(ctx.Request.Context())
 	if err != nil {
 		c.HandleError(ctx, err)
 		return
@@ -87,7 +87,7 @@ func (c *SystemController) UpdateSystemConfig(ctx *gin.Context) {
 // @Failure 500 {object} type_response.BaseResponse "服务器错误"
 // @Router /api/v1/system/metrics [get]
 func (c *SystemController) GetSystemMetrics(ctx *gin.Context) {
-	data, err := c.systemService.GetSystemMetrics()
+	data, err := c.systemService.GetSystemMetrics(ctx.Request.Context())
 	if err != nil {
 		c.HandleError(ctx, err)
 		return
@@ -103,9 +103,41 @@ func (c *SystemController) GetSystemMetrics(ctx *gin.Context) {
 // @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "获取成功"
 // @Router /api/v1/system/health [get]
 func (c *SystemController) GetSystemHealth(ctx *gin.Context) {
-	data := c.systemService.GetSystemHealth()
+	data := c.systemService.GetSystemHealth(ctx.Request.Context())
 	c.SendResponse(ctx, http.StatusOK, "success", data)
 }
 func (c *SystemController) validateSystemConfig(config *system.SystemConfig) error {
 	return nil
 }
+// @Summary 获取日志级别
+// @Description 获取当前的全局日志级别
+// @Tags 系统管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} type_response.BaseResponse{data=system.LogLevel} "获取成功"
+// @Router /api/v1/system/log-level [get]
+func (c *SystemController) GetLogLevel(ctx *gin.Context) {
+	c.SendResponse(ctx, http.StatusOK, "success", c.systemService.GetLogLevel())
+}
+// @Summary 调整日志级别
+// @Description 在运行时调整全局日志级别，立即生效，重启后恢复为配置文件中的级别
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body system.LogLevel true "日志级别"
+// @Success 200 {object} type_response.BaseResponse{data=system.LogLevel} "调整成功"
+// @Failure 400 {object} type_response.BaseResponse "无效的日志级别"
+// @Router /api/v1/system/log-level [put]
+func (c *SystemController) SetLogLevel(ctx *gin.Context) {
+	var req system.LogLevel
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.SendResponse(ctx, http.StatusBadRequest, "无效的日志级别", nil)
+		return
+	}
+	if err := c.systemService.SetLogLevel(req.Level); err != nil {
+		c.SendResponse(ctx, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	c.SendResponse(ctx, http.StatusOK, "success", c.systemService.GetLogLevel())
+}
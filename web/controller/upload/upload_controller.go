@@ -0,0 +1,237 @@
+package upload_controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_uploadInterface "gin-center/internal/domain/interface/upload"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	use_headers "gin-center/pkg/http/headers"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UploadController 分片上传与用户文件控制器
+type UploadController struct {
+	base_controller.BaseController
+	uploadService use_uploadInterface.UploadServiceInterface
+}
+
+// NewUploadController 创建新的分片上传控制器实例
+func NewUploadController(uploadService use_uploadInterface.UploadServiceInterface, logger *zaplogger.ServiceLogger) *UploadController {
+	return &UploadController{
+		BaseController: *base_controller.NewBaseController(logger),
+		uploadService:  uploadService,
+	}
+}
+
+// @Summary 创建分片上传任务
+// @Description 声明文件名、大小和整个文件的SHA-256摘要，返回任务ID及服务端规定的分片大小
+// @Tags 文件上传
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body structs.CreateUploadRequest true "上传任务参数"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UploadResponse} "创建成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Failure 413 {object} type_response.BaseResponse "超出文件大小上限或存储配额"
+// @Router /api/v1/uploads [post]
+func (c *UploadController) Create(ctx *gin.Context) {
+	var req structs.CreateUploadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		use_response.BadRequest(ctx, "无效的上传参数")
+		return
+	}
+	result, err := c.uploadService.Create(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), &req)
+	if err != nil {
+		c.handleError(ctx, "创建上传任务失败", err)
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 查询上传任务
+// @Description 返回已接收的分片序号，客户端据此续传缺失的分片
+// @Tags 文件上传
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UploadResponse} "获取成功"
+// @Failure 404 {object} type_response.BaseResponse "任务不存在或已过期"
+// @Router /api/v1/uploads/{id} [get]
+func (c *UploadController) Get(ctx *gin.Context) {
+	result, err := c.uploadService.Get(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, "查询上传任务失败", err)
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 上传分片
+// @Description 请求体为分片原始内容，分片序号从0开始；除最后一个分片外长度须等于chunk_size
+// @Tags 文件上传
+// @Accept octet-stream
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param index path int true "分片序号"
+// @Param X-Chunk-SHA256 header string true "分片的SHA-256摘要（十六进制）"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UploadResponse} "上传成功"
+// @Failure 400 {object} type_response.BaseResponse "分片无效或校验和不匹配"
+// @Failure 404 {object} type_response.BaseResponse "任务不存在或已过期"
+// @Router /api/v1/uploads/{id}/chunks/{index} [put]
+func (c *UploadController) UploadChunk(ctx *gin.Context) {
+	index, err := strconv.Atoi(ctx.Param("index"))
+	if err != nil || index < 0 {
+		use_response.BadRequest(ctx, "无效的分片序号")
+		return
+	}
+	checksum := ctx.GetHeader(use_headers.XChunkSHA256)
+	if len(checksum) != 64 {
+		use_response.BadRequest(ctx, "缺少分片的SHA-256摘要")
+		return
+	}
+	result, err := c.uploadService.UploadChunk(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), ctx.Param("id"), index, checksum, ctx.Request.Body)
+	if err != nil {
+		c.handleError(ctx, "上传分片失败", err)
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 完成上传
+// @Description 合并全部分片并校验整个文件的SHA-256摘要，摘要不一致时任务被取消
+// @Tags 文件上传
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.FileResponse} "上传完成"
+// @Failure 400 {object} type_response.BaseResponse "分片未全部上传或校验和不匹配"
+// @Failure 404 {object} type_response.BaseResponse "任务不存在或已过期"
+// @Router /api/v1/uploads/{id}/complete [post]
+func (c *UploadController) Complete(ctx *gin.Context) {
+	result, err := c.uploadService.Complete(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, "完成上传失败", err)
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 取消上传
+// @Tags 文件上传
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} type_response.BaseResponse "取消成功"
+// @Failure 404 {object} type_response.BaseResponse "任务不存在或已过期"
+// @Router /api/v1/uploads/{id} [delete]
+func (c *UploadController) Abort(ctx *gin.Context) {
+	if err := c.uploadService.Abort(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), ctx.Param("id")); err != nil {
+		c.handleError(ctx, "取消上传失败", err)
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+// @Summary 查询我的文件
+// @Tags 文件上传
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} type_response.BaseResponse{data=type_response.FileListResponse} "获取成功"
+// @Router /api/v1/files [get]
+func (c *UploadController) ListFiles(ctx *gin.Context) {
+	var query structs.FileQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		use_response.BadRequest(ctx, "无效的分页参数")
+		return
+	}
+	result, err := c.uploadService.ListFiles(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), &query)
+	if err != nil {
+		c.handleError(ctx, "查询文件失败", err)
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 获取文件
+// @Description 返回文件信息及15分钟内有效的下载地址
+// @Tags 文件上传
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文件ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.FileResponse} "获取成功"
+// @Failure 404 {object} type_response.BaseResponse "文件不存在"
+// @Router /api/v1/files/{id} [get]
+func (c *UploadController) GetFile(ctx *gin.Context) {
+	id, ok := parseFileID(ctx)
+	if !ok {
+		return
+	}
+	result, err := c.uploadService.GetFile(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), id)
+	if err != nil {
+		c.handleError(ctx, "查询文件失败", err)
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 删除文件
+// @Tags 文件上传
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文件ID"
+// @Success 200 {object} type_response.BaseResponse "删除成功"
+// @Failure 404 {object} type_response.BaseResponse "文件不存在"
+// @Router /api/v1/files/{id} [delete]
+func (c *UploadController) DeleteFile(ctx *gin.Context) {
+	id, ok := parseFileID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.uploadService.DeleteFile(ctx.Request.Context(), ctx.GetString("role"), ctx.GetUint("user_id"), id); err != nil {
+		c.handleError(ctx, "删除文件失败", err)
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+// handleError 将上传相关的领域错误映射为HTTP响应
+func (c *UploadController) handleError(ctx *gin.Context, message string, err error) {
+	if c.PayloadTooLarge(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, constants.ErrUploadNotFound), errors.Is(err, constants.ErrFileNotFound):
+		use_response.NotFound(ctx, err.Error())
+	case errors.Is(err, constants.ErrQuotaExceeded):
+		c.SendResponse(ctx, http.StatusRequestEntityTooLarge, err.Error(), nil)
+	case errors.Is(err, constants.ErrUploadBusy):
+		c.SendConflict(ctx, err.Error())
+	case errors.Is(err, constants.ErrInvalidChunk),
+		errors.Is(err, constants.ErrChecksumMismatch),
+		errors.Is(err, constants.ErrUploadIncomplete):
+		use_response.BadRequest(ctx, err.Error())
+	default:
+		c.Logger.LogError(message, zap.Uint("user_id", ctx.GetUint("user_id")), zap.Error(err))
+		use_response.ServerError(ctx, message)
+	}
+}
+
+func parseFileID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		use_response.BadRequest(ctx, "无效的文件ID")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -4,20 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"gin-center/internal/types/auth"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 
 	zaplogger "gin-center/infrastructure/zaplogger"
 	use_userInterface "gin-center/internal/domain/interface/user"
 	type_response "gin-center/internal/types/response"
+	"gin-center/pkg/http/clientip"
 	use_response "gin-center/pkg/http/response"
+	"gin-center/pkg/security/useCookie"
+	"gin-center/pkg/utils/imageproc"
 	base_controller "gin-center/web/controller"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -26,9 +28,9 @@ type UserController struct {
 	userService use_userInterface.UserServiceInterface
 }
 
-func NewUserController(logger *zaplogger.ServiceLogger, userServiceInterface use_userInterface.UserServiceInterface) *UserController {
+func NewUserController(logger *zaplogger.ServiceLogger, userServiceInterface use_userInterface.UserServiceInterface, cookies *useCookie.Transport) *UserController {
 	return &UserController{
-		BaseController: base_controller.BaseController{Logger: logger}, // 修改为使用 logger 本身
+		BaseController: base_controller.BaseController{Logger: logger, Cookies: cookies}, // 修改为使用 logger 本身
 		userService:    userServiceInterface,
 	}
 }
@@ -38,25 +40,30 @@ func NewUserController(logger *zaplogger.ServiceLogger, userServiceInterface use
 // @Tags 用户管理
 // @Accept json
 // @Produce json
-// @Param request body auth.LoginRequest true "登录请求参数"
+// @Param request body structs.LoginRequest true "登录请求参数"
 // @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "登录成功"
 // @Failure 400 {object} type_response.BaseResponse "请求参数错误"
 // @Failure 401 {object} type_response.BaseResponse "登录失败"
-// @Router /api/v1/user/login [post]
+// @Router /api/v1/auth/login [post]
 func (c *UserController) Login(ctx *gin.Context) {
-	c.Logger.LogInfo("User login attempt", zap.String("ip", ctx.ClientIP()))
-	var req auth.LoginRequest
+	c.Logger.LogInfo("User login attempt", zap.String("ip", clientip.Get(ctx)))
+	var req structs.LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.Logger.LogError("Login request validation failed", zap.Error(err))
 		use_response.BadRequest(ctx, "Invalid login request")
 		return
 	}
-	result, err := c.userService.Login(req.Username, req.Password)
+	result, err := c.userService.Login(ctx.Request.Context(), req.Username, req.Password, c.LoginMeta(ctx, req.Fingerprint, req.Device))
 	if err != nil {
 		c.Logger.LogError("Login failed", zap.String("username", req.Username), zap.Error(err))
 		use_response.Unauthorized(ctx, "Login failed: "+err.Error())
 		return
 	}
+	if err := c.UseTokenCookies(ctx, result); err != nil {
+		c.Logger.LogError("Failed to set token cookies", zap.Error(err))
+		use_response.ServerError(ctx, "Login failed")
+		return
+	}
 	use_response.Authenticated(ctx, result, "")
 }
 
@@ -69,16 +76,16 @@ func (c *UserController) Login(ctx *gin.Context) {
 // @Success 200 {object} type_response.BaseResponse "注册成功"
 // @Failure 400 {object} type_response.BaseResponse "请求参数错误"
 // @Failure 500 {object} type_response.BaseResponse "注册失败"
-// @Router /api/v1/user/register [post]
+// @Router /api/v1/auth/register [post]
 func (c *UserController) Register(ctx *gin.Context) {
-	c.Logger.LogInfo("User registration attempt", zap.String("ip", ctx.ClientIP()))
+	c.Logger.LogInfo("User registration attempt", zap.String("ip", clientip.Get(ctx)))
 	var req auth.RegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.Logger.LogError("Registration request validation failed", zap.Error(err))
 		use_response.BadRequest(ctx, "Invalid registration request")
 		return
 	}
-	if err := c.userService.Register(req.Username, req.Password); err != nil {
+	if err := c.userService.Register(ctx.Request.Context(), req.Username, req.Password); err != nil {
 		c.Logger.LogError("Registration failed",
 			zap.String("username", req.Username),
 			zap.Error(err),
@@ -176,51 +183,198 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 }
 
 // @Summary 获取用户列表
-// @Description 分页获取用户列表信息
+// @Description 分页查询普通用户，keyword匹配用户名、昵称或手机号
 // @Tags 用户管理
-// @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param page query int false "页码，默认1" default(1)
-// @Param size query int false "每页数量，默认10" default(10)
-// @Param username query string false "用户名筛选"
+// @Param page_size query int false "每页数量，默认10" default(10)
+// @Param keyword query string false "关键字"
+// @Param status query int false "状态 0:禁用 1:启用"
 // @Success 200 {object} type_response.BaseResponse{data=type_response.UserListResponse} "获取成功"
-// @Failure 500 {object} type_response.BaseResponse "服务器错误"
-// @Router /api/v1/user/list [get]
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Router /api/v1/admin/users [get]
 func (c *UserController) ListUsers(ctx *gin.Context) {
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(ctx.DefaultQuery("size", "10"))
-	username := ctx.Query("username")
-	query := map[string]interface{}{}
-	if username != "" {
-		query["username"] = username
+	var query structs.UserQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		use_response.BadRequest(ctx, "Invalid query parameters")
+		return
 	}
-
-	c.Logger.LogInfo("Listing users", zap.Int("page", page), zap.Int("size", size), zap.String("username", username))
-	users, err := c.userService.ListUsers(ctx, page, size, query)
+	users, err := c.userService.ListUsers(ctx.Request.Context(), &query)
 	if err != nil {
 		c.Logger.LogError("Failed to list users", zap.Error(err))
-		use_response.ServerError(ctx, "Failed to list users: "+err.Error())
+		use_response.ServerError(ctx, "Failed to list users")
 		return
 	}
 	use_response.Success(ctx, users)
 }
 
+// @Summary 获取用户详情
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UserResponse} "获取成功"
+// @Failure 404 {object} type_response.BaseResponse "用户不存在"
+// @Router /api/v1/admin/users/{id} [get]
+func (c *UserController) GetUser(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	user, err := c.userService.GetUser(ctx.Request.Context(), userID)
+	if err != nil {
+		c.handleManageError(ctx, err, userID)
+		return
+	}
+	use_response.Success(ctx, user)
+}
+
+// @Summary 创建用户
+// @Description 管理员创建普通用户，未指定状态时默认启用
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body structs.CreateUserRequest true "用户信息"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UserResponse} "创建成功"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Failure 409 {object} type_response.BaseResponse "用户名或手机号已存在"
+// @Router /api/v1/admin/users [post]
+func (c *UserController) CreateUser(ctx *gin.Context) {
+	var req structs.CreateUserRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	user, err := c.userService.CreateUser(ctx.Request.Context(), &req)
+	if err != nil {
+		c.handleManageError(ctx, err, 0)
+		return
+	}
+	c.Logger.LogInfo("User created by admin", zap.String("username", user.Username), zap.Uint("operator", ctx.GetUint("user_id")))
+	use_response.Success(ctx, user)
+}
+
+// @Summary 启用或禁用用户
+// @Description 禁用后用户无法登录，现有会话立即失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param request body structs.UpdateUserStatusRequest true "用户状态"
+// @Success 200 {object} type_response.BaseResponse "更新成功"
+// @Failure 404 {object} type_response.BaseResponse "用户不存在"
+// @Router /api/v1/admin/users/{id}/status [put]
+func (c *UserController) UpdateUserStatus(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	var req structs.UpdateUserStatusRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	if err := c.userService.SetUserStatus(ctx.Request.Context(), userID, *req.Status); err != nil {
+		c.handleManageError(ctx, err, userID)
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+// @Summary 重置用户密码
+// @Description 重置后用户的现有会话全部失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param request body structs.ResetPasswordRequest true "新密码"
+// @Success 200 {object} type_response.BaseResponse "重置成功"
+// @Failure 400 {object} type_response.BaseResponse "密码不符合要求"
+// @Failure 404 {object} type_response.BaseResponse "用户不存在"
+// @Router /api/v1/admin/users/{id}/password [put]
+func (c *UserController) ResetUserPassword(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	var req structs.ResetPasswordRequest
+	if err := c.ValidateRequest(ctx, &req); err != nil {
+		return
+	}
+	if err := c.userService.ResetPassword(ctx.Request.Context(), userID, req.Password); err != nil {
+		c.handleManageError(ctx, err, userID)
+		return
+	}
+	use_response.Success(ctx, nil)
+}
+
+// @Summary 删除用户
+// @Description 删除用户及其第三方身份、API密钥和权限，并注销全部会话
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} type_response.BaseResponse "删除成功"
+// @Failure 404 {object} type_response.BaseResponse "用户不存在"
+// @Router /api/v1/admin/users/{id} [delete]
+func (c *UserController) DeleteUser(ctx *gin.Context) {
+	userID, ok := c.parseUserID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.userService.DeleteUser(ctx.Request.Context(), userID); err != nil {
+		c.handleManageError(ctx, err, userID)
+		return
+	}
+	c.Logger.LogInfo("User deleted by admin", zap.Uint("user_id", userID), zap.Uint("operator", ctx.GetUint("user_id")))
+	use_response.Success(ctx, nil)
+}
+
+// handleManageError 将用户管理接口的服务层错误映射为响应
+func (c *UserController) handleManageError(ctx *gin.Context, err error, userID uint) {
+	var authErr *auth.AuthError
+	switch {
+	case errors.Is(err, constants.ErrUserNotFound):
+		use_response.NotFound(ctx, "User not found")
+	case errors.Is(err, constants.ErrUserExists):
+		c.SendConflict(ctx, "Username or phone already exists")
+	case errors.As(err, &authErr):
+		use_response.BadRequest(ctx, "Username or password does not meet requirements")
+	default:
+		c.Logger.LogError("User management failed", zap.Uint("user_id", userID), zap.Error(err))
+		use_response.ServerError(ctx, "User management failed")
+	}
+}
+
+func (c *UserController) parseUserID(ctx *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		use_response.BadRequest(ctx, "Invalid user ID")
+		return 0, false
+	}
+	return uint(userID), true
+}
+
 // @Summary 上传用户头像
-// @Description 上传并更新当前登录用户的头像
+// @Description 上传并更新当前登录用户的头像，服务端去除EXIF元数据、按方向矫正并裁剪为正方形，同时生成多个尺寸的缩略图
 // @Tags 用户管理
 // @Accept multipart/form-data
 // @Produce json
 // @Security ApiKeyAuth
 // @Param avatar formData file true "用户头像文件（支持jpg、png、gif，小于5MB）"
-// @Success 200 {object} type_response.BaseResponse{data=map[string]interface{}} "上传成功"
-// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.AvatarResponse} "上传成功，返回主图及64、128、256缩略图地址"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误或图片无法处理"
 // @Failure 500 {object} type_response.BaseResponse "服务器错误"
 // @Router /api/v1/user/avatar [post]
 func (c *UserController) UploadAvatar(ctx *gin.Context) {
 	userID := ctx.GetUint("user_id")
 	file, err := ctx.FormFile("avatar")
 	if err != nil {
+		if c.PayloadTooLarge(ctx, err) {
+			return
+		}
 		c.Logger.LogError("Failed to get avatar file", zap.Uint("user_id", userID), zap.Error(err))
 		use_response.BadRequest(ctx, "Invalid avatar file")
 		return
@@ -232,36 +386,31 @@ func (c *UserController) UploadAvatar(ctx *gin.Context) {
 		return
 	}
 
-	filename := fmt.Sprintf("avatar_%d_%s%s",
-		userID,
-		uuid.New().String(),
-		filepath.Ext(file.Filename),
-	)
-	avatarPath := filepath.Join("uploads", "avatars", filename)
-
-	if err := os.MkdirAll(filepath.Dir(avatarPath), os.ModePerm); err != nil {
-		c.Logger.LogError("Failed to create avatar directory", zap.Uint("user_id", userID), zap.Error(err))
-		use_response.ServerError(ctx, "Failed to create avatar directory")
-		return
-	}
-
-	if err := ctx.SaveUploadedFile(file, avatarPath); err != nil {
-		c.Logger.LogError("Failed to save avatar file", zap.Uint("user_id", userID), zap.Error(err))
-		use_response.ServerError(ctx, "Failed to save avatar file")
+	src, err := file.Open()
+	if err != nil {
+		c.Logger.LogError("Failed to open avatar file", zap.Uint("user_id", userID), zap.Error(err))
+		use_response.BadRequest(ctx, "Invalid avatar file")
 		return
 	}
+	defer src.Close()
 
-	err = c.userService.UpdateUserAvatar(ctx, userID, "/"+avatarPath)
+	result, err := c.userService.UploadAvatar(ctx.Request.Context(), userID, src)
 	if err != nil {
-		c.Logger.LogError("Failed to update user avatar in database", zap.Uint("user_id", userID), zap.Error(err))
-		use_response.ServerError(ctx, "Failed to update user avatar")
+		c.Logger.LogError("Failed to update user avatar", zap.Uint("user_id", userID), zap.Error(err))
+		switch {
+		case errors.Is(err, imageproc.ErrImageTooLarge):
+			use_response.BadRequest(ctx, "Avatar image dimensions are too large")
+		case errors.Is(err, imageproc.ErrUnsupportedFormat):
+			use_response.BadRequest(ctx, "Avatar image could not be decoded")
+		case errors.Is(err, constants.ErrUserNotFound):
+			use_response.NotFound(ctx, "User not found")
+		default:
+			use_response.ServerError(ctx, "Failed to update user avatar")
+		}
 		return
 	}
 
-	use_response.Success(ctx, gin.H{
-		"avatar_url": "/" + avatarPath,
-		"message":    "Avatar uploaded successfully",
-	})
+	use_response.Success(ctx, result)
 }
 
 // @Summary 修改用户密码
@@ -0,0 +1,141 @@
+package user_import_controller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	zaplogger "gin-center/infrastructure/zaplogger"
+	use_userImportInterface "gin-center/internal/domain/interface/user_import"
+	"gin-center/internal/types/constants"
+	"gin-center/internal/types/models/structs"
+	use_response "gin-center/pkg/http/response"
+	base_controller "gin-center/web/controller"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+// UserImportController 用户批量导入导出控制器
+type UserImportController struct {
+	base_controller.BaseController
+	importService use_userImportInterface.UserImportServiceInterface
+}
+
+// NewUserImportController 创建新的用户导入导出控制器实例
+func NewUserImportController(importService use_userImportInterface.UserImportServiceInterface, logger *zaplogger.ServiceLogger) *UserImportController {
+	return &UserImportController{
+		BaseController: *base_controller.NewBaseController(logger),
+		importService:  importService,
+	}
+}
+
+// @Summary 批量导入用户
+// @Description 上传CSV或XLSX文件批量创建普通用户，表头需包含username、password，可选nickname、phone、status。dry_run为true时仅返回校验报告；有效行较多时转为异步任务，通过job_id查询进度
+// @Tags 用户管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "CSV或XLSX文件"
+// @Param dry_run formData bool false "仅校验不写入"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UserImportResponse} "导入结果或校验报告"
+// @Failure 400 {object} type_response.BaseResponse "文件格式错误"
+// @Router /api/v1/admin/users/import [post]
+func (c *UserImportController) Import(ctx *gin.Context) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		if c.PayloadTooLarge(ctx, err) {
+			return
+		}
+		use_response.BadRequest(ctx, "请上传导入文件")
+		return
+	}
+	if header.Size > maxImportFileSize {
+		use_response.BadRequest(ctx, fmt.Sprintf("文件大小不能超过%dMB", maxImportFileSize>>20))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(ctx.PostForm("dry_run"))
+	file, err := header.Open()
+	if err != nil {
+		use_response.BadRequest(ctx, "无法读取导入文件")
+		return
+	}
+	defer file.Close()
+
+	operatorID := ctx.GetUint("user_id")
+	result, err := c.importService.Import(ctx.Request.Context(), header.Filename, file, dryRun, operatorID)
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidImportFile) {
+			use_response.BadRequest(ctx, err.Error())
+			return
+		}
+		c.Logger.LogError("导入用户失败", zap.String("filename", header.Filename), zap.Uint("operator", operatorID), zap.Error(err))
+		use_response.ServerError(ctx, "导入用户失败")
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 查询导入任务
+// @Description 查询异步导入任务的进度与逐行错误，任务保留24小时
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param job_id path string true "任务ID"
+// @Success 200 {object} type_response.BaseResponse{data=type_response.UserImportResponse} "获取成功"
+// @Failure 404 {object} type_response.BaseResponse "任务不存在"
+// @Router /api/v1/admin/users/import/{job_id} [get]
+func (c *UserImportController) GetImportJob(ctx *gin.Context) {
+	jobID := ctx.Param("job_id")
+	result, err := c.importService.GetJob(ctx.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, constants.ErrImportJobNotFound) {
+			use_response.NotFound(ctx, "导入任务不存在或已过期")
+			return
+		}
+		c.Logger.LogError("查询导入任务失败", zap.String("job_id", jobID), zap.Error(err))
+		use_response.ServerError(ctx, "查询导入任务失败")
+		return
+	}
+	use_response.Success(ctx, result)
+}
+
+// @Summary 导出用户
+// @Description 按筛选条件流式导出普通用户，支持csv和xlsx格式
+// @Tags 用户管理
+// @Produce octet-stream
+// @Security ApiKeyAuth
+// @Param format query string false "导出格式" Enums(csv, xlsx)
+// @Param keyword query string false "用户名、昵称或手机号关键字"
+// @Param status query int false "状态 0:禁用 1:启用"
+// @Success 200 {file} file "导出文件"
+// @Failure 400 {object} type_response.BaseResponse "请求参数错误"
+// @Router /api/v1/admin/users/export [get]
+func (c *UserImportController) Export(ctx *gin.Context) {
+	var query structs.UserExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		use_response.BadRequest(ctx, "无效的导出参数")
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if query.Format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	filename := fmt.Sprintf("users_%s.%s", time.Now().Format("20060102"), query.Format)
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// 响应头写出后无法再返回JSON错误，只能记录日志并中断连接
+	if err := c.importService.Export(ctx.Request.Context(), &query, ctx.Writer); err != nil {
+		c.Logger.LogError("导出用户失败", zap.String("format", query.Format), zap.Error(err))
+		if !ctx.Writer.Written() {
+			use_response.ServerError(ctx, "导出用户失败")
+			return
+		}
+		ctx.Abort()
+	}
+}
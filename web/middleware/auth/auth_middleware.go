@@ -1,32 +1,65 @@
 package use_AuthMiddleware
 
 import (
+	"errors"
 	"gin-center/configs/config"
+	"gin-center/pkg/http/clientip"
 	use_headers "gin-center/pkg/http/headers"
 	use_response "gin-center/pkg/http/response"
+	"gin-center/pkg/security/useCookie"
 	useJwt "gin-center/pkg/security/useJwt"
-	"strings"
+	"strconv"
 
 	"gin-center/infrastructure/zaplogger"
+	use_apiKeyInterface "gin-center/internal/domain/interface/api_key"
+	use_operationLogInterface "gin-center/internal/domain/interface/operation_log"
+	use_serviceAccountInterface "gin-center/internal/domain/interface/service_account"
+	use_sessionInterface "gin-center/internal/domain/interface/session"
+	APIKeyModel "gin-center/internal/domain/model/api_key"
+	LoginHistoryModel "gin-center/internal/domain/model/login_history"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+	"gin-center/internal/types/constants"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// 认证方式，保存在上下文的auth_method中
+const (
+	AuthMethodSession        = "session"
+	AuthMethodAPIKey         = "api_key"
+	AuthMethodServiceAccount = "service_account"
+)
+
 // JWTAuth 统一的JWT认证中间件
-func JWTAuth(cfg *config.GlobalConfig, logger *zaplogger.ServiceLogger) gin.HandlerFunc {
-	// 初始化JWT配置
-	jwtConfig := useJwt.NewJWTConfig(&useJwt.JWTConfig{
-		SecretKey:            cfg.JWT.SecretKey,
-		AccessTokenLifetime:  cfg.JWT.AccessTokenLifetime,
-		RefreshTokenLifetime: cfg.JWT.RefreshTokenLifetime,
-		Issuer:               cfg.JWT.Issuer,
-		BlacklistCleanupTick: cfg.JWT.BlacklistCleanupTick,
-	})
-	// 返回中间件处理函数
+// 令牌校验通过后还需对应会话仍然有效，会话被注销后其访问令牌立即失效
+// 同时接受X-API-Key请求头或带gck_/gcp_前缀的Bearer令牌形式的API密钥
+// 未携带令牌时依次尝试令牌Cookie与已校验的客户端证书，后者按证书身份认证为服务账号
+// 以Cookie认证的非安全方法请求须携带与CSRF Cookie一致的CSRF请求头
+// 模拟登录会话的每个请求都会同时记录目标用户与管理员到审计日志
+func JWTAuth(jwtConfig *useJwt.JWTConfig, sessions use_sessionInterface.SessionServiceInterface, apiKeys use_apiKeyInterface.APIKeyServiceInterface, serviceAccounts use_serviceAccountInterface.ServiceAccountServiceInterface, cookies *useCookie.Transport, audit use_operationLogInterface.OperationLogServiceInterface, logger *zaplogger.ServiceLogger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取并验证Authorization头
+		// 获取Bearer令牌，前缀已由GetAuthorizationToken去除
 		token := use_headers.GetAuthorizationToken(c)
+		if apiKey := use_headers.GetAPIKey(c); apiKey != "" || APIKeyModel.IsToken(token) {
+			if apiKey == "" {
+				apiKey = token
+			}
+			authenticateAPIKey(c, apiKeys, apiKey, logger)
+			return
+		}
+		if token == "" {
+			if token = cookies.AccessToken(c); token != "" && !useCookie.SafeMethod(c.Request.Method) && !cookies.VerifyCSRF(c) {
+				logger.LogWarn("CSRF令牌校验失败", zap.String("ip", clientip.Get(c)), zap.String("path", c.Request.URL.Path))
+				use_response.Forbidden(c, "CSRF令牌无效")
+				c.Abort()
+				return
+			}
+		}
+		if token == "" && c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			authenticateServiceAccount(c, serviceAccounts, logger)
+			return
+		}
 		if token == "" {
 			logger.LogWarn("缺少认证头")
 			use_response.Unauthorized(c, "缺少认证头")
@@ -34,31 +67,150 @@ func JWTAuth(cfg *config.GlobalConfig, logger *zaplogger.ServiceLogger) gin.Hand
 			return
 		}
 
-		// 解析Bearer token
-		parts := strings.SplitN(token, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			logger.LogWarn("无效的认证头格式")
-			use_response.Unauthorized(c, "无效的认证头格式")
+		// 验证JWT token
+		claims, err := jwtConfig.ParseClaims(token)
+		if err != nil {
+			logger.LogWarn("Token验证失败", zap.Error(err))
+			use_response.Unauthorized(c, "无效的Token")
 			c.Abort()
 			return
 		}
-
-		// 验证JWT token
-		claims, err := jwtConfig.ParseToken(parts[1])
+		userID, err := strconv.ParseUint(claims.UserID, 10, 64)
 		if err != nil {
-			logger.LogWarn("Token验证失败", zap.Error(err))
+			logger.LogWarn("Token中的用户ID无效", zap.String("user_id", claims.UserID))
 			use_response.Unauthorized(c, "无效的Token")
 			c.Abort()
 			return
 		}
 
+		// 校验会话
+		session, err := sessions.Authenticate(c.Request.Context(), claims, clientip.Get(c))
+		if err != nil {
+			if errors.Is(err, constants.ErrSessionNotFound) {
+				logger.LogWarn("会话已失效", zap.String("session_id", claims.SessionID), zap.Error(err))
+				use_response.Unauthorized(c, "会话已失效，请重新登录")
+			} else {
+				logger.LogError("会话校验失败", zap.String("session_id", claims.SessionID), zap.Error(err))
+				use_response.ServerError(c, "会话校验失败")
+			}
+			c.Abort()
+			return
+		}
+
 		// 设置用户信息到上下文
-		c.Set("user_id", claims.ID)
+		c.Set("user_id", uint(userID))
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("session_id", session.ID)
+		c.Set("auth_method", AuthMethodSession)
+		if !session.Impersonated() {
+			c.Next()
+			return
+		}
+
+		c.Set("actor_id", session.ActorID)
+		c.Set("actor_username", session.ActorUsername)
+		c.Header(use_headers.XImpersonator, session.ActorUsername)
 		c.Next()
+
+		audit.Record(c.Request.Context(), &OperationLogModel.OperationLog{
+			UserID:    claims.UserID,
+			UserType:  LoginHistoryModel.UserTypeNormal,
+			Operation: OperationLogModel.OperationImpersonation,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Params:    "session_id=" + session.ID,
+			IP:        clientip.Get(c),
+			Status:    c.Writer.Status(),
+			ActorID:   strconv.FormatUint(uint64(session.ActorID), 10),
+			ActorName: session.ActorUsername,
+		})
 	}
 }
 
+// OptionalSession 可选的会话认证中间件，用于公开接口中需要区分登录用户的场景
+// 请求携带有效的登录会话（Bearer令牌或令牌Cookie）时设置与JWTAuth相同的上下文信息，
+// 否则直接放行；不接受API密钥、服务账号与模拟登录会话
+func OptionalSession(jwtConfig *useJwt.JWTConfig, sessions use_sessionInterface.SessionServiceInterface, cookies *useCookie.Transport) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := use_headers.GetAuthorizationToken(c)
+		if token == "" {
+			token = cookies.AccessToken(c)
+		}
+		if token == "" || APIKeyModel.IsToken(token) {
+			c.Next()
+			return
+		}
+		claims, err := jwtConfig.ParseClaims(token)
+		if err != nil {
+			c.Next()
+			return
+		}
+		userID, err := strconv.ParseUint(claims.UserID, 10, 64)
+		if err != nil {
+			c.Next()
+			return
+		}
+		session, err := sessions.Authenticate(c.Request.Context(), claims, clientip.Get(c))
+		if err != nil || session.Impersonated() {
+			c.Next()
+			return
+		}
+		c.Set("user_id", uint(userID))
+		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("session_id", session.ID)
+		c.Set("auth_method", AuthMethodSession)
+		c.Next()
+	}
+}
+
+// authenticateAPIKey 校验API密钥，请求处理完成后将本次调用写入审计日志
+func authenticateAPIKey(c *gin.Context, apiKeys use_apiKeyInterface.APIKeyServiceInterface, raw string, logger *zaplogger.ServiceLogger) {
+	principal, err := apiKeys.Authenticate(c.Request.Context(), raw, clientip.Get(c))
+	if err != nil {
+		if errors.Is(err, constants.ErrAPIKeyNotFound) {
+			logger.LogWarn("API密钥无效", zap.String("ip", clientip.Get(c)))
+			use_response.Unauthorized(c, "无效的API密钥")
+		} else {
+			logger.LogError("API密钥校验失败", zap.Error(err))
+			use_response.ServerError(c, "API密钥校验失败")
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.UserID)
+	c.Set("username", principal.Username)
+	c.Set("role", principal.Role)
+	c.Set("auth_method", AuthMethodAPIKey)
+	c.Set("api_key_id", principal.KeyID)
+	c.Set("scopes", principal.Scopes)
+	c.Next()
+
+	apiKeys.RecordUsage(c.Request.Context(), principal, c.Request.Method, c.Request.URL.Path, clientip.Get(c), c.Writer.Status())
+}
+
+// authenticateServiceAccount 按客户端证书认证服务账号，权限同样受授权范围限制
+func authenticateServiceAccount(c *gin.Context, serviceAccounts use_serviceAccountInterface.ServiceAccountServiceInterface, logger *zaplogger.ServiceLogger) {
+	cert := c.Request.TLS.VerifiedChains[0][0]
+	principal, err := serviceAccounts.Authenticate(c.Request.Context(), cert)
+	if err != nil {
+		logger.LogWarn("客户端证书未映射到服务账号", zap.String("subject", cert.Subject.String()), zap.String("ip", clientip.Get(c)))
+		use_response.Unauthorized(c, "客户端证书未授权")
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.UserID)
+	c.Set("username", principal.Username)
+	c.Set("role", principal.Role)
+	c.Set("auth_method", AuthMethodServiceAccount)
+	c.Set("service_account", principal.Identity)
+	c.Set("scopes", principal.Scopes)
+	c.Next()
+}
+
 // AdminAuth 管理员权限验证中间件
 func AdminAuth(logger *zaplogger.ServiceLogger) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,80 @@
+package use_AuthMiddleware
+
+import (
+	"gin-center/infrastructure/zaplogger"
+	use_permissionInterface "gin-center/internal/domain/interface/permission"
+	use_response "gin-center/pkg/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequirePermission 权限校验中间件，需在JWTAuth之后使用
+// API密钥访问时，权限需同时在密钥的授权范围与所有者当前的权限内，服务账号同理
+func RequirePermission(permissions use_permissionInterface.PermissionServiceInterface, code string, logger *zaplogger.ServiceLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == AuthMethodAPIKey && !hasScope(c.GetStringSlice("scopes"), code) {
+			logger.LogWarn("API密钥缺少所需权限",
+				zap.Uint("key_id", c.GetUint("api_key_id")),
+				zap.String("permission", code))
+			use_response.Forbidden(c, "API密钥未授权该操作")
+			c.Abort()
+			return
+		}
+		if c.GetString("auth_method") == AuthMethodServiceAccount && !hasScope(c.GetStringSlice("scopes"), code) {
+			logger.LogWarn("服务账号缺少所需权限",
+				zap.String("service_account", c.GetString("service_account")),
+				zap.String("permission", code))
+			use_response.Forbidden(c, "服务账号未授权该操作")
+			c.Abort()
+			return
+		}
+
+		allowed, err := permissions.HasPermission(c.Request.Context(), c.GetString("role"), c.GetUint("user_id"), code)
+		if err != nil {
+			logger.LogError("权限校验失败", zap.String("permission", code), zap.Error(err))
+			use_response.ServerError(c, "权限校验失败")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			use_response.Forbidden(c, "权限不足")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession 仅允许登录会话访问，用于密钥管理、会话管理等不应由API密钥操作的接口
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != AuthMethodSession {
+			use_response.Forbidden(c, "该接口不支持API密钥访问")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// NoImpersonation 禁止模拟登录会话访问，用于创建凭据、绑定账号等会延续到模拟结束之后的操作
+func NoImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetUint("actor_id") != 0 {
+			use_response.Forbidden(c, "模拟登录状态下不允许该操作")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasScope(scopes []string, code string) bool {
+	for _, scope := range scopes {
+		if scope == code {
+			return true
+		}
+	}
+	return false
+}
@@ -1,20 +1,247 @@
 package cors
 
 import (
-	"gin-center/configs/config"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/gin-contrib/cors"
+	"gin-center/configs/config"
+
+	contribcors "github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware(cfg *config.GlobalConfig) gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     cfg.Server.CORS.AllowOrigins,
-		AllowMethods:     cfg.Server.CORS.AllowMethods,
-		AllowHeaders:     cfg.Server.CORS.AllowHeaders,
-		ExposeHeaders:    cfg.Server.CORS.ExposeHeaders,
-		AllowCredentials: cfg.Server.CORS.AllowCredentials,
-		MaxAge:           time.Duration(cfg.Server.CORS.MaxAge) * time.Second,
+// regexPrefix 以该前缀开头的来源按正则表达式匹配
+const regexPrefix = "regex:"
+
+var (
+	defaultMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead}
+	defaultHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID", "X-CSRF-Token", "X-Token-Transport"}
+)
+
+// CORS 按路由组选择跨域策略，策略可在配置变更后整体替换
+type CORS struct {
+	groups   map[string][]string
+	prefixes []groupPrefix
+	handlers atomic.Pointer[handlerSet]
+}
+
+// groupPrefix 路径前缀及其所属路由组，按前缀长度降序排列
+type groupPrefix struct {
+	prefix string
+	group  string
+}
+
+// handlerSet 一次加载的全部策略，未允许任何来源的策略为nil
+type handlerSet struct {
+	enabled  bool
+	fallback gin.HandlerFunc
+	groups   map[string]gin.HandlerFunc
+}
+
+// New 创建跨域中间件，groups为路由组名称与其路径前缀，配置中只能出现这些路由组
+func New(cfg *config.CORSConfig, groups map[string][]string) (*CORS, error) {
+	m := &CORS{groups: groups}
+	for group, prefixes := range groups {
+		for _, prefix := range prefixes {
+			m.prefixes = append(m.prefixes, groupPrefix{prefix: prefix, group: group})
+		}
+	}
+	sort.Slice(m.prefixes, func(i, j int) bool {
+		return len(m.prefixes[i].prefix) > len(m.prefixes[j].prefix)
 	})
+	if err := m.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload 校验并应用新的配置，校验失败时保留原有策略
+func (m *CORS) Reload(cfg *config.CORSConfig) error {
+	set := &handlerSet{enabled: cfg.Enabled, groups: map[string]gin.HandlerFunc{}}
+	var err error
+	if set.fallback, err = newHandler(&cfg.CORSPolicy); err != nil {
+		return fmt.Errorf("默认跨域策略: %w", err)
+	}
+	for group, policy := range cfg.Groups {
+		if _, ok := m.groups[group]; !ok {
+			return fmt.Errorf("未知的跨域路由组: %s", group)
+		}
+		if set.groups[group], err = newHandler(&policy); err != nil {
+			return fmt.Errorf("路由组%s的跨域策略: %w", group, err)
+		}
+	}
+	m.handlers.Store(set)
+	return nil
+}
+
+// Handler 返回全局注册的中间件，预检请求在路由匹配之前处理
+func (m *CORS) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set := m.handlers.Load()
+		if !set.enabled {
+			c.Next()
+			return
+		}
+		handler := set.fallback
+		if group, ok := m.group(c.Request.URL.Path); ok {
+			if h, configured := set.groups[group]; configured {
+				handler = h
+			}
+		}
+		if handler == nil {
+			c.Next()
+			return
+		}
+		handler(c)
+	}
+}
+
+func (m *CORS) group(path string) (string, bool) {
+	for _, p := range m.prefixes {
+		if path == p.prefix || strings.HasPrefix(path, p.prefix+"/") {
+			return p.group, true
+		}
+	}
+	return "", false
+}
+
+// newHandler 校验策略并构建处理器，未允许任何来源时返回nil
+func newHandler(policy *config.CORSPolicy) (gin.HandlerFunc, error) {
+	if len(policy.AllowOrigins) == 0 {
+		return nil, nil
+	}
+	if policy.MaxAge < 0 {
+		return nil, errors.New("max_age不能为负数")
+	}
+	matcher, allowAll, err := compileOrigins(policy.AllowOrigins)
+	if err != nil {
+		return nil, err
+	}
+	if allowAll && policy.AllowCredentials {
+		return nil, errors.New("允许携带凭据时allow_origins不能使用*")
+	}
+
+	cfg := contribcors.Config{
+		AllowMethods:     policy.AllowMethods,
+		AllowHeaders:     policy.AllowHeaders,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           time.Duration(policy.MaxAge) * time.Second,
+	}
+	if len(cfg.AllowMethods) == 0 {
+		cfg.AllowMethods = defaultMethods
+	}
+	if len(cfg.AllowHeaders) == 0 {
+		cfg.AllowHeaders = defaultHeaders
+	}
+	if allowAll {
+		cfg.AllowAllOrigins = true
+	} else {
+		cfg.AllowOriginFunc = matcher.match
+	}
+	return contribcors.New(cfg), nil
+}
+
+// originMatcher 匹配请求的Origin头
+type originMatcher struct {
+	exact    map[string]bool
+	suffixes []wildcardOrigin
+	patterns []*regexp.Regexp
+}
+
+// wildcardOrigin 通配子域名，只匹配子域名，不匹配域名本身
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+	port   string
+}
+
+// compileOrigins 解析来源配置，第二个返回值表示允许任意来源
+// 请求的来源转为小写后匹配；正则表达式需完整匹配来源，避免https://example.com.evil.net之类的来源绕过
+func compileOrigins(origins []string) (*originMatcher, bool, error) {
+	m := &originMatcher{exact: map[string]bool{}}
+	allowAll := false
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "*":
+			allowAll = true
+		case strings.HasPrefix(origin, regexPrefix):
+			re, err := regexp.Compile("^(?:" + strings.TrimPrefix(origin, regexPrefix) + ")$")
+			if err != nil {
+				return nil, false, fmt.Errorf("无效的来源正则表达式%q: %w", origin, err)
+			}
+			m.patterns = append(m.patterns, re)
+		case strings.Contains(origin, "*"):
+			w, err := parseWildcard(origin)
+			if err != nil {
+				return nil, false, err
+			}
+			m.suffixes = append(m.suffixes, w)
+		default:
+			normalized, err := normalizeOrigin(origin)
+			if err != nil {
+				return nil, false, err
+			}
+			m.exact[normalized] = true
+		}
+	}
+	if allowAll && len(origins) > 1 {
+		return nil, false, errors.New("allow_origins使用*时不能再配置其他来源")
+	}
+	return m, allowAll, nil
+}
+
+// normalizeOrigin 校验并规范化完整来源，来源只能包含协议、主机与端口
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("无效的来源%q，应形如https://example.com", origin)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// parseWildcard 解析形如https://*.example.com或https://*.example.com:8443的通配来源
+func parseWildcard(origin string) (wildcardOrigin, error) {
+	invalid := fmt.Errorf("无效的通配来源%q，应形如https://*.example.com", origin)
+	scheme, rest, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok || (scheme != "http" && scheme != "https") || !strings.HasPrefix(rest, "*.") {
+		return wildcardOrigin{}, invalid
+	}
+	host, port, _ := strings.Cut(rest[1:], ":")
+	if strings.ContainsAny(host, "*/") || strings.Count(host, ".") < 2 || strings.ContainsAny(port, "*/") {
+		return wildcardOrigin{}, invalid
+	}
+	return wildcardOrigin{scheme: scheme, suffix: host, port: port}, nil
+}
+
+func (m *originMatcher) match(origin string) bool {
+	origin = strings.ToLower(origin)
+	if m.exact[origin] {
+		return true
+	}
+	if len(m.suffixes) > 0 {
+		if scheme, rest, ok := strings.Cut(origin, "://"); ok {
+			host, port, _ := strings.Cut(rest, ":")
+			for _, w := range m.suffixes {
+				if scheme == w.scheme && port == w.port && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+					return true
+				}
+			}
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,79 @@
+package cors
+
+import "testing"
+
+func TestCompileOriginsRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+	}{
+		{name: "*与其他来源同时配置", origins: []string{"*", "https://example.com"}},
+		{name: "不支持的协议", origins: []string{"ftp://example.com"}},
+		{name: "包含路径", origins: []string{"https://example.com/app"}},
+		{name: "包含查询参数", origins: []string{"https://example.com?a=1"}},
+		{name: "包含用户信息", origins: []string{"https://user@example.com"}},
+		{name: "缺少协议", origins: []string{"example.com"}},
+		{name: "通配符不在最左侧", origins: []string{"https://api.*.example.com"}},
+		{name: "通配顶级域名", origins: []string{"https://*.com"}},
+		{name: "通配端口", origins: []string{"https://*.example.com:*"}},
+		{name: "无效的正则表达式", origins: []string{"regex:https://(.*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := compileOrigins(tt.origins); err == nil {
+				t.Error("compileOrigins() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCompileOriginsAllowAll(t *testing.T) {
+	_, allowAll, err := compileOrigins([]string{" * "})
+	if err != nil || !allowAll {
+		t.Errorf("compileOrigins() allowAll = %v, err = %v, want true, nil", allowAll, err)
+	}
+}
+
+func TestOriginMatcherMatch(t *testing.T) {
+	m, allowAll, err := compileOrigins([]string{
+		"https://Example.com/",
+		"http://localhost:3000",
+		"https://*.example.org",
+		"https://*.example.net:8443",
+		`regex:https://app-[0-9]+\.example\.io`,
+	})
+	if err != nil || allowAll {
+		t.Fatalf("compileOrigins() allowAll = %v, err = %v", allowAll, err)
+	}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://example.com", want: true},
+		{origin: "HTTPS://EXAMPLE.COM", want: true},
+		{origin: "http://example.com", want: false},
+		{origin: "https://example.com:8443", want: false},
+		{origin: "http://localhost:3000", want: true},
+		{origin: "http://localhost:3001", want: false},
+		{origin: "https://api.example.org", want: true},
+		{origin: "https://a.b.example.org", want: true},
+		{origin: "https://example.org", want: false},
+		{origin: "https://evilexample.org", want: false},
+		{origin: "http://api.example.org", want: false},
+		{origin: "https://api.example.org:8443", want: false},
+		{origin: "https://api.example.net:8443", want: true},
+		{origin: "https://api.example.net", want: false},
+		{origin: "https://app-12.example.io", want: true},
+		{origin: "https://app-12.example.io.evil.net", want: false},
+		{origin: "https://evil.net/https://app-12.example.io", want: false},
+		{origin: "", want: false},
+		{origin: "null", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := m.match(tt.origin); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package use_IPFilterMiddleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"gin-center/infrastructure/zaplogger"
+	use_ipRuleInterface "gin-center/internal/domain/interface/ip_rule"
+	use_operationLogInterface "gin-center/internal/domain/interface/operation_log"
+	OperationLogModel "gin-center/internal/domain/model/operation_log"
+	"gin-center/pkg/http/clientip"
+	use_response "gin-center/pkg/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// auditInterval 同一IP在同一路由组被拒绝时，该间隔内只写入一条审计日志，避免扫描请求写满审计表
+const auditInterval = time.Minute
+
+// maxThrottleEntries auditThrottle最多记录的来源数
+const maxThrottleEntries = 10000
+
+// IPFilter 按路由组的访问控制规则拒绝客户端，须注册在认证中间件之前，被拒绝的请求不会进行认证
+func IPFilter(rules use_ipRuleInterface.IPRuleServiceInterface, group string, audit use_operationLogInterface.OperationLogServiceInterface, logger *zaplogger.ServiceLogger) gin.HandlerFunc {
+	throttle := &auditThrottle{last: map[string]time.Time{}}
+	return func(c *gin.Context) {
+		ip := clientip.Get(c)
+		decision := rules.Check(group, ip)
+		if decision.Allowed {
+			c.Next()
+			return
+		}
+
+		logger.Ctx(c.Request.Context()).LogWarn("访问控制规则拒绝请求",
+			zap.String("group", group),
+			zap.String("ip", ip),
+			zap.String("country", decision.Country),
+			zap.String("reason", decision.Reason),
+			zap.String("path", c.Request.URL.Path))
+		if throttle.allow(group + "|" + ip) {
+			params := "group=" + group + ";reason=" + decision.Reason
+			if decision.Country != "" {
+				params += ";country=" + decision.Country
+			}
+			audit.Record(c.Request.Context(), &OperationLogModel.OperationLog{
+				Operation: OperationLogModel.OperationIPBlocked,
+				Method:    c.Request.Method,
+				Path:      c.Request.URL.Path,
+				Params:    params,
+				IP:        ip,
+				Status:    http.StatusForbidden,
+			})
+		}
+		use_response.Forbidden(c, "当前网络不允许访问")
+		c.Abort()
+	}
+}
+
+// auditThrottle 记录每个键最近一次写入审计日志的时间
+type auditThrottle struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func (t *auditThrottle) allow(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if at, ok := t.last[key]; ok && now.Sub(at) < auditInterval {
+		return false
+	}
+	// 条目过多时清理过期记录，仍然过多说明正被大量地址扫描，暂停写入审计日志
+	if len(t.last) >= maxThrottleEntries {
+		for k, at := range t.last {
+			if now.Sub(at) >= auditInterval {
+				delete(t.last, k)
+			}
+		}
+		if len(t.last) >= maxThrottleEntries {
+			return false
+		}
+	}
+	t.last[key] = now
+	return true
+}
@@ -0,0 +1,94 @@
+package use_LimitsMiddleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gin-center/configs/config"
+	infraErrors "gin-center/infrastructure/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxBodySize = 1 << 20
+)
+
+// limit 一组路径的处理时限与请求体大小，为0表示不限制
+type limit struct {
+	prefix      string
+	timeout     time.Duration
+	maxBodySize int64
+}
+
+// Limits 为请求设置处理时限并限制请求体大小
+// 时限通过请求的context传递给服务与数据库调用；超时后处理器未写入响应时返回504
+// Content-Length超出限制的请求直接返回413，未声明长度的请求在读取超出限制时读取失败
+func Limits(cfg *config.RequestLimitsConfig) gin.HandlerFunc {
+	fallback := limit{
+		timeout:     pick(cfg.Timeout, defaultTimeout),
+		maxBodySize: pick(cfg.MaxBodySize, defaultMaxBodySize),
+	}
+	routes := make([]limit, 0, len(cfg.Routes))
+	for _, r := range cfg.Routes {
+		routes = append(routes, limit{
+			prefix:      strings.TrimSuffix(r.Prefix, "/"),
+			timeout:     pick(r.Timeout, fallback.timeout),
+			maxBodySize: pick(r.MaxBodySize, fallback.maxBodySize),
+		})
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].prefix) > len(routes[j].prefix)
+	})
+
+	return func(c *gin.Context) {
+		l := match(routes, fallback, c.Request.URL.Path)
+
+		if l.maxBodySize > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			if c.Request.ContentLength > l.maxBodySize {
+				infraErrors.Abort(c, infraErrors.ErrPayloadTooLarge)
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, l.maxBodySize)
+		}
+
+		if l.timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), l.timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			infraErrors.Abort(c, infraErrors.ErrTimeout)
+		}
+	}
+}
+
+// match 返回最长匹配前缀的限制，前缀按路径段匹配，/api/v1/upload不匹配/api/v1/uploads
+func match(routes []limit, fallback limit, path string) limit {
+	for _, r := range routes {
+		if path == r.prefix || strings.HasPrefix(path, r.prefix+"/") {
+			return r
+		}
+	}
+	return fallback
+}
+
+// pick 为0时返回默认值，负数表示不限制，统一转为0
+func pick[T time.Duration | int64](value, def T) T {
+	switch {
+	case value == 0:
+		return def
+	case value < 0:
+		return 0
+	}
+	return value
+}
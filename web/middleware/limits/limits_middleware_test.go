@@ -0,0 +1,180 @@
+package use_LimitsMiddleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin-center/configs/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name  string
+		value int64
+		def   int64
+		want  int64
+	}{
+		{name: "为0时使用默认值", value: 0, def: 1024, want: 1024},
+		{name: "正数原样返回", value: 10, def: 1024, want: 10},
+		{name: "负数表示不限制", value: -1, def: 1024, want: 0},
+		{name: "默认值为不限制时仍可覆盖", value: 10, def: 0, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pick(tt.value, tt.def); got != tt.want {
+				t.Errorf("pick(%d, %d) = %d, want %d", tt.value, tt.def, got, tt.want)
+			}
+			if got := pick(time.Duration(tt.value), time.Duration(tt.def)); got != time.Duration(tt.want) {
+				t.Errorf("pick(%v, %v) = %v, want %v", time.Duration(tt.value), time.Duration(tt.def), got, time.Duration(tt.want))
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	fallback := limit{maxBodySize: 1}
+	// 与Limits中一致，按前缀长度从长到短排列
+	routes := []limit{
+		{prefix: "/api/v1/upload", maxBodySize: 2},
+		{prefix: "/api/v1", maxBodySize: 3},
+	}
+	withRoot := append(routes, limit{prefix: "", maxBodySize: 4})
+	tests := []struct {
+		name   string
+		routes []limit
+		path   string
+		want   int64
+	}{
+		{name: "完全匹配", routes: routes, path: "/api/v1/upload", want: 2},
+		{name: "匹配子路径", routes: routes, path: "/api/v1/upload/123/chunks/0", want: 2},
+		{name: "按路径段匹配", routes: routes, path: "/api/v1/uploads", want: 3},
+		{name: "选择最长的前缀", routes: routes, path: "/api/v1/users", want: 3},
+		{name: "前缀不是路径段", routes: routes, path: "/api/v10", want: 1},
+		{name: "未匹配时使用全局设置", routes: routes, path: "/health", want: 1},
+		{name: "根路径前缀匹配所有路径", routes: withRoot, path: "/health", want: 4},
+		{name: "根路径前缀匹配根路径", routes: withRoot, path: "/", want: 4},
+		{name: "根路径前缀不影响更长的前缀", routes: withRoot, path: "/api/v1/upload", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := match(tt.routes, fallback, tt.path); got.maxBodySize != tt.want {
+				t.Errorf("match(%q) = %+v, want maxBodySize %d", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// newRouter 所有路径都读取请求体，/slow等待超时后按written决定是否写入响应
+func newRouter(cfg *config.RequestLimitsConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Limits(cfg))
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/slow") {
+			<-c.Request.Context().Done()
+			if c.Query("written") == "1" {
+				c.String(http.StatusOK, "late")
+			}
+			return
+		}
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.String(http.StatusBadRequest, "read failed")
+			return
+		}
+		if _, ok := c.Request.Context().Deadline(); ok {
+			c.String(http.StatusOK, "deadline")
+			return
+		}
+		c.String(http.StatusOK, "no deadline")
+	})
+	return r
+}
+
+func TestLimits(t *testing.T) {
+	cfg := &config.RequestLimitsConfig{
+		MaxBodySize: 16,
+		Routes: []config.RouteLimitConfig{
+			{Prefix: "/api/v1/upload/", MaxBodySize: 4},
+			{Prefix: "/api/v1/import", MaxBodySize: -1, Timeout: -1},
+			{Prefix: "/slow", Timeout: 20 * time.Millisecond},
+		},
+	}
+	tests := []struct {
+		name string
+		path string
+		body string
+		// chunked 不声明Content-Length
+		chunked  bool
+		want     int
+		wantBody string
+	}{
+		{name: "请求体在限制内", path: "/api/v1/users", body: strings.Repeat("a", 16), want: http.StatusOK, wantBody: "deadline"},
+		{name: "Content-Length超出全局限制", path: "/api/v1/users", body: strings.Repeat("a", 17), want: http.StatusRequestEntityTooLarge},
+		{name: "前缀去掉结尾斜杠", path: "/api/v1/upload", body: strings.Repeat("a", 5), want: http.StatusRequestEntityTooLarge},
+		{name: "相邻路径使用全局限制", path: "/api/v1/uploads", body: strings.Repeat("a", 5), want: http.StatusOK},
+		{name: "未声明长度时读取超出限制失败", path: "/api/v1/upload/1", body: strings.Repeat("a", 5), chunked: true, want: http.StatusBadRequest},
+		{name: "负数表示不限制大小与时限", path: "/api/v1/import", body: strings.Repeat("a", 2<<20), want: http.StatusOK, wantBody: "no deadline"},
+		{name: "超时且未写入响应返回504", path: "/slow", want: http.StatusGatewayTimeout},
+		{name: "超时但已写入响应时保留原响应", path: "/slow?written=1", want: http.StatusOK, wantBody: "late"},
+	}
+	router := newRouter(cfg)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestLimitsRootPrefix(t *testing.T) {
+	// prefix为"/"时去掉结尾斜杠变为""，匹配所有路径
+	router := newRouter(&config.RequestLimitsConfig{
+		Routes: []config.RouteLimitConfig{{Prefix: "/", MaxBodySize: 2}},
+	})
+	for _, path := range []string{"/", "/health", "/api/v1/users"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("abc"))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("POST %s status = %d, want %d", path, w.Code, http.StatusRequestEntityTooLarge)
+		}
+	}
+}
+
+func TestLimitsDefaults(t *testing.T) {
+	router := newRouter(&config.RequestLimitsConfig{})
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{name: "默认1MB以内", size: defaultMaxBodySize, want: http.StatusOK},
+		{name: "超出默认1MB", size: defaultMaxBodySize + 1, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(strings.Repeat("a", tt.size)))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package use_LoggerMiddleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin-center/configs/config"
+	"gin-center/infrastructure/zaplogger"
+	"gin-center/pkg/http/clientip"
+	use_headers "gin-center/pkg/http/headers"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultMaxBodySize 未配置max_body_size时记录请求体与响应体的上限
+	defaultMaxBodySize = 64 << 10
+	// redacted 敏感字段脱敏后的取值
+	redacted = "[REDACTED]"
+)
+
+var (
+	// sensitiveKeywords 字段名包含这些词时脱敏
+	sensitiveKeywords = []string{"password", "secret", "token", "authorization", "cookie", "signature"}
+	// sensitiveKeys 字段名等于这些值时脱敏
+	sensitiveKeys = map[string]bool{"code": true, "code_verifier": true, "key": true, "api_key": true, "x-api-key": true}
+)
+
+// AccessLog 记录结构化访问日志
+// 按配置记录请求头、JSON或表单请求体及JSON响应体，默认对密码、令牌等敏感字段脱敏；
+// 状态码5xx记为Error，4xx记为Warn，其余记为Info
+func AccessLog(cfg *config.RequestLogConfig, logger *zaplogger.ServiceLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if skipPath(cfg.SkipPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		limit := cfg.MaxBodySize
+		if limit <= 0 {
+			limit = defaultMaxBodySize
+		}
+
+		var requestBody []byte
+		var requestTruncated bool
+		if cfg.LogBody && loggableRequest(c.Request) {
+			requestBody, requestTruncated = peekBody(c.Request, limit)
+		}
+		var writer *bodyWriter
+		if cfg.LogResponse {
+			writer = &bodyWriter{ResponseWriter: c.Writer, limit: limit}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("route", c.FullPath()),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", clientip.Get(c)),
+			zap.String("user_agent", c.Request.UserAgent()),
+			zap.Int64("request_size", c.Request.ContentLength),
+			zap.Int("response_size", c.Writer.Size()),
+		}
+		if c.Request.URL.RawQuery != "" {
+			fields = append(fields, zap.String("query", redactQuery(c.Request.URL.Query(), cfg.SensitiveLog)))
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			fields = append(fields, zap.Any("user_id", userID))
+		}
+		if cfg.LogHeaders {
+			fields = append(fields, zap.Any("headers", redactHeaders(use_headers.GetRequestHeaders(c), cfg.SensitiveLog)))
+		}
+		if requestTruncated {
+			fields = append(fields, zap.Bool("request_body_truncated", true))
+		} else if len(requestBody) > 0 {
+			fields = append(fields, bodyField("request_body", c.ContentType(), requestBody, cfg.SensitiveLog, false))
+		}
+		if writer != nil {
+			if writer.overflow {
+				fields = append(fields, zap.Bool("response_body_truncated", true))
+			} else if writer.buf.Len() > 0 {
+				fields = append(fields, bodyField("response_body", gin.MIMEJSON, writer.buf.Bytes(), cfg.SensitiveLog, true))
+			}
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			fields = append(fields, zap.String("errors", errs.String()))
+		}
+
+		log := logger.Ctx(c.Request.Context())
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.LogError("HTTP请求", fields...)
+		case status >= http.StatusBadRequest:
+			log.LogWarn("HTTP请求", fields...)
+		default:
+			log.LogInfo("HTTP请求", fields...)
+		}
+	}
+}
+
+func skipPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// loggableRequest 仅记录JSON和表单请求体，文件上传等二进制内容只记录大小
+func loggableRequest(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(use_headers.ContentType))
+	return mediaType == gin.MIMEJSON || mediaType == gin.MIMEPOSTForm
+}
+
+// peekBody 读取请求体的前limit字节并放回，超出limit时返回truncated
+func peekBody(r *http.Request, limit int64) ([]byte, bool) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), r.Body), Closer: r.Body}
+	if err != nil {
+		return nil, false
+	}
+	if int64(len(data)) > limit {
+		return nil, true
+	}
+	return data, false
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter 在写出响应的同时缓存不超过limit字节的JSON响应体
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyWriter) capture(b []byte) {
+	if w.overflow {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get(use_headers.ContentType))
+	if mediaType != gin.MIMEJSON {
+		return
+	}
+	if int64(w.buf.Len()+len(b)) > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(b)
+}
+
+// bodyField 解析JSON或表单内容并脱敏，无法解析的内容仅在关闭脱敏时原样记录
+// envelope为true时body为统一响应结构，顶层的业务状态码code不脱敏
+func bodyField(name, contentType string, body []byte, sensitive, envelope bool) zap.Field {
+	if contentType == gin.MIMEPOSTForm {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return zap.String(name, redactQuery(values, sensitive))
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err == nil {
+			if !sensitive {
+				value = redactBody(value, envelope)
+			}
+			return zap.Any(name, value)
+		}
+	}
+	if sensitive {
+		return zap.ByteString(name, body)
+	}
+	return zap.String(name, redacted)
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, keyword := range sensitiveKeywords {
+		if strings.Contains(key, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactBody 脱敏JSON内容，统一响应结构中顶层的数字code为业务状态码，原样保留
+func redactBody(value any, envelope bool) any {
+	root, ok := value.(map[string]any)
+	if !envelope || !ok {
+		return redactJSON(value)
+	}
+	code, isNumber := root["code"].(json.Number)
+	redactJSON(root)
+	if isNumber {
+		root["code"] = code
+	}
+	return root
+}
+
+// redactJSON 递归脱敏，敏感字段的值无论类型一律替换
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSON(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+func redactQuery(values url.Values, sensitive bool) string {
+	if !sensitive {
+		for key := range values {
+			if isSensitive(key) {
+				values[key] = []string{redacted}
+			}
+		}
+	}
+	return values.Encode()
+}
+
+func redactHeaders(headers map[string]string, sensitive bool) map[string]string {
+	if !sensitive {
+		for key := range headers {
+			if isSensitive(key) {
+				headers[key] = redacted
+			}
+		}
+	}
+	return headers
+}
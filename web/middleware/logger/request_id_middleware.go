@@ -0,0 +1,52 @@
+package use_LoggerMiddleware
+
+import (
+	"gin-center/infrastructure/zaplogger"
+	use_headers "gin-center/pkg/http/headers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDKey 请求ID在gin上下文中的键
+	RequestIDKey = "request_id"
+	// maxRequestIDLength 沿用客户端请求ID的最大长度
+	maxRequestIDLength = 128
+)
+
+// RequestID 为每个请求分配请求ID并写入响应头
+// 客户端或上游网关传入合法的X-Request-ID时沿用，否则生成UUID；
+// 同时将带request_id字段的日志记录器附加到请求context
+func RequestID(logger *zaplogger.ServiceLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(use_headers.XRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set(RequestIDKey, requestID)
+		c.Header(use_headers.XRequestID, requestID)
+
+		requestLogger := logger.With(zap.String(RequestIDKey, requestID))
+		c.Request = c.Request.WithContext(zaplogger.WithContext(c.Request.Context(), requestLogger))
+		c.Next()
+	}
+}
+
+// validRequestID 仅接受字母、数字及-_.:，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
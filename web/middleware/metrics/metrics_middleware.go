@@ -0,0 +1,72 @@
+package use_MetricsMiddleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin-center/configs/config"
+	"gin-center/infrastructure/metrics"
+	use_headers "gin-center/pkg/http/headers"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// unmatchedRoute 未匹配任何路由的请求使用的route标签，避免按原始路径产生大量标签值
+	unmatchedRoute = "unmatched"
+	// otherMethod 非标准请求方法使用的method标签，请求方法由客户端任意指定
+	otherMethod = "OTHER"
+)
+
+// standardMethods 按原值作为method标签的请求方法
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Metrics 按请求方法、路由模板和状态码统计请求数与耗时
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		if !standardMethods[method] {
+			method = otherMethod
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Endpoint 输出Prometheus指标，配置了令牌时要求请求携带相同的Bearer令牌
+func Endpoint(cfg *config.MetricsConfig) gin.HandlerFunc {
+	handler := metrics.Handler()
+	return func(c *gin.Context) {
+		if cfg.Token != "" {
+			token := use_headers.GetAuthorizationToken(c)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,52 @@
+package use_SecurityMiddleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-center/configs/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders 按配置发送安全响应头，响应头在处理请求前写入，处理器可以覆盖
+func SecurityHeaders(cfg *config.SecurityHeadersConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge/time.Second), 10)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		// 浏览器只接受HTTPS响应中的HSTS，经TLS终止代理转发的请求以X-Forwarded-Proto判断
+		if hsts != "" && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		if cfg.ContentSecurityPolicy != "" && !skipCSP(cfg.CSPSkipPaths, c.Request.URL.Path) {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		c.Next()
+	}
+}
+
+func skipCSP(prefixes []string, path string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package use_TracingMiddleware
+
+import (
+	"net/http"
+
+	use_headers "gin-center/pkg/http/headers"
+	"gin-center/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDKey trace_id在gin上下文中的键
+const TraceIDKey = "trace_id"
+
+// Tracing 从请求的traceparent头中提取追踪上下文，并以路由模板为名创建服务端span
+func Tracing(cfg *tracer.Config, serviceName string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !skip[r.URL.Path]
+	}))
+}
+
+// TraceID 将当前span的trace_id写入响应头和gin上下文，须注册在Tracing之后
+func TraceID(cfg *tracer.Config) gin.HandlerFunc {
+	header := cfg.HeaderName
+	if header == "" {
+		header = use_headers.XTraceID
+	}
+	return func(c *gin.Context) {
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			traceID := sc.TraceID().String()
+			c.Set(TraceIDKey, traceID)
+			c.Header(header, traceID)
+		}
+		c.Next()
+	}
+}
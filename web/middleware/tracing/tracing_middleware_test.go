@@ -0,0 +1,104 @@
+package use_TracingMiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin-center/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	const (
+		upstreamTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		upstreamSpanID  = "00f067aa0ba902b7"
+	)
+	cfg := &tracer.Config{Enabled: true, SkipPaths: []string{"/healthz"}}
+	if _, err := tracer.Setup(context.Background(), &tracer.Config{}, "test", "", ""); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracer.NewProvider(cfg, resource.Empty(), exporter)
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Tracing(cfg, "test"), TraceID(cfg))
+	engine.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	engine.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantSpan    bool
+		wantTraceID string
+		wantParent  string
+	}{
+		{
+			name:        "沿用上游的traceparent",
+			path:        "/users/1",
+			traceparent: "00-" + upstreamTraceID + "-" + upstreamSpanID + "-01",
+			wantSpan:    true,
+			wantTraceID: upstreamTraceID,
+			wantParent:  upstreamSpanID,
+		},
+		{name: "无traceparent时创建新的trace", path: "/users/1", wantSpan: true},
+		{name: "无效的traceparent视为不存在", path: "/users/1", traceparent: "00-invalid-01", wantSpan: true},
+		{name: "跳过的路径不创建span", path: "/healthz", traceparent: "00-" + upstreamTraceID + "-" + upstreamSpanID + "-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			if err := provider.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("ForceFlush() error = %v", err)
+			}
+
+			spans := exporter.GetSpans()
+			header := rec.Header().Get("X-Trace-ID")
+			if !tt.wantSpan {
+				if len(spans) != 0 || header != "" {
+					t.Errorf("got %d spans and X-Trace-ID %q, want none", len(spans), header)
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "/users/:id" || span.SpanKind != trace.SpanKindServer {
+				t.Errorf("span = %s %v, want /users/:id server", span.Name, span.SpanKind)
+			}
+			if header != span.SpanContext.TraceID().String() {
+				t.Errorf("X-Trace-ID = %q, want %q", header, span.SpanContext.TraceID())
+			}
+			if tt.wantTraceID != "" && header != tt.wantTraceID {
+				t.Errorf("X-Trace-ID = %q, want upstream %q", header, tt.wantTraceID)
+			}
+			if got := span.Parent.SpanID(); tt.wantParent != "" && got.String() != tt.wantParent {
+				t.Errorf("parent span = %s, want %s", got, tt.wantParent)
+			}
+			if tt.wantParent == "" && span.Parent.IsValid() {
+				t.Errorf("parent span = %s, want none", span.Parent.SpanID())
+			}
+		})
+	}
+}
@@ -3,33 +3,124 @@
 package use_routes
 
 import (
+	"fmt"
+	"net/http"
+
+	"gin-center/configs/config"
 	"gin-center/infrastructure/container"
+	infraErrors "gin-center/infrastructure/errors"
 	"gin-center/infrastructure/zaplogger"
+	IPRuleModel "gin-center/internal/domain/model/ip_rule"
+	PermissionModel "gin-center/internal/domain/model/permission"
+	"gin-center/pkg/storage/useStorage"
 	admin_controller "gin-center/web/controller/admin"
+	api_key_controller "gin-center/web/controller/api_key"
+	health_controller "gin-center/web/controller/health"
+	impersonation_controller "gin-center/web/controller/impersonation"
+	ip_rule_controller "gin-center/web/controller/ip_rule"
+	login_history_controller "gin-center/web/controller/login_history"
+	oauth_controller "gin-center/web/controller/oauth"
+	oauth_server_controller "gin-center/web/controller/oauth_server"
+	permission_controller "gin-center/web/controller/permission"
+	session_controller "gin-center/web/controller/session"
 	system_controller "gin-center/web/controller/system"
+	upload_controller "gin-center/web/controller/upload"
 	user_controller "gin-center/web/controller/user"
+	user_import_controller "gin-center/web/controller/user_import"
 	use_AuthMiddleware "gin-center/web/middleware/auth"
+	use_CorsMiddleware "gin-center/web/middleware/cors"
+	use_IPFilterMiddleware "gin-center/web/middleware/ipfilter"
+	use_LimitsMiddleware "gin-center/web/middleware/limits"
+	use_LoggerMiddleware "gin-center/web/middleware/logger"
+	use_MetricsMiddleware "gin-center/web/middleware/metrics"
+	use_SecurityMiddleware "gin-center/web/middleware/security"
+	use_TracingMiddleware "gin-center/web/middleware/tracing"
 
 	"gin-center/docs"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 )
 
-// SetupRoutes 配置应用程序的所有路由
-func SetupRoutes(r *gin.Engine, container *container.Container) {
+// corsGroups 可单独配置跨域策略的路由组及其路径前缀
+var corsGroups = map[string][]string{
+	"auth":  {"/api/v1/auth"},
+	"admin": {"/api/v1/admin", "/api/v1/system"},
+}
+
+// SetupRoutes 配置应用程序的所有路由，中间件配置无效时返回错误
+func SetupRoutes(r *gin.Engine, container *container.Container) error {
 	zapLogger := zaplogger.NewServiceLogger()
 
+	// 链路追踪最先注册，之后的中间件和日志都能取得当前span
+	if traceCfg := &container.Config.Trace; traceCfg.Enabled {
+		r.Use(use_TracingMiddleware.Tracing(traceCfg, container.Config.App.Name), use_TracingMiddleware.TraceID(traceCfg))
+	}
+
+	// 请求ID须先于访问日志注册，访问日志才能带上request_id
+	// panic恢复在访问日志之后，恢复后的500响应照常记录；处理时限与请求体限制对之后的所有处理器生效
+	r.Use(
+		use_LoggerMiddleware.RequestID(zapLogger),
+		use_LoggerMiddleware.AccessLog(&container.Config.Log.Request, zapLogger),
+		infraErrors.ErrorHandler(zapLogger),
+		use_LimitsMiddleware.Limits(&container.Config.Server.Limits),
+	)
+	if headersCfg := &container.Config.Security.Headers; headersCfg.Enabled {
+		r.Use(use_SecurityMiddleware.SecurityHeaders(headersCfg))
+	}
+
+	// 跨域须全局注册，预检请求没有对应的路由；来源等策略随配置文件重新加载
+	corsMiddleware, err := use_CorsMiddleware.New(&container.Config.Server.CORS, corsGroups)
+	if err != nil {
+		return fmt.Errorf("跨域配置无效: %w", err)
+	}
+	config.OnChange(func(cfg *config.GlobalConfig) {
+		if err := corsMiddleware.Reload(&cfg.Server.CORS); err != nil {
+			zapLogger.LogError("重新加载跨域配置失败，继续使用原有配置", zap.Error(err))
+			return
+		}
+		zapLogger.LogInfo("跨域配置已重新加载")
+	})
+	r.Use(corsMiddleware.Handler())
+	if metricsCfg := &container.Config.Metrics; metricsCfg.Enabled {
+		path := metricsCfg.Path
+		if path == "" {
+			path = "/metrics"
+		}
+		r.Use(use_MetricsMiddleware.Metrics())
+		r.GET(path, use_MetricsMiddleware.Endpoint(metricsCfg))
+	}
+
 	// 初始化所有控制器
-	userCtrl := user_controller.NewUserController(zapLogger, container.UserService)
-	adminCtrl := admin_controller.NewAdminController(container.AdminService, zapLogger)
+	userCtrl := user_controller.NewUserController(zapLogger, container.UserService, container.TokenCookies)
+	adminCtrl := admin_controller.NewAdminController(container.AdminService, container.TokenCookies, zapLogger)
 	systemCtrl := system_controller.NewSystemController(container.SystemService, zapLogger)
+	sessionCtrl := session_controller.NewSessionController(container.SessionService, container.TokenCookies, zapLogger)
+	loginHistoryCtrl := login_history_controller.NewLoginHistoryController(container.LoginHistoryService, zapLogger)
+	oauthCtrl := oauth_controller.NewOAuthController(container.OAuthService, container.TokenCookies, zapLogger)
+	oauthServerCtrl := oauth_server_controller.NewOAuthServerController(container.OAuthServerService, zapLogger)
+	permissionCtrl := permission_controller.NewPermissionController(container.PermissionService, zapLogger)
+	apiKeyCtrl := api_key_controller.NewAPIKeyController(container.APIKeyService, zapLogger)
+	impersonationCtrl := impersonation_controller.NewImpersonationController(container.ImpersonationService, zapLogger)
+	userImportCtrl := user_import_controller.NewUserImportController(container.UserImportService, zapLogger)
+	uploadCtrl := upload_controller.NewUploadController(container.UploadService, zapLogger)
+	healthCtrl := health_controller.NewHealthController(container.Health, zapLogger)
+	ipRuleCtrl := ip_rule_controller.NewIPRuleController(container.IPRuleService, zapLogger)
 
-	// 基础路由
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// 认证中间件，管理员路由与通用路由共用
+	jwtAuth := use_AuthMiddleware.JWTAuth(container.JWTConfig, container.SessionService, container.APIKeyService, container.ServiceAccountService, container.TokenCookies, container.OperationLogService, zapLogger)
+	sessionOnly := use_AuthMiddleware.RequireSession()
+	noImpersonation := use_AuthMiddleware.NoImpersonation()
+	perm := func(code string) gin.HandlerFunc {
+		return use_AuthMiddleware.RequirePermission(container.PermissionService, code, zapLogger)
+	}
+
+	// 探针，/health保留为存活探针的别名
+	r.GET("/livez", healthCtrl.Live)
+	r.GET("/readyz", healthCtrl.Ready)
+	r.GET("/health", healthCtrl.Live)
 
 	// Swagger文档配置
 	docs.SwaggerInfo.Title = "Gin-Center API"
@@ -37,6 +128,13 @@ func SetupRoutes(r *gin.Engine, container *container.Container) {
 	docs.SwaggerInfo.Host = "localhost:8080"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// 本地存储的文件访问，非公开文件需携带签名
+	if local, ok := container.Storage.(*useStorage.LocalStorage); ok {
+		files := gin.WrapH(http.StripPrefix(local.BasePath(), local))
+		r.GET(local.BasePath()+"/*filepath", files)
+		r.HEAD(local.BasePath()+"/*filepath", files)
+	}
+
 	// API v1 路由组
 	apiV1 := r.Group("/api/v1")
 	{
@@ -45,36 +143,143 @@ func SetupRoutes(r *gin.Engine, container *container.Container) {
 		{
 			authGroup.POST("/login", userCtrl.Login)
 			authGroup.POST("/register", userCtrl.Register)
+			authGroup.POST("/refresh", sessionCtrl.Refresh)
+
+			// 第三方登录
+			authGroup.GET("/oauth/providers", oauthCtrl.ListProviders)
+			authGroup.GET("/oauth/:provider/authorize", oauthCtrl.Authorize)
+			authGroup.GET("/oauth/:provider/callback", use_AuthMiddleware.OptionalSession(container.JWTConfig, container.SessionService, container.TokenCookies), oauthCtrl.Callback)
+		}
+
+		// OAuth2授权服务端点，客户端自行认证
+		oauthServerGroup := apiV1.Group("/oauth")
+		{
+			oauthServerGroup.POST("/token", oauthServerCtrl.Token)
+			oauthServerGroup.POST("/introspect", oauthServerCtrl.Introspect)
+			oauthServerGroup.POST("/revoke", oauthServerCtrl.Revoke)
+			oauthServerGroup.GET("/userinfo", oauthServerCtrl.UserInfo)
+			oauthServerGroup.POST("/userinfo", oauthServerCtrl.UserInfo)
 		}
 
+		// 管理端访问控制先于认证执行，管理员登录同样受限
+		adminIPFilter := use_IPFilterMiddleware.IPFilter(container.IPRuleService, IPRuleModel.GroupAdmin, container.OperationLogService, zapLogger)
+
+		// 管理员登录
+		apiV1.POST("/admin/login", adminIPFilter, adminCtrl.Login)
+
 		// 管理员专属路由
 		adminGroup := apiV1.Group("/admin")
-		adminGroup.Use(use_AuthMiddleware.AdminAuth(zapLogger))
+		adminGroup.Use(adminIPFilter, jwtAuth, use_AuthMiddleware.AdminAuth(zapLogger))
 		{
-			adminGroup.GET("/users", adminCtrl.PaginateAdmins)
-			adminGroup.GET("/profile", adminCtrl.GetAdminInfo)
-			adminGroup.PUT("/profile", adminCtrl.UpdateAdmin)
+			adminGroup.GET("/profile", sessionOnly, adminCtrl.GetAdminInfo)
+			adminGroup.PUT("/profile", sessionOnly, adminCtrl.UpdateAdmin)
+
+			// 管理员账号管理，创建管理员仅限超级管理员
+			adminGroup.GET("/admins", perm(PermissionModel.PermUserRead), adminCtrl.PaginateAdmins)
+			adminGroup.POST("/admins", sessionOnly, use_AuthMiddleware.SuperAuth(container.Config), adminCtrl.Register)
+
+			// 普通用户管理
+			adminGroup.GET("/users", perm(PermissionModel.PermUserRead), userCtrl.ListUsers)
+			adminGroup.POST("/users", perm(PermissionModel.PermUserWrite), userCtrl.CreateUser)
+			adminGroup.GET("/users/:id", perm(PermissionModel.PermUserRead), userCtrl.GetUser)
+			adminGroup.DELETE("/users/:id", perm(PermissionModel.PermUserWrite), userCtrl.DeleteUser)
+			adminGroup.PUT("/users/:id/status", perm(PermissionModel.PermUserWrite), userCtrl.UpdateUserStatus)
+			adminGroup.PUT("/users/:id/password", perm(PermissionModel.PermUserWrite), userCtrl.ResetUserPassword)
+
+			// 用户批量导入导出
+			adminGroup.POST("/users/import", perm(PermissionModel.PermUserWrite), userImportCtrl.Import)
+			adminGroup.GET("/users/import/:job_id", perm(PermissionModel.PermUserWrite), userImportCtrl.GetImportJob)
+			adminGroup.GET("/users/export", perm(PermissionModel.PermUserRead), userImportCtrl.Export)
+
+			// 用户会话管理
+			adminGroup.GET("/users/:id/sessions", perm(PermissionModel.PermUserRead), sessionCtrl.ListUserSessions)
+			adminGroup.DELETE("/users/:id/sessions", perm(PermissionModel.PermUserWrite), sessionCtrl.RevokeUserSessions)
+			adminGroup.DELETE("/users/:id/sessions/:sid", perm(PermissionModel.PermUserWrite), sessionCtrl.RevokeUserSession)
+			adminGroup.GET("/users/:id/login-history", perm(PermissionModel.PermUserRead), loginHistoryCtrl.ListUserLoginHistory)
+			adminGroup.GET("/online-users", perm(PermissionModel.PermUserRead), sessionCtrl.ListOnlineUsers)
+			adminGroup.POST("/users/:id/impersonate", sessionOnly, perm(PermissionModel.PermUserImpersonate), impersonationCtrl.Impersonate)
+
+			// 权限管理
+			adminGroup.GET("/permissions", perm(PermissionModel.PermPermissionManage), permissionCtrl.ListPermissions)
+			adminGroup.GET("/users/:id/permissions", perm(PermissionModel.PermPermissionManage), permissionCtrl.ListUserPermissions)
+			adminGroup.PUT("/users/:id/permissions", perm(PermissionModel.PermPermissionManage), permissionCtrl.SetUserPermissions)
+			// 管理员的显式权限仅超级管理员可授予，避免管理员为自己提权
+			adminGroup.GET("/admins/:id/permissions", perm(PermissionModel.PermPermissionManage), permissionCtrl.ListAdminPermissions)
+			adminGroup.PUT("/admins/:id/permissions", sessionOnly, use_AuthMiddleware.SuperAuth(container.Config), permissionCtrl.SetAdminPermissions)
+
+			// 授权服务客户端管理
+			oauthClients := adminGroup.Group("/oauth/clients", perm(PermissionModel.PermOAuthClientManage))
+			{
+				oauthClients.GET("", oauthServerCtrl.ListClients)
+				oauthClients.POST("", oauthServerCtrl.CreateClient)
+				oauthClients.PUT("/:id", oauthServerCtrl.UpdateClient)
+				oauthClients.DELETE("/:id", oauthServerCtrl.DeleteClient)
+				oauthClients.POST("/:id/secret", oauthServerCtrl.RotateSecret)
+			}
+
+			// 访问控制规则管理
+			ipRules := adminGroup.Group("/ip-rules", perm(PermissionModel.PermIPRuleManage))
+			{
+				ipRules.GET("", ipRuleCtrl.List)
+				ipRules.POST("", ipRuleCtrl.Create)
+				ipRules.DELETE("/:id", ipRuleCtrl.Delete)
+			}
 		}
 
 		// 需要JWT认证的通用路由
 		authRequired := apiV1.Group("")
-		authRequired.Use(use_AuthMiddleware.JWTAuth(container.Config, zapLogger))
+		authRequired.Use(jwtAuth)
 		{
-			// 用户个人中心
-			userCenter := authRequired.Group("/user")
+			authRequired.POST("/auth/logout", sessionOnly, sessionCtrl.Logout)
+			authRequired.GET("/auth/me", impersonationCtrl.WhoAmI)
+
+			// 用户确认向第三方应用授权
+			authRequired.GET("/oauth/authorize", sessionOnly, oauthServerCtrl.PreviewAuthorization)
+			authRequired.POST("/oauth/authorize", sessionOnly, noImpersonation, oauthServerCtrl.Authorize)
+
+			// 用户个人中心，仅限登录会话访问
+			userCenter := authRequired.Group("/user", sessionOnly)
 			{
 				userCenter.GET("/profile", userCtrl.GetProfile)
 				userCenter.PUT("/profile", userCtrl.UpdateProfile)
+				userCenter.PUT("/password", noImpersonation, userCtrl.ChangePassword)
 				userCenter.POST("/avatar", userCtrl.UploadAvatar)
+				userCenter.GET("/sessions", sessionCtrl.ListMySessions)
+				userCenter.DELETE("/sessions/:id", sessionCtrl.RevokeMySession)
+				userCenter.GET("/identities", oauthCtrl.ListIdentities)
+				userCenter.POST("/identities/:provider", noImpersonation, oauthCtrl.Link)
+				userCenter.DELETE("/identities/:provider", noImpersonation, oauthCtrl.Unlink)
+				userCenter.GET("/permissions", permissionCtrl.ListMyPermissions)
+				userCenter.GET("/api-keys", apiKeyCtrl.List)
+				userCenter.POST("/api-keys", noImpersonation, apiKeyCtrl.Create)
+				userCenter.DELETE("/api-keys/:id", noImpersonation, apiKeyCtrl.Revoke)
 			}
 
-			// 系统管理接口
-			systemGroup := authRequired.Group("/system")
+			// 分片上传与用户文件，与个人中心一样仅限登录会话，API密钥无论授权范围如何都不能访问
+			uploads := authRequired.Group("/uploads", sessionOnly)
 			{
-				systemGroup.GET("/config", systemCtrl.GetSystemConfig)
-				systemGroup.PUT("/config", systemCtrl.UpdateSystemConfig)
-				systemGroup.GET("/metrics", systemCtrl.GetSystemMetrics)
+				uploads.POST("", uploadCtrl.Create)
+				uploads.GET("/:id", uploadCtrl.Get)
+				uploads.PUT("/:id/chunks/:index", uploadCtrl.UploadChunk)
+				uploads.POST("/:id/complete", uploadCtrl.Complete)
+				uploads.DELETE("/:id", uploadCtrl.Abort)
 			}
+			authRequired.GET("/files", sessionOnly, uploadCtrl.ListFiles)
+			authRequired.GET("/files/:id", sessionOnly, uploadCtrl.GetFile)
+			authRequired.DELETE("/files/:id", sessionOnly, uploadCtrl.DeleteFile)
+		}
+
+		// 系统管理接口，与管理端使用同一组访问控制规则，同样先于认证执行
+		systemGroup := apiV1.Group("/system", adminIPFilter, jwtAuth)
+		{
+			systemGroup.GET("/config", perm(PermissionModel.PermSystemRead), systemCtrl.GetSystemConfig)
+			systemGroup.PUT("/config", perm(PermissionModel.PermSystemWrite), systemCtrl.UpdateSystemConfig)
+			systemGroup.GET("/metrics", perm(PermissionModel.PermSystemRead), systemCtrl.GetSystemMetrics)
+			systemGroup.GET("/info", perm(PermissionModel.PermSystemRead), systemCtrl.GetSystemInfo)
+			systemGroup.GET("/health", perm(PermissionModel.PermSystemRead), systemCtrl.GetSystemHealth)
+			systemGroup.GET("/log-level", perm(PermissionModel.PermSystemRead), systemCtrl.GetLogLevel)
+			systemGroup.PUT("/log-level", perm(PermissionModel.PermSystemWrite), systemCtrl.SetLogLevel)
 		}
 	}
+	return nil
 }